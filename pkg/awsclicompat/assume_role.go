@@ -0,0 +1,164 @@
+package awsclicompat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AssumeRole is a role to be assumed by the provider
+type AssumeRole struct {
+	RoleARN     string
+	ExternalID  string
+	SessionName string
+}
+
+func (c *Config) assumeRoleChain() []AssumeRole {
+	if c == nil {
+		return nil
+	}
+
+	return c.AssumeRoleChain
+}
+
+// AssumesRoles returns true when either the provider or the resource with the roles is configured to assume roles.
+// In that case, the subprocesses like eksctl must use the temporary credentials from EnvironForAssumeRoles,
+// rather than the profile.
+func (c *Config) AssumesRoles(roles []AssumeRole) bool {
+	return len(c.assumeRoleChain())+len(roles) > 0
+}
+
+func (c *Config) withAssumeRoleChain(sess *session.Session, profile string, roles []AssumeRole) *session.Session {
+	chain := append(append([]AssumeRole{}, c.assumeRoleChain()...), roles...)
+
+	if len(chain) == 0 {
+		return sess
+	}
+
+	// Without a config to cache the credentials in, the roles are assumed per session
+	if c == nil {
+		return sess.Copy(&aws.Config{Credentials: assumeRoleChainCredentials(sess, chain)})
+	}
+
+	c.chainedCredentialsMu.Lock()
+	defer c.chainedCredentialsMu.Unlock()
+
+	if c.chainedCredentials == nil {
+		c.chainedCredentials = map[string]*credentials.Credentials{}
+	}
+
+	key := assumeRoleChainKey(profile, chain)
+
+	creds, ok := c.chainedCredentials[key]
+	if !ok {
+		creds = assumeRoleChainCredentials(sess, chain)
+
+		c.chainedCredentials[key] = creds
+	}
+
+	return sess.Copy(&aws.Config{Credentials: creds})
+}
+
+// assumeRoleChainCredentials returns the credentials obtained by assuming the roles in order,
+// each with the credentials of the previous role
+func assumeRoleChainCredentials(sess *session.Session, chain []AssumeRole) *credentials.Credentials {
+	var creds *credentials.Credentials
+
+	base := sess
+
+	for _, r := range chain {
+		r := r
+
+		creds = stscreds.NewCredentials(base, r.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if r.ExternalID != "" {
+				p.ExternalID = aws.String(r.ExternalID)
+			}
+
+			if r.SessionName != "" {
+				p.RoleSessionName = r.SessionName
+			}
+		})
+
+		base = base.Copy(&aws.Config{Credentials: creds})
+	}
+
+	return creds
+}
+
+func assumeRoleChainKey(profile string, chain []AssumeRole) string {
+	key := profile
+
+	for _, r := range chain {
+		key += "|" + r.RoleARN + "," + r.ExternalID + "," + r.SessionName
+	}
+
+	return key
+}
+
+// EnvironForProfile returns the environment variables for subprocesses that operate on behalf of the profile.
+// When the provider is configured to assume a chain of roles, the resulting temporary credentials are
+// injected in place of any other credential sources, so that the subprocesses operate in the same account as the provider.
+// Otherwise the profile is passed via AWS_PROFILE, so that `aws eks get-token` run by kubectl and any other commands
+// resolve the credentials from the same profile, including ones from `credential_process`.
+func (c *Config) EnvironForProfile(region, profile string) ([]string, error) {
+	return c.EnvironForAssumeRoles(region, profile, nil)
+}
+
+// EnvironForAssumeRoles is EnvironForProfile that injects the temporary credentials obtained by additionally
+// assuming the roles of the resource.
+func (c *Config) EnvironForAssumeRoles(region, profile string, roles []AssumeRole) ([]string, error) {
+	env := c.endpoints().environ(c.Environ(), region)
+
+	if !c.AssumesRoles(roles) {
+		return withProfile(env, profile), nil
+	}
+
+	creds, err := c.NewSessionWithAssumeRoles(region, profile, roles).Config.Credentials.Get()
+	if err != nil {
+		return nil, fmt.Errorf("assuming roles: %w", err)
+	}
+
+	var result []string
+
+	for _, kv := range env {
+		switch strings.SplitN(kv, "=", 2)[0] {
+		case "AWS_PROFILE", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", envWebIdentityTokenFile, envRoleARN, envRoleSessionName:
+			continue
+		}
+
+		result = append(result, kv)
+	}
+
+	result = append(result,
+		"AWS_ACCESS_KEY_ID="+creds.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY="+creds.SecretAccessKey,
+		"AWS_SESSION_TOKEN="+creds.SessionToken,
+	)
+
+	return result, nil
+}
+
+func withProfile(env []string, profile string) []string {
+	if profile == "" {
+		return env
+	}
+
+	var result []string
+
+	for _, kv := range env {
+		switch strings.SplitN(kv, "=", 2)[0] {
+		case "AWS_PROFILE", "AWS_SDK_LOAD_CONFIG":
+			continue
+		}
+
+		result = append(result, kv)
+	}
+
+	// AWS_SDK_LOAD_CONFIG makes Go programs built with older AWS SDKs read ~/.aws/config,
+	// which is where `credential_process` and `role_arn` are configured
+	return append(result, "AWS_PROFILE="+profile, "AWS_SDK_LOAD_CONFIG=1")
+}
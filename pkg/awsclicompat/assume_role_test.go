@@ -0,0 +1,104 @@
+package awsclicompat
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSTS serves AssumeRole with the credentials numbered in the order of the calls
+func fakeSTS(t *testing.T) (*httptest.Server, func() []string) {
+	var (
+		mu      sync.Mutex
+		assumed []string
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		assumed = append(assumed, r.Form.Get("RoleArn")+","+r.Form.Get("ExternalId"))
+		n := len(assumed)
+		mu.Unlock()
+
+		fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>AKID%d</AccessKeyId>
+      <SecretAccessKey>SECRET%d</SecretAccessKey>
+      <SessionToken>TOKEN%d</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`, n, n, n)
+	}))
+
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string{}, assumed...)
+	}
+}
+
+func TestEnvironForAssumeRoles(t *testing.T) {
+	s, assumed := fakeSTS(t)
+	defer s.Close()
+
+	for k, v := range map[string]string{
+		"AWS_ACCESS_KEY_ID":     "BASE",
+		"AWS_SECRET_ACCESS_KEY": "BASESECRET",
+		"AWS_PROFILE":           "",
+	} {
+		prev, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		if ok {
+			defer os.Setenv(k, prev)
+		} else {
+			defer os.Unsetenv(k)
+		}
+	}
+
+	c := &Config{
+		Endpoints:       Endpoints{STS: s.URL},
+		AssumeRoleChain: []AssumeRole{{RoleARN: "arn:aws:iam::111111111111:role/hub"}},
+	}
+
+	assert.True(t, c.AssumesRoles(nil))
+
+	roles := []AssumeRole{{RoleARN: "arn:aws:iam::222222222222:role/spoke", ExternalID: "ext"}}
+
+	env, err := c.EnvironForAssumeRoles("us-east-1", "", roles)
+	require.NoError(t, err)
+
+	// The resource's roles are assumed after the provider's ones, with the credentials of the previous role
+	assert.Equal(t, []string{"arn:aws:iam::111111111111:role/hub,", "arn:aws:iam::222222222222:role/spoke,ext"}, assumed())
+
+	assert.Contains(t, env, "AWS_ACCESS_KEY_ID=AKID2")
+	assert.Contains(t, env, "AWS_SECRET_ACCESS_KEY=SECRET2")
+	assert.Contains(t, env, "AWS_SESSION_TOKEN=TOKEN2")
+	assert.NotContains(t, env, "AWS_ACCESS_KEY_ID=BASE")
+
+	// The credentials are cached per chain
+	_, err = c.EnvironForAssumeRoles("us-east-1", "", roles)
+	require.NoError(t, err)
+	assert.Len(t, assumed(), 2)
+}
+
+func TestEnvironForProfile_withoutAssumeRoles(t *testing.T) {
+	assert.False(t, (*Config)(nil).AssumesRoles(nil))
+	assert.True(t, (*Config)(nil).AssumesRoles([]AssumeRole{{RoleARN: "arn:aws:iam::222222222222:role/spoke"}}))
+	assert.False(t, (&Config{}).AssumesRoles(nil))
+
+	env, err := (*Config)(nil).EnvironForProfile("us-east-1", "myprofile")
+	require.NoError(t, err)
+
+	assert.Contains(t, env, "AWS_PROFILE=myprofile")
+	assert.Contains(t, env, "AWS_SDK_LOAD_CONFIG=1")
+}
@@ -0,0 +1,59 @@
+package awsclicompat
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// Config is the configuration of a provider instance that applies to both the AWS sessions and the environment of
+// subprocesses like eksctl and kubectl.
+//
+// Each provider instance, including aliased ones, has its own Config, so that they never see each other's settings.
+// A nil Config is valid and leaves everything to the environment.
+type Config struct {
+	Proxy Proxy
+
+	// Endpoints are used for both the AWS sessions created by NewSession and the environment of subprocesses
+	// returned by EnvironForProfile
+	Endpoints Endpoints
+
+	// AssumeRoleChain is the chain of roles assumed in order, like a hub account role and then a spoke account role,
+	// on top of the base credentials
+	AssumeRoleChain []AssumeRole
+
+	proxyHTTPClientOnce sync.Once
+	// proxyHTTPClient is shared across sessions so that connections to the proxy are reused
+	proxyHTTPClient *http.Client
+
+	chainedCredentialsMu sync.Mutex
+	// chainedCredentials caches the credentials obtained by assuming the chain of roles per base profile and chain,
+	// so that the roles aren't assumed on every AWS API call and command.
+	// The cached credentials are refreshed on expiry by the AWS SDK.
+	chainedCredentials map[string]*credentials.Credentials
+}
+
+func (c *Config) proxy() Proxy {
+	if c == nil {
+		return Proxy{}
+	}
+
+	return c.Proxy
+}
+
+// httpClient returns the client that sends the AWS API calls via the proxy, or nil when no proxy is configured
+func (c *Config) httpClient() *http.Client {
+	if !c.proxy().configured() {
+		return nil
+	}
+
+	c.proxyHTTPClientOnce.Do(func() {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = c.Proxy.proxyURL
+
+		c.proxyHTTPClient = &http.Client{Transport: t}
+	})
+
+	return c.proxyHTTPClient
+}
@@ -0,0 +1,166 @@
+package awsclicompat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// Endpoints are the custom AWS service endpoints used by both the AWS SDK and the subprocesses like eksctl and kubectl,
+// for regulated environments that require VPC endpoints or FIPS 140-2 validated endpoints.
+type Endpoints struct {
+	EKS            string
+	STS            string
+	ELBV2          string
+	CloudFormation string
+
+	// UseFIPS makes the services without custom endpoints use their FIPS endpoints
+	UseFIPS bool
+
+	// STSRegionalEndpoints is either "regional" or "legacy", like AWS_STS_REGIONAL_ENDPOINTS.
+	// "regional" is required where the global STS endpoint is unreachable, like in restricted VPCs
+	STSRegionalEndpoints string
+}
+
+// endpointServices are the services whose endpoints are customizable, along with the envvars read by
+// eksctl and by newer AWS SDKs and the AWS CLI
+var endpointServices = []struct {
+	id, eksctlEnv, sdkEnv string
+	get                   func(Endpoints) string
+}{
+	{eks.EndpointsID, "AWS_EKS_ENDPOINT", "AWS_ENDPOINT_URL_EKS", func(e Endpoints) string { return e.EKS }},
+	{endpoints.StsServiceID, "AWS_STS_ENDPOINT", "AWS_ENDPOINT_URL_STS", func(e Endpoints) string { return e.STS }},
+	{endpoints.ElasticloadbalancingServiceID, "AWS_ELBV2_ENDPOINT", "AWS_ENDPOINT_URL_ELASTIC_LOAD_BALANCING_V2", func(e Endpoints) string { return e.ELBV2 }},
+	{endpoints.CloudformationServiceID, "AWS_CLOUDFORMATION_ENDPOINT", "AWS_ENDPOINT_URL_CLOUDFORMATION", func(e Endpoints) string { return e.CloudFormation }},
+}
+
+const (
+	envUseFIPSEndpoint      = "AWS_USE_FIPS_ENDPOINT"
+	envSTSRegionalEndpoints = "AWS_STS_REGIONAL_ENDPOINTS"
+)
+
+func (c *Config) endpoints() Endpoints {
+	if c == nil {
+		return Endpoints{}
+	}
+
+	return c.Endpoints
+}
+
+func (e Endpoints) configured() bool {
+	return e.UseFIPS || e.STSRegionalEndpoints != "" || e.EKS != "" || e.STS != "" || e.ELBV2 != "" || e.CloudFormation != ""
+}
+
+// endpointFor returns the custom or FIPS endpoint for the service in the region, or an empty string to use the default one
+func (e Endpoints) endpointFor(service, region string) string {
+	for _, s := range endpointServices {
+		if s.id != service {
+			continue
+		}
+
+		if url := s.get(e); url != "" {
+			return url
+		}
+
+		if e.UseFIPS && region != "" {
+			return fipsEndpoint(service, region)
+		}
+	}
+
+	return ""
+}
+
+// fipsEndpoint returns the FIPS endpoint like https://fips.eks.us-east-1.amazonaws.com and
+// https://sts-fips.us-east-1.amazonaws.com, as the AWS SDK in use has no built-in support for FIPS endpoints.
+func fipsEndpoint(service, region string) string {
+	suffix := "amazonaws.com"
+
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		suffix = p.DNSSuffix()
+	}
+
+	host := service + "-fips"
+	if service == eks.EndpointsID {
+		host = "fips." + service
+	}
+
+	return fmt.Sprintf("https://%s.%s.%s", host, region, suffix)
+}
+
+// stsRegionalEndpoint returns the setting for aws.Config
+func (e Endpoints) stsRegionalEndpoint() endpoints.STSRegionalEndpoint {
+	switch e.STSRegionalEndpoints {
+	case "regional":
+		return endpoints.RegionalSTSEndpoint
+	case "legacy":
+		return endpoints.LegacySTSEndpoint
+	}
+
+	return endpoints.UnsetSTSEndpoint
+}
+
+// EndpointFor implements endpoints.Resolver
+func (e Endpoints) EndpointFor(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+	if url := e.endpointFor(service, region); url != "" {
+		return endpoints.ResolvedEndpoint{
+			URL:           url,
+			SigningRegion: region,
+			SigningMethod: "v4",
+		}, nil
+	}
+
+	return endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+}
+
+// environ sets the endpoints for both eksctl and the tools built with newer AWS SDKs, like the AWS CLI run by kubectl
+// to get the cluster token
+func (e Endpoints) environ(env []string, region string) []string {
+	if !e.configured() {
+		return env
+	}
+
+	vars := map[string]string{}
+
+	for _, s := range endpointServices {
+		if url := e.endpointFor(s.id, region); url != "" {
+			vars[s.eksctlEnv] = url
+			vars[s.sdkEnv] = url
+		}
+	}
+
+	if e.UseFIPS {
+		vars[envUseFIPSEndpoint] = "true"
+	}
+
+	if e.STSRegionalEndpoints != "" {
+		vars[envSTSRegionalEndpoints] = e.STSRegionalEndpoints
+	}
+
+	var result []string
+
+	for _, kv := range env {
+		if _, ok := vars[strings.SplitN(kv, "=", 2)[0]]; ok {
+			continue
+		}
+
+		result = append(result, kv)
+	}
+
+	for _, s := range endpointServices {
+		for _, name := range []string{s.eksctlEnv, s.sdkEnv} {
+			if v, ok := vars[name]; ok {
+				result = append(result, name+"="+v)
+			}
+		}
+	}
+
+	for _, name := range []string{envUseFIPSEndpoint, envSTSRegionalEndpoints} {
+		if v, ok := vars[name]; ok {
+			result = append(result, name+"="+v)
+		}
+	}
+
+	return result
+}
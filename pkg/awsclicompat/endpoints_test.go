@@ -0,0 +1,70 @@
+package awsclicompat
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoints(t *testing.T) {
+	e := Endpoints{
+		EKS:     "https://vpce-1.eks.us-gov-west-1.vpce.amazonaws.com",
+		UseFIPS: true,
+	}
+
+	r, err := e.EndpointFor("eks", "us-gov-west-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://vpce-1.eks.us-gov-west-1.vpce.amazonaws.com", r.URL)
+
+	r, err = e.EndpointFor("sts", "us-gov-west-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://sts-fips.us-gov-west-1.amazonaws.com", r.URL)
+
+	r, err = e.EndpointFor("ec2", "us-gov-west-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ec2.us-gov-west-1.amazonaws.com", r.URL)
+
+	assert.Equal(t, "https://fips.eks.us-east-1.amazonaws.com", fipsEndpoint("eks", "us-east-1"))
+
+	assert.Equal(t, []string{
+		"FOO=bar",
+		"AWS_EKS_ENDPOINT=https://vpce-1.eks.us-gov-west-1.vpce.amazonaws.com",
+		"AWS_ENDPOINT_URL_EKS=https://vpce-1.eks.us-gov-west-1.vpce.amazonaws.com",
+		"AWS_STS_ENDPOINT=https://sts-fips.us-gov-west-1.amazonaws.com",
+		"AWS_ENDPOINT_URL_STS=https://sts-fips.us-gov-west-1.amazonaws.com",
+		"AWS_ELBV2_ENDPOINT=https://elasticloadbalancing-fips.us-gov-west-1.amazonaws.com",
+		"AWS_ENDPOINT_URL_ELASTIC_LOAD_BALANCING_V2=https://elasticloadbalancing-fips.us-gov-west-1.amazonaws.com",
+		"AWS_CLOUDFORMATION_ENDPOINT=https://cloudformation-fips.us-gov-west-1.amazonaws.com",
+		"AWS_ENDPOINT_URL_CLOUDFORMATION=https://cloudformation-fips.us-gov-west-1.amazonaws.com",
+		"AWS_USE_FIPS_ENDPOINT=true",
+	}, e.environ([]string{"FOO=bar", "AWS_EKS_ENDPOINT=https://old"}, "us-gov-west-1"))
+
+	assert.Equal(t, []string{"FOO=bar"}, Endpoints{}.environ([]string{"FOO=bar"}, "us-east-1"))
+}
+
+func TestEndpoints_stsRegionalEndpoints(t *testing.T) {
+	e := Endpoints{STSRegionalEndpoints: "regional"}
+
+	assert.Equal(t, endpoints.RegionalSTSEndpoint, e.stsRegionalEndpoint())
+	assert.Equal(t, []string{"FOO=bar", "AWS_STS_REGIONAL_ENDPOINTS=regional"}, e.environ([]string{"AWS_STS_REGIONAL_ENDPOINTS=legacy", "FOO=bar"}, "us-east-1"))
+
+	r, err := e.EndpointFor("sts", "us-east-1", func(o *endpoints.Options) { o.STSRegionalEndpoint = e.stsRegionalEndpoint() })
+	assert.NoError(t, err)
+	assert.Equal(t, "https://sts.us-east-1.amazonaws.com", r.URL)
+
+	assert.Equal(t, endpoints.UnsetSTSEndpoint, Endpoints{}.stsRegionalEndpoint())
+}
+
+func TestConfig_endpoints(t *testing.T) {
+	c := &Config{Endpoints: Endpoints{EKS: "https://vpce-1.eks.us-east-1.vpce.amazonaws.com"}}
+
+	r, err := c.NewSession("us-east-1", "").Config.EndpointResolver.EndpointFor("eks", "us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://vpce-1.eks.us-east-1.vpce.amazonaws.com", r.URL)
+
+	// Another provider instance never sees the endpoints of the config
+	r, err = (&Config{}).NewSession("us-east-1", "").Config.EndpointResolver.EndpointFor("eks", "us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://eks.us-east-1.amazonaws.com", r.URL)
+}
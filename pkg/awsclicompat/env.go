@@ -0,0 +1,61 @@
+package awsclicompat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	envWebIdentityTokenFile = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	envRoleARN              = "AWS_ROLE_ARN"
+	envRoleSessionName      = "AWS_ROLE_SESSION_NAME"
+
+	defaultRoleSessionName = "terraform-provider-eksctl"
+)
+
+// Environ returns the environment variables for subprocesses like eksctl and kubectl,
+// so that they resolve the same AWS credentials as the provider.
+//
+// The provider and its subprocesses can run without static credentials at all.
+// Web identity tokens (IRSA when Terraform runs within a Kubernetes cluster) are passed through with an absolute
+// token file path, so that it keeps working for subprocesses run in a different working directory.
+// EC2 instance profiles, including IMDSv2, are resolved by each process on its own and require nothing to be passed.
+//
+// The proxy of the config overrides the proxy envvars.
+func (c *Config) Environ() []string {
+	return c.proxy().environ(environ(os.Environ()))
+}
+
+func environ(env []string) []string {
+	var (
+		result                     []string
+		hasRoleARN, hasSessionName bool
+		tokenFile                  string
+	)
+
+	for _, kv := range env {
+		switch {
+		case strings.HasPrefix(kv, envWebIdentityTokenFile+"="):
+			tokenFile = strings.TrimPrefix(kv, envWebIdentityTokenFile+"=")
+
+			if abs, err := filepath.Abs(tokenFile); tokenFile != "" && err == nil {
+				kv = envWebIdentityTokenFile + "=" + abs
+			}
+		case strings.HasPrefix(kv, envRoleARN+"="):
+			hasRoleARN = strings.TrimPrefix(kv, envRoleARN+"=") != ""
+		case strings.HasPrefix(kv, envRoleSessionName+"="):
+			hasSessionName = strings.TrimPrefix(kv, envRoleSessionName+"=") != ""
+		}
+
+		result = append(result, kv)
+	}
+
+	// Some AWS SDKs, including older ones embedded in eksctl, fail to assume the role with the web identity
+	// when the session name is missing
+	if tokenFile != "" && hasRoleARN && !hasSessionName {
+		result = append(result, envRoleSessionName+"="+defaultRoleSessionName)
+	}
+
+	return result
+}
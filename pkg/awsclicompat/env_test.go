@@ -0,0 +1,42 @@
+package awsclicompat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnviron_webIdentity(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := environ([]string{
+		"AWS_WEB_IDENTITY_TOKEN_FILE=token",
+		"AWS_ROLE_ARN=arn:aws:iam::123456789012:role/terraform",
+		"FOO=bar",
+	})
+
+	assert.Equal(t, []string{
+		"AWS_WEB_IDENTITY_TOKEN_FILE=" + filepath.Join(wd, "token"),
+		"AWS_ROLE_ARN=arn:aws:iam::123456789012:role/terraform",
+		"FOO=bar",
+		"AWS_ROLE_SESSION_NAME=terraform-provider-eksctl",
+	}, env)
+}
+
+func TestEnviron_noWebIdentity(t *testing.T) {
+	assert.Equal(t, []string{"FOO=bar"}, environ([]string{"FOO=bar"}))
+}
+
+func TestWithProfile(t *testing.T) {
+	assert.Equal(t, []string{"FOO=bar"}, withProfile([]string{"FOO=bar"}, ""))
+
+	assert.Equal(t,
+		[]string{"FOO=bar", "AWS_PROFILE=okta", "AWS_SDK_LOAD_CONFIG=1"},
+		withProfile([]string{"AWS_PROFILE=default", "FOO=bar", "AWS_SDK_LOAD_CONFIG=0"}, "okta"),
+	)
+}
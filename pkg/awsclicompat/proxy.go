@@ -0,0 +1,108 @@
+package awsclicompat
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy is the HTTP(S) proxy used by both the AWS SDK and the subprocesses like eksctl and kubectl,
+// for environments where the AWS APIs and the cluster endpoints are reachable only via a proxy.
+type Proxy struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+func (p Proxy) configured() bool {
+	return p.HTTPProxy != "" || p.HTTPSProxy != ""
+}
+
+// environ overrides the proxy envvars in both upper and lower cases, as tools differ in which ones they read
+func (p Proxy) environ(env []string) []string {
+	if !p.configured() {
+		return env
+	}
+
+	vars := map[string]string{
+		"HTTP_PROXY":  p.HTTPProxy,
+		"HTTPS_PROXY": p.HTTPSProxy,
+		"NO_PROXY":    p.NoProxy,
+	}
+
+	var result []string
+
+	for _, kv := range env {
+		name := strings.ToUpper(strings.SplitN(kv, "=", 2)[0])
+		if _, ok := vars[name]; ok {
+			continue
+		}
+
+		result = append(result, kv)
+	}
+
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+		if v := vars[name]; v != "" {
+			result = append(result, name+"="+v, strings.ToLower(name)+"="+v)
+		}
+	}
+
+	return result
+}
+
+// proxyURL returns the proxy for the request in the same way as HTTP_PROXY, HTTPS_PROXY, and NO_PROXY are interpreted by Go.
+func (p Proxy) proxyURL(req *http.Request) (*url.URL, error) {
+	proxy := p.HTTPProxy
+	if req.URL.Scheme == "https" {
+		proxy = p.HTTPSProxy
+	}
+
+	if proxy == "" || p.bypass(req.URL.Hostname()) {
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		// Allow proxies without the scheme like `proxy.example.com:3128`
+		return url.Parse("http://" + proxy)
+	}
+
+	return u, nil
+}
+
+func (p Proxy) bypass(host string) bool {
+	ip := net.ParseIP(host)
+
+	for _, e := range strings.Split(p.NoProxy, ",") {
+		e = strings.TrimSpace(e)
+
+		switch {
+		case e == "":
+			continue
+		case e == "*":
+			return true
+		}
+
+		if _, cidr, err := net.ParseCIDR(e); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		if h, _, err := net.SplitHostPort(e); err == nil {
+			e = h
+		}
+
+		e = strings.TrimPrefix(e, "*")
+		e = strings.TrimPrefix(e, ".")
+
+		if host == e || strings.HasSuffix(host, "."+e) {
+			return true
+		}
+	}
+
+	return false
+}
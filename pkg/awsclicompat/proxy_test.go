@@ -0,0 +1,57 @@
+package awsclicompat
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxy(t *testing.T) {
+	p := Proxy{
+		HTTPSProxy: "proxy.example.com:3128",
+		NoProxy:    "169.254.169.254,.internal.example.com,10.0.0.0/8",
+	}
+
+	proxyFor := func(rawurl string) string {
+		u, err := url.Parse(rawurl)
+		assert.NoError(t, err)
+
+		proxy, err := p.proxyURL(&http.Request{URL: u})
+		assert.NoError(t, err)
+
+		if proxy == nil {
+			return ""
+		}
+
+		return proxy.String()
+	}
+
+	assert.Equal(t, "http://proxy.example.com:3128", proxyFor("https://eks.us-east-1.amazonaws.com"))
+	assert.Equal(t, "", proxyFor("http://eks.us-east-1.amazonaws.com"))
+	assert.Equal(t, "", proxyFor("https://api.internal.example.com"))
+	assert.Equal(t, "", proxyFor("https://10.1.2.3:443"))
+	assert.Equal(t, "", proxyFor("http://169.254.169.254/latest/meta-data"))
+
+	assert.Equal(t, []string{
+		"FOO=bar",
+		"HTTPS_PROXY=proxy.example.com:3128",
+		"https_proxy=proxy.example.com:3128",
+		"NO_PROXY=169.254.169.254,.internal.example.com,10.0.0.0/8",
+		"no_proxy=169.254.169.254,.internal.example.com,10.0.0.0/8",
+	}, p.environ([]string{"https_proxy=old", "FOO=bar", "HTTP_PROXY=old"}))
+}
+
+func TestConfig_proxy(t *testing.T) {
+	c := &Config{Proxy: Proxy{HTTPSProxy: "proxy.example.com:3128"}}
+
+	assert.Same(t, c.httpClient(), c.httpClient(), "the client should be shared across sessions")
+	assert.Contains(t, c.Environ(), "HTTPS_PROXY=proxy.example.com:3128")
+
+	var none *Config
+
+	assert.Nil(t, none.httpClient())
+	assert.Nil(t, (&Config{}).httpClient())
+	assert.NotContains(t, none.Environ(), "HTTPS_PROXY=proxy.example.com:3128")
+}
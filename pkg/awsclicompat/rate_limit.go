@@ -0,0 +1,121 @@
+package awsclicompat
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// RateLimit limits the rate of the AWS API calls made by all the sessions created by NewSession,
+// so that large workspaces with dozens of clusters don't exhaust the account's API quotas during refresh.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate. Zero disables rate limiting
+	RequestsPerSecond float64
+	// Burst is the number of requests allowed at once. Defaults to RequestsPerSecond rounded up
+	Burst int
+}
+
+var (
+	rateLimiterMu sync.RWMutex
+	rateLimiter   *tokenBucket
+)
+
+// SetRateLimit sets the rate limit shared across the AWS sessions, including the ones already created
+func SetRateLimit(l RateLimit) {
+	rateLimiterMu.Lock()
+	defer rateLimiterMu.Unlock()
+
+	if l.RequestsPerSecond <= 0 {
+		rateLimiter = nil
+
+		return
+	}
+
+	rateLimiter = newTokenBucket(l, time.Now)
+}
+
+func getRateLimiter() *tokenBucket {
+	rateLimiterMu.RLock()
+	defer rateLimiterMu.RUnlock()
+
+	return rateLimiter
+}
+
+// withRateLimit makes every request of the session, including retries, wait for the shared rate limiter
+func withRateLimit(sess *session.Session) *session.Session {
+	sess.Handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: "eksctl.RateLimit",
+		Fn: func(r *request.Request) {
+			l := getRateLimiter()
+			if l == nil {
+				return
+			}
+
+			d := l.reserve()
+			if d <= 0 {
+				return
+			}
+
+			t := time.NewTimer(d)
+			defer t.Stop()
+
+			select {
+			case <-t.C:
+			case <-r.Context().Done():
+				r.Error = awserr.New(request.CanceledErrorCode, "request canceled while waiting for the rate limit", r.Context().Err())
+				r.Retryable = aws.Bool(false)
+			}
+		},
+	})
+
+	return sess
+}
+
+// tokenBucket is a token bucket limiter whose tokens can go negative,
+// so that each caller waits for its own turn instead of racing for the next token
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(l RateLimit, now func() time.Time) *tokenBucket {
+	burst := float64(l.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(l.RequestsPerSecond)
+	}
+
+	return &tokenBucket{
+		rate:   l.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   now(),
+		now:    now,
+	}
+}
+
+// reserve takes a token and returns how long the caller needs to wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,35 @@
+package awsclicompat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	b := newTokenBucket(RateLimit{RequestsPerSecond: 2, Burst: 2}, func() time.Time { return now })
+
+	// The burst is available at once
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, time.Duration(0), b.reserve())
+
+	// Subsequent callers queue up behind each other
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+	assert.Equal(t, time.Second, b.reserve())
+
+	// Tokens are refilled at the rate, up to the burst
+	now = now.Add(10 * time.Second)
+
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+}
+
+func TestTokenBucket_defaultBurst(t *testing.T) {
+	b := newTokenBucket(RateLimit{RequestsPerSecond: 0.5}, time.Now)
+
+	assert.Equal(t, float64(1), b.burst)
+}
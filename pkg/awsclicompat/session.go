@@ -1,10 +1,23 @@
 package awsclicompat
 
 import (
+	"os"
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
-	"os"
+)
+
+var (
+	profileCredentialsMu sync.Mutex
+
+	// profileCredentials caches the credentials resolved per profile, so that external credential helpers configured
+	// via `credential_process` are run once per profile instead of on every AWS API call.
+	// The cached credentials are refreshed on expiry by the AWS SDK.
+	profileCredentials = map[string]*credentials.Credentials{}
 )
 
 // NewSession creates a new AWS session for the given AWS region.
@@ -15,10 +28,23 @@ import (
 // 2. static credentials loaded from profiles (AWS_PROFILE, when AWS_SDK_LOAD_CONFIG=true)
 // 3. dynamic credentials obtained by assuming the role using static credentials loaded from the profile (AWS_PROFILE, when AWS_SDK_LOAD_CONFIG=true)
 // 4. dynamic credentials obtained by assuming the role using static credentials loaded from the env (FORCE_AWS_PROFILE=true w/ credential_source=Environment)
+// 5. dynamic credentials obtained by running the external credential helper set via `credential_process` in the profile
 //
 // The fourth option of using FORCE_AWS_PROFILE=true and AWS_PROFILE=yourprofile is equivalent to `aws --profile ${AWS_PROFILE}`.
 // See https://github.com/variantdev/vals/issues/19#issuecomment-600437486 for more details and why and when this is needed.
-func NewSession(region, profile string) *session.Session {
+//
+// The AWS API calls go through the proxy of the config, if any, to the endpoints of the config, if any.
+//
+// When the config has a chain of roles, the roles are assumed in order on top of the credentials above.
+//
+// The AWS API calls wait for the rate limit set via SetRateLimit, if any.
+func (c *Config) NewSession(region, profile string) *session.Session {
+	return c.NewSessionWithAssumeRoles(region, profile, nil)
+}
+
+// NewSessionWithAssumeRoles is NewSession that additionally assumes the roles in order on top of the chain
+// of the config, so that each resource can operate in its own account.
+func (c *Config) NewSessionWithAssumeRoles(region, profile string, roles []AssumeRole) *session.Session {
 	var cfg *aws.Config
 	if region != "" {
 		cfg = aws.NewConfig().WithRegion(region)
@@ -26,6 +52,18 @@ func NewSession(region, profile string) *session.Session {
 		cfg = aws.NewConfig()
 	}
 
+	if client := c.httpClient(); client != nil {
+		cfg = cfg.WithHTTPClient(client)
+	}
+
+	if e := c.endpoints(); e.configured() {
+		cfg = cfg.WithEndpointResolver(e)
+
+		if v := e.stsRegionalEndpoint(); v != endpoints.UnsetSTSEndpoint {
+			cfg = cfg.WithSTSRegionalEndpoint(v)
+		}
+	}
+
 	opts := session.Options{
 		AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
 		SharedConfigState:       session.SharedConfigEnable,
@@ -37,7 +75,20 @@ func NewSession(region, profile string) *session.Session {
 		opts.Profile = os.Getenv("AWS_PROFILE")
 	}
 
-	sess := session.Must(session.NewSessionWithOptions(opts))
+	sess := withProfileCredentials(session.Must(session.NewSessionWithOptions(opts)), opts.Profile)
+
+	return withRateLimit(c.withAssumeRoleChain(sess, opts.Profile, roles))
+}
+
+func withProfileCredentials(sess *session.Session, profile string) *session.Session {
+	profileCredentialsMu.Lock()
+	defer profileCredentialsMu.Unlock()
+
+	creds, ok := profileCredentials[profile]
+	if !ok {
+		creds = sess.Config.Credentials
+		profileCredentials[profile] = creds
+	}
 
-	return sess
+	return sess.Copy(&aws.Config{Credentials: creds})
 }
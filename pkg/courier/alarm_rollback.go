@@ -0,0 +1,102 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+const DefaultAlarmRollbackInterval = 10 * time.Second
+
+// AlarmRollback rolls back the traffic when any of the existing CloudWatch alarms goes into ALARM
+// during the traffic shift, or within Window after the traffic shift is completed.
+type AlarmRollback struct {
+	AlarmNames []string
+	Window     time.Duration
+	Interval   time.Duration
+	Region     string
+	Profile    string
+	// AssumeRoles are assumed on top of the provider's roles
+	AssumeRoles []awsclicompat.AssumeRole
+	// AWS is the provider instance's config for the AWS sessions
+	AWS *awsclicompat.Config
+
+	// CloudWatch is used instead of the client for Region and Profile when non-nil
+	CloudWatch cloudwatchiface.CloudWatchAPI
+}
+
+func (a *AlarmRollback) client() cloudwatchiface.CloudWatchAPI {
+	if a.CloudWatch == nil {
+		a.CloudWatch = cloudwatch.New(a.AWS.NewSessionWithAssumeRoles(a.Region, a.Profile, a.AssumeRoles))
+	}
+
+	return a.CloudWatch
+}
+
+// Check returns an error describing the alarms in the ALARM state, if any.
+func (a *AlarmRollback) Check() error {
+	if a == nil || len(a.AlarmNames) == 0 {
+		return nil
+	}
+
+	var firing []string
+
+	err := a.client().DescribeAlarmsPages(&cloudwatch.DescribeAlarmsInput{
+		AlarmNames: aws.StringSlice(a.AlarmNames),
+		StateValue: aws.String(cloudwatch.StateValueAlarm),
+	}, func(page *cloudwatch.DescribeAlarmsOutput, lastPage bool) bool {
+		for _, m := range page.MetricAlarms {
+			firing = append(firing, fmt.Sprintf("%s (%s)", aws.StringValue(m.AlarmName), aws.StringValue(m.StateReason)))
+		}
+
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("describing cloudwatch alarms %s: %w", strings.Join(a.AlarmNames, ", "), err)
+	}
+
+	if len(firing) > 0 {
+		return fmt.Errorf("cloudwatch alarms in ALARM: %s", strings.Join(firing, ", "))
+	}
+
+	return nil
+}
+
+// Watch keeps checking the alarms for a.Window, and returns an error as soon as any of them goes into ALARM.
+func (a *AlarmRollback) Watch(ctx context.Context) error {
+	if a == nil || len(a.AlarmNames) == 0 || a.Window == 0 {
+		return nil
+	}
+
+	interval := a.Interval
+	if interval == 0 {
+		interval = DefaultAlarmRollbackInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.Window)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Watching cloudwatch alarms %s for %v", strings.Join(a.AlarmNames, ", "), a.Window)
+
+	for {
+		if err := a.Check(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package courier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCloudWatch struct {
+	cloudwatchiface.CloudWatchAPI
+
+	// alarmsAt returns the alarms in ALARM on the n-th call
+	alarmsAt func(n int) []*cloudwatch.MetricAlarm
+	calls    int
+}
+
+func (c *fakeCloudWatch) DescribeAlarmsPages(in *cloudwatch.DescribeAlarmsInput, fn func(*cloudwatch.DescribeAlarmsOutput, bool) bool) error {
+	c.calls++
+
+	fn(&cloudwatch.DescribeAlarmsOutput{MetricAlarms: c.alarmsAt(c.calls)}, true)
+
+	return nil
+}
+
+func TestAlarmRollback(t *testing.T) {
+	firing := []*cloudwatch.MetricAlarm{
+		{AlarmName: aws.String("5xx"), StateReason: aws.String("Threshold Crossed")},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		a := &AlarmRollback{
+			AlarmNames: []string{"5xx"},
+			Window:     50 * time.Millisecond,
+			Interval:   10 * time.Millisecond,
+			CloudWatch: &fakeCloudWatch{alarmsAt: func(n int) []*cloudwatch.MetricAlarm { return nil }},
+		}
+
+		assert.NoError(t, a.Check())
+		assert.NoError(t, a.Watch(context.Background()))
+	})
+
+	t.Run("alarm within window", func(t *testing.T) {
+		a := &AlarmRollback{
+			AlarmNames: []string{"5xx"},
+			Window:     5 * time.Second,
+			Interval:   10 * time.Millisecond,
+			CloudWatch: &fakeCloudWatch{alarmsAt: func(n int) []*cloudwatch.MetricAlarm {
+				if n < 3 {
+					return nil
+				}
+
+				return firing
+			}},
+		}
+
+		err := a.Watch(context.Background())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "5xx (Threshold Crossed)")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var a *AlarmRollback
+
+		assert.NoError(t, a.Check())
+		assert.NoError(t, a.Watch(context.Background()))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
 	"log"
 	"strconv"
@@ -11,30 +12,54 @@ import (
 )
 
 type CourierALB struct {
-	Address      string
-	ListenerARN  string
+	Address     string
+	ListenerARN string
+	// ListenerARNs are the listeners whose rules are shifted in lockstep, which takes precedence over ListenerARN
+	ListenerARNs []string
 	Priority     int
 	ListenerRule *ListenerRule
 	Region       string
 	Profile      string
+	// AWS is the provider instance's config for the AWS sessions
+	AWS          *awsclicompat.Config
 	Destinations []Destination
 	StepWeight   int
 	StepInterval time.Duration
+	Steps        []int
 	Metrics      []Metric
+
+	GRPCHealthChecks []GRPCHealthCheck
+	PrometheusGates  []PrometheusGate
+	Approvals        []Approval
+
+	SwitchoverMetrics *SwitchoverMetrics
+}
+
+// GetListenerARNs returns the ARNs of all the listeners the courier manages the rules on
+func (d *CourierALB) GetListenerARNs() []string {
+	if len(d.ListenerARNs) > 0 {
+		return d.ListenerARNs
+	}
+
+	return []string{d.ListenerARN}
 }
 
 type ALB struct {
 }
 
 func (a *ALB) Delete(d *CourierALB) error {
-	sess := awsclicompat.NewSession(d.Region, d.Profile)
+	svc := d.newELBV2()
 
-	sess.Config.Endpoint = &d.Address
-
-	svc := elbv2.New(sess)
+	for _, listenerARN := range d.GetListenerARNs() {
+		if err := deleteListenerRule(svc, d, listenerARN); err != nil {
+			return fmt.Errorf("deleting rule for listener %s: %w", listenerARN, err)
+		}
+	}
 
-	listenerARN := d.ListenerARN
+	return nil
+}
 
+func deleteListenerRule(svc elbv2iface.ELBV2API, d *CourierALB, listenerARN string) error {
 	o, err := svc.DescribeRules(&elbv2.DescribeRulesInput{
 		ListenerArn: aws.String(listenerARN),
 	})
@@ -61,7 +86,7 @@ func (a *ALB) Delete(d *CourierALB) error {
 				appendix = fmt.Sprintf("\nOUTPUT:\n%v", *res)
 			}
 
-			log.Printf("Error: deleting rule: %w\nINPUT:\n%v%s", err, *input, appendix)
+			log.Printf("Error: deleting rule: %v\nINPUT:\n%v%s", err, *input, appendix)
 
 			return fmt.Errorf("deleting rule: %w", err)
 		}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/google/go-cmp/cmp"
-	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/xerrors"
 	"log"
@@ -18,19 +18,63 @@ import (
 func (a *ALB) Apply(d *CourierALB) error {
 	log.SetFlags(log.Lshortfile)
 
-	sess := awsclicompat.NewSession(d.Region, d.Profile)
+	svc := d.newELBV2()
 
-	sess.Config.Endpoint = &d.Address
+	// Rules are created or updated on all the listeners first, so that the traffic shift starts on all the listeners at once
+	var ls []ListenerStatus
 
-	svc := elbv2.New(sess)
+	for _, listenerARN := range d.GetListenerARNs() {
+		l, err := applyListenerRule(svc, d, listenerARN)
+		if err != nil {
+			return fmt.Errorf("applying rule for listener %s: %w", listenerARN, err)
+		}
+
+		if l != nil {
+			ls = append(ls, *l)
+		}
+	}
+
+	if len(ls) == 0 {
+		return nil
+	}
 
-	listenerARN := d.ListenerARN
+	ctx, cancel := context.WithCancel(context.Background())
+	e, errctx := errgroup.WithContext(ctx)
+
+	e.Go(func() error {
+		defer cancel()
+		return DoGradualTrafficShiftAcrossListeners(errctx, svc, ls, 1, CanaryOpts{
+			CanaryAdvancementInterval: d.StepInterval,
+			CanaryAdvancementStep:     d.StepWeight,
+			Steps:                     d.Steps,
+			Region:                    "",
+			ClusterName:               "",
+			GRPCHealthChecks:          d.GRPCHealthChecks,
+			PrometheusGates:           d.PrometheusGates,
+			Approvals:                 d.Approvals,
+			SwitchoverMetrics:         d.SwitchoverMetrics,
+		})
+	})
+
+	data := ListerStatusToTemplateData(ls[0])
+
+	region, profile := d.Region, d.Profile
+
+	e.Go(func() error {
+		return Analyze(errctx, d.AWS, region, profile, d.Metrics, data)
+	})
+
+	return e.Wait()
+}
 
+// applyListenerRule creates or updates the rule on the listener.
+// It returns the status of the listener only when the traffic needs to be shifted gradually.
+func applyListenerRule(svc elbv2iface.ELBV2API, d *CourierALB, listenerARN string) (*ListenerStatus, error) {
 	o, err := svc.DescribeRules(&elbv2.DescribeRulesInput{
 		ListenerArn: aws.String(listenerARN),
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	priority := d.Priority
@@ -51,178 +95,146 @@ func (a *ALB) Apply(d *CourierALB) error {
 
 	destinations := d.Destinations
 
-	stepInterval := d.StepInterval
-
-	stepWeight := d.StepWeight
-
 	if rule == nil {
 		log.Printf("Creating new rule for ALB listener %s", listenerARN)
 
 		createRuleInput, err := ruleCreationInput(listenerARN, lr, destinations)
 		o, err := svc.CreateRule(createRuleInput)
 		if err != nil {
-			return fmt.Errorf("creating listener rule: %w", err)
+			return nil, fmt.Errorf("creating listener rule: %w", err)
 		}
 
 		rule = o.Rules[0]
 
 		log.Printf("Created new rule: %+v", *rule)
-	} else {
-		log.Printf("Updating existing rule: %+v", *rule)
-
-		desiredRuleConditions := getRuleConditions(lr)
 
-		var conditionsModified bool
+		return nil, nil
+	}
 
-		currentConditions := []*elbv2.RuleCondition{}
+	log.Printf("Updating existing rule: %+v", *rule)
 
-		if rule.Conditions != nil {
-			currentConditions = rule.Conditions
-		}
+	desiredRuleConditions := getRuleConditions(lr)
 
-		for i := range rule.Conditions {
-			// Otherwise we end up observing changes on Condition.Values even though
-			// we can't set both Condition.Values and Condition.*.Values:
-			//
-			// alb_apply.go:83: Rule conditions has been changed: current (-), desired (+):
-			//   []*elbv2.RuleCondition{
-			//          &{
-			//                  ... // 5 identical fields
-			//                  QueryStringConfig: nil,
-			//                  SourceIpConfig:    nil,
-			// -                Values:            []*string{&"/*"},
-			// +                Values:            nil,
-			//          },
-			//   }
-			rule.Conditions[i].Values = nil
-		}
+	var conditionsModified bool
 
-		if d := cmp.Diff(currentConditions, desiredRuleConditions); d != "" {
-			log.Printf("Rule conditions has been changed: current (-), desired (+):\n%s", d)
+	currentConditions := []*elbv2.RuleCondition{}
 
-			conditionsModified = true
-		}
-
-		if conditionsModified {
-			log.Printf("Updating rule %s in-place, without traffic shifting", *rule.RuleArn)
+	if rule.Conditions != nil {
+		currentConditions = rule.Conditions
+	}
 
-			if len(desiredRuleConditions) == 0 {
-				return errors.New("ALB does not support rule with no condition(s). Please specify one ore more from `hosts`, `path_patterns`, `methods`, `source_ips` and `headers`")
-			}
+	for i := range rule.Conditions {
+		// Otherwise we end up observing changes on Condition.Values even though
+		// we can't set both Condition.Values and Condition.*.Values:
+		//
+		// alb_apply.go:83: Rule conditions has been changed: current (-), desired (+):
+		//   []*elbv2.RuleCondition{
+		//          &{
+		//                  ... // 5 identical fields
+		//                  QueryStringConfig: nil,
+		//                  SourceIpConfig:    nil,
+		// -                Values:            []*string{&"/*"},
+		// +                Values:            nil,
+		//          },
+		//   }
+		rule.Conditions[i].Values = nil
+	}
 
-			// ALB doesn't support traffic-weight between different rules.
-			// We have no other way than modifying the rule in-place, which means no gradual traffic shiting is done.
+	if d := cmp.Diff(currentConditions, desiredRuleConditions); d != "" {
+		log.Printf("Rule conditions has been changed: current (-), desired (+):\n%s", d)
 
-			desiredActions := getRuleActions(destinations)
-			modifyRuleInput := &elbv2.ModifyRuleInput{
-				Actions:    desiredActions,
-				Conditions: desiredRuleConditions,
-				RuleArn:    rule.RuleArn,
-			}
+		conditionsModified = true
+	}
 
-			_, err := svc.ModifyRule(modifyRuleInput)
-			if err != nil {
-				return fmt.Errorf("updating listener rule: %w", err)
-			}
+	if conditionsModified {
+		log.Printf("Updating rule %s in-place, without traffic shifting", *rule.RuleArn)
 
-			return nil
+		if len(desiredRuleConditions) == 0 {
+			return nil, errors.New("ALB does not support rule with no condition(s). Please specify one ore more from `hosts`, `path_patterns`, `methods`, `source_ips` and `headers`")
 		}
 
-		// We can gradually shift traffic because Rule.Conditions are unchanged.
-
-		log.Printf("Updating rule %s with traffic shifting", *rule.RuleArn)
-
-		ctx := context.Background()
-
-		var nextTGARN, prevTGARN string
+		// ALB doesn't support traffic-weight between different rules.
+		// We have no other way than modifying the rule in-place, which means no gradual traffic shiting is done.
 
-		if destinations[0].Weight > destinations[1].Weight {
-			nextTGARN = destinations[0].TargetGroupARN
-			prevTGARN = destinations[1].TargetGroupARN
-		} else {
-			prevTGARN = destinations[0].TargetGroupARN
-			nextTGARN = destinations[1].TargetGroupARN
+		desiredActions := getRuleActions(destinations)
+		modifyRuleInput := &elbv2.ModifyRuleInput{
+			Actions:    desiredActions,
+			Conditions: desiredRuleConditions,
+			RuleArn:    rule.RuleArn,
 		}
 
-		tgs, err := svc.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
-			TargetGroupArns: []*string{
-				aws.String(nextTGARN),
-				aws.String(prevTGARN),
-			},
-		})
+		_, err := svc.ModifyRule(modifyRuleInput)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("updating listener rule: %w", err)
 		}
 
-		var desired, current *elbv2.TargetGroup
-
-		for i := range tgs.TargetGroups {
-			tg := *tgs.TargetGroups[i]
-			switch *tg.TargetGroupArn {
-			case nextTGARN:
-				desired = &tg
-			case prevTGARN:
-				current = &tg
-			}
-		}
+		return nil, nil
+	}
 
-		if desired == nil {
-			return xerrors.Errorf("next=desired target group %s not found", nextTGARN)
-		}
+	// We can gradually shift traffic because Rule.Conditions are unchanged.
 
-		if current == nil {
-			return xerrors.Errorf("prev=current target group %s not found", prevTGARN)
-		}
+	log.Printf("Updating rule %s with traffic shifting", *rule.RuleArn)
 
-		log.Printf("Starting to update rule %s, so that the traffic is gradually migrated from %s to %s", *rule.RuleArn, *current.TargetGroupArn, *desired.TargetGroupArn)
+	var nextTGARN, prevTGARN string
 
-		describeListenersResult, err := svc.DescribeListeners(&elbv2.DescribeListenersInput{
-			ListenerArns: aws.StringSlice([]string{lr.ListenerARN}),
-		})
-		if err != nil {
-			return err
-		}
+	if destinations[0].Weight > destinations[1].Weight {
+		nextTGARN = destinations[0].TargetGroupARN
+		prevTGARN = destinations[1].TargetGroupARN
+	} else {
+		prevTGARN = destinations[0].TargetGroupARN
+		nextTGARN = destinations[1].TargetGroupARN
+	}
 
-		l := ListenerStatus{
-			Listener:       describeListenersResult.Listeners[0],
-			Rule:           rule,
-			ALBAttachments: nil,
-			DesiredTG:      desired,
-			CurrentTG:      current,
-			DeletedTGs:     nil,
-			Metrics:        metrics,
-		}
+	tgs, err := svc.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		TargetGroupArns: []*string{
+			aws.String(nextTGARN),
+			aws.String(prevTGARN),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		ctx, cancel := context.WithCancel(ctx)
-		e, errctx := errgroup.WithContext(ctx)
+	var desired, current *elbv2.TargetGroup
 
-		e.Go(func() error {
-			defer cancel()
-			return DoGradualTrafficShift(errctx, svc, l, 1, CanaryOpts{
-				CanaryAdvancementInterval: stepInterval,
-				CanaryAdvancementStep:     stepWeight,
-				Region:                    "",
-				ClusterName:               "",
-			})
-		})
+	for i := range tgs.TargetGroups {
+		tg := *tgs.TargetGroups[i]
+		switch *tg.TargetGroupArn {
+		case nextTGARN:
+			desired = &tg
+		case prevTGARN:
+			current = &tg
+		}
+	}
 
-		data := ListerStatusToTemplateData(l)
+	if desired == nil {
+		return nil, xerrors.Errorf("next=desired target group %s not found", nextTGARN)
+	}
 
-		region, profile := d.Region, d.Profile
+	if current == nil {
+		return nil, xerrors.Errorf("prev=current target group %s not found", prevTGARN)
+	}
 
-		e.Go(func() error {
-			return Analyze(errctx, region, profile, l.Metrics, data)
-		})
+	log.Printf("Starting to update rule %s, so that the traffic is gradually migrated from %s to %s", *rule.RuleArn, *current.TargetGroupArn, *desired.TargetGroupArn)
 
-		if err := e.Wait(); err != nil {
-			return err
-		}
+	describeListenersResult, err := svc.DescribeListeners(&elbv2.DescribeListenersInput{
+		ListenerArns: aws.StringSlice([]string{listenerARN}),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		return err
+	l := ListenerStatus{
+		Listener:       describeListenersResult.Listeners[0],
+		Rule:           rule,
+		ALBAttachments: nil,
+		DesiredTG:      desired,
+		CurrentTG:      current,
+		DeletedTGs:     nil,
+		Metrics:        metrics,
 	}
-	return nil
+
+	return &l, nil
 }
 
 func getRuleConditions(listenerRule *ListenerRule) []*elbv2.RuleCondition {
@@ -0,0 +1,116 @@
+package courier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+)
+
+const (
+	// DefaultRulePriority is the priority of the listener rule when neither priority nor priority_range is set
+	DefaultRulePriority = 10
+
+	MinRulePriority = 1
+	MaxRulePriority = 50000
+)
+
+func (d *CourierALB) newELBV2() elbv2iface.ELBV2API {
+	sess := d.AWS.NewSession(d.Region, d.Profile)
+
+	sess.Config.Endpoint = &d.Address
+
+	return elbv2.New(sess)
+}
+
+// ValidatePriority returns an error when any of the listeners already has a rule with the priority,
+// which would otherwise be taken over by the courier.
+func (d *CourierALB) ValidatePriority() error {
+	svc := d.newELBV2()
+
+	var conflicts []string
+
+	for _, listenerARN := range d.GetListenerARNs() {
+		used, err := usedRulePriorities(svc, listenerARN)
+		if err != nil {
+			return err
+		}
+
+		if ruleARN, ok := used[d.Priority]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s on listener %s", ruleARN, listenerARN))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("priority %d conflicts with existing rule(s): %s", d.Priority, strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// AllocatePriority returns the lowest priority within min and max that is unused on all the listeners
+func (d *CourierALB) AllocatePriority(min, max int) (int, error) {
+	svc := d.newELBV2()
+
+	var used []map[int]string
+
+	for _, listenerARN := range d.GetListenerARNs() {
+		u, err := usedRulePriorities(svc, listenerARN)
+		if err != nil {
+			return 0, err
+		}
+
+		used = append(used, u)
+	}
+
+	return lowestFreePriority(used, min, max)
+}
+
+// usedRulePriorities returns the ARNs of the rules on the listener keyed by their priorities, excluding the default rule
+func usedRulePriorities(svc elbv2iface.ELBV2API, listenerARN string) (map[int]string, error) {
+	o, err := svc.DescribeRules(&elbv2.DescribeRulesInput{
+		ListenerArn: aws.String(listenerARN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing rules for listener %s: %w", listenerARN, err)
+	}
+
+	used := map[int]string{}
+
+	for _, r := range o.Rules {
+		if r.Priority == nil || aws.BoolValue(r.IsDefault) {
+			continue
+		}
+
+		p, err := strconv.Atoi(*r.Priority)
+		if err != nil {
+			continue
+		}
+
+		used[p] = aws.StringValue(r.RuleArn)
+	}
+
+	return used, nil
+}
+
+func lowestFreePriority(used []map[int]string, min, max int) (int, error) {
+	for p := min; p <= max; p++ {
+		var found bool
+
+		for _, u := range used {
+			if _, ok := u[p]; ok {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return p, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no free priority within %d and %d on all the listeners", min, max)
+}
@@ -0,0 +1,25 @@
+package courier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowestFreePriority(t *testing.T) {
+	used := []map[int]string{
+		{100: "rule1", 101: "rule2"},
+		{102: "rule3"},
+	}
+
+	p, err := lowestFreePriority(used, 100, 110)
+	assert.NoError(t, err)
+	assert.Equal(t, 103, p)
+
+	p, err = lowestFreePriority(used, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p)
+
+	_, err = lowestFreePriority(used, 100, 102)
+	assert.Error(t, err)
+}
@@ -12,7 +12,7 @@ import (
 	"time"
 )
 
-func MetricsToAnalyzers(region, profile string, ms []Metric) ([]*Analyzer, error) {
+func MetricsToAnalyzers(awsConfig *awsclicompat.Config, region, profile string, ms []Metric) ([]*Analyzer, error) {
 	var analyzers []*Analyzer
 
 	for _, m := range ms {
@@ -30,7 +30,7 @@ func MetricsToAnalyzers(region, profile string, ms []Metric) ([]*Analyzer, error
 				profile = m.AWSProfile
 			}
 
-			s := awsclicompat.NewSession(region, profile)
+			s := awsConfig.NewSession(region, profile)
 
 			s.Config.Endpoint = aws.String(m.Address)
 			c := cloudwatch.New(s)
@@ -46,6 +46,11 @@ func MetricsToAnalyzers(region, profile string, ms []Metric) ([]*Analyzer, error
 				APIKey:         os.Getenv("DATADOG_API_KEY"),
 				ApplicationKey: os.Getenv("DATADOG_APPLICATION_KEY"),
 			})
+		case "prometheus":
+			provider, err = metrics.NewPrometheusProvider(metrics.ProviderOpts{
+				Address:  m.Address,
+				Interval: 1 * time.Minute,
+			})
 		default:
 			return nil, fmt.Errorf("creating metrics provider: unknown and unsupported provider %q specified", m.Provider)
 		}
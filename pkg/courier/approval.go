@@ -0,0 +1,156 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+const (
+	ApprovalTypeFile = "file"
+	ApprovalTypeSSM  = "ssm"
+	ApprovalTypeHTTP = "http"
+
+	DefaultApprovalSSMParameterValue = "approved"
+	DefaultApprovalTimeout           = 1 * time.Hour
+	DefaultApprovalInterval          = 10 * time.Second
+)
+
+// Approval pauses the traffic shift once the weight of the desired destination reaches Weight,
+// until an external approval signal is observed.
+type Approval struct {
+	Weight int
+	Type   string
+
+	// FilePath is the path to the file whose existence approves the traffic shift, for the "file" type
+	FilePath string
+
+	// SSMParameterName is the name of the SSM parameter whose value is compared against SSMParameterValue, for the "ssm" type
+	SSMParameterName  string
+	SSMParameterValue string
+	Region            string
+	Profile           string
+	AssumeRoles       []awsclicompat.AssumeRole
+	// AWS is the provider instance's config for the AWS sessions
+	AWS *awsclicompat.Config
+
+	// URL is the HTTP endpoint that returns 200 once approved, for the "http" type
+	URL string
+
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+func (a Approval) approved() (bool, error) {
+	switch a.Type {
+	case ApprovalTypeFile:
+		_, err := os.Stat(a.FilePath)
+		if os.IsNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, fmt.Errorf("checking approval file %s: %w", a.FilePath, err)
+		}
+
+		return true, nil
+	case ApprovalTypeSSM:
+		svc := ssm.New(a.AWS.NewSessionWithAssumeRoles(a.Region, a.Profile, a.AssumeRoles))
+
+		r, err := svc.GetParameter(&ssm.GetParameterInput{Name: aws.String(a.SSMParameterName)})
+		if err != nil {
+			if isSSMParameterNotFound(err) {
+				return false, nil
+			}
+
+			return false, fmt.Errorf("getting approval ssm parameter %s: %w", a.SSMParameterName, err)
+		}
+
+		want := a.SSMParameterValue
+		if want == "" {
+			want = DefaultApprovalSSMParameterValue
+		}
+
+		return r.Parameter != nil && aws.StringValue(r.Parameter.Value) == want, nil
+	case ApprovalTypeHTTP:
+		res, err := http.Get(a.URL)
+		if err != nil {
+			log.Printf("Failed checking approval endpoint %s: %v", a.URL, err)
+
+			return false, nil
+		}
+
+		res.Body.Close()
+
+		return res.StatusCode == http.StatusOK, nil
+	default:
+		return false, fmt.Errorf("unsupported approval type %q: it must be one of %q, %q, and %q", a.Type, ApprovalTypeFile, ApprovalTypeSSM, ApprovalTypeHTTP)
+	}
+}
+
+func isSSMParameterNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+
+	return ok && aerr.Code() == ssm.ErrCodeParameterNotFound
+}
+
+// WaitForApproval blocks until the approval signal is observed, and returns an error when it isn't within a.Timeout.
+func WaitForApproval(ctx context.Context, a Approval) error {
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = DefaultApprovalTimeout
+	}
+
+	interval := a.Interval
+	if interval == 0 {
+		interval = DefaultApprovalInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := a.approved()
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			log.Printf("Traffic shift approved at weight %d", a.Weight)
+
+			return nil
+		}
+
+		log.Printf("Waiting for %s approval to continue traffic shift from weight %d", a.Type, a.Weight)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s approval at weight %d: not approved within %v", a.Type, a.Weight, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForApprovals waits for the approvals whose weights have been reached by advancing the weight from prev to current.
+func waitForApprovals(ctx context.Context, approvals []Approval, prev, current int) error {
+	for _, a := range approvals {
+		if a.Weight <= prev || a.Weight > current || current >= 100 {
+			continue
+		}
+
+		if err := WaitForApproval(ctx, a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package courier
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForApproval_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "approval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "approved")
+
+	a := Approval{
+		Weight:   25,
+		Type:     ApprovalTypeFile,
+		FilePath: path,
+		Timeout:  50 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+	}
+
+	assert.Error(t, WaitForApproval(context.Background(), a))
+
+	assert.NoError(t, ioutil.WriteFile(path, nil, 0644))
+
+	assert.NoError(t, WaitForApproval(context.Background(), a))
+}
+
+func TestWaitForApproval_HTTP(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls < 3 {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	a := Approval{
+		Weight:   50,
+		Type:     ApprovalTypeHTTP,
+		URL:      ts.URL,
+		Timeout:  5 * time.Second,
+		Interval: 10 * time.Millisecond,
+	}
+
+	assert.NoError(t, WaitForApproval(context.Background(), a))
+	assert.Equal(t, 3, calls)
+}
+
+func TestWaitForApprovals(t *testing.T) {
+	a := Approval{
+		Weight:   25,
+		Type:     ApprovalTypeFile,
+		FilePath: "/nonexistent/approval",
+		Timeout:  10 * time.Millisecond,
+		Interval: 5 * time.Millisecond,
+	}
+
+	assert.NoError(t, waitForApprovals(context.Background(), []Approval{a}, 0, 10))
+	assert.Error(t, waitForApprovals(context.Background(), []Approval{a}, 10, 25))
+	assert.NoError(t, waitForApprovals(context.Background(), []Approval{a}, 25, 50))
+	assert.NoError(t, waitForApprovals(context.Background(), []Approval{a}, 10, 100))
+}
@@ -0,0 +1,165 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/globalaccelerator"
+	"github.com/aws/aws-sdk-go/service/globalaccelerator/globalacceleratoriface"
+)
+
+// GlobalAcceleratorAPIRegion is the only region that serves the Global Accelerator API
+const GlobalAcceleratorAPIRegion = "us-west-2"
+
+// DestinationEndpoint is an endpoint in a Global Accelerator endpoint group, like the ALB or NLB in front of a cluster
+type DestinationEndpoint struct {
+	EndpointID string
+	Weight     int
+}
+
+// GlobalAcceleratorRouter shifts traffic between two endpoints of a Global Accelerator endpoint group
+// by gradually updating their weights. The other endpoints in the group are kept as they are.
+type GlobalAcceleratorRouter struct {
+	Service                   globalacceleratoriface.GlobalAcceleratorAPI
+	EndpointGroupARN          string
+	Destinations              []DestinationEndpoint
+	CanaryAdvancementInterval time.Duration
+	CanaryAdvancementStep     int
+
+	// Steps, when non-empty, is the list of weights of the destination to advance through, instead of CanaryAdvancementStep
+	Steps []int
+}
+
+func (r *GlobalAcceleratorRouter) TrafficShift(ctx context.Context) error {
+	var src, dst DestinationEndpoint
+
+	switch len(r.Destinations) {
+	case 2:
+		if r.Destinations[0].Weight < r.Destinations[1].Weight {
+			src = r.Destinations[0]
+			dst = r.Destinations[1]
+		} else if r.Destinations[0].Weight > r.Destinations[1].Weight {
+			src = r.Destinations[1]
+			dst = r.Destinations[0]
+		} else {
+			return fmt.Errorf("two destinations' weights must have different values: %v", r.Destinations)
+		}
+	default:
+		return fmt.Errorf("unsupported number of destinations: %d", len(r.Destinations))
+	}
+
+	step := r.CanaryAdvancementStep
+	if step <= 0 {
+		step = DefaultCanaryAdvancementStep
+	}
+
+	advancementInterval := r.CanaryAdvancementInterval
+	if advancementInterval == 0 {
+		advancementInterval = DefaultCanaryAdvancementInterval
+	}
+
+	var current int
+
+	for _, p := range TrafficShiftWeights(step, step, r.Steps) {
+		select {
+		case <-time.After(advancementInterval):
+		case <-ctx.Done():
+			if current != 100 {
+				log.Printf("Rolling back traffic for endpoint group %s", r.EndpointGroupARN)
+
+				if err := r.setWeights(src.EndpointID, dst.EndpointID, 0); err != nil {
+					return err
+				}
+			}
+
+			return fmt.Errorf("traffic shift canceled at weight %d%%: %w", current, ctx.Err())
+		}
+
+		log.Printf("Setting weight of endpoint %s to %v, and %s to %v", dst.EndpointID, p, src.EndpointID, 100-p)
+
+		if err := r.setWeights(src.EndpointID, dst.EndpointID, p); err != nil {
+			return err
+		}
+
+		current = p
+	}
+
+	log.Printf("[DEBUG] Traffic shift finished at weight %d%%", current)
+
+	return nil
+}
+
+// setWeights sets the weight of dst to p and src to 100-p.
+// The endpoint group is updated as a whole, so that the weights of src and dst are changed atomically.
+func (r *GlobalAcceleratorRouter) setWeights(src, dst string, p int) error {
+	o, err := r.Service.DescribeEndpointGroup(&globalaccelerator.DescribeEndpointGroupInput{
+		EndpointGroupArn: aws.String(r.EndpointGroupARN),
+	})
+	if err != nil {
+		return fmt.Errorf("describing endpoint group %s: %w", r.EndpointGroupARN, err)
+	}
+
+	configs := globalAcceleratorEndpointConfigurations(o.EndpointGroup.EndpointDescriptions, map[string]int{
+		src: 100 - p,
+		dst: p,
+	})
+
+	_, err = r.Service.UpdateEndpointGroup(&globalaccelerator.UpdateEndpointGroupInput{
+		EndpointGroupArn:       aws.String(r.EndpointGroupARN),
+		EndpointConfigurations: configs,
+	})
+	if err != nil {
+		return fmt.Errorf("updating endpoint group %s: %w", r.EndpointGroupARN, err)
+	}
+
+	return nil
+}
+
+// globalAcceleratorEndpointConfigurations returns the endpoint configurations that keep the existing endpoints
+// with the weights overridden, adding the endpoints missing in the group.
+func globalAcceleratorEndpointConfigurations(existing []*globalaccelerator.EndpointDescription, weights map[string]int) []*globalaccelerator.EndpointConfiguration {
+	var configs []*globalaccelerator.EndpointConfiguration
+
+	seen := map[string]bool{}
+
+	for _, e := range existing {
+		id := aws.StringValue(e.EndpointId)
+
+		c := &globalaccelerator.EndpointConfiguration{
+			EndpointId:                  e.EndpointId,
+			Weight:                      e.Weight,
+			ClientIPPreservationEnabled: e.ClientIPPreservationEnabled,
+		}
+
+		if w, ok := weights[id]; ok {
+			c.Weight = aws.Int64(int64(w))
+		}
+
+		seen[id] = true
+
+		configs = append(configs, c)
+	}
+
+	var missing []string
+
+	for id := range weights {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	sort.Strings(missing)
+
+	for _, id := range missing {
+		configs = append(configs, &globalaccelerator.EndpointConfiguration{
+			EndpointId: aws.String(id),
+			Weight:     aws.Int64(int64(weights[id])),
+		})
+	}
+
+	return configs
+}
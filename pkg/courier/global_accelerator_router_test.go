@@ -0,0 +1,81 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/globalaccelerator"
+	"github.com/aws/aws-sdk-go/service/globalaccelerator/globalacceleratoriface"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalAcceleratorEndpointConfigurations(t *testing.T) {
+	existing := []*globalaccelerator.EndpointDescription{
+		{EndpointId: aws.String("alb-blue"), Weight: aws.Int64(100), ClientIPPreservationEnabled: aws.Bool(true)},
+		{EndpointId: aws.String("alb-other"), Weight: aws.Int64(128)},
+	}
+
+	configs := globalAcceleratorEndpointConfigurations(existing, map[string]int{
+		"alb-blue":  70,
+		"alb-green": 30,
+	})
+
+	assert.Equal(t, []*globalaccelerator.EndpointConfiguration{
+		{EndpointId: aws.String("alb-blue"), Weight: aws.Int64(70), ClientIPPreservationEnabled: aws.Bool(true)},
+		{EndpointId: aws.String("alb-other"), Weight: aws.Int64(128)},
+		{EndpointId: aws.String("alb-green"), Weight: aws.Int64(30)},
+	}, configs)
+}
+
+type fakeGlobalAccelerator struct {
+	globalacceleratoriface.GlobalAcceleratorAPI
+
+	updates []map[string]int64
+}
+
+func (f *fakeGlobalAccelerator) DescribeEndpointGroup(*globalaccelerator.DescribeEndpointGroupInput) (*globalaccelerator.DescribeEndpointGroupOutput, error) {
+	return &globalaccelerator.DescribeEndpointGroupOutput{EndpointGroup: &globalaccelerator.EndpointGroup{}}, nil
+}
+
+func (f *fakeGlobalAccelerator) UpdateEndpointGroup(i *globalaccelerator.UpdateEndpointGroupInput) (*globalaccelerator.UpdateEndpointGroupOutput, error) {
+	weights := map[string]int64{}
+
+	for _, c := range i.EndpointConfigurations {
+		weights[*c.EndpointId] = *c.Weight
+	}
+
+	f.updates = append(f.updates, weights)
+
+	return &globalaccelerator.UpdateEndpointGroupOutput{}, nil
+}
+
+func TestGlobalAcceleratorRouter_TrafficShift(t *testing.T) {
+	// The traffic is shifted to the destination with the higher weight
+	destinations := []DestinationEndpoint{{EndpointID: "alb-blue", Weight: 0}, {EndpointID: "alb-green", Weight: 100}}
+
+	t.Run("shift", func(t *testing.T) {
+		svc := &fakeGlobalAccelerator{}
+
+		r := &GlobalAcceleratorRouter{Service: svc, Destinations: destinations, Steps: []int{50, 100}, CanaryAdvancementInterval: time.Millisecond}
+
+		assert.NoError(t, r.TrafficShift(context.Background()))
+		assert.Equal(t, []map[string]int64{{"alb-blue": 50, "alb-green": 50}, {"alb-blue": 0, "alb-green": 100}}, svc.updates)
+	})
+
+	t.Run("rollback on cancellation", func(t *testing.T) {
+		svc := &fakeGlobalAccelerator{}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		r := &GlobalAcceleratorRouter{Service: svc, Destinations: destinations, Steps: []int{50, 100}, CanaryAdvancementInterval: time.Hour}
+
+		err := r.TrafficShift(ctx)
+
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+		assert.Equal(t, []map[string]int64{{"alb-blue": 100, "alb-green": 0}}, svc.updates)
+	})
+}
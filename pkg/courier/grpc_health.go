@@ -0,0 +1,72 @@
+package courier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const DefaultGRPCHealthCheckTimeout = 5 * time.Second
+
+// GRPCHealthCheck is the configuration for calling grpc.health.v1.Health/Check on a backend of the new cluster
+// before each traffic-shift step, for backends that don't expose HTTP health endpoints.
+type GRPCHealthCheck struct {
+	// Address is the host:port of the gRPC server
+	Address string
+	// Service is the name of the service to check. Empty means the overall health of the server.
+	Service string
+	TLS     bool
+	Timeout time.Duration
+}
+
+// CheckGRPCHealth returns an error unless the gRPC server reports the service as SERVING.
+func CheckGRPCHealth(ctx context.Context, c GRPCHealthCheck) error {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultGRPCHealthCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	creds := grpc.WithInsecure()
+	if c.TLS {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+
+	conn, err := grpc.DialContext(ctx, c.Address, creds, grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("connecting to gRPC server %s: %w", c.Address, err)
+	}
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.Service})
+	if err != nil {
+		return fmt.Errorf("checking health of gRPC service %q at %s: %w", c.Service, c.Address, err)
+	}
+
+	if res.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("checking health of gRPC service %q at %s: got %s, want %s", c.Service, c.Address, res.Status, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	return nil
+}
+
+// CheckGRPCHealths runs all the health checks and returns the first error.
+func CheckGRPCHealths(ctx context.Context, checks []GRPCHealthCheck) error {
+	for _, c := range checks {
+		if err := CheckGRPCHealth(ctx, c); err != nil {
+			return err
+		}
+
+		log.Printf("gRPC service %q at %s is serving", c.Service, c.Address)
+	}
+
+	return nil
+}
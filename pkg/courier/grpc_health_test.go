@@ -0,0 +1,44 @@
+package courier
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCheckGRPCHealth(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := health.NewServer()
+	hs.SetServingStatus("myapp", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("other", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+
+	go s.Serve(lis)
+	defer s.Stop()
+
+	check := GRPCHealthCheck{
+		Address: lis.Addr().String(),
+		Timeout: 5 * time.Second,
+	}
+
+	check.Service = "myapp"
+	assert.NoError(t, CheckGRPCHealth(context.Background(), check))
+
+	check.Service = "other"
+	assert.Error(t, CheckGRPCHealth(context.Background(), check))
+
+	check.Service = "unknown"
+	assert.Error(t, CheckGRPCHealth(context.Background(), check))
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// https://prometheus.io/docs/prometheus/latest/querying/api/#instant-queries
+const (
+	prometheusQueryPath = "/api/v1/query"
+)
+
+type Prometheus struct {
+	queryEndpoint string
+
+	timeout time.Duration
+}
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+func NewPrometheusProvider(provider ProviderOpts) (*Prometheus, error) {
+	if provider.Address == "" {
+		return nil, fmt.Errorf("address of the Prometheus server is not set")
+	}
+
+	return &Prometheus{
+		queryEndpoint: strings.TrimSuffix(provider.Address, "/") + prometheusQueryPath,
+		timeout:       5 * time.Second,
+	}, nil
+}
+
+// Execute runs the PromQL query as an instant query against Prometheus.queryEndpoint
+// and returns the value of the scalar result or the first sample of the vector result as float64
+func (p *Prometheus) Execute(query string) (float64, error) {
+	req, err := http.NewRequest("GET", p.queryEndpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("query", query)
+	req.URL.RawQuery = q.Encode()
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error response: %s", string(b))
+	}
+
+	var res prometheusResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if res.Status != "success" {
+		return 0, fmt.Errorf("query failed: %s", res.Error)
+	}
+
+	var sample []interface{}
+
+	switch res.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(res.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("error unmarshaling scalar: %w, '%s'", err, string(b))
+		}
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+
+		if err := json.Unmarshal(res.Data.Result, &vector); err != nil {
+			return 0, fmt.Errorf("error unmarshaling vector: %w, '%s'", err, string(b))
+		}
+
+		if len(vector) < 1 {
+			return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		}
+
+		sample = vector[0].Value
+	default:
+		return 0, fmt.Errorf("unsupported result type %q: the query must return a scalar or an instant vector", res.Data.ResultType)
+	}
+
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	s, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value in response: %s", string(b))
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing sample value %q: %w", s, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusProvider_RunQuery(t *testing.T) {
+	t.Run("vector", func(t *testing.T) {
+		eq := `sum(rate(http_requests_total{code=~"5.."}[1m]))`
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/query", r.URL.Path)
+			assert.Equal(t, eq, r.URL.Query().Get("query"))
+
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1577232000.123,"1.5"]}]}}`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPrometheusProvider(ProviderOpts{Address: ts.URL, Interval: 1 * time.Minute})
+		require.NoError(t, err)
+
+		f, err := p.Execute(eq)
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, f)
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1577232000.123,"42"]}}`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPrometheusProvider(ProviderOpts{Address: ts.URL + "/"})
+		require.NoError(t, err)
+
+		f, err := p.Execute("scalar(1)")
+		require.NoError(t, err)
+		assert.Equal(t, float64(42), f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPrometheusProvider(ProviderOpts{Address: ts.URL})
+		require.NoError(t, err)
+
+		_, err = p.Execute("up")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPrometheusProvider(ProviderOpts{Address: ts.URL})
+		require.NoError(t, err)
+
+		_, err = p.Execute("up{")
+		require.Error(t, err)
+	})
+}
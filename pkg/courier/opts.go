@@ -7,4 +7,39 @@ type CanaryOpts struct {
 	CanaryAdvancementStep     int
 	Region                    string
 	ClusterName               string
+
+	// Steps, when non-empty, is the list of weights of the desired destination to advance through, instead of CanaryAdvancementStep
+	Steps []int
+	// BakeDuration, when non-zero, is how long each step lasts before advancing to the next one, instead of CanaryAdvancementInterval
+	BakeDuration time.Duration
+
+	// TargetHealthCheck, when non-nil, gates the switchover on the health of the desired target group
+	TargetHealthCheck *TargetHealthCheck
+
+	// GRPCHealthChecks are called before each traffic-shift step. The traffic is rolled back when any of them fails.
+	GRPCHealthChecks []GRPCHealthCheck
+
+	// PrometheusGates are evaluated before each traffic-shift step
+	PrometheusGates []PrometheusGate
+
+	// Approvals pause the traffic shift at the configured weights until approved externally
+	Approvals []Approval
+
+	// AlarmRollback, when non-nil, rolls back the traffic when any of the CloudWatch alarms goes into ALARM during and shortly after the traffic shift
+	AlarmRollback *AlarmRollback
+
+	// SwitchoverMetrics, when non-nil, publishes the progress of the traffic shift to CloudWatch
+	SwitchoverMetrics *SwitchoverMetrics
+}
+
+func (o CanaryOpts) bakeDuration() time.Duration {
+	if o.BakeDuration != 0 {
+		return o.BakeDuration
+	}
+
+	if o.CanaryAdvancementInterval != 0 {
+		return o.CanaryAdvancementInterval
+	}
+
+	return DefaultCanaryAdvancementInterval
 }
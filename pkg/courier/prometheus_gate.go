@@ -0,0 +1,108 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier/metrics"
+)
+
+const (
+	PrometheusGateOnViolationRollback = "rollback"
+	PrometheusGateOnViolationPause    = "pause"
+
+	DefaultPrometheusGatePauseTimeout = 10 * time.Minute
+)
+
+// PrometheusGate is a PromQL query evaluated against Prometheus before each traffic-shift step.
+type PrometheusGate struct {
+	Address string
+	Query   string
+	Min     *float64
+	Max     *float64
+
+	// OnViolation is either "rollback", which rolls back the traffic immediately, or "pause",
+	// which holds the current weights until the query is back within the thresholds.
+	OnViolation string
+	// PauseTimeout is how long the traffic shift is paused before rolling back
+	PauseTimeout time.Duration
+	// Interval is how often the query is re-evaluated while paused
+	Interval time.Duration
+}
+
+func (g PrometheusGate) analyzer() (*Analyzer, error) {
+	provider, err := metrics.NewPrometheusProvider(metrics.ProviderOpts{
+		Address:  g.Address,
+		Interval: g.Interval,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating prometheus provider: %w", err)
+	}
+
+	return &Analyzer{
+		MetricProvider: provider,
+		Query:          g.Query,
+		Min:            g.Min,
+		Max:            g.Max,
+	}, nil
+}
+
+// CheckPrometheusGate returns an error when the query result violates the thresholds.
+// With OnViolation "pause", it keeps re-evaluating the query until it passes or PauseTimeout elapses.
+func CheckPrometheusGate(ctx context.Context, g PrometheusGate) error {
+	a, err := g.analyzer()
+	if err != nil {
+		return err
+	}
+
+	err = a.Analyze(nil)
+	if err == nil || g.OnViolation != PrometheusGateOnViolationPause {
+		return err
+	}
+
+	timeout := g.PauseTimeout
+	if timeout == 0 {
+		timeout = DefaultPrometheusGatePauseTimeout
+	}
+
+	interval := g.Interval
+	if interval == 0 {
+		interval = DefaultAnalyzeInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		log.Printf("Pausing traffic shift until prometheus query %q passes: %v", g.Query, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("prometheus query %q didn't pass within %v: %w", g.Query, timeout, err)
+		case <-ticker.C:
+		}
+
+		err = a.Analyze(nil)
+		if err == nil {
+			log.Printf("Resuming traffic shift as prometheus query %q passed", g.Query)
+
+			return nil
+		}
+	}
+}
+
+// CheckPrometheusGates runs all the gates in order and returns the first error.
+func CheckPrometheusGates(ctx context.Context, gates []PrometheusGate) error {
+	for _, g := range gates {
+		if err := CheckPrometheusGate(ctx, g); err != nil {
+			return fmt.Errorf("checking prometheus query %q: %w", g.Query, err)
+		}
+	}
+
+	return nil
+}
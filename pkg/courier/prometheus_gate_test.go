@@ -0,0 +1,75 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrometheusServer(values ...string) *httptest.Server {
+	var mu sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		v := values[0]
+		if len(values) > 1 {
+			values = values[1:]
+		}
+
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1577232000,"%s"]}]}}`, v)
+	}))
+}
+
+func TestCheckPrometheusGate(t *testing.T) {
+	max := 1.0
+
+	t.Run("pass", func(t *testing.T) {
+		ts := newPrometheusServer("0")
+		defer ts.Close()
+
+		assert.NoError(t, CheckPrometheusGate(context.Background(), PrometheusGate{Address: ts.URL, Query: "errors", Max: &max}))
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		ts := newPrometheusServer("2", "0")
+		defer ts.Close()
+
+		assert.Error(t, CheckPrometheusGate(context.Background(), PrometheusGate{Address: ts.URL, Query: "errors", Max: &max}))
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		ts := newPrometheusServer("2", "2", "2", "0")
+		defer ts.Close()
+
+		assert.NoError(t, CheckPrometheusGate(context.Background(), PrometheusGate{
+			Address:      ts.URL,
+			Query:        "errors",
+			Max:          &max,
+			OnViolation:  PrometheusGateOnViolationPause,
+			PauseTimeout: 5 * time.Second,
+			Interval:     10 * time.Millisecond,
+		}))
+	})
+
+	t.Run("pause timeout", func(t *testing.T) {
+		ts := newPrometheusServer("2")
+		defer ts.Close()
+
+		assert.Error(t, CheckPrometheusGate(context.Background(), PrometheusGate{
+			Address:      ts.URL,
+			Query:        "errors",
+			Max:          &max,
+			OnViolation:  PrometheusGateOnViolationPause,
+			PauseTimeout: 50 * time.Millisecond,
+			Interval:     10 * time.Millisecond,
+		}))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"math"
 	"time"
 )
 
@@ -136,3 +137,101 @@ func LoadMetrics(metrics []interface{}) ([]Metric, error) {
 
 	return result, nil
 }
+
+func LoadGRPCHealthChecks(checks []interface{}) []GRPCHealthCheck {
+	var result []GRPCHealthCheck
+
+	for _, r := range checks {
+		m := r.(map[string]interface{})
+
+		c := GRPCHealthCheck{
+			Address: m["address"].(string),
+			Service: m["service"].(string),
+			TLS:     m["tls"].(bool),
+			Timeout: time.Duration(m["timeout_sec"].(int)) * time.Second,
+		}
+
+		result = append(result, c)
+	}
+
+	return result
+}
+
+// LoadPrometheusGates reads prometheus gates, where the thresholds default to +/-math.MaxFloat64 so that 0 can be used as a threshold
+func LoadPrometheusGates(gates []interface{}) []PrometheusGate {
+	var result []PrometheusGate
+
+	for _, r := range gates {
+		m := r.(map[string]interface{})
+
+		g := PrometheusGate{
+			Address:      m["address"].(string),
+			Query:        m["query"].(string),
+			OnViolation:  m["on_violation"].(string),
+			PauseTimeout: time.Duration(m["pause_timeout_sec"].(int)) * time.Second,
+			Interval:     time.Duration(m["interval_sec"].(int)) * time.Second,
+		}
+
+		if v := m["max"].(float64); v != math.MaxFloat64 {
+			g.Max = &v
+		}
+
+		if v := m["min"].(float64); v != -math.MaxFloat64 {
+			g.Min = &v
+		}
+
+		result = append(result, g)
+	}
+
+	return result
+}
+
+func LoadApprovals(approvals []interface{}, region, profile string) []Approval {
+	var result []Approval
+
+	for _, r := range approvals {
+		m := r.(map[string]interface{})
+
+		a := Approval{
+			Weight:            m["weight"].(int),
+			Type:              m["type"].(string),
+			FilePath:          m["file_path"].(string),
+			SSMParameterName:  m["ssm_parameter_name"].(string),
+			SSMParameterValue: m["ssm_parameter_value"].(string),
+			Region:            region,
+			Profile:           profile,
+			URL:               m["url"].(string),
+			Timeout:           time.Duration(m["timeout_sec"].(int)) * time.Second,
+			Interval:          time.Duration(m["interval_sec"].(int)) * time.Second,
+		}
+
+		result = append(result, a)
+	}
+
+	return result
+}
+
+// LoadSwitchoverMetrics returns nil when the switchover_metrics block is absent, so that no metrics are published
+func LoadSwitchoverMetrics(blocks []interface{}, region, profile string) *SwitchoverMetrics {
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+
+	m := blocks[0].(map[string]interface{})
+
+	metrics := &SwitchoverMetrics{
+		Namespace: m["namespace"].(string),
+		Region:    region,
+		Profile:   profile,
+	}
+
+	if dims, ok := m["dimensions"].(map[string]interface{}); ok && len(dims) > 0 {
+		metrics.Dimensions = map[string]string{}
+
+		for k, v := range dims {
+			metrics.Dimensions[k] = v.(string)
+		}
+	}
+
+	return metrics
+}
@@ -0,0 +1,261 @@
+package courier
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+const (
+	RoutingPolicyLatency  = "latency"
+	RoutingPolicyFailover = "failover"
+)
+
+// RegionalEndpoint is the endpoint of a cluster in a region, like the DNS name of the ALB or NLB in front of the cluster.
+type RegionalEndpoint struct {
+	SetIdentifier string
+	Region        string
+
+	// AliasDNSName and AliasHostedZoneID are used for alias records. Otherwise Values are used.
+	AliasDNSName      string
+	AliasHostedZoneID string
+	Values            []string
+
+	HealthCheckID string
+
+	// HealthCheck makes the router manage the health check for the endpoint, which takes precedence over HealthCheckID
+	HealthCheck *Route53HealthCheck
+
+	// Failover is either PRIMARY or SECONDARY. Used only for the failover routing policy.
+	Failover string
+
+	// Evacuate removes the endpoint from the routing so that the traffic is routed to the other regions
+	Evacuate bool
+}
+
+// Route53FailoverRouter manages a set of latency-based or failover record sets of the same name across regions,
+// so that the traffic can be shifted between clusters in multiple regions based on Route 53 health checks,
+// and a region can be evacuated.
+type Route53FailoverRouter struct {
+	Service              route53iface.Route53API
+	HostedZoneID         string
+	RecordName           string
+	RecordType           string
+	RoutingPolicy        string
+	TTL                  int64
+	EvaluateTargetHealth bool
+	Endpoints            []RegionalEndpoint
+}
+
+// Apply upserts the record sets for the endpoints, and deletes the ones for evacuated endpoints and
+// the endpoints in `removed` which are set identifiers no longer managed.
+// It refuses to evacuate an endpoint unless at least one remaining endpoint is healthy.
+func (r *Route53FailoverRouter) Apply(removed []string) error {
+	var (
+		active   []RegionalEndpoint
+		evacuate []string
+	)
+
+	for _, e := range r.Endpoints {
+		if e.Evacuate {
+			evacuate = append(evacuate, e.SetIdentifier)
+		} else {
+			active = append(active, e)
+		}
+	}
+
+	if len(active) == 0 {
+		return fmt.Errorf("evacuating all the endpoints of record %s is not allowed", r.RecordName)
+	}
+
+	if len(evacuate) > 0 {
+		if err := r.ensureAnyHealthy(active); err != nil {
+			return err
+		}
+	}
+
+	var changes []*route53.Change
+
+	for _, e := range active {
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(route53.ChangeActionUpsert),
+			ResourceRecordSet: r.recordSet(e),
+		})
+	}
+
+	deletions, err := r.deletions(append(evacuate, removed...))
+	if err != nil {
+		return err
+	}
+
+	changes = append(changes, deletions...)
+
+	log.Printf("Applying %d changes to Route 53 record %s", len(changes), r.RecordName)
+
+	_, err = r.Service.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.HostedZoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Comment: aws.String("Managed by terraform-provider-eksctl"),
+			Changes: changes,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("changing record sets for %s: %w", r.RecordName, err)
+	}
+
+	return nil
+}
+
+// Destroy deletes all the record sets managed by the router.
+func (r *Route53FailoverRouter) Destroy() error {
+	var ids []string
+
+	for _, e := range r.Endpoints {
+		ids = append(ids, e.SetIdentifier)
+	}
+
+	deletions, err := r.deletions(ids)
+	if err != nil {
+		return err
+	}
+
+	if len(deletions) == 0 {
+		return nil
+	}
+
+	_, err = r.Service.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.HostedZoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: deletions,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("deleting record sets for %s: %w", r.RecordName, err)
+	}
+
+	return nil
+}
+
+func (r *Route53FailoverRouter) recordSet(e RegionalEndpoint) *route53.ResourceRecordSet {
+	rs := &route53.ResourceRecordSet{
+		Name:          aws.String(r.RecordName),
+		Type:          aws.String(r.RecordType),
+		SetIdentifier: aws.String(e.SetIdentifier),
+	}
+
+	switch r.RoutingPolicy {
+	case RoutingPolicyFailover:
+		rs.Failover = aws.String(e.Failover)
+	default:
+		rs.Region = aws.String(e.Region)
+	}
+
+	if e.HealthCheckID != "" {
+		rs.HealthCheckId = aws.String(e.HealthCheckID)
+	}
+
+	if e.AliasDNSName != "" {
+		rs.AliasTarget = &route53.AliasTarget{
+			DNSName:              aws.String(e.AliasDNSName),
+			HostedZoneId:         aws.String(e.AliasHostedZoneID),
+			EvaluateTargetHealth: aws.Bool(r.EvaluateTargetHealth),
+		}
+	} else {
+		rs.TTL = aws.Int64(r.TTL)
+
+		for _, v := range e.Values {
+			rs.ResourceRecords = append(rs.ResourceRecords, &route53.ResourceRecord{Value: aws.String(v)})
+		}
+	}
+
+	return rs
+}
+
+// deletions returns the changes to delete the existing record sets for the set identifiers.
+// Route 53 requires the deleted record set to exactly match the existing one, so the existing ones are looked up.
+func (r *Route53FailoverRouter) deletions(setIdentifiers []string) ([]*route53.Change, error) {
+	if len(setIdentifiers) == 0 {
+		return nil, nil
+	}
+
+	targets := map[string]bool{}
+
+	for _, id := range setIdentifiers {
+		targets[id] = true
+	}
+
+	var changes []*route53.Change
+
+	name := strings.TrimSuffix(r.RecordName, ".") + "."
+
+	err := r.Service.ListResourceRecordSetsPages(&route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(r.HostedZoneID),
+		StartRecordName: aws.String(r.RecordName),
+		StartRecordType: aws.String(r.RecordType),
+	}, func(o *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+		for _, rs := range o.ResourceRecordSets {
+			if aws.StringValue(rs.Name) != name || aws.StringValue(rs.Type) != r.RecordType {
+				// Record sets are sorted by name and type, so there's no more matching record set
+				return false
+			}
+
+			if targets[aws.StringValue(rs.SetIdentifier)] {
+				changes = append(changes, &route53.Change{
+					Action:            aws.String(route53.ChangeActionDelete),
+					ResourceRecordSet: rs,
+				})
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing record sets for %s: %w", r.RecordName, err)
+	}
+
+	return changes, nil
+}
+
+func (r *Route53FailoverRouter) ensureAnyHealthy(endpoints []RegionalEndpoint) error {
+	for _, e := range endpoints {
+		if e.HealthCheckID == "" {
+			// Without health check we have no way to tell, so trust it the same way Route 53 does
+			return nil
+		}
+
+		healthy, err := Route53HealthCheckHealthy(r.Service, e.HealthCheckID)
+		if err != nil {
+			return err
+		}
+
+		if healthy {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("refusing to evacuate: none of the remaining endpoints of record %s is healthy", r.RecordName)
+}
+
+// Route53HealthCheckHealthy returns true when the majority of the Route 53 health checkers report the health check healthy.
+func Route53HealthCheckHealthy(svc route53iface.Route53API, healthCheckID string) (bool, error) {
+	o, err := svc.GetHealthCheckStatus(&route53.GetHealthCheckStatusInput{
+		HealthCheckId: aws.String(healthCheckID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("getting status of health check %s: %w", healthCheckID, err)
+	}
+
+	var healthy int
+
+	for _, obs := range o.HealthCheckObservations {
+		if obs.StatusReport != nil && strings.HasPrefix(aws.StringValue(obs.StatusReport.Status), "Success") {
+			healthy++
+		}
+	}
+
+	return len(o.HealthCheckObservations) > 0 && healthy*2 > len(o.HealthCheckObservations), nil
+}
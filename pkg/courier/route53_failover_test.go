@@ -0,0 +1,77 @@
+package courier
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/stretchr/testify/assert"
+)
+
+type route53FailoverMock struct {
+	route53iface.Route53API
+
+	existing []*route53.ResourceRecordSet
+	status   string
+	changes  []*route53.Change
+}
+
+func (m *route53FailoverMock) ListResourceRecordSetsPages(_ *route53.ListResourceRecordSetsInput, fn func(*route53.ListResourceRecordSetsOutput, bool) bool) error {
+	fn(&route53.ListResourceRecordSetsOutput{ResourceRecordSets: m.existing}, true)
+
+	return nil
+}
+
+func (m *route53FailoverMock) ChangeResourceRecordSets(in *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	m.changes = in.ChangeBatch.Changes
+
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func (m *route53FailoverMock) GetHealthCheckStatus(_ *route53.GetHealthCheckStatusInput) (*route53.GetHealthCheckStatusOutput, error) {
+	return &route53.GetHealthCheckStatusOutput{
+		HealthCheckObservations: []*route53.HealthCheckObservation{
+			{StatusReport: &route53.StatusReport{Status: aws.String(m.status)}},
+		},
+	}, nil
+}
+
+func TestRoute53FailoverRouter_evacuate(t *testing.T) {
+	west := &route53.ResourceRecordSet{
+		Name:          aws.String("app.example.com."),
+		Type:          aws.String("A"),
+		SetIdentifier: aws.String("us-west-2"),
+		Region:        aws.String("us-west-2"),
+	}
+
+	svc := &route53FailoverMock{
+		existing: []*route53.ResourceRecordSet{west},
+		status:   "Success: HTTP Status Code 200, OK",
+	}
+
+	r := &Route53FailoverRouter{
+		Service:       svc,
+		HostedZoneID:  "Z1",
+		RecordName:    "app.example.com",
+		RecordType:    "A",
+		RoutingPolicy: RoutingPolicyLatency,
+		Endpoints: []RegionalEndpoint{
+			{SetIdentifier: "us-east-2", Region: "us-east-2", AliasDNSName: "east.elb.amazonaws.com", AliasHostedZoneID: "Z2", HealthCheckID: "hc-east"},
+			{SetIdentifier: "us-west-2", Region: "us-west-2", AliasDNSName: "west.elb.amazonaws.com", AliasHostedZoneID: "Z3", Evacuate: true},
+		},
+	}
+
+	assert.NoError(t, r.Apply(nil))
+	assert.Len(t, svc.changes, 2)
+	assert.Equal(t, route53.ChangeActionUpsert, aws.StringValue(svc.changes[0].Action))
+	assert.Equal(t, "us-east-2", aws.StringValue(svc.changes[0].ResourceRecordSet.Region))
+	assert.Equal(t, route53.ChangeActionDelete, aws.StringValue(svc.changes[1].Action))
+	assert.Equal(t, west, svc.changes[1].ResourceRecordSet)
+
+	svc.status = "Failure: Connection timed out"
+	svc.changes = nil
+
+	assert.Error(t, r.Apply(nil))
+	assert.Nil(t, svc.changes)
+}
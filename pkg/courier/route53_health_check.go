@@ -0,0 +1,168 @@
+package courier
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/rs/xid"
+)
+
+const (
+	Route53HealthCheckTypeHTTP  = "HTTP"
+	Route53HealthCheckTypeHTTPS = "HTTPS"
+	Route53HealthCheckTypeTCP   = "TCP"
+)
+
+// Route53HealthCheck is the Route 53 health check the provider manages for the endpoint of a cluster,
+// so that Route 53 stops routing to the cluster once it dies, without any `terraform apply`.
+type Route53HealthCheck struct {
+	Type string
+	// FQDN defaults to the endpoint's AliasDNSName
+	FQDN string
+	// Port defaults to 443 for HTTPS and 80 for the others
+	Port             int64
+	ResourcePath     string
+	FailureThreshold int64
+	// RequestInterval is either 10 or 30 seconds. Route 53 doesn't allow changing it, so a change results in a new health check
+	RequestInterval int64
+}
+
+func (h Route53HealthCheck) config(e RegionalEndpoint) *route53.HealthCheckConfig {
+	fqdn := h.FQDN
+	if fqdn == "" {
+		fqdn = e.AliasDNSName
+	}
+
+	port := h.Port
+	if port == 0 {
+		if h.Type == Route53HealthCheckTypeHTTPS {
+			port = 443
+		} else {
+			port = 80
+		}
+	}
+
+	c := &route53.HealthCheckConfig{
+		Type:                     aws.String(h.Type),
+		FullyQualifiedDomainName: aws.String(fqdn),
+		Port:                     aws.Int64(port),
+		FailureThreshold:         aws.Int64(h.FailureThreshold),
+		RequestInterval:          aws.Int64(h.RequestInterval),
+	}
+
+	if h.Type != Route53HealthCheckTypeTCP {
+		c.ResourcePath = aws.String(h.ResourcePath)
+	}
+
+	return c
+}
+
+// EnsureHealthChecks creates or updates the health checks for the endpoints that have HealthCheck, and binds them to the endpoints.
+// existing is the IDs of the health checks created in the previous apply, keyed by set identifiers.
+// It returns the IDs of the health checks in use, and the IDs of the health checks to be deleted once the record sets are updated.
+func (r *Route53FailoverRouter) EnsureHealthChecks(existing map[string]string) (map[string]string, []string, error) {
+	ids := map[string]string{}
+
+	for i := range r.Endpoints {
+		e := &r.Endpoints[i]
+
+		if e.HealthCheck == nil {
+			continue
+		}
+
+		id, err := r.ensureHealthCheck(*e, existing[e.SetIdentifier])
+		if id != "" {
+			ids[e.SetIdentifier] = id
+		}
+
+		if err != nil {
+			return ids, nil, fmt.Errorf("ensuring health check for endpoint %q: %w", e.SetIdentifier, err)
+		}
+
+		e.HealthCheckID = id
+	}
+
+	var stale []string
+
+	for setIdentifier, id := range existing {
+		if ids[setIdentifier] != id {
+			stale = append(stale, id)
+		}
+	}
+
+	return ids, stale, nil
+}
+
+func (r *Route53FailoverRouter) ensureHealthCheck(e RegionalEndpoint, id string) (string, error) {
+	desired := e.HealthCheck.config(e)
+
+	if id != "" {
+		o, err := r.Service.GetHealthCheck(&route53.GetHealthCheckInput{HealthCheckId: aws.String(id)})
+		if err != nil {
+			log.Printf("Recreating health check %s for endpoint %q: %v", id, e.SetIdentifier, err)
+		} else if current := o.HealthCheck.HealthCheckConfig; aws.StringValue(current.Type) == aws.StringValue(desired.Type) &&
+			aws.Int64Value(current.RequestInterval) == aws.Int64Value(desired.RequestInterval) {
+
+			in := &route53.UpdateHealthCheckInput{
+				HealthCheckId:            aws.String(id),
+				HealthCheckVersion:       o.HealthCheck.HealthCheckVersion,
+				FullyQualifiedDomainName: desired.FullyQualifiedDomainName,
+				Port:                     desired.Port,
+				ResourcePath:             desired.ResourcePath,
+				FailureThreshold:         desired.FailureThreshold,
+			}
+
+			if _, err := r.Service.UpdateHealthCheck(in); err != nil {
+				return "", fmt.Errorf("updating health check %s: %w", id, err)
+			}
+
+			return id, nil
+		}
+	}
+
+	o, err := r.Service.CreateHealthCheck(&route53.CreateHealthCheckInput{
+		CallerReference:   aws.String(xid.New().String()),
+		HealthCheckConfig: desired,
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating health check: %w", err)
+	}
+
+	newID := aws.StringValue(o.HealthCheck.Id)
+
+	_, err = r.Service.ChangeTagsForResource(&route53.ChangeTagsForResourceInput{
+		ResourceId:   aws.String(newID),
+		ResourceType: aws.String(route53.TagResourceTypeHealthcheck),
+		AddTags: []*route53.Tag{
+			{Key: aws.String("Name"), Value: aws.String(fmt.Sprintf("%s %s", r.RecordName, e.SetIdentifier))},
+		},
+	})
+	if err != nil {
+		return newID, fmt.Errorf("tagging health check %s: %w", newID, err)
+	}
+
+	log.Printf("Created health check %s for endpoint %q", newID, e.SetIdentifier)
+
+	return newID, nil
+}
+
+// DeleteHealthChecks deletes the health checks, ignoring the ones already deleted
+func (r *Route53FailoverRouter) DeleteHealthChecks(ids []string) error {
+	for _, id := range ids {
+		_, err := r.Service.DeleteHealthCheck(&route53.DeleteHealthCheckInput{HealthCheckId: aws.String(id)})
+		if err != nil && !isNoSuchHealthCheck(err) {
+			return fmt.Errorf("deleting health check %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func isNoSuchHealthCheck(err error) bool {
+	aerr, ok := err.(awserr.Error)
+
+	return ok && aerr.Code() == route53.ErrCodeNoSuchHealthCheck
+}
@@ -0,0 +1,88 @@
+package courier
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/stretchr/testify/assert"
+)
+
+type route53HealthCheckMock struct {
+	route53iface.Route53API
+
+	healthChecks map[string]*route53.HealthCheckConfig
+	created      int
+	updated      []string
+	deleted      []string
+}
+
+func (m *route53HealthCheckMock) GetHealthCheck(in *route53.GetHealthCheckInput) (*route53.GetHealthCheckOutput, error) {
+	c := m.healthChecks[*in.HealthCheckId]
+
+	return &route53.GetHealthCheckOutput{
+		HealthCheck: &route53.HealthCheck{Id: in.HealthCheckId, HealthCheckConfig: c, HealthCheckVersion: aws.Int64(1)},
+	}, nil
+}
+
+func (m *route53HealthCheckMock) CreateHealthCheck(in *route53.CreateHealthCheckInput) (*route53.CreateHealthCheckOutput, error) {
+	m.created++
+
+	return &route53.CreateHealthCheckOutput{
+		HealthCheck: &route53.HealthCheck{Id: aws.String("hc-new"), HealthCheckConfig: in.HealthCheckConfig},
+	}, nil
+}
+
+func (m *route53HealthCheckMock) UpdateHealthCheck(in *route53.UpdateHealthCheckInput) (*route53.UpdateHealthCheckOutput, error) {
+	m.updated = append(m.updated, *in.HealthCheckId)
+
+	return &route53.UpdateHealthCheckOutput{}, nil
+}
+
+func (m *route53HealthCheckMock) ChangeTagsForResource(_ *route53.ChangeTagsForResourceInput) (*route53.ChangeTagsForResourceOutput, error) {
+	return &route53.ChangeTagsForResourceOutput{}, nil
+}
+
+func (m *route53HealthCheckMock) DeleteHealthCheck(in *route53.DeleteHealthCheckInput) (*route53.DeleteHealthCheckOutput, error) {
+	m.deleted = append(m.deleted, *in.HealthCheckId)
+
+	return &route53.DeleteHealthCheckOutput{}, nil
+}
+
+func TestRoute53FailoverRouter_EnsureHealthChecks(t *testing.T) {
+	hc := &Route53HealthCheck{
+		Type:             Route53HealthCheckTypeHTTPS,
+		ResourcePath:     "/healthz",
+		FailureThreshold: 3,
+		RequestInterval:  30,
+	}
+
+	svc := &route53HealthCheckMock{
+		healthChecks: map[string]*route53.HealthCheckConfig{
+			"hc-primary": {Type: aws.String("HTTPS"), RequestInterval: aws.Int64(30)},
+		},
+	}
+
+	r := &Route53FailoverRouter{
+		Service:       svc,
+		RecordName:    "app.example.com",
+		RoutingPolicy: RoutingPolicyFailover,
+		Endpoints: []RegionalEndpoint{
+			{SetIdentifier: "primary", Failover: "PRIMARY", AliasDNSName: "blue.elb.amazonaws.com", HealthCheck: hc},
+			{SetIdentifier: "secondary", Failover: "SECONDARY", AliasDNSName: "green.elb.amazonaws.com", HealthCheck: hc},
+		},
+	}
+
+	ids, stale, err := r.EnsureHealthChecks(map[string]string{"primary": "hc-primary", "removed": "hc-removed"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"primary": "hc-primary", "secondary": "hc-new"}, ids)
+	assert.Equal(t, []string{"hc-removed"}, stale)
+	assert.Equal(t, []string{"hc-primary"}, svc.updated)
+	assert.Equal(t, 1, svc.created)
+	assert.Equal(t, "hc-primary", r.Endpoints[0].HealthCheckID)
+	assert.Equal(t, "hc-new", r.Endpoints[1].HealthCheckID)
+	assert.Equal(t, "green.elb.amazonaws.com", aws.StringValue(hc.config(r.Endpoints[1]).FullyQualifiedDomainName))
+	assert.Equal(t, int64(443), aws.Int64Value(hc.config(r.Endpoints[1]).Port))
+}
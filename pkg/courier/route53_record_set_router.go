@@ -16,6 +16,9 @@ type Route53RecordSetRouter struct {
 	Destinations              []DestinationRecordSet
 	CanaryAdvancementInterval time.Duration
 	CanaryAdvancementStep     int
+
+	// Steps, when non-empty, is the list of weights of the destination to advance through, instead of CanaryAdvancementStep
+	Steps []int
 }
 
 func (r *Route53RecordSetRouter) TrafficShift(ctx context.Context) error {
@@ -55,57 +58,41 @@ func (r *Route53RecordSetRouter) TrafficShift(ctx context.Context) error {
 	if r.CanaryAdvancementStep > 0 {
 		step = r.CanaryAdvancementStep
 	} else {
-		step = 5
+		step = DefaultCanaryAdvancementStep
 	}
 
-	var advancementInterval time.Duration
-
-	if r.CanaryAdvancementInterval != 0 {
-		advancementInterval = r.CanaryAdvancementInterval
-	} else {
-		advancementInterval = 30 * time.Second
+	advancementInterval := r.CanaryAdvancementInterval
+	if advancementInterval == 0 {
+		advancementInterval = DefaultCanaryAdvancementInterval
 	}
 
-	ticker := time.NewTicker(advancementInterval)
-	defer ticker.Stop()
-
-	p := step
+	var current int
 
-	for {
+	for _, p := range TrafficShiftWeights(step, step, r.Steps) {
 		select {
-		case <-ticker.C:
-			if p >= 100 {
-				p = 100
-			}
-
-			log.Printf("Setting weight to %v", p)
-
-			if err := rp.Update(float64(p)); err != nil {
-				return err
-			}
-
-			if p == 100 {
-				fmt.Printf("Done.")
-				return nil
-			}
-
-			p += step
+		case <-time.After(advancementInterval):
 		case <-ctx.Done():
-			if p != 100 {
+			if current != 100 {
 				log.Printf("Rolling back traffic for record %s", r.RecordName)
 
 				if err := rp.Update(0); err != nil {
 					return err
 				}
-
-				break
 			}
 
-			return nil
+			return fmt.Errorf("traffic shift canceled at weight %d%%: %w", current, ctx.Err())
 		}
+
+		log.Printf("Setting weight to %v", p)
+
+		if err := rp.Update(float64(p)); err != nil {
+			return err
+		}
+
+		current = p
 	}
 
-	log.Printf("Rolling back traffic shift for Route 53 record %s", r.RecordName)
+	log.Printf("[DEBUG] Traffic shift finished at weight %d%%", current)
 
 	return nil
 }
@@ -0,0 +1,48 @@
+package courier
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	DefaultCanaryAdvancementStep     = 5
+	DefaultCanaryAdvancementInterval = 30 * time.Second
+)
+
+// TrafficShiftWeights returns the weights of the desired destination at each traffic-shift step.
+// When steps is non-empty, it is used in ascending order, with 100 appended when missing, so that users can shape their own rollout curve.
+// Otherwise the weight is advanced by step, starting from start.
+func TrafficShiftWeights(start, step int, steps []int) []int {
+	var weights []int
+
+	if len(steps) > 0 {
+		sorted := append([]int{}, steps...)
+
+		sort.Ints(sorted)
+
+		for _, w := range sorted {
+			if w <= 0 || w > 100 || (len(weights) > 0 && weights[len(weights)-1] == w) {
+				continue
+			}
+
+			weights = append(weights, w)
+		}
+
+		if len(weights) == 0 || weights[len(weights)-1] != 100 {
+			weights = append(weights, 100)
+		}
+
+		return weights
+	}
+
+	if step <= 0 {
+		step = DefaultCanaryAdvancementStep
+	}
+
+	for w := start; w < 100; w += step {
+		weights = append(weights, w)
+	}
+
+	return append(weights, 100)
+}
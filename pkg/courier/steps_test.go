@@ -0,0 +1,27 @@
+package courier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrafficShiftWeights(t *testing.T) {
+	testcases := []struct {
+		start, step int
+		steps       []int
+		want        []int
+	}{
+		{start: 1, step: 25, want: []int{1, 26, 51, 76, 100}},
+		{start: 50, step: 50, want: []int{50, 100}},
+		{start: 100, step: 5, want: []int{100}},
+		{start: 1, step: 0, want: []int{1, 6, 11, 16, 21, 26, 31, 36, 41, 46, 51, 56, 61, 66, 71, 76, 81, 86, 91, 96, 100}},
+		{start: 1, step: 5, steps: []int{5, 25, 50, 100}, want: []int{5, 25, 50, 100}},
+		{start: 1, step: 5, steps: []int{50, 10, 10}, want: []int{10, 50, 100}},
+		{start: 1, step: 5, steps: []int{0, 100, 150}, want: []int{100}},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.want, TrafficShiftWeights(tc.start, tc.step, tc.steps))
+	}
+}
@@ -0,0 +1,155 @@
+package courier
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+const (
+	DefaultSwitchoverMetricsNamespace = "EKSCtl/Switchover"
+
+	SwitchoverMetricTrafficWeight     = "TrafficWeight"
+	SwitchoverMetricTrafficShiftStep  = "TrafficShiftStep"
+	SwitchoverMetricHealthCheckPassed = "HealthCheckPassed"
+	SwitchoverMetricRollback          = "Rollback"
+
+	// SwitchoverHealthCheckStepGates and SwitchoverHealthCheckTargetHealth are the values of the Check dimension of HealthCheckPassed
+	SwitchoverHealthCheckStepGates    = "step_gates"
+	SwitchoverHealthCheckTargetHealth = "target_health"
+)
+
+// SwitchoverMetrics publishes custom CloudWatch metrics during the traffic shift,
+// so that rollouts are observable on existing dashboards and can trigger alarms.
+// Failures to publish metrics are logged but never fail the traffic shift.
+type SwitchoverMetrics struct {
+	Namespace string
+	// Dimensions are added to all the metrics, in addition to ClusterName and Listener when known
+	Dimensions map[string]string
+	Region     string
+	Profile    string
+	// AssumeRoles are assumed on top of the provider's roles
+	AssumeRoles []awsclicompat.AssumeRole
+	// AWS is the provider instance's config for the AWS sessions
+	AWS *awsclicompat.Config
+
+	// ClusterName is the name of the cluster the traffic is shifted to
+	ClusterName string
+
+	// CloudWatch is used instead of the client for Region and Profile when non-nil
+	CloudWatch cloudwatchiface.CloudWatchAPI
+}
+
+func (m *SwitchoverMetrics) client() cloudwatchiface.CloudWatchAPI {
+	if m.CloudWatch == nil {
+		m.CloudWatch = cloudwatch.New(m.AWS.NewSessionWithAssumeRoles(m.Region, m.Profile, m.AssumeRoles))
+	}
+
+	return m.CloudWatch
+}
+
+// PutStep publishes the weight of the desired destination and the number of the step, starting from 1, for the listener
+func (m *SwitchoverMetrics) PutStep(listener string, step, weight int) {
+	if m == nil {
+		return
+	}
+
+	dims := m.dimensions(map[string]string{"Listener": listener})
+
+	m.put(
+		m.datum(SwitchoverMetricTrafficWeight, float64(weight), cloudwatch.StandardUnitPercent, dims),
+		m.datum(SwitchoverMetricTrafficShiftStep, float64(step), cloudwatch.StandardUnitCount, dims),
+	)
+}
+
+// PutHealthCheck publishes 1 when err is nil and 0 otherwise, for the check
+func (m *SwitchoverMetrics) PutHealthCheck(check string, err error) {
+	if m == nil {
+		return
+	}
+
+	var v float64
+	if err == nil {
+		v = 1
+	}
+
+	m.put(m.datum(SwitchoverMetricHealthCheckPassed, v, cloudwatch.StandardUnitCount, m.dimensions(map[string]string{"Check": check})))
+}
+
+// PutRollback publishes the traffic weight of 0 and a rollback for the listener
+func (m *SwitchoverMetrics) PutRollback(listener string) {
+	if m == nil {
+		return
+	}
+
+	dims := m.dimensions(map[string]string{"Listener": listener})
+
+	m.put(
+		m.datum(SwitchoverMetricTrafficWeight, 0, cloudwatch.StandardUnitPercent, dims),
+		m.datum(SwitchoverMetricRollback, 1, cloudwatch.StandardUnitCount, m.dimensions(nil)),
+	)
+}
+
+func (m *SwitchoverMetrics) dimensions(extra map[string]string) []*cloudwatch.Dimension {
+	all := map[string]string{}
+
+	for k, v := range m.Dimensions {
+		all[k] = v
+	}
+
+	if m.ClusterName != "" {
+		all["ClusterName"] = m.ClusterName
+	}
+
+	for k, v := range extra {
+		if v != "" {
+			all[k] = v
+		}
+	}
+
+	var keys []string
+
+	for k := range all {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var dims []*cloudwatch.Dimension
+
+	for _, k := range keys {
+		dims = append(dims, &cloudwatch.Dimension{Name: aws.String(k), Value: aws.String(all[k])})
+	}
+
+	return dims
+}
+
+func (m *SwitchoverMetrics) datum(name string, value float64, unit string, dims []*cloudwatch.Dimension) *cloudwatch.MetricDatum {
+	return &cloudwatch.MetricDatum{
+		MetricName: aws.String(name),
+		Value:      aws.Float64(value),
+		Unit:       aws.String(unit),
+		Timestamp:  aws.Time(time.Now()),
+		Dimensions: dims,
+	}
+}
+
+func (m *SwitchoverMetrics) put(data ...*cloudwatch.MetricDatum) {
+	namespace := m.Namespace
+	if namespace == "" {
+		namespace = DefaultSwitchoverMetricsNamespace
+	}
+
+	_, err := m.client().PutMetricData(&cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String(namespace),
+		MetricData: data,
+	})
+	if err != nil {
+		log.Printf("Failed putting switchover metrics to %s: %v", namespace, err)
+	}
+}
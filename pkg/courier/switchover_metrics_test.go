@@ -0,0 +1,92 @@
+package courier
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMetricsCloudWatch struct {
+	cloudwatchiface.CloudWatchAPI
+
+	inputs []*cloudwatch.PutMetricDataInput
+	err    error
+}
+
+func (c *fakeMetricsCloudWatch) PutMetricData(in *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error) {
+	c.inputs = append(c.inputs, in)
+
+	return &cloudwatch.PutMetricDataOutput{}, c.err
+}
+
+func TestSwitchoverMetrics(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var m *SwitchoverMetrics
+
+		m.PutStep("listener", 1, 10)
+		m.PutHealthCheck(SwitchoverHealthCheckStepGates, nil)
+		m.PutRollback("listener")
+	})
+
+	t.Run("step", func(t *testing.T) {
+		cw := &fakeMetricsCloudWatch{}
+
+		m := &SwitchoverMetrics{
+			ClusterName: "mycluster-b",
+			Dimensions:  map[string]string{"Env": "prod"},
+			CloudWatch:  cw,
+		}
+
+		m.PutStep("arn:listener", 2, 20)
+
+		if assert.Len(t, cw.inputs, 1) {
+			in := cw.inputs[0]
+
+			assert.Equal(t, DefaultSwitchoverMetricsNamespace, aws.StringValue(in.Namespace))
+			assert.Len(t, in.MetricData, 2)
+
+			assert.Equal(t, SwitchoverMetricTrafficWeight, aws.StringValue(in.MetricData[0].MetricName))
+			assert.Equal(t, 20.0, aws.Float64Value(in.MetricData[0].Value))
+			assert.Equal(t, SwitchoverMetricTrafficShiftStep, aws.StringValue(in.MetricData[1].MetricName))
+			assert.Equal(t, 2.0, aws.Float64Value(in.MetricData[1].Value))
+
+			assert.Equal(t, []*cloudwatch.Dimension{
+				{Name: aws.String("ClusterName"), Value: aws.String("mycluster-b")},
+				{Name: aws.String("Env"), Value: aws.String("prod")},
+				{Name: aws.String("Listener"), Value: aws.String("arn:listener")},
+			}, in.MetricData[0].Dimensions)
+		}
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		cw := &fakeMetricsCloudWatch{}
+
+		m := &SwitchoverMetrics{Namespace: "MyApp/Rollout", CloudWatch: cw}
+
+		m.PutHealthCheck(SwitchoverHealthCheckStepGates, nil)
+		m.PutHealthCheck(SwitchoverHealthCheckTargetHealth, errors.New("unhealthy"))
+
+		if assert.Len(t, cw.inputs, 2) {
+			assert.Equal(t, "MyApp/Rollout", aws.StringValue(cw.inputs[0].Namespace))
+			assert.Equal(t, 1.0, aws.Float64Value(cw.inputs[0].MetricData[0].Value))
+			assert.Equal(t, 0.0, aws.Float64Value(cw.inputs[1].MetricData[0].Value))
+			assert.Equal(t, []*cloudwatch.Dimension{
+				{Name: aws.String("Check"), Value: aws.String(SwitchoverHealthCheckTargetHealth)},
+			}, cw.inputs[1].MetricData[0].Dimensions)
+		}
+	})
+
+	t.Run("put failure is not fatal", func(t *testing.T) {
+		cw := &fakeMetricsCloudWatch{err: errors.New("throttled")}
+
+		m := &SwitchoverMetrics{CloudWatch: cw}
+
+		m.PutRollback("arn:listener")
+
+		assert.Len(t, cw.inputs, 1)
+	})
+}
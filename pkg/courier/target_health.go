@@ -0,0 +1,89 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"log"
+	"time"
+)
+
+const (
+	DefaultTargetHealthCheckTimeout  = 5 * time.Minute
+	DefaultTargetHealthCheckInterval = 10 * time.Second
+)
+
+// TargetHealthCheck is the configuration for gating a traffic switchover on the health of
+// the targets registered to the destination target group.
+type TargetHealthCheck struct {
+	// MinHealthyRatio is the fraction of targets, from 0 to 1, that must be reported healthy by ELBv2
+	MinHealthyRatio float64
+	Timeout         time.Duration
+	Interval        time.Duration
+}
+
+// WaitForTargetGroupHealth polls the target group until at least c.MinHealthyRatio of its targets are healthy.
+// It returns an error when the ratio isn't reached within c.Timeout.
+func WaitForTargetGroupHealth(ctx context.Context, svc elbv2iface.ELBV2API, tgARN string, c TargetHealthCheck) error {
+	if c.MinHealthyRatio <= 0 {
+		return nil
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTargetHealthCheckTimeout
+	}
+
+	interval := c.Interval
+	if interval == 0 {
+		interval = DefaultTargetHealthCheckInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		healthy, total, err := countHealthyTargets(svc, tgARN)
+		if err != nil {
+			return err
+		}
+
+		if total > 0 && float64(healthy)/float64(total) >= c.MinHealthyRatio {
+			log.Printf("Target group %s is healthy: %d/%d targets healthy", tgARN, healthy, total)
+
+			return nil
+		}
+
+		log.Printf("Waiting for target group %s to become healthy: %d/%d targets healthy, want ratio %v", tgARN, healthy, total, c.MinHealthyRatio)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for target group %s to become healthy: %d/%d targets healthy after %v, want ratio %v", tgARN, healthy, total, timeout, c.MinHealthyRatio)
+		case <-ticker.C:
+		}
+	}
+}
+
+func countHealthyTargets(svc elbv2iface.ELBV2API, tgARN string) (int, int, error) {
+	r, err := svc.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(tgARN),
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("describing target health for %s: %w", tgARN, err)
+	}
+
+	var healthy int
+
+	for _, d := range r.TargetHealthDescriptions {
+		if d.TargetHealth != nil && aws.StringValue(d.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+			healthy++
+		}
+	}
+
+	return healthy, len(r.TargetHealthDescriptions), nil
+}
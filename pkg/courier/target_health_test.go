@@ -0,0 +1,71 @@
+package courier
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type targetHealthMock struct {
+	elbv2iface.ELBV2API
+
+	states [][]string
+	calls  int
+}
+
+func (m *targetHealthMock) DescribeTargetHealth(_ *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	i := m.calls
+	if i >= len(m.states) {
+		i = len(m.states) - 1
+	}
+	m.calls++
+
+	var descs []*elbv2.TargetHealthDescription
+
+	for _, s := range m.states[i] {
+		descs = append(descs, &elbv2.TargetHealthDescription{
+			TargetHealth: &elbv2.TargetHealth{State: aws.String(s)},
+		})
+	}
+
+	return &elbv2.DescribeTargetHealthOutput{TargetHealthDescriptions: descs}, nil
+}
+
+func TestWaitForTargetGroupHealth(t *testing.T) {
+	svc := &targetHealthMock{
+		states: [][]string{
+			{},
+			{"initial", "initial"},
+			{"healthy", "initial"},
+		},
+	}
+
+	err := WaitForTargetGroupHealth(context.Background(), svc, "tg", TargetHealthCheck{
+		MinHealthyRatio: 0.5,
+		Timeout:         time.Second,
+		Interval:        time.Millisecond,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, svc.calls)
+}
+
+func TestWaitForTargetGroupHealth_timeout(t *testing.T) {
+	svc := &targetHealthMock{
+		states: [][]string{
+			{"healthy", "unhealthy", "unhealthy"},
+		},
+	}
+
+	err := WaitForTargetGroupHealth(context.Background(), svc, "tg", TargetHealthCheck{
+		MinHealthyRatio: 0.5,
+		Timeout:         10 * time.Millisecond,
+		Interval:        time.Millisecond,
+	})
+
+	assert.Error(t, err)
+}
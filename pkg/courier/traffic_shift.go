@@ -4,86 +4,133 @@ import (
 	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
 	"golang.org/x/sync/errgroup"
 	"log"
 	"time"
 )
 
 func DoGradualTrafficShift(ctx context.Context, svc elbv2iface.ELBV2API, l ListenerStatus, p int, opts CanaryOpts) error {
-	if l.Rule.Actions != nil && len(l.Rule.Actions) > 0 {
+	return DoGradualTrafficShiftAcrossListeners(ctx, svc, []ListenerStatus{l}, p, opts)
+}
+
+// DoGradualTrafficShiftAcrossListeners shifts traffic on all the listeners in lockstep,
+// so that the weights of the target groups never diverge across listeners mid-rollout.
+// When setting the weight fails on any of the listeners, the traffic is rolled back on all of them.
+func DoGradualTrafficShiftAcrossListeners(ctx context.Context, svc elbv2iface.ELBV2API, ls []ListenerStatus, p int, opts CanaryOpts) error {
+	var shifted []ListenerStatus
+
+	for _, l := range ls {
+		if l.Rule.Actions == nil || len(l.Rule.Actions) == 0 {
+			continue
+		}
+
 		if len(l.Rule.Actions) != 1 {
 			return fmt.Errorf("unexpected number of actions in rule %q: want 2, got %d", *l.Rule.RuleArn, len(l.Rule.Actions))
 		}
 
-		// Gradually shift traffic from current tg to desired tg by
-		// updating rule
-		var step int
+		shifted = append(shifted, l)
+	}
+
+	if len(shifted) == 0 {
+		return nil
+	}
+
+	// Gradually shift traffic from current tg to desired tg by
+	// updating rule
+	weights := TrafficShiftWeights(p, opts.CanaryAdvancementStep, opts.Steps)
+
+	bakeDuration := opts.bakeDuration()
 
-		if opts.CanaryAdvancementStep > 0 {
-			step = opts.CanaryAdvancementStep
-		} else {
-			step = 5
+	var current int
+
+	for i, w := range weights {
+		select {
+		case <-time.After(bakeDuration):
+		case <-ctx.Done():
+			err := fmt.Errorf("traffic shift canceled at weight %d%%: %w", current, ctx.Err())
+
+			if current != 100 {
+				return rollbackListeners(svc, shifted, opts.SwitchoverMetrics, err)
+			}
+
+			return err
 		}
 
-		var advancementInterval time.Duration
+		err := checkStepGates(ctx, opts)
+
+		opts.SwitchoverMetrics.PutHealthCheck(SwitchoverHealthCheckStepGates, err)
 
-		if opts.CanaryAdvancementInterval != 0 {
-			advancementInterval = opts.CanaryAdvancementInterval
-		} else {
-			advancementInterval = 30 * time.Second
+		if err != nil {
+			return rollbackListeners(svc, shifted, opts.SwitchoverMetrics, fmt.Errorf("gating traffic shift to %d%%: %w", w, err))
 		}
 
-		ticker := time.NewTicker(advancementInterval)
-		defer ticker.Stop()
+		for _, l := range shifted {
+			log.Printf("Setting weight to DesiredTG %s: Weight %v, CurrentTG %s: Weight %v.", *l.DesiredTG.TargetGroupName, int64(w), *l.CurrentTG.TargetGroupName, int64(100-w))
 
-		for {
-			select {
-			case <-ticker.C:
-				if p >= 100 {
-					p = 100
-				}
+			if err := SetDesiredTGTrafficPercentage(svc, l, w); err != nil {
+				return rollbackListeners(svc, shifted, opts.SwitchoverMetrics, fmt.Errorf("setting weight for listener %s: %w", *l.Listener.ListenerArn, err))
+			}
 
-				log.Printf("Setting weight to DesiredTG %s: Weight %v, CurrentTG %s: Weight %v.", *l.DesiredTG.TargetGroupName, int64(p), *l.CurrentTG.TargetGroupName, int64(100-p))
+			opts.SwitchoverMetrics.PutStep(*l.Listener.ListenerArn, i+1, w)
+		}
 
-				if err := SetDesiredTGTrafficPercentage(svc, l, p); err != nil {
-					return err
-				}
+		prev := current
 
-				if p == 100 {
-					fmt.Printf("Done.")
-					return nil
-				}
+		current = w
 
-				p += step
-			case <-ctx.Done():
-				if p != 100 {
-					log.Printf("Rolling back traffic for listener %s", *l.Listener.ListenerArn)
+		if err := waitForApprovals(ctx, opts.Approvals, prev, current); err != nil {
+			return rollbackListeners(svc, shifted, opts.SwitchoverMetrics, err)
+		}
+	}
 
-					if err := SetDesiredTGTrafficPercentage(svc, l, 0); err != nil {
-						return err
-					}
+	log.Printf("[DEBUG] Traffic shift finished at weight %d%%", current)
 
-					break
-				}
+	return nil
+}
 
-				return nil
-			}
+// rollbackListeners shifts all the traffic back to the current target groups, and returns the cause as the error.
+func rollbackListeners(svc elbv2iface.ELBV2API, ls []ListenerStatus, metrics *SwitchoverMetrics, cause error) error {
+	if cause != nil {
+		log.Printf("Rolling back traffic: %v", cause)
+	}
+
+	for _, l := range ls {
+		log.Printf("Rolling back traffic for listener %s", *l.Listener.ListenerArn)
+
+		if err := SetDesiredTGTrafficPercentage(svc, l, 0); err != nil {
+			return err
 		}
 
-		log.Printf("Rolling back traffic shift for rule on listener %s", *l.Listener.ListenerArn)
+		metrics.PutRollback(*l.Listener.ListenerArn)
+	}
 
-		return nil
+	return cause
+}
+
+// checkStepGates returns an error when the traffic shift should not advance to the next step
+func checkStepGates(ctx context.Context, opts CanaryOpts) error {
+	if err := CheckGRPCHealths(ctx, opts.GRPCHealthChecks); err != nil {
+		return err
+	}
+
+	if err := CheckPrometheusGates(ctx, opts.PrometheusGates); err != nil {
+		return err
+	}
+
+	if err := opts.AlarmRollback.Check(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func Analyze(ctx context.Context, region, profile string, metrics []Metric, data interface{}) error {
+func Analyze(ctx context.Context, awsConfig *awsclicompat.Config, region, profile string, metrics []Metric, data interface{}) error {
 	var analyzers []*Analyzer
 	{
 		var err error
 
-		analyzers, err = MetricsToAnalyzers(region, profile, metrics)
+		analyzers, err = MetricsToAnalyzers(awsConfig, region, profile, metrics)
 		if err != nil {
 			return err
 		}
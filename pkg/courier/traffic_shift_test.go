@@ -0,0 +1,88 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeELBV2 struct {
+	elbv2iface.ELBV2API
+
+	failures map[string]int
+	calls    []string
+}
+
+func (f *fakeELBV2) ModifyRule(i *elbv2.ModifyRuleInput) (*elbv2.ModifyRuleOutput, error) {
+	rule := *i.RuleArn
+	weight := *i.Actions[0].ForwardConfig.TargetGroups[0].Weight
+
+	f.calls = append(f.calls, fmt.Sprintf("%s=%d", rule, weight))
+
+	if f.failures[rule] > 0 {
+		f.failures[rule]--
+
+		return nil, errors.New("throttled")
+	}
+
+	return &elbv2.ModifyRuleOutput{}, nil
+}
+
+func testListenerStatus(n string) ListenerStatus {
+	return ListenerStatus{
+		Listener: &elbv2.Listener{ListenerArn: aws.String("listener" + n)},
+		Rule: &elbv2.Rule{
+			RuleArn: aws.String("rule" + n),
+			Actions: []*elbv2.Action{{Type: aws.String("forward")}},
+		},
+		DesiredTG: &elbv2.TargetGroup{TargetGroupArn: aws.String("next"), TargetGroupName: aws.String("next")},
+		CurrentTG: &elbv2.TargetGroup{TargetGroupArn: aws.String("prev"), TargetGroupName: aws.String("prev")},
+	}
+}
+
+func TestDoGradualTrafficShiftAcrossListeners(t *testing.T) {
+	opts := CanaryOpts{
+		Steps:        []int{50, 100},
+		BakeDuration: time.Millisecond,
+	}
+
+	ls := []ListenerStatus{testListenerStatus("1"), testListenerStatus("2")}
+
+	t.Run("lockstep", func(t *testing.T) {
+		svc := &fakeELBV2{}
+
+		err := DoGradualTrafficShiftAcrossListeners(context.Background(), svc, ls, 1, opts)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"rule1=50", "rule2=50", "rule1=100", "rule2=100"}, svc.calls)
+	})
+
+	t.Run("rollback on failure", func(t *testing.T) {
+		svc := &fakeELBV2{failures: map[string]int{"rule2": 1}}
+
+		err := DoGradualTrafficShiftAcrossListeners(context.Background(), svc, ls, 1, opts)
+
+		assert.Error(t, err)
+		assert.Equal(t, []string{"rule1=50", "rule2=50", "rule1=0", "rule2=0"}, svc.calls)
+	})
+
+	t.Run("rollback on cancellation", func(t *testing.T) {
+		svc := &fakeELBV2{}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := DoGradualTrafficShiftAcrossListeners(ctx, svc, ls, 1, CanaryOpts{Steps: []int{50, 100}, BakeDuration: time.Hour})
+
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+		assert.EqualError(t, err, "traffic shift canceled at weight 0%: context canceled")
+		assert.Equal(t, []string{"rule1=0", "rule2=0"}, svc.calls)
+	})
+}
@@ -24,6 +24,13 @@ type ALBAttachment struct {
 	NodePort int
 	Protocol string
 
+	// TargetType is either "instance" for registering the nodegroup's instances at NodePort,
+	// or "ip" for registering the IPs of the pods behind the service via a TargetGroupBinding
+	TargetType       string
+	ServiceName      string
+	ServiceNamespace string
+	ServicePort      int
+
 	// ALB Listener Rule settings
 	Priority     int
 	Hosts        []string
@@ -1,21 +1,141 @@
 package provider
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 )
 
-type ProviderInstance struct {
-	AWSSession *session.Session
-}
+const (
+	KeyRegion       = "region"
+	KeyProfile      = "profile"
+	KeyAssumeRole   = "assume_role"
+	KeyRedactOutput = "redact_output"
+	KeyHTTPProxy    = "http_proxy"
+	KeyHTTPSProxy   = "https_proxy"
+	KeyNoProxy      = "no_proxy"
+
+	KeyEndpoints              = "endpoints"
+	KeyEndpointEKS            = "eks"
+	KeyEndpointSTS            = "sts"
+	KeyEndpointELBV2          = "elbv2"
+	KeyEndpointCloudFormation = "cloudformation"
+	KeyUseFIPSEndpoints       = "use_fips_endpoints"
+	KeySTSRegionalEndpoints   = "sts_regional_endpoints"
+
+	KeyWorkDir        = "work_dir"
+	KeyWorkDirCleanup = "work_dir_cleanup"
+
+	KeyCredentialHelper = "credential_helper"
 
-func providerConfigure() func(*schema.ResourceData) (interface{}, error) {
+	KeyAWSAPIRateLimit = "aws_api_rate_limit"
+	KeyAWSAPIBurst     = "aws_api_burst"
+
+	KeyAuditLog            = "audit_log"
+	KeyAuditLogPath        = "path"
+	KeyAuditLogS3Bucket    = "s3_bucket"
+	KeyAuditLogS3KeyPrefix = "s3_key_prefix"
+)
+
+func providerConfigure(p *schema.Provider) func(*schema.ResourceData) (interface{}, error) {
 	return func(d *schema.ResourceData) (interface{}, error) {
-		s := resource.AWSSessionFromResourceData(d)
+		// Interrupt in-flight eksctl and kubectl commands when Terraform is canceled
+		resource.SetStopContext(p.StopContext())
+
+		awsclicompat.SetRateLimit(awsclicompat.RateLimit{
+			RequestsPerSecond: d.Get(KeyAWSAPIRateLimit).(float64),
+			Burst:             d.Get(KeyAWSAPIBurst).(int),
+		})
 
-		return &ProviderInstance{
-			AWSSession: s,
+		workDir := d.Get(KeyWorkDir).(string)
+
+		if err := resource.CreateWorkDir(workDir); err != nil {
+			return nil, err
+		}
+
+		var credentialHelper []string
+
+		for _, v := range d.Get(KeyCredentialHelper).([]interface{}) {
+			credentialHelper = append(credentialHelper, v.(string))
+		}
+
+		awsConfig := &awsclicompat.Config{
+			Proxy: awsclicompat.Proxy{
+				HTTPProxy:  d.Get(KeyHTTPProxy).(string),
+				HTTPSProxy: d.Get(KeyHTTPSProxy).(string),
+				NoProxy:    d.Get(KeyNoProxy).(string),
+			},
+			Endpoints:       readEndpoints(d),
+			AssumeRoleChain: resource.ReadAssumeRoles(d.Get(KeyAssumeRole)),
+		}
+
+		s := awsConfig.NewSession(resource.GetAWSRegionAndProfile(d, nil))
+
+		auditLog, err := readAuditLog(d, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if v, ok := d.Get(KeyRedactOutput).(bool); ok {
+			resource.SetRedactOutput(v)
+		}
+
+		return &resource.ProviderConfig{
+			Region:           d.Get(KeyRegion).(string),
+			Profile:          d.Get(KeyProfile).(string),
+			AWS:              awsConfig,
+			AWSSession:       s,
+			WorkDir:          workDir,
+			WorkDirCleanup:   d.Get(KeyWorkDirCleanup).(string),
+			CredentialHelper: credentialHelper,
+			AuditLog:         resource.NewAuditLog(auditLog),
 		}, nil
 	}
 }
+
+func readAuditLog(d *schema.ResourceData, s *session.Session) (resource.AuditLog, error) {
+	var l resource.AuditLog
+
+	v, ok := d.Get(KeyAuditLog).([]interface{})
+	if !ok || len(v) == 0 || v[0] == nil {
+		return l, nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	l.Path = m[KeyAuditLogPath].(string)
+	l.S3Bucket = m[KeyAuditLogS3Bucket].(string)
+	l.S3KeyPrefix = m[KeyAuditLogS3KeyPrefix].(string)
+
+	if l.Path == "" && l.S3Bucket == "" {
+		return l, fmt.Errorf("%s: either %s or %s must be set", KeyAuditLog, KeyAuditLogPath, KeyAuditLogS3Bucket)
+	}
+
+	if l.S3Bucket != "" {
+		l.S3 = s3.New(s)
+	}
+
+	return l, nil
+}
+
+func readEndpoints(d *schema.ResourceData) awsclicompat.Endpoints {
+	e := awsclicompat.Endpoints{
+		UseFIPS:              d.Get(KeyUseFIPSEndpoints).(bool),
+		STSRegionalEndpoints: d.Get(KeySTSRegionalEndpoints).(string),
+	}
+
+	if v, ok := d.Get(KeyEndpoints).([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		m := v[0].(map[string]interface{})
+
+		e.EKS = m[KeyEndpointEKS].(string)
+		e.STS = m[KeyEndpointSTS].(string)
+		e.ELBV2 = m[KeyEndpointELBV2].(string)
+		e.CloudFormation = m[KeyEndpointCloudFormation].(string)
+	}
+
+	return e
+}
@@ -2,7 +2,9 @@ package provider
 
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource/cluster"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource/courier"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource/iamserviceaccount"
@@ -12,15 +14,176 @@ import (
 func Provider() terraform.ResourceProvider {
 
 	// The actual provider
-	return &schema.Provider{
-		Schema: map[string]*schema.Schema{},
+	p := &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			// region and profile are used by resources that don't specify their own.
+			// See resource.GetAWSRegionAndProfile for the full order of precedence.
+			KeyRegion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyProfile: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			// assume_role is the chain of roles assumed in order, like a hub account role and then a spoke account role,
+			// before calling AWS APIs and running eksctl and kubectl.
+			KeyAssumeRole: resource.AssumeRoleSchema(),
+			// redact_output makes the provider redact lines that seem to contain tokens or credentials
+			// from the output of eksctl and other commands, so that they won't leak into CI logs.
+			KeyRedactOutput: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			// http_proxy, https_proxy, and no_proxy are used for both the AWS API calls and the subprocesses like eksctl and kubectl,
+			// in place of HTTP_PROXY, HTTPS_PROXY, and NO_PROXY.
+			KeyHTTPProxy: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyHTTPSProxy: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyNoProxy: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			// endpoints and use_fips_endpoints are used for both the AWS API calls and the subprocesses like eksctl and kubectl,
+			// for regulated environments that require VPC endpoints or FIPS endpoints.
+			KeyEndpoints: {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						KeyEndpointEKS: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						KeyEndpointSTS: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						KeyEndpointELBV2: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						KeyEndpointCloudFormation: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+					},
+				},
+			},
+			KeyUseFIPSEndpoints: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			// sts_regional_endpoints is passed to the subprocesses via AWS_STS_REGIONAL_ENDPOINTS.
+			// Left to AWS_STS_REGIONAL_ENDPOINTS and the shared config when empty.
+			KeySTSRegionalEndpoints: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				ValidateFunc: validation.StringInSlice([]string{"", "regional", "legacy"}, false),
+			},
+			// work_dir is where the provider and its subprocesses write the generated files like kubeconfigs, in place of $TMPDIR.
+			// work_dir_cleanup = "never" keeps the generated files there after use.
+			KeyWorkDir: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyWorkDirCleanup: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      resource.WorkDirCleanupAlways,
+				ValidateFunc: validation.StringInSlice([]string{resource.WorkDirCleanupAlways, resource.WorkDirCleanupNever}, false),
+			},
+			// credential_helper is the command that wraps every eksctl invocation to provide it short-lived credentials,
+			// like ["aws-vault", "exec", "prod", "--"]
+			KeyCredentialHelper: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			// aws_api_rate_limit is the maximum number of AWS API calls per second shared across all the resources,
+			// so that large workspaces don't exhaust the account's API quotas during refresh. 0 disables rate limiting.
+			// aws_api_burst is the number of calls allowed at once, which defaults to aws_api_rate_limit rounded up.
+			KeyAWSAPIRateLimit: {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatBetween(0, 1000),
+			},
+			KeyAWSAPIBurst: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			// audit_log appends a JSON line per eksctl, kubectl, and hook command run by the provider to the file
+			// and/or the S3 bucket, for change tracking of cluster-mutating operations.
+			KeyAuditLog: {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						KeyAuditLogPath: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						KeyAuditLogS3Bucket: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						KeyAuditLogS3KeyPrefix: {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+					},
+				},
+			},
+		},
 		ResourcesMap: map[string]*schema.Resource{
-			"eksctl_cluster":                cluster.ResourceCluster(),
-			"eksctl_cluster_deployment":     cluster.ResourceClusterDeployment(),
-			"eksctl_iamserviceaccount":      iamserviceaccount.Resource(),
-			"eksctl_courier_alb":            courier.ResourceALB(),
-			"eksctl_courier_route53_record": courier.ResourceRoute53Record(),
+			"eksctl_cluster":                    cluster.ResourceCluster(),
+			"eksctl_cluster_deployment":         cluster.ResourceClusterDeployment(),
+			"eksctl_cluster_upgrade":            cluster.ResourceClusterUpgrade(),
+			"eksctl_iamserviceaccount":          iamserviceaccount.Resource(),
+			"eksctl_labels":                     cluster.ResourceLabels(),
+			"eksctl_nodegroup":                  cluster.ResourceNodeGroup(),
+			"eksctl_courier_alb":                courier.ResourceALB(),
+			"eksctl_courier_route53_record":     courier.ResourceRoute53Record(),
+			"eksctl_courier_route53_failover":   courier.ResourceRoute53Failover(),
+			"eksctl_courier_global_accelerator": courier.ResourceGlobalAccelerator(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"eksctl_iamidentitymapping": cluster.DataSourceIAMIdentityMapping(),
+			"eksctl_oidc_provider":      cluster.DataSourceOIDCProvider(),
+			"eksctl_nodegroups":         cluster.DataSourceNodeGroups(),
+			"eksctl_versions":           cluster.DataSourceVersions(),
+			"eksctl_kubeconfig":         cluster.DataSourceKubeconfig(),
+			"eksctl_cluster_auth":       cluster.DataSourceClusterAuth(),
 		},
-		ConfigureFunc: providerConfigure(),
 	}
+
+	p.ConfigureFunc = providerConfigure(p)
+
+	return p
 }
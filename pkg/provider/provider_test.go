@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -27,6 +28,39 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+// Any attribute carrying kubeconfig content, tokens, or CA data must be sensitive so that it is redacted in plan output
+func TestProvider_sensitiveAttributes(t *testing.T) {
+	p := Provider().(*schema.Provider)
+
+	isSensitiveName := func(k string) bool {
+		switch k {
+		case "kubeconfig_path", "kubeconfig_mode", "kubeconfig_merge":
+			return false
+		}
+
+		for _, s := range []string{"kubeconfig", "token", "certificate_authority", "ca_data"} {
+			if strings.Contains(k, s) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	check := func(kind string, resources map[string]*schema.Resource) {
+		for name, r := range resources {
+			for k, s := range r.Schema {
+				if isSensitiveName(k) && !s.Sensitive {
+					t.Errorf("%s %s: attribute %q must be marked sensitive", kind, name, k)
+				}
+			}
+		}
+	}
+
+	check("resource", p.ResourcesMap)
+	check("data source", p.DataSourcesMap)
+}
+
 func testAccPreCheck(t *testing.T) {
 
 }
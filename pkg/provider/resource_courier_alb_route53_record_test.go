@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier/metrics"
+	resource2 "github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
@@ -258,7 +259,7 @@ func TestAccCourierRoute53Record_create(t *testing.T) {
 }
 
 func testAccCheckCourierRoute53RecordDestroy(s *terraform.State) error {
-	_ = testAccProvider.Meta().(*ProviderInstance)
+	_ = testAccProvider.Meta().(*resource2.ProviderConfig)
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "eksctl_courier_route53_record" {
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier/metrics"
+	resource2 "github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
@@ -377,7 +378,7 @@ func TestAccCourierALB_update(t *testing.T) {
 }
 
 func testAccCheckCourierALBListenerDestroy(s *terraform.State) error {
-	_ = testAccProvider.Meta().(*ProviderInstance)
+	_ = testAccProvider.Meta().(*resource2.ProviderConfig)
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "eksctl_courier_alb" {
@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+const KeyAssumeRole = "assume_role"
+
+// AssumeRoleSchema is the schema of `assume_role` blocks, which are the chain of roles to be assumed in order
+func AssumeRoleSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"role_arn": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"external_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"session_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+			},
+		},
+	}
+}
+
+// ReadAssumeRoles reads the value of `assume_role` blocks
+func ReadAssumeRoles(v interface{}) []awsclicompat.AssumeRole {
+	var chain []awsclicompat.AssumeRole
+
+	if v == nil {
+		return chain
+	}
+
+	for _, r := range v.([]interface{}) {
+		m := r.(map[string]interface{})
+
+		chain = append(chain, awsclicompat.AssumeRole{
+			RoleARN:     m["role_arn"].(string),
+			ExternalID:  m["external_id"].(string),
+			SessionName: m["session_name"].(string),
+		})
+	}
+
+	return chain
+}
+
+// GetAssumeRoles returns the roles to be assumed for the resource, on top of the ones of the provider
+func GetAssumeRoles(d Read) []awsclicompat.AssumeRole {
+	return ReadAssumeRoles(d.Get(KeyAssumeRole))
+}
@@ -0,0 +1,179 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// AuditLog is the destination of the append-only audit log of all the commands run by the provider,
+// for change tracking of cluster-mutating operations.
+type AuditLog struct {
+	// Path is the local file that the entries are appended to as JSON lines
+	Path string
+	// S3Bucket receives an object per entry under S3KeyPrefix, as S3 objects can't be appended to
+	S3Bucket    string
+	S3KeyPrefix string
+	S3          s3iface.S3API
+
+	// id is how the commands refer to the audit log via envAuditLog
+	id string
+}
+
+// AuditEntry is a line of the audit log
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// ResourceID is the name of the cluster the command operated on. Empty for commands that aren't bound to a cluster
+	ResourceID      string  `json:"resource_id"`
+	Command         string  `json:"command"`
+	ExitCode        int     `json:"exit_code"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+const (
+	// envAuditLog and envAuditResourceID attach the audit log of the provider and the resource to the command,
+	// so that the command carries them through CommandRunners to ExecRunner.
+	// ExecRunner removes them from the environment before running the command.
+	envAuditLog        = "TF_EKSCTL_AUDIT_LOG"
+	envAuditResourceID = "TF_EKSCTL_AUDIT_RESOURCE_ID"
+)
+
+var (
+	// auditLogMu serializes the writes so that concurrent commands never interleave their lines
+	auditLogMu sync.Mutex
+
+	auditLogsMu sync.RWMutex
+	// auditLogs are the audit logs of the provider instances by id. It grows per configured provider, not per command.
+	auditLogs      = map[string]*AuditLog{}
+	lastAuditLogID uint64
+)
+
+// NewAuditLog enables the audit log for the commands of a provider instance. The zero AuditLog returns nil,
+// which disables it.
+func NewAuditLog(l AuditLog) *AuditLog {
+	if l.Path == "" && l.S3Bucket == "" {
+		return nil
+	}
+
+	auditLogsMu.Lock()
+	defer auditLogsMu.Unlock()
+
+	lastAuditLogID++
+
+	l.id = strconv.FormatUint(lastAuditLogID, 10)
+
+	auditLogs[l.id] = &l
+
+	return &l
+}
+
+// WithAuditLog marks the command to be recorded into the audit log of the provider, if enabled,
+// so that its audit log entry tells which resource ran it. Commands that aren't bound to a resource have an empty id.
+func (p *ProviderConfig) WithAuditLog(cmd *exec.Cmd, resourceID string) *exec.Cmd {
+	if p == nil || p.AuditLog == nil {
+		return cmd
+	}
+
+	setCommandEnv(cmd, envAuditLog, p.AuditLog.id)
+	setCommandEnv(cmd, envAuditResourceID, resourceID)
+
+	return cmd
+}
+
+// popAuditLog returns the audit log and the resource id that the command is marked with by WithAuditLog,
+// removing them from the environment of the command
+func popAuditLog(cmd *exec.Cmd) (*AuditLog, string) {
+	id, ok := popCommandEnv(cmd, envAuditLog)
+	resourceID, _ := popCommandEnv(cmd, envAuditResourceID)
+
+	if !ok {
+		return nil, ""
+	}
+
+	auditLogsMu.RLock()
+	defer auditLogsMu.RUnlock()
+
+	return auditLogs[id], resourceID
+}
+
+func newAuditEntry(cmd *exec.Cmd, resourceID string, exitCode int, startedAt time.Time) AuditEntry {
+	return AuditEntry{
+		Timestamp:       startedAt.UTC(),
+		ResourceID:      resourceID,
+		Command:         strings.Join(redactArgs(cmd.Args), " "),
+		ExitCode:        exitCode,
+		DurationSeconds: time.Since(startedAt).Round(time.Millisecond).Seconds(),
+	}
+}
+
+// record writes the entry to the audit log, if enabled.
+// Failures are only logged, as the command has already run and its result must be returned as is.
+func (l *AuditLog) record(e AuditEntry) {
+	if l == nil {
+		return
+	}
+
+	auditLogMu.Lock()
+	defer auditLogMu.Unlock()
+
+	if err := l.write(e); err != nil {
+		log.Printf("[WARN] failed writing audit log entry for %q: %v", e.Command, err)
+	}
+}
+
+func (l *AuditLog) write(e AuditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	if l.Path != "" {
+		f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("opening audit log %s: %w", l.Path, err)
+		}
+		defer f.Close()
+
+		if _, err := f.Write(line); err != nil {
+			return fmt.Errorf("writing audit log %s: %w", l.Path, err)
+		}
+	}
+
+	if l.S3Bucket != "" {
+		key := l.S3KeyPrefix + auditLogObjectKey(e)
+
+		if _, err := l.S3.PutObject(&s3.PutObjectInput{
+			Bucket:      aws.String(l.S3Bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(line),
+			ContentType: aws.String("application/x-ndjson"),
+		}); err != nil {
+			return fmt.Errorf("uploading audit log entry to s3://%s/%s: %w", l.S3Bucket, key, err)
+		}
+	}
+
+	return nil
+}
+
+// auditLogObjectKey returns the key that sorts the entries by time, like `2020/09/01/120000.123456789Z-mycluster.json`
+func auditLogObjectKey(e AuditEntry) string {
+	id := e.ResourceID
+	if id == "" {
+		id = "provider"
+	}
+
+	return fmt.Sprintf("%s-%s.json", e.Timestamp.UTC().Format("2006/01/02/150405.000000000Z"), strings.ReplaceAll(id, "/", "_"))
+}
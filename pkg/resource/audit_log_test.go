@@ -0,0 +1,101 @@
+package resource
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type s3PutObjectMock struct {
+	s3iface.S3API
+
+	keys   []string
+	bodies []string
+}
+
+func (m *s3PutObjectMock) PutObject(in *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	body, _ := ioutil.ReadAll(in.Body)
+
+	m.keys = append(m.keys, aws.StringValue(in.Key))
+	m.bodies = append(m.bodies, string(body))
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.jsonl")
+	svc := &s3PutObjectMock{}
+
+	p := &ProviderConfig{AuditLog: NewAuditLog(AuditLog{Path: path, S3Bucket: "audit", S3KeyPrefix: "eksctl/", S3: svc})}
+
+	_, err = Run(p.WithAuditLog(exec.Command("bash", "-c", "echo ok", "--token=abc"), "primary"))
+	assert.NoError(t, err)
+
+	_, err = Run(p.WithAuditLog(exec.Command("bash", "-c", "exit 3"), ""))
+	assert.Error(t, err)
+
+	// Commands of other provider instances are recorded into their own audit logs, if any
+	var other *ProviderConfig
+
+	_, err = Run(other.WithAuditLog(exec.Command("bash", "-c", "echo other"), "secondary"))
+	assert.NoError(t, err)
+
+	bs, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(bs)), "\n")
+	require.Len(t, lines, 2)
+
+	var first, second AuditEntry
+
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+
+	assert.Equal(t, "primary", first.ResourceID)
+	assert.Equal(t, "bash -c echo ok --token=[REDACTED]", first.Command)
+	assert.Equal(t, 0, first.ExitCode)
+	assert.WithinDuration(t, time.Now(), first.Timestamp, time.Minute)
+
+	assert.Equal(t, "", second.ResourceID)
+	assert.Equal(t, 3, second.ExitCode)
+
+	require.Len(t, svc.keys, 2)
+	assert.True(t, strings.HasPrefix(svc.keys[0], "eksctl/"+first.Timestamp.Format("2006/01/02/")), svc.keys[0])
+	assert.True(t, strings.HasSuffix(svc.keys[0], "-primary.json"), svc.keys[0])
+	assert.True(t, strings.HasSuffix(svc.keys[1], "-provider.json"), svc.keys[1])
+	assert.Equal(t, lines[0]+"\n", svc.bodies[0])
+}
+
+func TestAuditLog_environ(t *testing.T) {
+	assert.Nil(t, NewAuditLog(AuditLog{}))
+
+	dir, err := ioutil.TempDir("", "audit-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := &ProviderConfig{AuditLog: NewAuditLog(AuditLog{Path: filepath.Join(dir, "audit.jsonl")})}
+
+	cmd := p.WithAuditLog(exec.Command("bash", "-c", "echo ${TF_EKSCTL_AUDIT_LOG:-unset} ${TF_EKSCTL_AUDIT_RESOURCE_ID:-unset} $FOO"), "primary")
+	cmd.Env = append(cmd.Env, "FOO=bar")
+
+	res, err := Run(cmd)
+	require.NoError(t, err)
+
+	// The audit log is attached to the command without leaking into the subprocess
+	assert.Equal(t, "unset unset bar\n", res.Stdout)
+}
@@ -1,15 +1,26 @@
 package resource
 
 import (
+	"os"
+
 	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
 )
 
 type Read interface {
 	Get(string) interface{}
 }
 
-func GetAWSRegionAndProfile(d Read) (string, string) {
+// GetAWSRegionAndProfile resolves the region and the profile in the following order of precedence:
+//
+// 1. the resource's `region` and `profile` attributes
+// 2. the `region` and `profile` attributes of the provider instance that the resource belongs to
+// 3. AWS_REGION, AWS_DEFAULT_REGION and AWS_PROFILE envvars
+// 4. the region of the profile in the shared config, and then the region of the EC2 instance from IMDS
+//
+// An empty profile means that it is left to the shared config, the same way for both the AWS SDK and eksctl.
+// The region is passed explicitly to eksctl, so that eksctl never resolves a different one.
+// Both the AWS session and the eksctl command use the result so that they never operate in different accounts or regions.
+func GetAWSRegionAndProfile(d Read, p *ProviderConfig) (string, string) {
 	var region string
 
 	if v := d.Get("region"); v != nil {
@@ -22,11 +33,39 @@ func GetAWSRegionAndProfile(d Read) (string, string) {
 		profile = v.(string)
 	}
 
+	defaultRegion, defaultProfile := p.defaults()
+
+	if region == "" {
+		region = defaultRegion
+	}
+
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	if profile == "" {
+		profile = defaultProfile
+	}
+
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+
+	if region == "" {
+		region = fallbackRegion(profile)
+	}
+
 	return region, profile
 }
 
-func AWSSessionFromResourceData(d Read) *session.Session {
-	region, profile := GetAWSRegionAndProfile(d)
+// AWSSessionFromResourceData returns the session for the region, the profile, and the roles of the resource,
+// configured by the provider instance that the resource belongs to
+func AWSSessionFromResourceData(d Read, p *ProviderConfig) *session.Session {
+	region, profile := GetAWSRegionAndProfile(d, p)
 
-	return awsclicompat.NewSession(region, profile)
+	return p.NewAWSSession(region, profile, GetAssumeRoles(d))
 }
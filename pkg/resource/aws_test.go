@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapRead map[string]interface{}
+
+func (m mapRead) Get(k string) interface{} {
+	return m[k]
+}
+
+func TestGetAWSRegionAndProfile_precedence(t *testing.T) {
+	for _, k := range []string{"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_PROFILE"} {
+		if v, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, v)
+		} else {
+			defer os.Unsetenv(k)
+		}
+	}
+
+	os.Setenv("AWS_REGION", "")
+	os.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	os.Setenv("AWS_PROFILE", "env")
+
+	region, profile := GetAWSRegionAndProfile(mapRead{}, nil)
+	assert.Equal(t, "eu-west-1", region)
+	assert.Equal(t, "env", profile)
+
+	os.Setenv("AWS_REGION", "eu-central-1")
+
+	region, _ = GetAWSRegionAndProfile(mapRead{}, nil)
+	assert.Equal(t, "eu-central-1", region)
+
+	p := &ProviderConfig{Region: "us-west-2", Profile: "provider"}
+
+	region, profile = GetAWSRegionAndProfile(mapRead{"region": "", "profile": ""}, p)
+	assert.Equal(t, "us-west-2", region)
+	assert.Equal(t, "provider", profile)
+
+	// Aliased providers never see each other's region
+	region, _ = GetAWSRegionAndProfile(mapRead{"region": "", "profile": ""}, &ProviderConfig{Region: "eu-west-2"})
+	assert.Equal(t, "eu-west-2", region)
+
+	region, profile = GetAWSRegionAndProfile(mapRead{"region": "us-east-2", "profile": "resource"}, p)
+	assert.Equal(t, "us-east-2", region)
+	assert.Equal(t, "resource", profile)
+}
+
+func TestGetAWSRegionAndProfile_fallback(t *testing.T) {
+	defer func(f func(string) string) {
+		lookupFallbackRegion = f
+		fallbackRegions = map[string]string{}
+	}(lookupFallbackRegion)
+
+	for _, k := range []string{"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_PROFILE"} {
+		if v, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, v)
+		} else {
+			defer os.Unsetenv(k)
+		}
+
+		os.Unsetenv(k)
+	}
+
+	var lookups int
+
+	lookupFallbackRegion = func(profile string) string {
+		lookups++
+
+		if profile == "shared" {
+			return "ap-northeast-1"
+		}
+
+		return ""
+	}
+	fallbackRegions = map[string]string{}
+
+	region, _ := GetAWSRegionAndProfile(mapRead{"profile": "shared"}, nil)
+	assert.Equal(t, "ap-northeast-1", region)
+
+	region, _ = GetAWSRegionAndProfile(mapRead{"profile": "shared"}, nil)
+	assert.Equal(t, "ap-northeast-1", region)
+	assert.Equal(t, 1, lookups, "fallback region should be cached per profile")
+
+	region, _ = GetAWSRegionAndProfile(mapRead{"region": "us-east-2", "profile": "shared"}, nil)
+	assert.Equal(t, "us-east-2", region)
+
+	assert.NoError(t, ValidateAWSRegion(mapRead{"profile": "shared"}, nil))
+	assert.EqualError(t, ValidateAWSRegion(mapRead{"profile": "none"}, nil), "validating region: "+RegionNotFoundMessage)
+}
+
+type fakeRegionDiff struct {
+	mapRead
+
+	id      string
+	unknown bool
+	planned map[string]interface{}
+}
+
+func (d *fakeRegionDiff) Id() string {
+	return d.id
+}
+
+func (d *fakeRegionDiff) NewValueKnown(string) bool {
+	return !d.unknown
+}
+
+func (d *fakeRegionDiff) SetNew(k string, v interface{}) error {
+	d.planned[k] = v
+
+	return nil
+}
+
+func TestPlanAWSRegion(t *testing.T) {
+	p := &ProviderConfig{Region: "us-west-2"}
+
+	d := &fakeRegionDiff{mapRead: mapRead{"region": ""}, planned: map[string]interface{}{}}
+	assert.NoError(t, PlanAWSRegion(d, p))
+	assert.Equal(t, map[string]interface{}{"region": "us-west-2"}, d.planned)
+
+	// The region of the existing resource is kept even when the provider's region is changed
+	d = &fakeRegionDiff{mapRead: mapRead{"region": ""}, id: "mycluster", planned: map[string]interface{}{}}
+	assert.NoError(t, PlanAWSRegion(d, p))
+	assert.Empty(t, d.planned)
+
+	d = &fakeRegionDiff{mapRead: mapRead{"region": "us-east-2"}, planned: map[string]interface{}{}}
+	assert.NoError(t, PlanAWSRegion(d, p))
+	assert.Empty(t, d.planned)
+
+	d = &fakeRegionDiff{mapRead: mapRead{"region": ""}, unknown: true, planned: map[string]interface{}{}}
+	assert.NoError(t, PlanAWSRegion(d, p))
+	assert.Empty(t, d.planned)
+}
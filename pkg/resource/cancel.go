@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ErrCanceled is returned when a command is interrupted because Terraform has been canceled, e.g. by Ctrl-C.
+var ErrCanceled = errors.New("canceled")
+
+// ErrTimedOut is returned when a command is interrupted because it didn't finish within the timeout given to RunWithTimeout.
+var ErrTimedOut = errors.New("timed out")
+
+// CancelGracePeriod is how long an interrupted command is given to exit before it is killed.
+// eksctl needs some time to stop waiting on CloudFormation and exit cleanly.
+const CancelGracePeriod = 2 * time.Minute
+
+var (
+	stopContextMu sync.RWMutex
+	stopContext   = context.Background()
+)
+
+// SetStopContext sets the context that is canceled when Terraform asks the provider to stop.
+func SetStopContext(ctx context.Context) {
+	stopContextMu.Lock()
+	defer stopContextMu.Unlock()
+
+	stopContext = ctx
+}
+
+func getStopContext() context.Context {
+	stopContextMu.RLock()
+	defer stopContextMu.RUnlock()
+
+	return stopContext
+}
+
+// runInterruptible runs the command to completion, or until the context is canceled.
+// On cancellation, the command is sent SIGINT so that eksctl can stop mutating AWS resources and exit,
+// and then killed if it doesn't exit within the grace period.
+func runInterruptible(ctx context.Context, cmd *exec.Cmd, gracePeriod time.Duration) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	waitCh := make(chan error, 1)
+
+	go func() {
+		waitCh <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-waitCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("[INFO] Interrupting %s: %v", cmd.Path, ctx.Err())
+
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		log.Printf("[WARN] Failed to interrupt %s: %v", cmd.Path, err)
+	}
+
+	select {
+	case <-waitCh:
+	case <-time.After(gracePeriod):
+		log.Printf("[WARN] Killing %s as it didn't exit within %s after interrupted", cmd.Path, gracePeriod)
+
+		if err := cmd.Process.Kill(); err != nil {
+			log.Printf("[WARN] Failed to kill %s: %v", cmd.Path, err)
+		}
+
+		<-waitCh
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimedOut
+	}
+
+	return ErrCanceled
+}
@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInterruptible(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.NoError(t, runInterruptible(ctx, exec.Command("true"), time.Second))
+
+	cmd := exec.Command("sleep", "60")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+
+	err := runInterruptible(ctx, cmd, time.Second)
+
+	assert.True(t, errors.Is(err, ErrCanceled))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	start := time.Now()
+
+	_, err := RunWithTimeout(exec.Command("sleep", "60"), 100*time.Millisecond)
+
+	assert.True(t, errors.Is(err, ErrTimedOut))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+}
@@ -0,0 +1,306 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KeyAddonDrift is the differences between the EKS addons declared in `addons` of the spec and the installed ones,
+	// keyed by the addon names
+	KeyAddonDrift = "addon_drift"
+
+	addonNotInstalled = "not installed"
+)
+
+// declaredAddon is the part of the addon in the spec that is compared against the installed addon
+type declaredAddon struct {
+	Name                  string `yaml:"name"`
+	Version               string `yaml:"version"`
+	ServiceAccountRoleARN string `yaml:"serviceAccountRoleARN"`
+	ConfigurationValues   string `yaml:"configurationValues"`
+}
+
+// AddonSummary is an item of the output of `eksctl get addon -o json`
+type AddonSummary struct {
+	Name                string `json:"Name"`
+	Version             string `json:"Version"`
+	IAMRole             string `json:"IAMRole"`
+	Status              string `json:"Status"`
+	ConfigurationValues string `json:"ConfigurationValues"`
+}
+
+func getDeclaredAddons(spec string) ([]declaredAddon, error) {
+	var config struct {
+		Addons []declaredAddon `yaml:"addons"`
+	}
+
+	if err := yaml.Unmarshal([]byte(spec), &config); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	return config.Addons, nil
+}
+
+// errUnparsableAddons is returned when `eksctl get addon` succeeds but its output isn't the expected JSON
+var errUnparsableAddons = errors.New("parsing get-addon output as json")
+
+func runGetAddons(d Read, cluster *Cluster) ([]AddonSummary, error) {
+	v, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(cluster.Profile, cluster.AssumeRoles), cluster.Region, cluster.Name, "addons"), func() (interface{}, error) {
+		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, "get", "addon", "--cluster", cluster.Name, "-o", "json")
+		if err != nil {
+			return nil, fmt.Errorf("creating eksctl-get-addon command: %w", err)
+		}
+
+		run, err := resource.Run(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("running get-addon: %w", err)
+		}
+
+		var summaries []AddonSummary
+
+		if err := json.Unmarshal([]byte(run.Stdout), &summaries); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnparsableAddons, err)
+		}
+
+		return summaries, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]AddonSummary), nil
+}
+
+// diffAddons returns the human-readable differences of the declared addons from the installed ones.
+// Only the fields set in the spec are compared, so that e.g. an addon without `version` can be upgraded outside of Terraform.
+func diffAddons(declared []declaredAddon, installed []AddonSummary) map[string]string {
+	byName := map[string]AddonSummary{}
+
+	for _, a := range installed {
+		byName[a.Name] = a
+	}
+
+	drift := map[string]string{}
+
+	for _, a := range declared {
+		s, ok := byName[a.Name]
+		if !ok {
+			drift[a.Name] = addonNotInstalled
+
+			continue
+		}
+
+		var diffs []string
+
+		if a.Version != "" && a.Version != "latest" && !addonVersionMatches(a.Version, s.Version) {
+			diffs = append(diffs, fmt.Sprintf("version %s (declared %s)", s.Version, a.Version))
+		}
+
+		if a.ServiceAccountRoleARN != "" && !strings.EqualFold(a.ServiceAccountRoleARN, s.IAMRole) {
+			diffs = append(diffs, fmt.Sprintf("service account role %s (declared %s)", s.IAMRole, a.ServiceAccountRoleARN))
+		}
+
+		if a.ConfigurationValues != "" && !addonConfigurationValuesMatch(a.ConfigurationValues, s.ConfigurationValues) {
+			diffs = append(diffs, "configuration values modified")
+		}
+
+		if len(diffs) > 0 {
+			drift[a.Name] = strings.Join(diffs, ", ")
+		}
+	}
+
+	return drift
+}
+
+// addonVersionMatches returns true when the installed version is the declared one.
+// A declared version without the build suffix like `v1.7.5` matches any build of it like `v1.7.5-eksbuild.1`.
+func addonVersionMatches(declared, installed string) bool {
+	declared, installed = strings.TrimPrefix(declared, "v"), strings.TrimPrefix(installed, "v")
+
+	return declared == installed || strings.HasPrefix(installed, declared+"-")
+}
+
+// addonConfigurationValuesMatch compares the configuration values as YAML, which is a superset of JSON,
+// so that formatting differences aren't reported as drift
+func addonConfigurationValuesMatch(declared, installed string) bool {
+	var d, i interface{}
+
+	if err := yaml.Unmarshal([]byte(declared), &d); err != nil {
+		return declared == installed
+	}
+
+	if err := yaml.Unmarshal([]byte(installed), &i); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(d, i)
+}
+
+// loadAddonDrift sets `addon_drift` to the differences of the installed addons from the ones declared in the spec.
+// Failures running `eksctl get addon` are logged and never fail the read, as e.g. eksctl older than 0.35.0 doesn't support it.
+// An unparsable output fails the read, as it would otherwise hide any drift silently.
+func loadAddonDrift(d ReadWrite, cluster *Cluster) error {
+	drift := map[string]interface{}{}
+
+	if err := func() error {
+		declared, err := getDeclaredAddons(cluster.Spec)
+		if err != nil {
+			return err
+		}
+
+		if len(declared) == 0 {
+			return nil
+		}
+
+		installed, err := runGetAddons(d, cluster)
+		if err != nil {
+			return err
+		}
+
+		for name, diff := range diffAddons(declared, installed) {
+			log.Printf("[WARN] addon %s of cluster %s drifted from the spec: %s", name, cluster.Name, diff)
+
+			drift[name] = diff
+		}
+
+		return nil
+	}(); errors.Is(err, errUnparsableAddons) {
+		return fmt.Errorf("detecting addon drift: %w", err)
+	} else if err != nil {
+		log.Printf("[WARN] failed detecting addon drift: %v", err)
+	}
+
+	if err := d.Set(KeyAddonDrift, drift); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyAddonDrift, err)
+	}
+
+	return nil
+}
+
+// planAddonDrift plans clearing the drift, so that `terraform plan` shows the addons modified outside of Terraform
+// and `terraform apply` brings them back to the spec.
+func planAddonDrift(d *schema.ResourceDiff) error {
+	drift, _ := d.Get(KeyAddonDrift).(map[string]interface{})
+	if len(drift) == 0 {
+		return nil
+	}
+
+	return d.SetNew(KeyAddonDrift, map[string]interface{}{})
+}
+
+// driftedAddons returns the names of the drifted addons whose drift is planned to be cleared,
+// split into the ones to be created and the ones to be updated
+func driftedAddons(d interface {
+	GetChange(string) (interface{}, interface{})
+}) ([]string, []string) {
+	o, n := d.GetChange(KeyAddonDrift)
+
+	drifted, _ := o.(map[string]interface{})
+	remaining, _ := n.(map[string]interface{})
+
+	var missing, modified []string
+
+	for name, diff := range drifted {
+		if _, ok := remaining[name]; ok {
+			continue
+		}
+
+		if diff == addonNotInstalled {
+			missing = append(missing, name)
+		} else {
+			modified = append(modified, name)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(modified)
+
+	return missing, modified
+}
+
+// filterAddons returns the cluster config with only the named addons, so that eksctl doesn't touch the other ones
+func filterAddons(clusterConfig []byte, names []string) ([]byte, error) {
+	config := map[string]interface{}{}
+
+	if err := yaml.Unmarshal(clusterConfig, &config); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	keep := map[string]bool{}
+
+	for _, n := range names {
+		keep[n] = true
+	}
+
+	addons, _ := config["addons"].([]interface{})
+
+	var filtered []interface{}
+
+	for _, a := range addons {
+		if m, ok := a.(map[string]interface{}); ok && keep[fmt.Sprintf("%v", m["name"])] {
+			filtered = append(filtered, a)
+		}
+	}
+
+	config["addons"] = filtered
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// doReconcileAddonDrift creates the declared addons that were deleted, and updates the ones modified outside of Terraform
+// back to the spec
+func doReconcileAddonDrift(d *schema.ResourceData, cluster *Cluster, clusterConfig []byte) error {
+	missing, modified := driftedAddons(d)
+
+	for _, c := range []struct {
+		verb  string
+		names []string
+	}{
+		{verb: "create", names: missing},
+		{verb: "update", names: modified},
+	} {
+		if len(c.names) == 0 {
+			continue
+		}
+
+		log.Printf("Running eksctl %s addon for the drifted addons %s of cluster %s", c.verb, strings.Join(c.names, ", "), cluster.Name)
+
+		config, err := filterAddons(clusterConfig, c.names)
+		if err != nil {
+			return err
+		}
+
+		cmd, err := newEksctlCommandWithAWSProfile(cluster, c.verb, "addon", "-f", "-")
+		if err != nil {
+			return fmt.Errorf("creating eksctl-%s-addon command: %w", c.verb, err)
+		}
+
+		cmd.Stdin = bytes.NewReader(config)
+
+		if _, err := resource.Run(cmd); err != nil {
+			return fmt.Errorf("reconciling drifted addons %s: %w", strings.Join(c.names, ", "), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAddons(t *testing.T) {
+	declared, err := getDeclaredAddons(`
+addons:
+- name: vpc-cni
+  version: v1.7.5
+  serviceAccountRoleARN: arn:aws:iam::123456789012:role/vpc-cni
+- name: coredns
+  version: latest
+- name: kube-proxy
+  version: v1.18.8-eksbuild.1
+- name: aws-ebs-csi-driver
+  configurationValues: '{"controller":{"replicaCount":2}}'
+- name: adot
+`)
+	require.NoError(t, err)
+
+	drift := diffAddons(declared, []AddonSummary{
+		{Name: "vpc-cni", Version: "v1.7.5-eksbuild.1", IAMRole: "arn:aws:iam::123456789012:role/vpc-cni"},
+		{Name: "coredns", Version: "v1.8.0-eksbuild.1"},
+		{Name: "kube-proxy", Version: "v1.18.9-eksbuild.1"},
+		{Name: "aws-ebs-csi-driver", Version: "v1.5.0-eksbuild.1", ConfigurationValues: "controller:\n  replicaCount: 3\n"},
+	})
+
+	assert.Equal(t, map[string]string{
+		"kube-proxy":         "version v1.18.9-eksbuild.1 (declared v1.18.8-eksbuild.1)",
+		"aws-ebs-csi-driver": "configuration values modified",
+		"adot":               addonNotInstalled,
+	}, drift)
+}
+
+func TestAddonConfigurationValuesMatch(t *testing.T) {
+	assert.True(t, addonConfigurationValuesMatch(`{"controller": {"replicaCount": 2}}`, "controller:\n  replicaCount: 2\n"))
+	assert.False(t, addonConfigurationValuesMatch(`{"controller": {"replicaCount": 2}}`, ""))
+}
+
+func TestDriftedAddons(t *testing.T) {
+	d := changeRead{
+		KeyAddonDrift: {
+			map[string]interface{}{"adot": addonNotInstalled, "kube-proxy": "version v1.18.9-eksbuild.1 (declared v1.18.8-eksbuild.1)"},
+			map[string]interface{}{},
+		},
+	}
+
+	missing, modified := driftedAddons(d)
+	assert.Equal(t, []string{"adot"}, missing)
+	assert.Equal(t, []string{"kube-proxy"}, modified)
+}
+
+func TestFilterAddons(t *testing.T) {
+	config, err := filterAddons([]byte(`
+apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+addons:
+- name: vpc-cni
+- name: kube-proxy
+  version: v1.18.8-eksbuild.1
+`), []string{"kube-proxy"})
+	require.NoError(t, err)
+	assert.Equal(t, `addons:
+  - name: kube-proxy
+    version: v1.18.8-eksbuild.1
+apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+`, string(config))
+}
+
+func TestRunGetAddons_fakeEksctl(t *testing.T) {
+	var args []string
+
+	prev := resource.SetCommandRunner(resource.CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*resource.CommandResult, error) {
+		args = cmd.Args[1:]
+
+		return &resource.CommandResult{
+			Output: "warning: deprecated flag\n" + `[{"Name":"vpc-cni","Version":"v1.7.5-eksbuild.1","Status":"ACTIVE"}]`,
+			Stdout: `[{"Name":"vpc-cni","Version":"v1.7.5-eksbuild.1","Status":"ACTIVE"}]`,
+		}, nil
+	}))
+	defer resource.SetCommandRunner(prev)
+
+	d := mapRead{
+		KeyBin:           "fake-eksctl",
+		KeyEksctlVersion: "",
+		KeyRegion:        "us-east-2",
+		KeyProfile:       "",
+	}
+
+	addons, err := runGetAddons(d, &Cluster{Name: "fake-addons", Region: "us-east-2"})
+	require.NoError(t, err)
+	assert.Equal(t, []AddonSummary{{Name: "vpc-cni", Version: "v1.7.5-eksbuild.1", Status: "ACTIVE"}}, addons)
+	assert.Equal(t, []string{"get", "addon", "--cluster", "fake-addons", "-o", "json", "--region", "us-east-2"}, args)
+}
+
+func TestRunGetAddons_unparsable(t *testing.T) {
+	prev := resource.SetCommandRunner(resource.CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*resource.CommandResult, error) {
+		return &resource.CommandResult{Output: "no addons", Stdout: "no addons"}, nil
+	}))
+	defer resource.SetCommandRunner(prev)
+
+	d := mapRead{
+		KeyBin:           "fake-eksctl",
+		KeyEksctlVersion: "",
+		KeyRegion:        "us-east-2",
+		KeyProfile:       "",
+	}
+
+	_, err := runGetAddons(d, &Cluster{Name: "fake-unparsable-addons", Region: "us-east-2"})
+	assert.True(t, errors.Is(err, errUnparsableAddons), "unexpected error: %v", err)
+}
@@ -142,10 +142,14 @@ func planListenerChanges(cluster *Cluster, oldId, newId string) (ListenerStatuse
 
 		a := listenerStatus.ALBAttachments[0]
 
+		if err := validateALBAttachment(cluster, a); err != nil {
+			return nil, err
+		}
+
 		// We need to determine the current tg first.
 		// Otherwise the desired and the current tg points to the same tg, which isn't what we want here.
 		if oldId != "" {
-			currentTGName := fmt.Sprintf("%s-%d-%s", a.NodeGroupName, a.NodePort, oldId)
+			currentTGName := albAttachmentTargetGroupName(a, oldId)
 			result, err := svc.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
 				Names: []*string{aws.String(currentTGName)},
 			})
@@ -170,23 +174,20 @@ func planListenerChanges(cluster *Cluster, oldId, newId string) (ListenerStatuse
 		}
 
 		if newId != "" {
-			desiredTGName := fmt.Sprintf("%s-%d-%s", a.NodeGroupName, a.NodePort, newId)
+			desiredTGName := albAttachmentTargetGroupName(a, newId)
 
 			if len(desiredTGName) > 32 {
 				return nil, fmt.Errorf("creating target group %s for cluster %s: target group name too long. it must be shorter than 33, but was %d", desiredTGName, cluster.Name, len(desiredTGName))
 			}
 
-			var targetType string
-
-			if a.NodePort != 0 {
-				targetType = "instance"
-			} else {
-				return nil, fmt.Errorf("BUG: alb_attachment.node_port cannot be omitted yet: %v", a)
+			targetType := TargetTypeInstance
+			if a.TargetType == TargetTypeIP {
+				targetType = TargetTypeIP
 			}
 
 			createTgInput := &elbv2.CreateTargetGroupInput{
 				Name:       aws.String(desiredTGName),
-				Port:       aws.Int64(int64(a.NodePort)),
+				Port:       aws.Int64(int64(albAttachmentTargetGroupPort(a))),
 				TargetType: aws.String(targetType),
 				VpcId:      aws.String(cluster.VPCID),
 				Protocol:   aws.String(strings.ToUpper(a.Protocol)),
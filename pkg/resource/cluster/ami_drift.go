@@ -0,0 +1,332 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KeyAMIDrift          = "ami_drift"
+	KeyNodeGroupAMIDrift = "nodegroup_ami_drift"
+
+	// AMIDriftIgnore never looks up the latest AMIs
+	AMIDriftIgnore = "ignore"
+	// AMIDriftWarn reports the nodegroups running outdated AMIs in `nodegroup_ami_drift` and the logs
+	AMIDriftWarn = "warn"
+	// AMIDriftReplace also plans upgrading the managed nodegroups running outdated AMIs to the latest ones
+	AMIDriftReplace = "replace"
+)
+
+func amiDriftSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      AMIDriftIgnore,
+		ValidateFunc: validation.StringInSlice([]string{AMIDriftIgnore, AMIDriftWarn, AMIDriftReplace}, false),
+	}
+}
+
+// armInstanceTypePattern matches the Graviton instance types like m6g.large and c6gn.xlarge, and a1 instances
+var armInstanceTypePattern = regexp.MustCompile(`^(a1|[a-z]+[0-9]+g[a-z]*)\.`)
+
+// nodeGroupAMI is the part of the nodegroup in the spec that determines the AMI
+type nodeGroupAMI struct {
+	Name          string   `yaml:"name"`
+	AMIFamily     string   `yaml:"amiFamily"`
+	AMI           string   `yaml:"ami"`
+	InstanceType  string   `yaml:"instanceType"`
+	InstanceTypes []string `yaml:"instanceTypes"`
+}
+
+func getNodeGroupAMIs(spec string) ([]nodeGroupAMI, error) {
+	var config struct {
+		NodeGroups        []nodeGroupAMI `yaml:"nodeGroups"`
+		ManagedNodeGroups []nodeGroupAMI `yaml:"managedNodeGroups"`
+	}
+
+	if err := yaml.Unmarshal([]byte(spec), &config); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	return append(config.NodeGroups, config.ManagedNodeGroups...), nil
+}
+
+// latestAMIParameter returns the name of the SSM public parameter for the latest EKS-optimized AMI for the nodegroup.
+// False is returned for custom AMIs and the AMI families without the parameters.
+func latestAMIParameter(ng nodeGroupAMI, k8sVersion string) (string, bool) {
+	if ng.AMI != "" && ng.AMI != "auto" && ng.AMI != "auto-ssm" {
+		return "", false
+	}
+
+	instanceType := ng.InstanceType
+	if instanceType == "" && len(ng.InstanceTypes) > 0 {
+		instanceType = ng.InstanceTypes[0]
+	}
+
+	arm := armInstanceTypePattern.MatchString(instanceType)
+
+	switch ng.AMIFamily {
+	case "", "AmazonLinux2":
+		image := "amazon-linux-2"
+		if arm {
+			image = "amazon-linux-2-arm64"
+		}
+
+		return fmt.Sprintf("/aws/service/eks/optimized-ami/%s/%s/recommended/image_id", k8sVersion, image), true
+	case "Bottlerocket":
+		arch := "x86_64"
+		if arm {
+			arch = "arm64"
+		}
+
+		return fmt.Sprintf("/aws/service/bottlerocket/aws-k8s-%s/%s/latest/image_id", k8sVersion, arch), true
+	}
+
+	return "", false
+}
+
+// loadAMIDrift sets `nodegroup_ami_drift` to the latest AMIs keyed by the names of the nodegroups running outdated AMIs.
+// Failures are logged and never fail the read, as the drift is informational until the policy is "replace".
+func loadAMIDrift(d ReadWrite, cluster *Cluster, summaries []NodeGroupSummary) error {
+	drift := map[string]interface{}{}
+
+	if cluster.AMIDrift != "" && cluster.AMIDrift != AMIDriftIgnore {
+		latest, err := detectAMIDrift(d, cluster, summaries)
+		if err != nil {
+			log.Printf("[WARN] failed detecting nodegroup AMI drift: %v", err)
+
+			return nil
+		}
+
+		for ng, ami := range latest {
+			log.Printf("[WARN] nodegroup %s of cluster %s is running an outdated AMI. The latest AMI is %s", ng, cluster.Name, ami)
+
+			drift[ng] = ami
+		}
+	}
+
+	if err := d.Set(KeyNodeGroupAMIDrift, drift); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyNodeGroupAMIDrift, err)
+	}
+
+	return nil
+}
+
+func detectAMIDrift(d Read, cluster *Cluster, summaries []NodeGroupSummary) (map[string]string, error) {
+	state, err := runGetCluster(d, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	ngs, err := getNodeGroupAMIs(cluster.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	asgs := map[string]string{}
+
+	for _, s := range summaries {
+		if s.AutoScalingGroupName != "" {
+			asgs[s.Name] = s.AutoScalingGroupName
+		}
+	}
+
+	sess := AWSSessionFromCluster(cluster)
+
+	var (
+		asgNames   []string
+		parameters = map[string]string{}
+	)
+
+	for _, ng := range ngs {
+		if _, ok := asgs[ng.Name]; !ok {
+			continue
+		}
+
+		if p, ok := latestAMIParameter(ng, state.Version); ok {
+			parameters[ng.Name] = p
+			asgNames = append(asgNames, asgs[ng.Name])
+		}
+	}
+
+	if len(parameters) == 0 {
+		return nil, nil
+	}
+
+	launchTemplates, err := getAutoScalingGroupLaunchTemplates(cluster, asgNames)
+	if err != nil {
+		return nil, err
+	}
+
+	latestAMIs := map[string]string{}
+	drift := map[string]string{}
+
+	for ng, p := range parameters {
+		lt, ok := launchTemplates[asgs[ng]]
+		if !ok {
+			continue
+		}
+
+		current, err := getLaunchTemplateImageID(sess, lt)
+		if err != nil {
+			return nil, err
+		}
+
+		latest, ok := latestAMIs[p]
+		if !ok {
+			latest, err = getSSMParameter(sess, p)
+			if err != nil {
+				return nil, err
+			}
+
+			latestAMIs[p] = latest
+		}
+
+		if current != "" && current != latest {
+			drift[ng] = latest
+		}
+	}
+
+	return drift, nil
+}
+
+func getLaunchTemplateImageID(sess *session.Session, lt launchTemplateRef) (string, error) {
+	res, err := ec2.New(sess).DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String(lt.ID),
+		Versions:         aws.StringSlice([]string{lt.Version}),
+	})
+	if err != nil {
+		return "", fmt.Errorf("describing launch template %s version %s: %w", lt.ID, lt.Version, err)
+	}
+
+	if len(res.LaunchTemplateVersions) == 0 || res.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return "", nil
+	}
+
+	return aws.StringValue(res.LaunchTemplateVersions[0].LaunchTemplateData.ImageId), nil
+}
+
+func getSSMParameter(sess *session.Session, name string) (string, error) {
+	res, err := ssm.New(sess).GetParameter(&ssm.GetParameterInput{
+		Name: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting ssm parameter %s: %w", name, err)
+	}
+
+	return aws.StringValue(res.Parameter.Value), nil
+}
+
+// planAMIDrift plans clearing the drift of the managed nodegroups when the policy is "replace",
+// so that the nodegroups are upgraded to the latest AMIs on apply.
+// Unmanaged nodegroups can't be upgraded in place, so their drift is left as is.
+func planAMIDrift(d *schema.ResourceDiff) error {
+	if d.Get(KeyAMIDrift).(string) != AMIDriftReplace {
+		return nil
+	}
+
+	drift, _ := d.Get(KeyNodeGroupAMIDrift).(map[string]interface{})
+	if len(drift) == 0 {
+		return nil
+	}
+
+	spec, err := getSpec(d)
+	if err != nil {
+		return err
+	}
+
+	managed, err := getManagedNodeGroupNames(spec)
+	if err != nil {
+		return err
+	}
+
+	remaining := map[string]interface{}{}
+
+	for ng, ami := range drift {
+		remaining[ng] = ami
+	}
+
+	for _, ng := range managed {
+		delete(remaining, ng)
+	}
+
+	if len(remaining) == len(drift) {
+		return nil
+	}
+
+	return d.SetNew(KeyNodeGroupAMIDrift, remaining)
+}
+
+// driftedNodeGroupsToReplace returns the names of the nodegroups whose drift is planned to be cleared
+func driftedNodeGroupsToReplace(d interface {
+	GetChange(string) (interface{}, interface{})
+}) []string {
+	o, n := d.GetChange(KeyNodeGroupAMIDrift)
+
+	drifted, _ := o.(map[string]interface{})
+	remaining, _ := n.(map[string]interface{})
+
+	var names []string
+
+	for ng := range drifted {
+		if _, ok := remaining[ng]; !ok {
+			names = append(names, ng)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// doReplaceDriftedNodeGroups upgrades the managed nodegroups running outdated AMIs to the latest AMIs for the current Kubernetes version
+func doReplaceDriftedNodeGroups(d *schema.ResourceData, cluster *Cluster, clusterName ClusterName) error {
+	if cluster.AMIDrift != AMIDriftReplace {
+		return nil
+	}
+
+	u := NodeGroupUpgrade{Parallelism: 1}
+	if cluster.NodeGroupUpgrade != nil {
+		u = *cluster.NodeGroupUpgrade
+	}
+
+	var tasks []func() error
+
+	for _, n := range driftedNodeGroupsToReplace(d) {
+		n := n
+
+		tasks = append(tasks, func() error {
+			log.Printf("Upgrading nodegroup %s of cluster %s to the latest AMI", n, clusterName)
+
+			args := []string{"upgrade", "nodegroup", "--cluster", string(clusterName), "--name", n, "--region", cluster.Region}
+
+			if u.ForceUpgrade {
+				args = append(args, "--force-upgrade")
+			}
+
+			cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+			if err != nil {
+				return fmt.Errorf("creating eksctl-upgrade-nodegroup command: %w", err)
+			}
+
+			if _, err := resource.Run(cmd); err != nil {
+				return fmt.Errorf("upgrading nodegroup %s to the latest AMI: %w", n, err)
+			}
+
+			return nil
+		})
+	}
+
+	return runWithParallelism(u.Parallelism, tasks)
+}
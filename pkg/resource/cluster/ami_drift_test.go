@@ -0,0 +1,53 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestAMIParameter(t *testing.T) {
+	ngs, err := getNodeGroupAMIs(`
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+- name: ng2
+  instanceType: m6g.large
+  amiFamily: Bottlerocket
+- name: ng3
+  ami: ami-0123456789abcdef0
+managedNodeGroups:
+- name: mng1
+  instanceTypes: [c6gn.large]
+- name: mng2
+  amiFamily: Ubuntu2004
+`)
+	require.NoError(t, err)
+	require.Len(t, ngs, 5)
+
+	var params []string
+
+	for _, ng := range ngs {
+		if p, ok := latestAMIParameter(ng, "1.18"); ok {
+			params = append(params, ng.Name+"="+p)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"ng1=/aws/service/eks/optimized-ami/1.18/amazon-linux-2/recommended/image_id",
+		"ng2=/aws/service/bottlerocket/aws-k8s-1.18/arm64/latest/image_id",
+		"mng1=/aws/service/eks/optimized-ami/1.18/amazon-linux-2-arm64/recommended/image_id",
+	}, params)
+}
+
+func TestDriftedNodeGroupsToReplace(t *testing.T) {
+	d := changeRead{
+		KeyNodeGroupAMIDrift: {
+			map[string]interface{}{"ng1": "ami-1", "mng1": "ami-2", "mng2": "ami-2"},
+			map[string]interface{}{"ng1": "ami-1"},
+		},
+	}
+
+	assert.Equal(t, []string{"mng1", "mng2"}, driftedNodeGroupsToReplace(d))
+}
@@ -15,7 +15,46 @@ func doAttachAutoScalingGroupsToTargetGroups(set *ClusterSet) error {
 		return nil
 	}
 
-	cfn := cloudformation.New(AWSSessionFromCluster(set.Cluster))
+	asgs, err := getNodeGroupAutoScalingGroups(set.Cluster, set.ClusterName)
+	if err != nil {
+		return fmt.Errorf("attaching autoscaling groups to target groups: %w", err)
+	}
+
+	asSvc := autoscaling.New(AWSSessionFromCluster(set.Cluster))
+
+	for ngName, asgName := range asgs {
+		var targetGroupARNS []*string
+
+		for _, l := range set.ListenerStatuses {
+			for _, a := range l.ALBAttachments {
+				if a.TargetType != TargetTypeIP && a.NodeGroupName == ngName {
+					targetGroupARNS = append(targetGroupARNS, l.DesiredTG.TargetGroupArn)
+				}
+			}
+		}
+
+		if len(targetGroupARNS) == 0 {
+			continue
+		}
+
+		_, asErr := asSvc.AttachLoadBalancerTargetGroups(&autoscaling.AttachLoadBalancerTargetGroupsInput{
+			AutoScalingGroupName: aws.String(asgName),
+			TargetGroupARNs:      targetGroupARNS,
+		})
+		if aerr, ok := asErr.(awserr.Error); ok {
+			return fmt.Errorf("attaching load balancer target groups: Code %s: %w", aerr.Code(), asErr)
+		} else if asErr != nil {
+			return fmt.Errorf("attaching load balancer target groups: unexpected error: %w", asErr)
+		}
+	}
+
+	return nil
+}
+
+// getNodeGroupAutoScalingGroups returns the names of the autoscaling groups of the cluster's nodegroups, keyed by the nodegroup names.
+// The autoscaling groups are found from the nodegroup stacks created by eksctl.
+func getNodeGroupAutoScalingGroups(cluster *Cluster, clusterName ClusterName) (map[string]string, error) {
+	cfn := cloudformation.New(AWSSessionFromCluster(cluster))
 
 	var stackSummaries []*cloudformation.StackSummary
 
@@ -32,7 +71,7 @@ func doAttachAutoScalingGroupsToTargetGroups(set *ClusterSet) error {
 			),
 		})
 		if err != nil {
-			return fmt.Errorf("attaching autoscaling groups to target groups: %w", err)
+			return nil, fmt.Errorf("listing stacks: %w", err)
 		}
 
 		stackSummaries = append(stackSummaries, res.StackSummaries...)
@@ -44,54 +83,31 @@ func doAttachAutoScalingGroupsToTargetGroups(set *ClusterSet) error {
 		}
 	}
 
-	stackNamePrefix := fmt.Sprintf("eksctl-%s-nodegroup-", set.ClusterName)
+	stackNamePrefix := fmt.Sprintf("eksctl-%s-nodegroup-", clusterName)
 
 	log.Printf("Finding stacks whose name is prefixd with %q from %d stack summaries", stackNamePrefix, len(stackSummaries))
 
-	asSvc := autoscaling.New(AWSSessionFromCluster(set.Cluster))
+	asgs := map[string]string{}
 
 	for _, s := range stackSummaries {
+		if !strings.HasPrefix(*s.StackName, stackNamePrefix) {
+			continue
+		}
 
-		if strings.HasPrefix(*s.StackName, stackNamePrefix) {
-			log.Printf("processing stack summary for %s", *s.StackName)
-
-			var targetGroupARNS []*string
-
-			ngName := strings.TrimPrefix(*s.StackName, stackNamePrefix)
-
-			for _, l := range set.ListenerStatuses {
-				for _, a := range l.ALBAttachments {
-					if a.NodeGroupName == ngName {
-						targetGroupARNS = append(targetGroupARNS, l.DesiredTG.TargetGroupArn)
-					}
-				}
-			}
-
-			if len(targetGroupARNS) == 0 {
-				continue
-			}
-
-			res, err := cfn.DescribeStackResource(&cloudformation.DescribeStackResourceInput{
-				LogicalResourceId: aws.String("NodeGroup"),
-				StackName:         s.StackName,
-			})
-			if err != nil {
-				return fmt.Errorf("describing stack resource for %s: %w", *s.StackName, err)
-			}
+		log.Printf("processing stack summary for %s", *s.StackName)
 
-			asgARN := *res.StackResourceDetail.PhysicalResourceId
+		ngName := strings.TrimPrefix(*s.StackName, stackNamePrefix)
 
-			_, asErr := asSvc.AttachLoadBalancerTargetGroups(&autoscaling.AttachLoadBalancerTargetGroupsInput{
-				AutoScalingGroupName: aws.String(asgARN),
-				TargetGroupARNs:      targetGroupARNS,
-			})
-			if aerr, ok := asErr.(awserr.Error); ok {
-				return fmt.Errorf("attaching load balancer target groups: Code %s: %w", aerr.Code(), asErr)
-			} else if asErr != nil {
-				return fmt.Errorf("attaching load balancer target groups: unexpected error: %w", asErr)
-			}
+		res, err := cfn.DescribeStackResource(&cloudformation.DescribeStackResourceInput{
+			LogicalResourceId: aws.String("NodeGroup"),
+			StackName:         s.StackName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing stack resource for %s: %w", *s.StackName, err)
 		}
+
+		asgs[ngName] = *res.StackResourceDetail.PhysicalResourceId
 	}
 
-	return nil
+	return asgs, nil
 }
@@ -0,0 +1,299 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const KeyAutoscaler = "autoscaler"
+
+const (
+	DefaultClusterAutoscalerImage = "registry.k8s.io/autoscaling/cluster-autoscaler"
+
+	clusterAutoscalerNamespace          = "kube-system"
+	clusterAutoscalerServiceAccountName = "cluster-autoscaler"
+)
+
+// Autoscaler is the opt-in cluster-autoscaler bootstrap.
+// The IRSA role and the service account are created by eksctl along with the cluster,
+// and the deployment is applied right after the cluster is created.
+type Autoscaler struct {
+	Image string
+	// ImageTag defaults to the patch version 0 of the cluster's Kubernetes version, as cluster-autoscaler's minor version
+	// must match the Kubernetes minor version.
+	ImageTag string
+}
+
+func autoscalerSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"image": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  DefaultClusterAutoscalerImage,
+				},
+				"image_tag": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+			},
+		},
+	}
+}
+
+func readAutoscaler(v interface{}) *Autoscaler {
+	autoscalers := v.([]interface{})
+	if len(autoscalers) == 0 {
+		return nil
+	}
+
+	a := &Autoscaler{Image: DefaultClusterAutoscalerImage}
+
+	if autoscalers[0] == nil {
+		return a
+	}
+
+	m := autoscalers[0].(map[string]interface{})
+
+	if image := m["image"].(string); image != "" {
+		a.Image = image
+	}
+
+	a.ImageTag = m["image_tag"].(string)
+
+	return a
+}
+
+// enableAutoscaler adds the IRSA service account for cluster-autoscaler and the auto-discovery tags
+// to the cluster.yaml.
+// Tags are added only to unmanaged nodegroups, as EKS tags the autoscaling groups of managed nodegroups by itself.
+func enableAutoscaler(c *EksctlClusterConfig, clusterName ClusterName) {
+	addWellKnownPolicyServiceAccount(c, clusterAutoscalerNamespace, clusterAutoscalerServiceAccountName, "autoScaler")
+
+	for i := range c.NodeGroups {
+		ng := &c.NodeGroups[i]
+
+		if ng.Rest == nil {
+			ng.Rest = map[string]interface{}{}
+		}
+
+		tags, _ := ng.Rest["tags"].(map[string]interface{})
+		if tags == nil {
+			tags = map[string]interface{}{}
+		}
+
+		tags["k8s.io/cluster-autoscaler/enabled"] = "true"
+		tags["k8s.io/cluster-autoscaler/"+string(clusterName)] = "owned"
+
+		ng.Rest["tags"] = tags
+	}
+}
+
+var clusterAutoscalerManifestTemplate = template.Must(template.New("cluster-autoscaler").Parse(`apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cluster-autoscaler
+  labels:
+    k8s-addon: cluster-autoscaler.addons.k8s.io
+    k8s-app: cluster-autoscaler
+rules:
+- apiGroups: [""]
+  resources: ["events", "endpoints"]
+  verbs: ["create", "patch"]
+- apiGroups: [""]
+  resources: ["pods/eviction"]
+  verbs: ["create"]
+- apiGroups: [""]
+  resources: ["pods/status"]
+  verbs: ["update"]
+- apiGroups: [""]
+  resources: ["endpoints"]
+  resourceNames: ["cluster-autoscaler"]
+  verbs: ["get", "update"]
+- apiGroups: [""]
+  resources: ["nodes"]
+  verbs: ["watch", "list", "get", "update"]
+- apiGroups: [""]
+  resources: ["namespaces", "pods", "services", "replicationcontrollers", "persistentvolumeclaims", "persistentvolumes"]
+  verbs: ["watch", "list", "get"]
+- apiGroups: ["extensions"]
+  resources: ["replicasets", "daemonsets"]
+  verbs: ["watch", "list", "get"]
+- apiGroups: ["policy"]
+  resources: ["poddisruptionbudgets"]
+  verbs: ["watch", "list"]
+- apiGroups: ["apps"]
+  resources: ["statefulsets", "replicasets", "daemonsets"]
+  verbs: ["watch", "list", "get"]
+- apiGroups: ["storage.k8s.io"]
+  resources: ["storageclasses", "csinodes", "csidrivers", "csistoragecapacities"]
+  verbs: ["watch", "list", "get"]
+- apiGroups: ["batch", "extensions"]
+  resources: ["jobs"]
+  verbs: ["get", "list", "watch", "patch"]
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["create"]
+- apiGroups: ["coordination.k8s.io"]
+  resourceNames: ["cluster-autoscaler"]
+  resources: ["leases"]
+  verbs: ["get", "update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: cluster-autoscaler
+  namespace: {{ .Namespace }}
+  labels:
+    k8s-addon: cluster-autoscaler.addons.k8s.io
+    k8s-app: cluster-autoscaler
+rules:
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["create", "list", "watch"]
+- apiGroups: [""]
+  resources: ["configmaps"]
+  resourceNames: ["cluster-autoscaler-status", "cluster-autoscaler-priority-expander"]
+  verbs: ["delete", "get", "update", "watch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: cluster-autoscaler
+  labels:
+    k8s-addon: cluster-autoscaler.addons.k8s.io
+    k8s-app: cluster-autoscaler
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-autoscaler
+subjects:
+- kind: ServiceAccount
+  name: {{ .ServiceAccountName }}
+  namespace: {{ .Namespace }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: cluster-autoscaler
+  namespace: {{ .Namespace }}
+  labels:
+    k8s-addon: cluster-autoscaler.addons.k8s.io
+    k8s-app: cluster-autoscaler
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: cluster-autoscaler
+subjects:
+- kind: ServiceAccount
+  name: {{ .ServiceAccountName }}
+  namespace: {{ .Namespace }}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cluster-autoscaler
+  namespace: {{ .Namespace }}
+  labels:
+    app: cluster-autoscaler
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: cluster-autoscaler
+  template:
+    metadata:
+      labels:
+        app: cluster-autoscaler
+      annotations:
+        cluster-autoscaler.kubernetes.io/safe-to-evict: "false"
+    spec:
+      priorityClassName: system-cluster-critical
+      serviceAccountName: {{ .ServiceAccountName }}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 65534
+        fsGroup: 65534
+      containers:
+      - name: cluster-autoscaler
+        image: {{ .Image }}:{{ .ImageTag }}
+        resources:
+          limits:
+            cpu: 100m
+            memory: 600Mi
+          requests:
+            cpu: 100m
+            memory: 600Mi
+        command:
+        - ./cluster-autoscaler
+        - --v=4
+        - --stderrthreshold=info
+        - --cloud-provider=aws
+        - --skip-nodes-with-local-storage=false
+        - --expander=least-waste
+        - --node-group-auto-discovery=asg:tag=k8s.io/cluster-autoscaler/enabled,k8s.io/cluster-autoscaler/{{ .ClusterName }}
+        - --balance-similar-node-groups
+        - --skip-nodes-with-system-pods=false
+`))
+
+func renderClusterAutoscalerManifest(a *Autoscaler, clusterName ClusterName, kubernetesVersion string) (string, error) {
+	tag := a.ImageTag
+	if tag == "" {
+		tag = fmt.Sprintf("v%s.0", kubernetesVersion)
+	}
+
+	var buf bytes.Buffer
+
+	if err := clusterAutoscalerManifestTemplate.Execute(&buf, map[string]string{
+		"Namespace":          clusterAutoscalerNamespace,
+		"ServiceAccountName": clusterAutoscalerServiceAccountName,
+		"Image":              a.Image,
+		"ImageTag":           tag,
+		"ClusterName":        string(clusterName),
+	}); err != nil {
+		return "", fmt.Errorf("rendering cluster-autoscaler manifest: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// doDeployClusterAutoscaler applies the cluster-autoscaler deployment pinned to the cluster's Kubernetes version.
+// It's applied on every update too, so that cluster-autoscaler follows cluster upgrades.
+func doDeployClusterAutoscaler(cluster *Cluster, clusterName ClusterName) error {
+	if cluster.Autoscaler == nil {
+		return nil
+	}
+
+	manifest, err := renderClusterAutoscalerManifest(cluster.Autoscaler, clusterName, cluster.Version)
+	if err != nil {
+		return err
+	}
+
+	kubeconfigPath, err := writeTempKubeconfig(cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig for deploying cluster-autoscaler: %w", err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	kubectlCmd, err := newKubectlCommand(cluster, kubeconfigPath, kubectlApplyArgs(cluster)...)
+	if err != nil {
+		return err
+	}
+
+	kubectlCmd.Stdin = bytes.NewBufferString(manifest)
+
+	if _, err := resource.Run(kubectlCmd); err != nil {
+		return fmt.Errorf("deploying cluster-autoscaler: %w", err)
+	}
+
+	return nil
+}
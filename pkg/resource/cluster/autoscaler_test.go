@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnableAutoscaler(t *testing.T) {
+	var c EksctlClusterConfig
+
+	err := yaml.Unmarshal([]byte(`
+iam:
+  serviceAccounts:
+  - metadata:
+      name: app
+nodeGroups:
+- name: ng1
+  tags:
+    team: a
+`), &c)
+	assert.NoError(t, err)
+
+	enableAutoscaler(&c, "prod-abc")
+
+	assert.True(t, c.IAM.WithOIDC)
+	assert.Len(t, c.IAM.Rest["serviceAccounts"], 2)
+	assert.Equal(t, map[string]interface{}{
+		"team":                               "a",
+		"k8s.io/cluster-autoscaler/enabled":  "true",
+		"k8s.io/cluster-autoscaler/prod-abc": "owned",
+	}, c.NodeGroups[0].Rest["tags"])
+
+	// Enabling twice doesn't duplicate the service account
+	enableAutoscaler(&c, "prod-abc")
+
+	assert.Len(t, c.IAM.Rest["serviceAccounts"], 2)
+}
+
+func TestRenderClusterAutoscalerManifest(t *testing.T) {
+	manifest, err := renderClusterAutoscalerManifest(&Autoscaler{Image: DefaultClusterAutoscalerImage}, "prod-abc", "1.18")
+
+	assert.NoError(t, err)
+	assert.Contains(t, manifest, "image: registry.k8s.io/autoscaling/cluster-autoscaler:v1.18.0")
+	assert.Contains(t, manifest, "k8s.io/cluster-autoscaler/prod-abc")
+
+	manifest, err = renderClusterAutoscalerManifest(&Autoscaler{Image: "example.com/ca", ImageTag: "v1.18.3"}, "prod-abc", "1.18")
+
+	assert.NoError(t, err)
+	assert.Contains(t, manifest, "image: example.com/ca:v1.18.3")
+}
@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// awsAuthMapping is an entry in either `mapRoles` or `mapUsers` of the aws-auth ConfigMap,
+// or in the output of `eksctl get iamidentitymapping -o json`
+type awsAuthMapping struct {
+	RoleARN  string   `yaml:"rolearn" json:"rolearn,omitempty"`
+	UserARN  string   `yaml:"userarn" json:"userarn,omitempty"`
+	Username string   `yaml:"username" json:"username"`
+	Groups   []string `yaml:"groups" json:"groups"`
+}
+
+const (
+	awsAuthMappingKindRole = "role"
+	awsAuthMappingKindUser = "user"
+)
+
+// ARN returns either the role ARN or the user ARN
+func (m awsAuthMapping) ARN() string {
+	if m.RoleARN != "" {
+		return m.RoleARN
+	}
+
+	return m.UserARN
+}
+
+// Kind returns either "role" or "user", depending on whether the mapping is in `mapRoles` or `mapUsers`
+func (m awsAuthMapping) Kind() string {
+	if m.RoleARN != "" {
+		return awsAuthMappingKindRole
+	}
+
+	return awsAuthMappingKindUser
+}
+
+// flattenAWSAuthMappings converts the mappings into the value of `aws_auth_configmap`, sorted by the ARN for diff.
+func flattenAWSAuthMappings(mappings []awsAuthMapping) []map[string]interface{} {
+	sorted := make([]awsAuthMapping, len(mappings))
+	copy(sorted, mappings)
+
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ARN() < sorted[j].ARN() })
+
+	iams := make([]map[string]interface{}, 0, len(sorted))
+
+	for _, m := range sorted {
+		groups := make([]interface{}, 0, len(m.Groups))
+
+		for _, g := range m.Groups {
+			groups = append(groups, g)
+		}
+
+		iams = append(iams, map[string]interface{}{
+			"iamarn":   m.ARN(),
+			"username": m.Username,
+			"groups":   groups,
+		})
+	}
+
+	return iams
+}
+
+// getAWSAuthConfigMapMappings reads the aws-auth ConfigMap directly from the cluster with kubectl.
+// Unlike `eksctl get iamidentitymapping`, this doesn't require eksctl to render and rewrite the mappings,
+// and is much faster for drift detection.
+func getAWSAuthConfigMapMappings(d Read, cluster *Cluster) ([]awsAuthMapping, error) {
+	var kubeconfigPath string
+
+	if v := d.Get(KeyKubeconfigPath); v != nil {
+		kubeconfigPath = v.(string)
+	}
+
+	// The current context of a merged kubeconfig may point to another cluster
+	if kubeconfigPath == "" || kubeconfigMerge(d) {
+		path, err := writeTempKubeconfig(cluster, ClusterName(cluster.Name))
+		if err != nil {
+			return nil, fmt.Errorf("preparing kubeconfig for reading aws-auth: %w", err)
+		}
+		defer cluster.Provider.RemoveTempFile(path)
+
+		kubeconfigPath = path
+	} else if _, err := os.Stat(kubeconfigPath); err != nil {
+		return nil, fmt.Errorf("reading kubeconfig for reading aws-auth: %w", err)
+	}
+
+	kubectlCmd, err := newKubectlCommand(cluster, kubeconfigPath, "get", "configmap", "aws-auth", "-n", "kube-system", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := resource.Run(kubectlCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var cm struct {
+		Data map[string]string `json:"data"`
+	}
+
+	if err := json.Unmarshal([]byte(r.Stdout), &cm); err != nil {
+		return nil, fmt.Errorf("parsing aws-auth configmap: %w", err)
+	}
+
+	return parseAWSAuthConfigMapData(cm.Data)
+}
+
+// parseAWSAuthConfigMapData parses `mapRoles` and `mapUsers` in the `data` of the aws-auth ConfigMap.
+func parseAWSAuthConfigMapData(data map[string]string) ([]awsAuthMapping, error) {
+	var iams []awsAuthMapping
+
+	for _, key := range []string{"mapRoles", "mapUsers"} {
+		var mappings []awsAuthMapping
+
+		if err := yaml.Unmarshal([]byte(data[key]), &mappings); err != nil {
+			return nil, fmt.Errorf("parsing %s in aws-auth configmap: %w", key, err)
+		}
+
+		iams = append(iams, mappings...)
+	}
+
+	if accounts := strings.TrimSpace(data["mapAccounts"]); accounts != "" && accounts != "[]" {
+		log.Printf("aws-auth has mapAccounts that are not tracked in %s: %s", KeyAWSAuthConfigMap, accounts)
+	}
+
+	return iams, nil
+}
+
+// parseEksctlGetIAMIdentityMappingOutput parses the output of `eksctl get iamidentitymapping -o json`
+func parseEksctlGetIAMIdentityMappingOutput(output string) ([]awsAuthMapping, error) {
+	var iams []awsAuthMapping
+
+	if err := json.Unmarshal([]byte(output), &iams); err != nil {
+		return nil, fmt.Errorf("parse iamidentitymapping : %w", err)
+	}
+
+	return iams, nil
+}
@@ -0,0 +1,250 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyAWSAuthSources is the list of the sources of the aws-auth mappings, which are merged in order into `aws_auth_configmap` on plan
+const KeyAWSAuthSources = "aws_auth_sources"
+
+// awsAuthNodesGroup is the group of the nodegroup roles that eksctl adds to aws-auth
+const awsAuthNodesGroup = "system:nodes"
+
+func awsAuthSourcesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		ConflictsWith: []string{KeyAWSAuthConfigMap},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				// mapping is the inline set of mappings
+				"mapping": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"iamarn": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"username": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"groups": {
+								Type:     schema.TypeList,
+								Required: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+				// yaml is a YAML or JSON list of mappings, like `jsonencode(module.team.aws_auth_mappings)`
+				"yaml": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				// file is the path to a YAML or JSON file containing a list of mappings
+				"file": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+			},
+		},
+	}
+}
+
+// awsAuthSourceMapping is a mapping in `yaml` or `file` of aws_auth_sources.
+// Both the provider's `iamarn` and aws-auth's `rolearn` and `userarn` are accepted, so that mapRoles and mapUsers can be used as is.
+type awsAuthSourceMapping struct {
+	IAMARN   string   `yaml:"iamarn"`
+	RoleARN  string   `yaml:"rolearn"`
+	UserARN  string   `yaml:"userarn"`
+	Username string   `yaml:"username"`
+	Groups   []string `yaml:"groups"`
+}
+
+func (m awsAuthSourceMapping) arn() string {
+	for _, arn := range []string{m.IAMARN, m.RoleARN, m.UserARN} {
+		if arn != "" {
+			return arn
+		}
+	}
+
+	return ""
+}
+
+// readAWSAuthSources reads the mappings of each source in order
+func readAWSAuthSources(d Read) ([][]awsAuthSourceMapping, error) {
+	v, _ := d.Get(KeyAWSAuthSources).([]interface{})
+
+	var sources [][]awsAuthSourceMapping
+
+	for i, s := range v {
+		if s == nil {
+			continue
+		}
+
+		m := s.(map[string]interface{})
+
+		var mappings []awsAuthSourceMapping
+
+		for _, e := range m["mapping"].([]interface{}) {
+			em := e.(map[string]interface{})
+
+			mappings = append(mappings, awsAuthSourceMapping{
+				IAMARN:   em["iamarn"].(string),
+				Username: em["username"].(string),
+				Groups:   toStrings(em["groups"]),
+			})
+		}
+
+		// `yaml` and `file` are parsed separately, as concatenating a flow sequence and a block sequence isn't valid YAML
+		contents := []string{m["yaml"].(string)}
+
+		if path := m["file"].(string); path != "" {
+			bs, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%d: reading %s: %w", KeyAWSAuthSources, i, path, err)
+			}
+
+			contents = append(contents, string(bs))
+		}
+
+		for _, content := range contents {
+			if content == "" {
+				continue
+			}
+
+			var parsed []awsAuthSourceMapping
+
+			if err := yaml.Unmarshal([]byte(content), &parsed); err != nil {
+				return nil, fmt.Errorf("%s.%d: parsing mappings: %w", KeyAWSAuthSources, i, err)
+			}
+
+			mappings = append(mappings, parsed...)
+		}
+
+		for j, mapping := range mappings {
+			if mapping.arn() == "" {
+				return nil, fmt.Errorf("%s.%d: mapping %d has no iamarn, rolearn, or userarn", KeyAWSAuthSources, i, j)
+			}
+		}
+
+		sources = append(sources, mappings)
+	}
+
+	return sources, nil
+}
+
+// mergeAWSAuthSources merges the mappings of the sources in order, de-duplicating them by the ARN.
+// For an ARN in multiple sources, the groups are unioned and the username of the last source wins,
+// so that a team-level source can add groups to a platform-level mapping.
+func mergeAWSAuthSources(sources [][]awsAuthSourceMapping) ([]awsAuthMapping, error) {
+	var arns []string
+
+	merged := map[string]*awsAuthMapping{}
+
+	for _, mappings := range sources {
+		for _, m := range mappings {
+			arn := m.arn()
+
+			cur, ok := merged[arn]
+			if !ok {
+				cur = &awsAuthMapping{RoleARN: arn}
+				if strings.Contains(arn, ":user/") {
+					cur = &awsAuthMapping{UserARN: arn}
+				}
+
+				merged[arn] = cur
+				arns = append(arns, arn)
+			}
+
+			if m.Username != "" {
+				cur.Username = m.Username
+			}
+
+			cur.Groups = unionStrings(cur.Groups, m.Groups)
+		}
+	}
+
+	var result []awsAuthMapping
+
+	for _, arn := range arns {
+		m := merged[arn]
+
+		if m.Username == "" {
+			return nil, fmt.Errorf("mapping for %s has no username in any of %s", arn, KeyAWSAuthSources)
+		}
+
+		result = append(result, *m)
+	}
+
+	return result, nil
+}
+
+func unionStrings(a, b []string) []string {
+	seen := map[string]bool{}
+
+	var union []string
+
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			union = append(union, s)
+		}
+	}
+
+	return union
+}
+
+// planAWSAuthSources plans `aws_auth_configmap` to be the merged mappings of aws_auth_sources, so that
+// the mappings are reconciled the same way as the inline `aws_auth_configmap`.
+// The mappings of the nodegroup roles, which eksctl adds to aws-auth, are retained unless overridden by the sources,
+// so that nodes keep joining the cluster.
+func planAWSAuthSources(d *schema.ResourceDiff) error {
+	if v, _ := d.Get(KeyAWSAuthSources).([]interface{}); len(v) == 0 {
+		return nil
+	}
+
+	if !d.NewValueKnown(KeyAWSAuthSources) {
+		return d.SetNewComputed(KeyAWSAuthConfigMap)
+	}
+
+	sources, err := readAWSAuthSources(d)
+	if err != nil {
+		return err
+	}
+
+	merged, err := mergeAWSAuthSources(sources)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+
+	for _, m := range merged {
+		seen[m.ARN()] = true
+	}
+
+	if s, ok := d.Get(KeyAWSAuthConfigMap).(*schema.Set); ok {
+		for _, v := range s.List() {
+			m := v.(map[string]interface{})
+
+			arn := m["iamarn"].(string)
+
+			if !seen[arn] && containsString(m["groups"], awsAuthNodesGroup) {
+				merged = append(merged, awsAuthMapping{RoleARN: arn, Username: m["username"].(string), Groups: toStrings(m["groups"])})
+			}
+		}
+	}
+
+	return d.SetNew(KeyAWSAuthConfigMap, flattenAWSAuthMappings(merged))
+}
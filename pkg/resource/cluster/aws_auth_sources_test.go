@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeAWSAuthSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-auth-sources")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "team.yaml")
+
+	require.NoError(t, ioutil.WriteFile(file, []byte(`
+- rolearn: arn:aws:iam::123456789012:role/admin
+  groups:
+  - team-a:admin
+- userarn: arn:aws:iam::123456789012:user/alice
+  username: alice
+  groups:
+  - team-a:view
+`), 0644))
+
+	d := mapRead{
+		KeyAWSAuthSources: []interface{}{
+			map[string]interface{}{
+				"mapping": []interface{}{
+					map[string]interface{}{
+						"iamarn":   "arn:aws:iam::123456789012:role/admin",
+						"username": "admin",
+						"groups":   []interface{}{"system:masters"},
+					},
+				},
+				"yaml": "",
+				"file": "",
+			},
+			map[string]interface{}{
+				"mapping": []interface{}{},
+				"yaml":    `[{"iamarn": "arn:aws:iam::123456789012:role/ci", "username": "ci", "groups": ["system:masters"]}]`,
+				"file":    file,
+			},
+		},
+	}
+
+	sources, err := readAWSAuthSources(d)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+
+	merged, err := mergeAWSAuthSources(sources)
+	require.NoError(t, err)
+	assert.Equal(t, []awsAuthMapping{
+		{RoleARN: "arn:aws:iam::123456789012:role/admin", Username: "admin", Groups: []string{"system:masters", "team-a:admin"}},
+		{RoleARN: "arn:aws:iam::123456789012:role/ci", Username: "ci", Groups: []string{"system:masters"}},
+		{UserARN: "arn:aws:iam::123456789012:user/alice", Username: "alice", Groups: []string{"team-a:view"}},
+	}, merged)
+}
+
+func TestReadAWSAuthSources_yamlAndFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-auth-sources")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "users.yaml")
+
+	require.NoError(t, ioutil.WriteFile(file, []byte(`
+- userarn: arn:aws:iam::123456789012:user/bob
+  username: bob
+  groups:
+  - team-b:view
+`), 0644))
+
+	sources, err := readAWSAuthSources(mapRead{
+		KeyAWSAuthSources: []interface{}{
+			map[string]interface{}{
+				"mapping": []interface{}{},
+				"yaml":    `[{"rolearn": "arn:aws:iam::123456789012:role/ops", "username": "ops", "groups": ["system:masters"]}]`,
+				"file":    file,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]awsAuthSourceMapping{
+		{
+			{RoleARN: "arn:aws:iam::123456789012:role/ops", Username: "ops", Groups: []string{"system:masters"}},
+			{UserARN: "arn:aws:iam::123456789012:user/bob", Username: "bob", Groups: []string{"team-b:view"}},
+		},
+	}, sources)
+
+	merged, err := mergeAWSAuthSources(sources)
+	require.NoError(t, err)
+	assert.Equal(t, []awsAuthMapping{
+		{RoleARN: "arn:aws:iam::123456789012:role/ops", Username: "ops", Groups: []string{"system:masters"}},
+		{UserARN: "arn:aws:iam::123456789012:user/bob", Username: "bob", Groups: []string{"team-b:view"}},
+	}, merged)
+}
+
+func TestMergeAWSAuthSources_errors(t *testing.T) {
+	_, err := readAWSAuthSources(mapRead{
+		KeyAWSAuthSources: []interface{}{
+			map[string]interface{}{"mapping": []interface{}{}, "yaml": "- username: foo\n", "file": ""},
+		},
+	})
+	assert.EqualError(t, err, "aws_auth_sources.0: mapping 0 has no iamarn, rolearn, or userarn")
+
+	_, err = mergeAWSAuthSources([][]awsAuthSourceMapping{
+		{{RoleARN: "arn:aws:iam::123456789012:role/admin", Groups: []string{"system:masters"}}},
+	})
+	assert.EqualError(t, err, "mapping for arn:aws:iam::123456789012:role/admin has no username in any of aws_auth_sources")
+}
@@ -0,0 +1,59 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAWSAuthConfigMapData(t *testing.T) {
+	iams, err := parseAWSAuthConfigMapData(map[string]string{
+		"mapRoles": `- rolearn: arn:aws:iam::123456789012:role/node
+  username: system:node:{{EC2PrivateDNSName}}
+  groups:
+  - system:bootstrappers
+  - system:nodes
+`,
+		"mapUsers": `- userarn: arn:aws:iam::123456789012:user/admin
+  username: admin
+  groups:
+  - system:masters
+`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []awsAuthMapping{
+		{
+			RoleARN:  "arn:aws:iam::123456789012:role/node",
+			Username: "system:node:{{EC2PrivateDNSName}}",
+			Groups:   []string{"system:bootstrappers", "system:nodes"},
+		},
+		{
+			UserARN:  "arn:aws:iam::123456789012:user/admin",
+			Username: "admin",
+			Groups:   []string{"system:masters"},
+		},
+	}, iams)
+}
+
+func TestFlattenEksctlGetIAMIdentityMappingOutput(t *testing.T) {
+	iams, err := parseEksctlGetIAMIdentityMappingOutput(`[
+  {"userarn": "arn:aws:iam::123456789012:user/admin", "username": "admin", "groups": ["system:masters"]},
+  {"rolearn": "arn:aws:iam::123456789012:role/node", "username": "system:node:{{EC2PrivateDNSName}}", "groups": ["system:bootstrappers", "system:nodes"]}
+]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, awsAuthMappingKindUser, iams[0].Kind())
+	assert.Equal(t, awsAuthMappingKindRole, iams[1].Kind())
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"iamarn":   "arn:aws:iam::123456789012:role/node",
+			"username": "system:node:{{EC2PrivateDNSName}}",
+			"groups":   []interface{}{"system:bootstrappers", "system:nodes"},
+		},
+		{
+			"iamarn":   "arn:aws:iam::123456789012:user/admin",
+			"username": "admin",
+			"groups":   []interface{}{"system:masters"},
+		},
+	}, flattenAWSAuthMappings(iams))
+}
@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// awsAuthKeys are the attributes reconciled with targeted `eksctl create/delete iamidentitymapping` calls
+var awsAuthKeys = []string{KeyAWSAuthConfigMap, KeyIAMIdentityMapping}
+
+// onlyAWSAuthChanged returns true when the aws-auth mappings are the only changes to the resource,
+// so that the update never runs any nodegroup or cluster-level operation.
+// Computed-only attributes are ignored, as they are never changed by the user.
+func onlyAWSAuthChanged(d *schema.ResourceData, s map[string]*schema.Schema) bool {
+	var changed bool
+
+	for k, sch := range s {
+		if !d.HasChange(k) {
+			continue
+		}
+
+		if isAWSAuthKey(k) || k == KeyAWSAuthSources {
+			changed = true
+
+			continue
+		}
+
+		if sch.Computed && !sch.Optional {
+			continue
+		}
+
+		return false
+	}
+
+	return changed
+}
+
+func isAWSAuthKey(k string) bool {
+	for _, a := range awsAuthKeys {
+		if a == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateAWSAuthMappings deletes the mappings removed from and creates the mappings added to all the awsAuthKeys.
+func updateAWSAuthMappings(d *schema.ResourceData, cluster *Cluster) error {
+	if !cluster.ManageAWSAuth {
+		return nil
+	}
+
+	for _, k := range awsAuthKeys {
+		if err := updateAWSAuthMappingsForKey(d, cluster, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateAWSAuthMappingsForKey(d *schema.ResourceData, cluster *Cluster, key string) error {
+	if !d.HasChange(key) {
+		return nil
+	}
+
+	a, b := d.GetChange(key)
+
+	current, desired := a.(*schema.Set), b.(*schema.Set)
+
+	removed, added := current.Difference(desired), desired.Difference(current)
+
+	// aws_auth_configmap is refreshed from aws-auth, so it contains the mappings that eksctl and iam_identity_mapping manage
+	if key == KeyAWSAuthConfigMap {
+		identityMappings, _ := d.Get(KeyIAMIdentityMapping).(*schema.Set)
+
+		removed = removableAWSAuthMappings(removed, added, identityMappings)
+	}
+
+	// Removed mappings are deleted before the added ones are created, so that a mapping whose groups or username
+	// are changed is replaced, instead of resulting in two mappings for the same ARN
+	if err := runDeleteIAMIdentityMapping(d, removed, cluster); err != nil {
+		return fmt.Errorf("deleting iamidentitymappings removed from %s: %w", key, err)
+	}
+
+	if err := runCreateIAMIdentityMapping(d, added, cluster); err != nil {
+		return fmt.Errorf("creating iamidentitymappings added to %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// removableAWSAuthMappings returns the mappings removed from aws_auth_configmap that can be deleted from aws-auth.
+// The mappings of the nodegroup roles are retained unless replaced, so that nodes keep joining the cluster,
+// and the mappings declared in iam_identity_mapping are retained, as they are deleted only when removed from there.
+func removableAWSAuthMappings(removed, added, identityMappings *schema.Set) *schema.Set {
+	replaced := map[string]bool{}
+
+	for _, v := range added.List() {
+		replaced[v.(map[string]interface{})["iamarn"].(string)] = true
+	}
+
+	owned := map[string]bool{}
+
+	if identityMappings != nil {
+		for _, v := range identityMappings.List() {
+			owned[v.(map[string]interface{})["iamarn"].(string)] = true
+		}
+	}
+
+	removable := schema.NewSet(removed.F, nil)
+
+	for _, v := range removed.List() {
+		m := v.(map[string]interface{})
+
+		arn := m["iamarn"].(string)
+
+		if owned[arn] {
+			log.Printf("Retaining aws-auth mapping for %s, which is managed by %s", arn, KeyIAMIdentityMapping)
+
+			continue
+		}
+
+		if containsString(m["groups"], awsAuthNodesGroup) && !replaced[arn] {
+			log.Printf("Retaining aws-auth mapping for %s, which is the role of nodes", arn)
+
+			continue
+		}
+
+		removable.Add(v)
+	}
+
+	return removable
+}
+
+// validateManageAWSAuth fails the plan when iam_identity_mapping is declared while the provider doesn't manage aws-auth,
+// as the mappings would be silently ignored otherwise.
+func validateManageAWSAuth(d Read) error {
+	if v, ok := d.Get(KeyManageAWSAuth).(bool); !ok || v {
+		return nil
+	}
+
+	if s, ok := d.Get(KeyIAMIdentityMapping).(*schema.Set); ok && s.Len() > 0 {
+		return fmt.Errorf("%s can't be used when %s is false", KeyIAMIdentityMapping, KeyManageAWSAuth)
+	}
+
+	if v, ok := d.Get(KeyAWSAuthSources).([]interface{}); ok && len(v) > 0 {
+		return fmt.Errorf("%s can't be used when %s is false", KeyAWSAuthSources, KeyManageAWSAuth)
+	}
+
+	return nil
+}
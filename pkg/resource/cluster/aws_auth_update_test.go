@@ -0,0 +1,124 @@
+package cluster
+
+import (
+	"errors"
+	"os/exec"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAWSAuthMappingSet(mappings ...map[string]interface{}) *schema.Set {
+	s := schema.NewSet(schema.HashResource(ResourceCluster().Schema[KeyAWSAuthConfigMap].Elem.(*schema.Resource)), nil)
+
+	for _, m := range mappings {
+		s.Add(m)
+	}
+
+	return s
+}
+
+func testAWSAuthMapping(arn, username string, groups ...string) map[string]interface{} {
+	var gs []interface{}
+
+	for _, g := range groups {
+		gs = append(gs, g)
+	}
+
+	return map[string]interface{}{"iamarn": arn, "username": username, "groups": gs}
+}
+
+func awsAuthMappingARNs(s *schema.Set) []string {
+	var arns []string
+
+	for _, v := range s.List() {
+		arns = append(arns, v.(map[string]interface{})["iamarn"].(string))
+	}
+
+	sort.Strings(arns)
+
+	return arns
+}
+
+func TestRemovableAWSAuthMappings(t *testing.T) {
+	node := testAWSAuthMapping("arn:aws:iam::123456789012:role/node", "system:node:{{EC2PrivateDNSName}}", "system:bootstrappers", "system:nodes")
+	deployer := testAWSAuthMapping("arn:aws:iam::123456789012:role/deployer", "deployer", "system:masters")
+	admin := testAWSAuthMapping("arn:aws:iam::123456789012:user/admin", "admin", "system:masters")
+
+	t.Run("retains mappings of nodes and iam_identity_mapping", func(t *testing.T) {
+		// The mappings refreshed from aws-auth but not declared in aws_auth_configmap
+		removed := testAWSAuthMappingSet(node, deployer, admin)
+
+		removable := removableAWSAuthMappings(removed, testAWSAuthMappingSet(), testAWSAuthMappingSet(deployer))
+
+		assert.Equal(t, []string{"arn:aws:iam::123456789012:user/admin"}, awsAuthMappingARNs(removable))
+	})
+
+	t.Run("replaces mappings of nodes", func(t *testing.T) {
+		replaced := testAWSAuthMapping("arn:aws:iam::123456789012:role/node", "system:node:{{EC2PrivateDNSName}}", "system:bootstrappers", "system:nodes", "monitoring")
+
+		removable := removableAWSAuthMappings(testAWSAuthMappingSet(node), testAWSAuthMappingSet(replaced), nil)
+
+		assert.Equal(t, []string{"arn:aws:iam::123456789012:role/node"}, awsAuthMappingARNs(removable))
+	})
+}
+
+func TestValidateManageAWSAuth(t *testing.T) {
+	identityMapping := []interface{}{testAWSAuthMapping("arn:aws:iam::123456789012:role/deployer", "deployer", "system:masters")}
+
+	d := schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:               "mycluster",
+		KeyIAMIdentityMapping: identityMapping,
+	})
+	assert.NoError(t, validateManageAWSAuth(d))
+
+	d = schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:          "mycluster",
+		KeyManageAWSAuth: false,
+	})
+	assert.NoError(t, validateManageAWSAuth(d))
+
+	d = schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:               "mycluster",
+		KeyManageAWSAuth:      false,
+		KeyIAMIdentityMapping: identityMapping,
+	})
+	assert.EqualError(t, validateManageAWSAuth(d), "iam_identity_mapping can't be used when manage_aws_auth is false")
+
+	d = schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:          "mycluster",
+		KeyManageAWSAuth: false,
+		KeyAWSAuthSources: []interface{}{map[string]interface{}{
+			"yaml": `[{"rolearn": "arn:aws:iam::123456789012:role/deployer", "username": "deployer", "groups": ["system:masters"]}]`,
+		}},
+	})
+	assert.EqualError(t, validateManageAWSAuth(d), "aws_auth_sources can't be used when manage_aws_auth is false")
+}
+
+func TestManageAWSAuth_disabled(t *testing.T) {
+	prev := resource.SetCommandRunner(resource.CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*resource.CommandResult, error) {
+		t.Errorf("unexpected command: %v", cmd.Args)
+
+		return nil, errors.New("unexpected command")
+	}))
+	defer resource.SetCommandRunner(prev)
+
+	d := schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:          "mycluster",
+		KeyManageAWSAuth: false,
+	})
+
+	cluster, err := ReadCluster(d, nil)
+	require.NoError(t, err)
+	assert.False(t, cluster.ManageAWSAuth)
+
+	// Neither eksctl get, create, nor delete iamidentitymapping is run for the aws-auth ConfigMap owned by something else
+	assert.NoError(t, createIAMIdentityMapping(d, cluster))
+	assert.NoError(t, readIAMIdentityMapping(d, cluster))
+	assert.NoError(t, updateAWSAuthMappings(d, cluster))
+}
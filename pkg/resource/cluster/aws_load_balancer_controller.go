@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const KeyAWSLoadBalancerController = "aws_load_balancer_controller"
+
+const (
+	awsLoadBalancerControllerNamespace          = "kube-system"
+	awsLoadBalancerControllerServiceAccountName = "aws-load-balancer-controller"
+	awsLoadBalancerControllerChartRepo          = "https://aws.github.io/eks-charts"
+)
+
+// AWSLoadBalancerController is the opt-in AWS Load Balancer Controller bootstrap.
+// The IRSA role and the service account are created by eksctl along with the cluster,
+// and the controller is installed with helm right after the cluster is created, so that
+// the new cluster is ready for ingresses and services behind ALBs and NLBs before the traffic is shifted to it.
+type AWSLoadBalancerController struct {
+	HelmBin      string
+	ChartVersion string
+}
+
+func awsLoadBalancerControllerSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"helm_bin": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "helm",
+				},
+				// chart_version defaults to the latest version of the chart
+				"chart_version": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+			},
+		},
+	}
+}
+
+func readAWSLoadBalancerController(v interface{}) *AWSLoadBalancerController {
+	controllers := v.([]interface{})
+	if len(controllers) == 0 {
+		return nil
+	}
+
+	c := &AWSLoadBalancerController{HelmBin: "helm"}
+
+	if controllers[0] == nil {
+		return c
+	}
+
+	m := controllers[0].(map[string]interface{})
+
+	if bin := m["helm_bin"].(string); bin != "" {
+		c.HelmBin = bin
+	}
+
+	c.ChartVersion = m["chart_version"].(string)
+
+	return c
+}
+
+// enableAWSLoadBalancerController adds the IRSA service account for the controller to the cluster.yaml
+func enableAWSLoadBalancerController(c *EksctlClusterConfig) {
+	addWellKnownPolicyServiceAccount(c, awsLoadBalancerControllerNamespace, awsLoadBalancerControllerServiceAccountName, "awsLoadBalancerController")
+}
+
+func awsLoadBalancerControllerHelmArgs(c *AWSLoadBalancerController, clusterName ClusterName, region, vpcID string) []string {
+	args := []string{
+		"upgrade", "--install", "aws-load-balancer-controller", "aws-load-balancer-controller",
+		"--repo", awsLoadBalancerControllerChartRepo,
+		"--namespace", awsLoadBalancerControllerNamespace,
+		"--set", "clusterName=" + string(clusterName),
+		"--set", "serviceAccount.create=false",
+		"--set", "serviceAccount.name=" + awsLoadBalancerControllerServiceAccountName,
+		"--set", "region=" + region,
+		"--wait",
+	}
+
+	// Without vpcId the controller tries to read it from EC2 instance metadata, which is often unreachable from pods
+	if vpcID != "" {
+		args = append(args, "--set", "vpcId="+vpcID)
+	}
+
+	if c.ChartVersion != "" {
+		args = append(args, "--version", c.ChartVersion)
+	}
+
+	return args
+}
+
+// doInstallAWSLoadBalancerController installs or upgrades the controller with helm.
+// It's run on every update too, so that the chart_version change is applied.
+func doInstallAWSLoadBalancerController(cluster *Cluster, clusterName ClusterName) error {
+	if cluster.AWSLoadBalancerController == nil {
+		return nil
+	}
+
+	vpcID := cluster.VPCID
+
+	if vpcID == "" {
+		c := *cluster
+		c.Name = string(clusterName)
+
+		state, err := describeClusterWithSDK(&c)
+		if err != nil {
+			return fmt.Errorf("reading vpc id for aws-load-balancer-controller: %w", err)
+		}
+
+		vpcID = state.ResourcesVpcConfig.VpcId
+	}
+
+	kubeconfigPath, err := writeTempKubeconfig(cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig for installing aws-load-balancer-controller: %w", err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	args := awsLoadBalancerControllerHelmArgs(cluster.AWSLoadBalancerController, clusterName, cluster.Region, vpcID)
+
+	cmd, err := newKubeconfigCommand(cluster, cluster.AWSLoadBalancerController.HelmBin, kubeconfigPath, args...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := resource.Run(cmd); err != nil {
+		return fmt.Errorf("installing aws-load-balancer-controller: %w", err)
+	}
+
+	return nil
+}
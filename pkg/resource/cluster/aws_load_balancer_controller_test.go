@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestReadAWSLoadBalancerController(t *testing.T) {
+	assert.Nil(t, readAWSLoadBalancerController([]interface{}{}))
+
+	// An empty block enables the controller with the defaults
+	assert.Equal(t, &AWSLoadBalancerController{HelmBin: "helm"}, readAWSLoadBalancerController([]interface{}{nil}))
+
+	assert.Equal(t, &AWSLoadBalancerController{HelmBin: "helm3", ChartVersion: "1.1.0"}, readAWSLoadBalancerController([]interface{}{
+		map[string]interface{}{"helm_bin": "helm3", "chart_version": "1.1.0"},
+	}))
+}
+
+func TestEnableAWSLoadBalancerController(t *testing.T) {
+	var c EksctlClusterConfig
+
+	err := yaml.Unmarshal([]byte(`
+iam:
+  serviceAccounts:
+  - metadata:
+      name: aws-load-balancer-controller
+      namespace: kube-system
+    attachPolicyARNs:
+    - arn:aws:iam::123456789012:policy/custom
+`), &c)
+	assert.NoError(t, err)
+
+	enableAWSLoadBalancerController(&c)
+
+	// The service account in cluster.yaml takes precedence
+	assert.True(t, c.IAM.WithOIDC)
+	assert.Len(t, c.IAM.Rest["serviceAccounts"], 1)
+
+	c = EksctlClusterConfig{}
+
+	enableAWSLoadBalancerController(&c)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "aws-load-balancer-controller",
+				"namespace": "kube-system",
+			},
+			"wellKnownPolicies": map[string]interface{}{
+				"awsLoadBalancerController": true,
+			},
+		},
+	}, c.IAM.Rest["serviceAccounts"])
+}
+
+func TestAWSLoadBalancerControllerHelmArgs(t *testing.T) {
+	args := awsLoadBalancerControllerHelmArgs(&AWSLoadBalancerController{HelmBin: "helm", ChartVersion: "1.1.0"}, "prod-abc", "us-east-2", "vpc-1")
+
+	assert.Equal(t, []string{
+		"upgrade", "--install", "aws-load-balancer-controller", "aws-load-balancer-controller",
+		"--repo", "https://aws.github.io/eks-charts",
+		"--namespace", "kube-system",
+		"--set", "clusterName=prod-abc",
+		"--set", "serviceAccount.create=false",
+		"--set", "serviceAccount.name=aws-load-balancer-controller",
+		"--set", "region=us-east-2",
+		"--wait",
+		"--set", "vpcId=vpc-1",
+		"--version", "1.1.0",
+	}, args)
+
+	args = awsLoadBalancerControllerHelmArgs(&AWSLoadBalancerController{HelmBin: "helm"}, "prod-abc", "us-east-2", "")
+
+	// Neither vpcId nor the chart version is set
+	assert.Len(t, args, 17)
+	assert.NotContains(t, args, "--version")
+}
@@ -0,0 +1,313 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const KeyBackup = "backup"
+
+const (
+	DefaultBackupTimeout   = time.Hour
+	DefaultBackupInterval  = 10 * time.Second
+	DefaultVeleroNamespace = "velero"
+)
+
+// Backup takes a recovery point of the workloads before the cluster is deleted, either on destroy or
+// when the previous cluster is deleted after a blue/green cluster deployment.
+// The deletion is aborted when the backup fails.
+type Backup struct {
+	// Velero, when non-nil, creates a Velero Backup and waits for it to complete
+	Velero *VeleroBackup
+	// Command, when non-nil, is an arbitrary backup command run with KUBECONFIG pointing to the cluster
+	Command  *Hook
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+type VeleroBackup struct {
+	Namespace          string
+	IncludedNamespaces []string
+	ExcludedNamespaces []string
+	StorageLocation    string
+	TTL                string
+	SnapshotVolumes    bool
+}
+
+func backupSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"velero": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"namespace": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  DefaultVeleroNamespace,
+							},
+							"included_namespaces": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"excluded_namespaces": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"storage_location": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "",
+							},
+							"ttl": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "",
+							},
+							"snapshot_volumes": {
+								Type:     schema.TypeBool,
+								Optional: true,
+								Default:  true,
+							},
+						},
+					},
+				},
+				"command": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"args": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"environment": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"timeout_sec": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  int(DefaultBackupTimeout / time.Second),
+				},
+				"interval_sec": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  int(DefaultBackupInterval / time.Second),
+				},
+			},
+		},
+	}
+}
+
+func readBackup(v interface{}) (*Backup, error) {
+	blocks := v.([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil, nil
+	}
+
+	m := blocks[0].(map[string]interface{})
+
+	b := &Backup{Timeout: DefaultBackupTimeout, Interval: DefaultBackupInterval}
+
+	if sec := m["timeout_sec"].(int); sec > 0 {
+		b.Timeout = time.Duration(sec) * time.Second
+	}
+
+	if sec := m["interval_sec"].(int); sec > 0 {
+		b.Interval = time.Duration(sec) * time.Second
+	}
+
+	if vs, _ := m["velero"].([]interface{}); len(vs) > 0 {
+		v := &VeleroBackup{Namespace: DefaultVeleroNamespace, SnapshotVolumes: true}
+
+		if vs[0] != nil {
+			vm := vs[0].(map[string]interface{})
+
+			v.Namespace = vm["namespace"].(string)
+			v.IncludedNamespaces = toStrings(vm["included_namespaces"])
+			v.ExcludedNamespaces = toStrings(vm["excluded_namespaces"])
+			v.StorageLocation = vm["storage_location"].(string)
+			v.TTL = vm["ttl"].(string)
+			v.SnapshotVolumes = vm["snapshot_volumes"].(bool)
+		}
+
+		b.Velero = v
+	}
+
+	if command := m["command"].(string); command != "" {
+		h := &Hook{
+			Command:       command,
+			Args:          toStrings(m["args"]),
+			Environment:   map[string]string{},
+			Timeout:       b.Timeout,
+			FailurePolicy: HookFailurePolicyFail,
+		}
+
+		if env, ok := m["environment"].(map[string]interface{}); ok {
+			for k, v := range env {
+				h.Environment[k] = v.(string)
+			}
+		}
+
+		b.Command = h
+	}
+
+	if (b.Velero == nil) == (b.Command == nil) {
+		return nil, fmt.Errorf("%s: exactly one of velero and command must be set", KeyBackup)
+	}
+
+	return b, nil
+}
+
+// doBackup takes the backup of the cluster and waits for it to complete
+func doBackup(cluster *Cluster, clusterName ClusterName) error {
+	b := cluster.Backup
+	if b == nil {
+		return nil
+	}
+
+	if b.Command != nil {
+		return runHooks("backup", cluster, clusterName, []Hook{*b.Command})
+	}
+
+	kubeconfigPath, err := writeTempKubeconfig(cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig for backup: %w", err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	name := veleroBackupName(clusterName, time.Now())
+
+	manifest, err := renderVeleroBackup(name, b.Velero)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := newKubectlCommand(cluster, kubeconfigPath, "create", "-f", "-")
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin = bytes.NewReader(manifest)
+
+	if _, err := resource.Run(cmd); err != nil {
+		return fmt.Errorf("creating velero backup %s/%s: %w", b.Velero.Namespace, name, err)
+	}
+
+	return waitForVeleroBackup(cluster, kubeconfigPath, name, b)
+}
+
+func waitForVeleroBackup(cluster *Cluster, kubeconfigPath, name string, b *Backup) error {
+	deadline := time.Now().Add(b.Timeout)
+
+	for {
+		cmd, err := newKubectlCommand(cluster, kubeconfigPath, "get", "backups.velero.io", name, "--namespace", b.Velero.Namespace, "-o", "jsonpath={.status.phase}")
+		if err != nil {
+			return err
+		}
+
+		res, err := resource.Run(cmd)
+		if err != nil {
+			return fmt.Errorf("getting velero backup %s/%s: %w", b.Velero.Namespace, name, err)
+		}
+
+		phase := strings.TrimSpace(res.Stdout)
+
+		done, err := veleroBackupDone(phase)
+		if err != nil {
+			return fmt.Errorf("velero backup %s/%s: %w", b.Velero.Namespace, name, err)
+		}
+
+		if done {
+			log.Printf("Velero backup %s/%s completed", b.Velero.Namespace, name)
+
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("waiting for velero backup %s/%s to complete: still %q after %v", b.Velero.Namespace, name, phase, b.Timeout)
+		}
+
+		log.Printf("Waiting for velero backup %s/%s to complete: currently %q", b.Velero.Namespace, name, phase)
+
+		time.Sleep(b.Interval)
+	}
+}
+
+// veleroBackupDone returns true when the backup completed, and an error when it failed.
+// PartiallyFailed is a failure too, as the backup may lack the very resources needed for the recovery.
+func veleroBackupDone(phase string) (bool, error) {
+	switch phase {
+	case "Completed":
+		return true, nil
+	case "Failed", "PartiallyFailed", "FailedValidation":
+		return false, fmt.Errorf("backup %s. Run `velero backup describe` for details", phase)
+	}
+
+	return false, nil
+}
+
+// veleroBackupName returns the name of the backup, which is unique per cluster and time of the backup
+func veleroBackupName(clusterName ClusterName, now time.Time) string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(string(clusterName)), now.UTC().Format("20060102150405"))
+}
+
+func renderVeleroBackup(name string, v *VeleroBackup) ([]byte, error) {
+	spec := map[string]interface{}{
+		"snapshotVolumes": v.SnapshotVolumes,
+	}
+
+	if len(v.IncludedNamespaces) > 0 {
+		spec["includedNamespaces"] = v.IncludedNamespaces
+	}
+
+	if len(v.ExcludedNamespaces) > 0 {
+		spec["excludedNamespaces"] = v.ExcludedNamespaces
+	}
+
+	if v.StorageLocation != "" {
+		spec["storageLocation"] = v.StorageLocation
+	}
+
+	if v.TTL != "" {
+		spec["ttl"] = v.TTL
+	}
+
+	manifest := map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": v.Namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "terraform-provider-eksctl",
+			},
+		},
+		"spec": spec,
+	}
+
+	bs, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("rendering velero backup: %w", err)
+	}
+
+	return bs, nil
+}
@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBackup(t *testing.T) {
+	b, err := readBackup([]interface{}{map[string]interface{}{
+		"velero": []interface{}{map[string]interface{}{
+			"namespace":           "velero",
+			"included_namespaces": []interface{}{"app"},
+			"excluded_namespaces": []interface{}{},
+			"storage_location":    "",
+			"ttl":                 "720h",
+			"snapshot_volumes":    true,
+		}},
+		"command":      "",
+		"args":         []interface{}{},
+		"environment":  map[string]interface{}{},
+		"timeout_sec":  600,
+		"interval_sec": 5,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, &Backup{
+		Velero: &VeleroBackup{
+			Namespace:          "velero",
+			IncludedNamespaces: []string{"app"},
+			TTL:                "720h",
+			SnapshotVolumes:    true,
+		},
+		Timeout:  10 * time.Minute,
+		Interval: 5 * time.Second,
+	}, b)
+
+	_, err = readBackup([]interface{}{map[string]interface{}{
+		"velero":       []interface{}{},
+		"command":      "",
+		"args":         []interface{}{},
+		"environment":  map[string]interface{}{},
+		"timeout_sec":  0,
+		"interval_sec": 0,
+	}})
+	assert.EqualError(t, err, "backup: exactly one of velero and command must be set")
+
+	b, err = readBackup([]interface{}{})
+	assert.NoError(t, err)
+	assert.Nil(t, b)
+}
+
+func TestRenderVeleroBackup(t *testing.T) {
+	name := veleroBackupName("Primary-1", time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, "primary-1-20201001120000", name)
+
+	manifest, err := renderVeleroBackup(name, &VeleroBackup{
+		Namespace:          "velero",
+		IncludedNamespaces: []string{"app"},
+		TTL:                "720h",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: velero.io/v1
+kind: Backup
+metadata:
+    labels:
+        app.kubernetes.io/managed-by: terraform-provider-eksctl
+    name: primary-1-20201001120000
+    namespace: velero
+spec:
+    includedNamespaces:
+      - app
+    snapshotVolumes: false
+    ttl: 720h
+`, string(manifest))
+}
+
+func TestVeleroBackupDone(t *testing.T) {
+	for phase, want := range map[string]bool{"": false, "New": false, "InProgress": false, "Completed": true} {
+		done, err := veleroBackupDone(phase)
+		assert.NoError(t, err)
+		assert.Equal(t, want, done, phase)
+	}
+
+	_, err := veleroBackupDone("PartiallyFailed")
+	assert.EqualError(t, err, "backup PartiallyFailed. Run `velero backup describe` for details")
+}
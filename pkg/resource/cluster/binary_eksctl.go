@@ -17,26 +17,25 @@ func prepareEksctlBinary(cluster *Cluster) (*string, error) {
 func prepareEksctlBinaryInternal(eksctlBin, eksctlVersion string) (*string, error) {
 	log.Print("Preparing eksctl binary")
 
+	// The binary is used as is when no version is pinned, which also lets tests run against a fake eksctl
+	// without touching the shoal directory
+	if eksctlVersion == "" {
+		return &eksctlBin, nil
+	}
+
+	log.Printf("Installing eksctl %s", eksctlVersion)
+
 	conf := shoal.Config{
 		Git: shoal.Git{
 			Provider: "go-git",
 		},
-	}
-
-	rig := "https://github.com/fishworks/fish-food"
-
-	installEksctl := eksctlVersion != ""
-
-	if installEksctl {
-		log.Printf("Installing eksctl %s", eksctlVersion)
-
-		conf.Dependencies = append(conf.Dependencies,
-			shoal.Dependency{
-				Rig:     rig,
+		Dependencies: []shoal.Dependency{
+			{
+				Rig:     "https://github.com/fishworks/fish-food",
 				Food:    "eksctl",
 				Version: eksctlVersion,
 			},
-		)
+		},
 	}
 
 	log.Print("Started taking exclusive lock on shoal")
@@ -53,31 +52,25 @@ func prepareEksctlBinaryInternal(eksctlBin, eksctlVersion string) (*string, erro
 
 	log.Print("Shoal instance created")
 
-	if len(conf.Dependencies) > 0 {
-		if err := s.Init(); err != nil {
-			return nil, fmt.Errorf("initializing shoal: %w", err)
-		}
-
-		log.Print("Shoal initialized")
+	if err := s.Init(); err != nil {
+		return nil, fmt.Errorf("initializing shoal: %w", err)
+	}
 
-		if err := s.InitGitProvider(conf); err != nil {
-			return nil, fmt.Errorf("initializing shoal git provider: %w", err)
-		}
+	log.Print("Shoal initialized")
 
-		log.Print("Shoal's Git provider initialized")
+	if err := s.InitGitProvider(conf); err != nil {
+		return nil, fmt.Errorf("initializing shoal git provider: %w", err)
+	}
 
-		if err := s.Sync(conf); err != nil {
-			return nil, err
-		}
+	log.Print("Shoal's Git provider initialized")
 
-		log.Print("Shoal sync finished")
+	if err := s.Sync(conf); err != nil {
+		return nil, err
 	}
 
-	binPath := s.BinPath()
+	log.Print("Shoal sync finished")
 
-	if eksctlVersion != "" {
-		eksctlBin = filepath.Join(binPath, "eksctl")
-	}
+	eksctlBin = filepath.Join(s.BinPath(), "eksctl")
 
 	return &eksctlBin, nil
 }
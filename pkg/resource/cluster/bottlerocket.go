@@ -0,0 +1,210 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// AMIFamilyBottlerocket is the amiFamily of the nodegroups that accept `bottlerocket_settings`
+	AMIFamilyBottlerocket = "Bottlerocket"
+)
+
+// bottlerocketSettingSections are the top-level sections of the Bottlerocket settings.
+// Anything else is rejected on validate, as Bottlerocket refuses to boot with unknown settings.
+var bottlerocketSettingSections = []string{
+	"autoscaling",
+	"aws",
+	"boot",
+	"bootstrap-containers",
+	"cloudformation",
+	"container-registry",
+	"container-runtime",
+	"dns",
+	"host-containers",
+	"kernel",
+	"kubernetes",
+	"metrics",
+	"motd",
+	"network",
+	"ntp",
+	"oci-defaults",
+	"oci-hooks",
+	"pki",
+	"updates",
+}
+
+var bottlerocketSettingKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// bottlerocketSettingsSchema is the map of the dotted TOML keys to the values of the Bottlerocket settings, like
+// `"kubernetes.max-pods" = "110"`.
+// Key components containing dots, like node labels, are double-quoted as in TOML.
+// Values are parsed as YAML scalars, so that numbers and booleans are rendered as such.
+func bottlerocketSettingsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		ValidateFunc: func(v interface{}, name string) ([]string, []error) {
+			var errs []error
+
+			for k, value := range v.(map[string]interface{}) {
+				if _, err := parseBottlerocketSetting(k, value.(string)); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				}
+			}
+
+			return nil, errs
+		},
+	}
+}
+
+// parseBottlerocketSettingKey splits the dotted key into the path of the setting, unquoting the double-quoted components
+func parseBottlerocketSettingKey(key string) ([]string, error) {
+	var (
+		path   []string
+		buf    strings.Builder
+		quoted bool
+	)
+
+	for _, r := range key {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			path = append(path, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteRune(r)
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("invalid setting %q: unterminated quote", key)
+	}
+
+	path = append(path, buf.String())
+
+	for _, p := range path {
+		if p == "" {
+			return nil, fmt.Errorf("invalid setting %q: empty key component", key)
+		}
+	}
+
+	if len(path) < 2 {
+		return nil, fmt.Errorf("invalid setting %q: must be a dotted key like kubernetes.max-pods", key)
+	}
+
+	section := path[0]
+
+	i := sort.SearchStrings(bottlerocketSettingSections, section)
+	if i == len(bottlerocketSettingSections) || bottlerocketSettingSections[i] != section {
+		return nil, fmt.Errorf("invalid setting %q: unknown section %q, must be one of %s", key, section, strings.Join(bottlerocketSettingSections, ", "))
+	}
+
+	if !bottlerocketSettingKeyPattern.MatchString(path[1]) {
+		return nil, fmt.Errorf("invalid setting %q: %q must consist of lower case alphanumerics and dashes", key, path[1])
+	}
+
+	return path, nil
+}
+
+type bottlerocketSetting struct {
+	Path  []string
+	Value interface{}
+}
+
+func parseBottlerocketSetting(key, value string) (bottlerocketSetting, error) {
+	path, err := parseBottlerocketSettingKey(key)
+	if err != nil {
+		return bottlerocketSetting{}, err
+	}
+
+	var v interface{}
+
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return bottlerocketSetting{}, fmt.Errorf("invalid value of setting %q: %w", key, err)
+	}
+
+	// An empty value is an empty string rather than null, which isn't representable in TOML
+	if v == nil {
+		v = value
+	}
+
+	if _, ok := v.(map[string]interface{}); ok {
+		return bottlerocketSetting{}, fmt.Errorf("invalid value of setting %q: set each nested key as a dotted key instead of a map", key)
+	}
+
+	return bottlerocketSetting{Path: path, Value: v}, nil
+}
+
+// mergeBottlerocketSettings renders `bottlerocket_settings` of the typed nodegroup block into
+// `bottlerocket.settings` of the nodegroup, from which eksctl generates the settings TOML of the userdata.
+func mergeBottlerocketSettings(ng map[string]interface{}, m map[string]interface{}) error {
+	kvs, _ := m["bottlerocket_settings"].(map[string]interface{})
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	if family, _ := ng["amiFamily"].(string); family != AMIFamilyBottlerocket {
+		return fmt.Errorf("bottlerocket_settings requires ami_family to be %q, but got %q", AMIFamilyBottlerocket, family)
+	}
+
+	bottlerocket, err := childMap(ng, "bottlerocket")
+	if err != nil {
+		return err
+	}
+
+	settings, err := childMap(bottlerocket, "settings")
+	if err != nil {
+		return fmt.Errorf("bottlerocket: %w", err)
+	}
+
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s, err := parseBottlerocketSetting(k, kvs[k].(string))
+		if err != nil {
+			return err
+		}
+
+		if err := setBottlerocketSetting(settings, s); err != nil {
+			return fmt.Errorf("setting %q: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+func setBottlerocketSetting(settings map[string]interface{}, s bottlerocketSetting) error {
+	parent := settings
+
+	for i, p := range s.Path[:len(s.Path)-1] {
+		child, err := childMap(parent, p)
+		if err != nil {
+			return fmt.Errorf("%s is already set to a non-map value", strings.Join(s.Path[:i+1], "."))
+		}
+
+		parent = child
+	}
+
+	last := s.Path[len(s.Path)-1]
+
+	if _, ok := parent[last]; ok {
+		return fmt.Errorf("already set, either in bottlerocket_settings or the bottlerocket.settings of the nodegroup")
+	}
+
+	parent[last] = s.Value
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBottlerocketSettingKey(t *testing.T) {
+	path, err := parseBottlerocketSettingKey(`kubernetes.node-labels."example.com/role"`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kubernetes", "node-labels", "example.com/role"}, path)
+
+	_, err = parseBottlerocketSettingKey("kubernetes")
+	assert.EqualError(t, err, `invalid setting "kubernetes": must be a dotted key like kubernetes.max-pods`)
+
+	_, err = parseBottlerocketSettingKey("kubernets.max-pods")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown section "kubernets"`)
+
+	_, err = parseBottlerocketSettingKey("kubernetes.maxPods")
+	assert.EqualError(t, err, `invalid setting "kubernetes.maxPods": "maxPods" must consist of lower case alphanumerics and dashes`)
+
+	_, err = parseBottlerocketSettingKey(`kubernetes.node-labels."foo`)
+	assert.EqualError(t, err, `invalid setting "kubernetes.node-labels.\"foo": unterminated quote`)
+}
+
+func TestMergeTypedSpec_bottlerocketSettings(t *testing.T) {
+	d := mapRead{
+		KeyManagedNodeGroup: []interface{}{nodeGroupBlock("br1", map[string]interface{}{
+			"ami_family":     AMIFamilyBottlerocket,
+			"instance_types": []interface{}{"m5.large"},
+			"spot":           false,
+			"extra":          "bottlerocket:\n  enableAdminContainer: true\n",
+			"bottlerocket_settings": map[string]interface{}{
+				"kubernetes.max-pods":                           "110",
+				`kubernetes.node-labels."example.com/role"`:     "app",
+				"host-containers.admin.enabled":                 "true",
+				"kernel.sysctl.\"net.core.somaxconn\"":          "'4096'",
+				"kubernetes.cluster-dns-ip":                     "",
+				"network.hostname":                              "node",
+				"kubernetes.eviction-hard.\"memory.available\"": "15%",
+			},
+		})},
+	}
+
+	merged, err := mergeTypedSpec("", d)
+	assert.NoError(t, err)
+	assert.Equal(t, `managedNodeGroups:
+  - amiFamily: Bottlerocket
+    bottlerocket:
+      enableAdminContainer: true
+      settings:
+        host-containers:
+          admin:
+            enabled: true
+        kernel:
+          sysctl:
+            net.core.somaxconn: "4096"
+        kubernetes:
+          cluster-dns-ip: ""
+          eviction-hard:
+            memory.available: 15%
+          max-pods: 110
+          node-labels:
+            example.com/role: app
+        network:
+          hostname: node
+    instanceTypes:
+      - m5.large
+    name: br1
+`, merged)
+}
+
+func TestMergeTypedSpec_bottlerocketSettingsErrors(t *testing.T) {
+	d := mapRead{
+		KeyNodeGroup: []interface{}{nodeGroupBlock("ng1", map[string]interface{}{
+			"instance_type":         "m5.large",
+			"bottlerocket_settings": map[string]interface{}{"kubernetes.max-pods": "110"},
+		})},
+	}
+
+	_, err := mergeTypedSpec("", d)
+	assert.EqualError(t, err, `nodeGroups "ng1": bottlerocket_settings requires ami_family to be "Bottlerocket", but got ""`)
+
+	d = mapRead{
+		KeyNodeGroup: []interface{}{nodeGroupBlock("ng1", map[string]interface{}{
+			"instance_type":         "m5.large",
+			"ami_family":            AMIFamilyBottlerocket,
+			"extra":                 "bottlerocket:\n  settings:\n    kubernetes:\n      max-pods: 58\n",
+			"bottlerocket_settings": map[string]interface{}{"kubernetes.max-pods": "110"},
+		})},
+	}
+
+	_, err = mergeTypedSpec("", d)
+	assert.EqualError(t, err, `nodeGroups "ng1": setting "kubernetes.max-pods": already set, either in bottlerocket_settings or the bottlerocket.settings of the nodegroup`)
+
+	d = mapRead{
+		KeyNodeGroup: []interface{}{nodeGroupBlock("ng1", map[string]interface{}{
+			"instance_type": "m5.large",
+			"ami_family":    AMIFamilyBottlerocket,
+			"bottlerocket_settings": map[string]interface{}{
+				"kubernetes.node-labels":     "a",
+				"kubernetes.node-labels.foo": "b",
+			},
+		})},
+	}
+
+	_, err = mergeTypedSpec("", d)
+	assert.EqualError(t, err, `nodeGroups "ng1": setting "kubernetes.node-labels.foo": kubernetes.node-labels is already set to a non-map value`)
+}
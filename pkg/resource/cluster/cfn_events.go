@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// maxStackFailures is the max number of failed stack events appended to errors
+const maxStackFailures = 5
+
+// failedStackStatuses are the statuses of the stacks that have failed events worth reporting
+var failedStackStatuses = []string{
+	cloudformation.StackStatusCreateFailed,
+	cloudformation.StackStatusRollbackInProgress,
+	cloudformation.StackStatusRollbackFailed,
+	cloudformation.StackStatusRollbackComplete,
+	cloudformation.StackStatusDeleteFailed,
+	cloudformation.StackStatusUpdateRollbackInProgress,
+	cloudformation.StackStatusUpdateRollbackFailed,
+	cloudformation.StackStatusUpdateRollbackComplete,
+}
+
+// withStackFailures appends the first failed events of the cluster's eksctl stacks to the error,
+// so that the cause like missing IAM permissions or ENI limits can be seen without opening the AWS console.
+func withStackFailures(err error, cluster *Cluster, clusterName ClusterName) error {
+	if err == nil {
+		return nil
+	}
+
+	failures, findErr := getStackFailures(cluster, clusterName)
+	if findErr != nil {
+		log.Printf("Failed getting failed CloudFormation stack events: %v", findErr)
+
+		return err
+	}
+
+	if len(failures) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w\n\nFAILED CLOUDFORMATION STACK EVENTS:\n%s", err, formatStackFailures(failures))
+}
+
+func getStackFailures(cluster *Cluster, clusterName ClusterName) ([]*cloudformation.StackEvent, error) {
+	cfn := cloudformation.New(AWSSessionFromCluster(cluster))
+
+	stackNamePrefix := fmt.Sprintf("eksctl-%s-", clusterName)
+
+	var stacks []string
+
+	err := cfn.ListStacksPages(&cloudformation.ListStacksInput{
+		StackStatusFilter: aws.StringSlice(failedStackStatuses),
+	}, func(o *cloudformation.ListStacksOutput, lastPage bool) bool {
+		for _, s := range o.StackSummaries {
+			if strings.HasPrefix(aws.StringValue(s.StackName), stackNamePrefix) {
+				stacks = append(stacks, aws.StringValue(s.StackId))
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing stacks: %w", err)
+	}
+
+	var failures []*cloudformation.StackEvent
+
+	for _, stack := range stacks {
+		var events []*cloudformation.StackEvent
+
+		err := cfn.DescribeStackEventsPages(&cloudformation.DescribeStackEventsInput{
+			StackName: aws.String(stack),
+		}, func(o *cloudformation.DescribeStackEventsOutput, lastPage bool) bool {
+			events = append(events, o.StackEvents...)
+
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing events of stack %s: %w", stack, err)
+		}
+
+		failures = append(failures, firstFailedStackEvents(events)...)
+	}
+
+	return failures, nil
+}
+
+// firstFailedStackEvents returns the failed events in the chronological order from the events in the reverse chronological order,
+// as returned by DescribeStackEvents.
+// Cancellations caused by another failure are omitted so that the root cause comes first.
+func firstFailedStackEvents(events []*cloudformation.StackEvent) []*cloudformation.StackEvent {
+	var failures []*cloudformation.StackEvent
+
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+
+		if !strings.HasSuffix(aws.StringValue(e.ResourceStatus), "_FAILED") {
+			continue
+		}
+
+		reason := aws.StringValue(e.ResourceStatusReason)
+
+		if strings.Contains(reason, "Resource creation cancelled") || strings.HasPrefix(reason, "The following resource(s) failed") {
+			continue
+		}
+
+		failures = append(failures, e)
+	}
+
+	return failures
+}
+
+func formatStackFailures(failures []*cloudformation.StackEvent) string {
+	var lines []string
+
+	for i, e := range failures {
+		if i >= maxStackFailures {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(failures)-maxStackFailures))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("%s: %s %s (%s): %s",
+			aws.StringValue(e.StackName),
+			aws.StringValue(e.ResourceStatus),
+			aws.StringValue(e.LogicalResourceId),
+			aws.StringValue(e.ResourceType),
+			aws.StringValue(e.ResourceStatusReason),
+		))
+	}
+
+	return strings.Join(lines, "\n")
+}
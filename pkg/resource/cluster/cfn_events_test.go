@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstFailedStackEvents(t *testing.T) {
+	event := func(status, logicalID, reason string) *cloudformation.StackEvent {
+		return &cloudformation.StackEvent{
+			StackName:            aws.String("eksctl-prod-nodegroup-ng1"),
+			ResourceStatus:       aws.String(status),
+			LogicalResourceId:    aws.String(logicalID),
+			ResourceType:         aws.String("AWS::AutoScaling::AutoScalingGroup"),
+			ResourceStatusReason: aws.String(reason),
+		}
+	}
+
+	// DescribeStackEvents returns the most recent event first
+	events := []*cloudformation.StackEvent{
+		event("ROLLBACK_COMPLETE", "eksctl-prod-nodegroup-ng1", ""),
+		event("CREATE_FAILED", "eksctl-prod-nodegroup-ng1", "The following resource(s) failed to create: [NodeGroup]."),
+		event("CREATE_FAILED", "NodeInstanceProfile", "Resource creation cancelled"),
+		event("CREATE_FAILED", "NodeGroup", "We currently do not have sufficient m5.large capacity in the Availability Zone you requested"),
+		event("CREATE_IN_PROGRESS", "NodeGroup", ""),
+	}
+
+	failures := firstFailedStackEvents(events)
+
+	assert.Equal(t, "eksctl-prod-nodegroup-ng1: CREATE_FAILED NodeGroup (AWS::AutoScaling::AutoScalingGroup): We currently do not have sufficient m5.large capacity in the Availability Zone you requested", formatStackFailures(failures))
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+)
+
+const KeyMaxCreateRetries = "max_create_retries"
+
+// withNodeGroupRollbackRecovery runs create, and when it fails leaving nodegroup stacks in ROLLBACK_COMPLETE,
+// deletes the failed stacks and retries up to cluster.MaxCreateRetries times.
+// The most common cause of nodegroup creation failures, like capacity or spot shortage in an AZ, is transient.
+// retry is called instead of create on retries, so that e.g. only nodegroups are created after a failed cluster creation.
+func withNodeGroupRollbackRecovery(cluster *Cluster, clusterName ClusterName, create, retry func() error) error {
+	err := create()
+	if err == nil || cluster.MaxCreateRetries == 0 {
+		return err
+	}
+
+	return retryAfterNodeGroupRollbacks(cloudformation.New(AWSSessionFromCluster(cluster)), clusterName, cluster.MaxCreateRetries, err, retry)
+}
+
+func retryAfterNodeGroupRollbacks(cfn cloudformationiface.CloudFormationAPI, clusterName ClusterName, maxRetries int, err error, retry func() error) error {
+	for i := 0; err != nil && i < maxRetries; i++ {
+		stacks, findErr := findRolledBackNodeGroupStacks(cfn, clusterName)
+		if findErr != nil {
+			log.Printf("Skipped recovering from the failure as finding rolled back nodegroup stacks failed: %v", findErr)
+
+			return err
+		}
+
+		if len(stacks) == 0 {
+			return err
+		}
+
+		log.Printf("Retrying nodegroup creation (%d/%d) after deleting rolled back stacks %v: %v", i+1, maxRetries, stacks, err)
+
+		if delErr := deleteStacks(cfn, stacks); delErr != nil {
+			return fmt.Errorf("%v\n\ndeleting rolled back nodegroup stacks for retry: %w", err, delErr)
+		}
+
+		err = retry()
+	}
+
+	return err
+}
+
+// findRolledBackNodeGroupStacks returns the names of the cluster's nodegroup stacks in ROLLBACK_COMPLETE,
+// which can't be updated and must be deleted before the nodegroup is created again.
+func findRolledBackNodeGroupStacks(cfn cloudformationiface.CloudFormationAPI, clusterName ClusterName) ([]string, error) {
+	stackNamePrefix := fmt.Sprintf("eksctl-%s-nodegroup-", clusterName)
+
+	var stacks []string
+
+	err := cfn.ListStacksPages(&cloudformation.ListStacksInput{
+		StackStatusFilter: aws.StringSlice([]string{cloudformation.StackStatusRollbackComplete}),
+	}, func(o *cloudformation.ListStacksOutput, lastPage bool) bool {
+		for _, s := range o.StackSummaries {
+			if strings.HasPrefix(aws.StringValue(s.StackName), stackNamePrefix) {
+				stacks = append(stacks, aws.StringValue(s.StackName))
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing stacks: %w", err)
+	}
+
+	return stacks, nil
+}
+
+func deleteStacks(cfn cloudformationiface.CloudFormationAPI, stacks []string) error {
+	for _, name := range stacks {
+		if _, err := cfn.DeleteStack(&cloudformation.DeleteStackInput{StackName: aws.String(name)}); err != nil {
+			return fmt.Errorf("deleting stack %s: %w", name, err)
+		}
+	}
+
+	for _, name := range stacks {
+		if err := cfn.WaitUntilStackDeleteComplete(&cloudformation.DescribeStacksInput{StackName: aws.String(name)}); err != nil {
+			return fmt.Errorf("waiting for stack %s to be deleted: %w", name, err)
+		}
+	}
+
+	return nil
+}
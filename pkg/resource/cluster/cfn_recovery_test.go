@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type rolledBackStacksMock struct {
+	cloudformationiface.CloudFormationAPI
+
+	// rolledBack are the stacks in ROLLBACK_COMPLETE
+	rolledBack []string
+	deleted    []string
+}
+
+func (m *rolledBackStacksMock) ListStacksPages(in *cloudformation.ListStacksInput, f func(*cloudformation.ListStacksOutput, bool) bool) error {
+	var summaries []*cloudformation.StackSummary
+
+	for _, s := range m.rolledBack {
+		summaries = append(summaries, &cloudformation.StackSummary{StackName: aws.String(s), StackStatus: aws.String(cloudformation.StackStatusRollbackComplete)})
+	}
+
+	f(&cloudformation.ListStacksOutput{StackSummaries: summaries}, true)
+
+	return nil
+}
+
+func (m *rolledBackStacksMock) DeleteStack(in *cloudformation.DeleteStackInput) (*cloudformation.DeleteStackOutput, error) {
+	m.deleted = append(m.deleted, aws.StringValue(in.StackName))
+
+	return &cloudformation.DeleteStackOutput{}, nil
+}
+
+func (m *rolledBackStacksMock) WaitUntilStackDeleteComplete(in *cloudformation.DescribeStacksInput) error {
+	m.rolledBack = nil
+
+	return nil
+}
+
+func TestRetryAfterNodeGroupRollbacks(t *testing.T) {
+	cfn := &rolledBackStacksMock{rolledBack: []string{"eksctl-prod-nodegroup-ng1", "eksctl-prod-eu-nodegroup-ng1", "eksctl-prod-cluster"}}
+
+	var retries int
+
+	err := retryAfterNodeGroupRollbacks(cfn, "prod", 2, errors.New("capacity shortage"), func() error {
+		retries++
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, retries)
+	// Stacks of the cluster itself and of other clusters sharing the name prefix are kept
+	assert.Equal(t, []string{"eksctl-prod-nodegroup-ng1"}, cfn.deleted)
+}
+
+func TestRetryAfterNodeGroupRollbacks_maxRetries(t *testing.T) {
+	cfn := &rolledBackStacksMock{}
+
+	var retries int
+
+	err := retryAfterNodeGroupRollbacks(cfn, "prod", 2, errors.New("capacity shortage"), func() error {
+		retries++
+
+		cfn.rolledBack = []string{"eksctl-prod-nodegroup-ng1"}
+
+		return errors.New("capacity shortage again")
+	})
+
+	// Nothing is retried without rolled back stacks
+	assert.EqualError(t, err, "capacity shortage")
+	assert.Equal(t, 0, retries)
+
+	cfn.rolledBack = []string{"eksctl-prod-nodegroup-ng1"}
+
+	err = retryAfterNodeGroupRollbacks(cfn, "prod", 2, errors.New("capacity shortage"), func() error {
+		retries++
+
+		cfn.rolledBack = []string{"eksctl-prod-nodegroup-ng1"}
+
+		return errors.New("capacity shortage again")
+	})
+
+	assert.EqualError(t, err, "capacity shortage again")
+	assert.Equal(t, 2, retries)
+}
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"github.com/rs/xid"
 	"gopkg.in/yaml.v3"
 )
@@ -19,9 +21,15 @@ const KeyRegion = "region"
 const KeyProfile = "profile"
 const KeyAPIVersion = "api_version"
 const KeyVersion = "version"
+const KeyPlatformVersion = "platform_version"
 const KeyTags = "tags"
 const KeyRevision = "revision"
 const KeySpec = "spec"
+const KeySpecSource = "spec_source"
+const KeyResolvedSpec = "resolved_spec"
+const KeySpecSourceCommit = "spec_source_commit"
+const KeySpecVars = "spec_vars"
+const KeySpecJSON = "spec_json"
 const KeyBin = "eksctl_bin"
 const KeyEksctlVersion = "eksctl_version"
 const KeyKubeconfigPath = "kubeconfig_path"
@@ -35,6 +43,19 @@ const KeyMetrics = "metrics"
 const KeyDrainNodeGroups = "drain_node_groups"
 const KeyIAMIdentityMapping = "iam_identity_mapping"
 const KeyAWSAuthConfigMap = "aws_auth_configmap"
+const KeyTargetHealthCheck = "target_health_check"
+const KeyGRPCHealthCheck = "grpc_health_check"
+const KeyPrometheusGate = "prometheus_gate"
+const KeySteps = "steps"
+const KeyBakeDuration = "bake_duration"
+const KeyApproval = "approval"
+const KeyRollbackAlarms = "rollback_alarms"
+const KeyRollbackWindow = "rollback_window"
+const KeySwitchoverMetrics = "switchover_metrics"
+const KeyManageNodeGroups = "manage_nodegroups"
+const KeyManageAWSAuth = "manage_aws_auth"
+const KeyDestroyHooks = "destroy_hooks"
+const KeyCreateHooks = "create_hooks"
 const (
 	KeyTargetGroupARNs  = "target_group_arns"
 	KeyOIDCProviderURL  = "oidc_provider_url"
@@ -70,6 +91,15 @@ type Cluster struct {
 	Output     string
 	Manifests  []string
 
+	// ServiceRoleARN is the pre-created service role of the cluster. eksctl creates one when empty
+	ServiceRoleARN string
+
+	// AssumeRoles are assumed on top of the provider's roles, so that the cluster can be in a different account
+	AssumeRoles []awsclicompat.AssumeRole
+
+	// Provider is the config of the provider instance that manages the cluster
+	Provider *resource.ProviderConfig
+
 	// EksctlVersion lets the provider to install the eksctl binary for the specified versino using shoal
 	EksctlVersion string
 
@@ -77,11 +107,86 @@ type Cluster struct {
 
 	DeleteKubernetesResourcesBeforeDestroy []DeleteKubernetesResource
 
+	// CreateHooks are run after the cluster is created and ready
+	CreateHooks []Hook
+
+	// DestroyHooks are run before `eksctl delete cluster`
+	DestroyHooks []Hook
+
+	// Backup, when non-nil, is taken before the cluster is deleted
+	Backup *Backup
+
 	PublicSubnetIDs  []string
 	PrivateSubnetIDs []string
 	ALBAttachments   []courier.ALBAttachment
 	TargetGroupARNs  []string
 	Metrics          []courier.Metric
+
+	TargetHealthCheck *courier.TargetHealthCheck
+
+	GRPCHealthChecks []courier.GRPCHealthCheck
+	PrometheusGates  []courier.PrometheusGate
+
+	// Steps and BakeDuration shape the traffic shift from the current cluster to the new one
+	Steps        []int
+	BakeDuration time.Duration
+
+	Approvals []courier.Approval
+
+	AlarmRollback *courier.AlarmRollback
+
+	SwitchoverMetrics *courier.SwitchoverMetrics
+
+	// Prewarm, when non-nil, delays the traffic shift until the new cluster is warmed up
+	Prewarm *Prewarm
+
+	// Autoscaler is set when the cluster-autoscaler bootstrap is enabled
+	Autoscaler *Autoscaler
+
+	// InstallNvidiaPlugin and InstallNeuronPlugin make eksctl install the device plugins on GPU and Inferentia nodegroups
+	InstallNvidiaPlugin bool
+	InstallNeuronPlugin bool
+
+	// MaxCreateRetries is the max number of retries of nodegroup creations whose stacks are rolled back
+	MaxCreateRetries int
+
+	// UpgradeAddons updates the system components after the control plane upgrade
+	UpgradeAddons bool
+
+	// ServerSideApply applies the manifests with `kubectl apply --server-side`
+	ServerSideApply bool
+
+	// NodeGroupUpgrade is set when managed nodegroups are upgraded after the control plane upgrade
+	NodeGroupUpgrade *NodeGroupUpgrade
+
+	// AMIDrift is the policy for the nodegroups running outdated AMIs
+	AMIDrift string
+
+	// SubnetTagging is either "none", "validate", or "fix" to control how the load balancer role tags on existing subnets are handled
+	SubnetTagging string
+
+	// Connection is set when the private-only cluster endpoint is accessed through a tunnel
+	Connection *Connection
+
+	// NodeGroupBlueGreen is set when nodegroups are replaced in a blue/green manner
+	NodeGroupBlueGreen *NodeGroupBlueGreen
+
+	// CleanupOrphanedResources deletes the ENIs and the security group left after the cluster deletion
+	CleanupOrphanedResources bool
+
+	// NodeGroupDrain configures draining nodes on nodegroup and cluster deletions
+	NodeGroupDrain NodeGroupDrain
+
+	// ManageNodeGroups is false when the cluster is created without nodegroups, and its nodegroups are managed
+	// by `eksctl_nodegroup` resources instead
+	ManageNodeGroups bool
+
+	// ManageAWSAuth is false when the aws-auth ConfigMap is owned by something else like GitOps,
+	// so that the provider neither reads nor reconciles iamidentitymappings
+	ManageAWSAuth bool
+
+	// AWSLoadBalancerController is set when the aws-load-balancer-controller bootstrap is enabled
+	AWSLoadBalancerController *AWSLoadBalancerController
 }
 
 func (c Cluster) IAMWithOIDCEnabled() (bool, error) {
@@ -91,7 +196,18 @@ func (c Cluster) IAMWithOIDCEnabled() (bool, error) {
 		return false, fmt.Errorf("parsing cluster.yaml: %w\nCONTENT:\n%s", err, c.Spec)
 	}
 
-	return config.IAM.WithOIDC, nil
+	// The cluster-autoscaler and aws-load-balancer-controller bootstraps rely on IRSA
+	return config.IAM.WithOIDC || c.Autoscaler != nil || c.AWSLoadBalancerController != nil, nil
+}
+
+func (c Cluster) LocalClusterEnabled() (bool, error) {
+	var config EksctlClusterConfig
+
+	if err := yaml.Unmarshal([]byte(c.Spec), &config); err != nil {
+		return false, fmt.Errorf("parsing cluster.yaml: %w\nCONTENT:\n%s", err, c.Spec)
+	}
+
+	return config.Outpost != nil, nil
 }
 
 func (c Cluster) GitOpsEnabled() (bool, error) {
@@ -125,10 +241,14 @@ type EksctlClusterConfig struct {
 	Git  map[string]interface{} `yaml:"git,omitempty"`
 	Rest map[string]interface{} `yaml:",inline"`
 	IAM  IAM                    `yaml:"iam"`
+
+	// Outpost is set for EKS local clusters on AWS Outposts
+	Outpost *Outpost `yaml:"outpost,omitempty"`
 }
 
 type IAM struct {
-	WithOIDC bool `yaml:"withOIDC"`
+	WithOIDC bool                   `yaml:"withOIDC"`
+	Rest     map[string]interface{} `yaml:",inline"`
 }
 
 type VPC struct {
@@ -165,8 +285,8 @@ type Manager struct {
 	DisableClusterNameSuffix bool
 }
 
-func (m *Manager) PrepareClusterSet(d *schema.ResourceData, optNewId ...string) (*ClusterSet, error) {
-	a, err := ReadCluster(d)
+func (m *Manager) PrepareClusterSet(d *schema.ResourceData, p *resource.ProviderConfig, optNewId ...string) (*ClusterSet, error) {
+	a, err := ReadCluster(d, p)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +319,16 @@ func (m *Manager) PrepareClusterSet(d *schema.ResourceData, optNewId ...string)
 		}
 	}
 
+	if err := setServiceRoleARN(spec, a.ServiceRoleARN); err != nil {
+		return nil, err
+	}
+
+	if a.NodeGroupBlueGreen != nil {
+		if err := suffixNodeGroupNamesWithHash(spec); err != nil {
+			return nil, fmt.Errorf("naming nodegroups for blue/green replacement: %w", err)
+		}
+	}
+
 	var specStr string
 	{
 		var buf bytes.Buffer
@@ -275,6 +405,18 @@ metadata:
 	if err := yaml.Unmarshal(seedClusterConfig, &c); err != nil {
 		return nil, fmt.Errorf("parsing generate cluster.yaml: %w: INPUT:\n%s", err, string(seedClusterConfig))
 	}
+
+	if !a.ManageNodeGroups && (len(c.NodeGroups) > 0 || c.Rest["managedNodeGroups"] != nil) {
+		return nil, fmt.Errorf("spec must not contain nodeGroups or managedNodeGroups when %s is false. Use eksctl_nodegroup resources instead", KeyManageNodeGroups)
+	}
+
+	if a.Autoscaler != nil {
+		enableAutoscaler(&c, clusterName)
+	}
+
+	if a.AWSLoadBalancerController != nil {
+		enableAWSLoadBalancerController(&c)
+	}
 	//
 	//for i := range c.NodeGroups {
 	//	ng := c.NodeGroups[i]
@@ -309,7 +451,7 @@ metadata:
 		a.PublicSubnetIDs = append(a.PublicSubnetIDs, s.ID)
 	}
 
-	for _, s := range c.VPC.Subnets.Public {
+	for _, s := range c.VPC.Subnets.Private {
 		a.PrivateSubnetIDs = append(a.PrivateSubnetIDs, s.ID)
 	}
 
@@ -326,6 +468,14 @@ metadata:
 			CanaryAdvancementStep:     5,
 			Region:                    a.Region,
 			ClusterName:               string(clusterName),
+			TargetHealthCheck:         a.TargetHealthCheck,
+			GRPCHealthChecks:          a.GRPCHealthChecks,
+			PrometheusGates:           a.PrometheusGates,
+			Steps:                     a.Steps,
+			BakeDuration:              a.BakeDuration,
+			Approvals:                 a.Approvals,
+			AlarmRollback:             a.AlarmRollback,
+			SwitchoverMetrics:         a.switchoverMetrics(string(clusterName)),
 		},
 	}, nil
 }
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	KeyHost                 = "host"
+	KeyClusterCACertificate = "cluster_ca_certificate"
+	KeyToken                = "token"
+)
+
+const (
+	// clusterTokenPrefix and clusterTokenHeader are the ones used by aws-iam-authenticator and `aws eks get-token`
+	clusterTokenPrefix = "k8s-aws-v1."
+	clusterTokenHeader = "x-k8s-aws-id"
+
+	// clusterTokenExpiration is how long the presigned URL in the token is valid. EKS accepts tokens up to 15 minutes old.
+	clusterTokenExpiration = 15 * time.Minute
+)
+
+// loadClusterAuth sets `host`, `cluster_ca_certificate` and `token` so that they can be fed into the kubernetes and
+// helm providers without the kubeconfig file.
+func loadClusterAuth(d ReadWrite, cluster *Cluster, clusterName ClusterName) error {
+	c := &Cluster{
+		Name:        string(clusterName),
+		Region:      cluster.Region,
+		Profile:     cluster.Profile,
+		AssumeRoles: cluster.AssumeRoles,
+		Provider:    cluster.Provider,
+	}
+
+	state, err := runGetCluster(d, c)
+	if err != nil {
+		return fmt.Errorf("reading cluster endpoint: %w", err)
+	}
+
+	ca, err := base64.StdEncoding.DecodeString(state.CertificateAuthority.Data)
+	if err != nil {
+		return fmt.Errorf("decoding certificate authority data: %w", err)
+	}
+
+	token, err := getClusterToken(AWSSessionFromCluster(c), string(clusterName))
+	if err != nil {
+		return err
+	}
+
+	d.Set(KeyHost, state.Endpoint)
+	d.Set(KeyClusterCACertificate, string(ca))
+	d.Set(KeyToken, token)
+
+	return nil
+}
+
+// getClusterToken generates a token for the cluster in the same way as `aws eks get-token`,
+// which is a presigned sts:GetCallerIdentity URL that EKS calls to authenticate the caller.
+func getClusterToken(sess *session.Session, clusterName string) (string, error) {
+	req, _ := sts.New(sess).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	req.HTTPRequest.Header.Add(clusterTokenHeader, clusterName)
+
+	url, err := req.Presign(clusterTokenExpiration)
+	if err != nil {
+		return "", fmt.Errorf("presigning sts:GetCallerIdentity for cluster token: %w", err)
+	}
+
+	return encodeClusterToken(url), nil
+}
+
+func encodeClusterToken(presignedURL string) string {
+	return clusterTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presignedURL))
+}
@@ -0,0 +1,13 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeClusterToken(t *testing.T) {
+	token := encodeClusterToken("https://sts.amazonaws.com/?Action=GetCallerIdentity&Version=2011-06-15")
+
+	assert.Equal(t, "k8s-aws-v1.aHR0cHM6Ly9zdHMuYW1hem9uYXdzLmNvbS8_QWN0aW9uPUdldENhbGxlcklkZW50aXR5JlZlcnNpb249MjAxMS0wNi0xNQ", token)
+}
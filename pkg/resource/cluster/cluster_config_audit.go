@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	KeyClusterConfigDir          = "cluster_config_dir"
+	KeyClusterConfigDryRun       = "cluster_config_dry_run"
+	KeyClusterConfig             = "cluster_config"
+	KeyClusterConfigWithDefaults = "cluster_config_with_defaults"
+)
+
+func computedStringSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+}
+
+// recordClusterConfig records the cluster.yaml about to be passed to eksctl, so that security audits can review
+// exactly what was applied for each revision.
+// It's recorded before eksctl runs, so that failed applies are recorded too.
+func recordClusterConfig(d *schema.ResourceData, set *ClusterSet) error {
+	config := string(set.ClusterConfig)
+
+	if err := d.Set(KeyClusterConfig, config); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyClusterConfig, err)
+	}
+
+	var withDefaults string
+
+	if d.Get(KeyClusterConfigDryRun).(bool) {
+		out, err := runEksctlDryRun(set)
+		if err != nil {
+			return err
+		}
+
+		withDefaults = out
+	}
+
+	if err := d.Set(KeyClusterConfigWithDefaults, withDefaults); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyClusterConfigWithDefaults, err)
+	}
+
+	dir := d.Get(KeyClusterConfigDir).(string)
+	if dir == "" {
+		return nil
+	}
+
+	files := map[string]string{
+		string(set.ClusterName) + ".yaml": config,
+	}
+
+	if withDefaults != "" {
+		files[string(set.ClusterName)+".dry-run.yaml"] = withDefaults
+	}
+
+	return writeClusterConfigFiles(dir, files)
+}
+
+func writeClusterConfigFiles(dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", KeyClusterConfigDir, err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing cluster config to %s: %w", path, err)
+		}
+
+		log.Printf("Wrote cluster config to %s", path)
+	}
+
+	return nil
+}
+
+// runEksctlDryRun returns the cluster config with the defaults filled by eksctl.
+// Only the stdout is returned, as eksctl writes its logs to the stderr.
+func runEksctlDryRun(set *ClusterSet) (string, error) {
+	cluster := set.Cluster
+
+	args := []string{"create", "cluster", "-f", "-", "--dry-run"}
+
+	if !cluster.ManageNodeGroups {
+		args = append(args, "--without-nodegroup")
+	}
+
+	cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+	if err != nil {
+		return "", fmt.Errorf("creating eksctl-create-cluster-dry-run command: %w", err)
+	}
+
+	cmd.Stdin = bytes.NewReader(set.ClusterConfig)
+
+	res, err := resource.Run(cmd)
+	if err != nil {
+		return "", fmt.Errorf("--dry-run requires eksctl 0.54.0 or later: %w", err)
+	}
+
+	return res.Stdout, nil
+}
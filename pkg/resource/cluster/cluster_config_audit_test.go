@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteClusterConfigFiles(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "cluster-config-audit")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "audit")
+
+	require.NoError(t, writeClusterConfigFiles(dir, map[string]string{
+		"primary.yaml":         "apiVersion: eksctl.io/v1alpha5\n",
+		"primary.dry-run.yaml": "apiVersion: eksctl.io/v1alpha5\nkind: ClusterConfig\n",
+	}))
+
+	config, err := ioutil.ReadFile(filepath.Join(dir, "primary.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: eksctl.io/v1alpha5\n", string(config))
+
+	dryRun, err := ioutil.ReadFile(filepath.Join(dir, "primary.dry-run.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: eksctl.io/v1alpha5\nkind: ClusterConfig\n", string(dryRun))
+
+	require.NoError(t, writeClusterConfigFiles(dir, map[string]string{"primary.yaml": "updated\n"}))
+
+	config, err = ioutil.ReadFile(filepath.Join(dir, "primary.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "updated\n", string(config))
+}
+
+func TestRunEksctlDryRun(t *testing.T) {
+	var args []string
+
+	prev := resource.SetCommandRunner(resource.CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*resource.CommandResult, error) {
+		args = cmd.Args
+
+		res := resource.NewCommandResult()
+		res.Output = "[ℹ]  eksctl version 0.54.0\napiVersion: eksctl.io/v1alpha5\n"
+		res.Stdout = "apiVersion: eksctl.io/v1alpha5\n"
+
+		return res, nil
+	}))
+	defer resource.SetCommandRunner(prev)
+
+	config, err := runEksctlDryRun(&ClusterSet{Cluster: &Cluster{EksctlBin: "eksctl"}, ClusterConfig: []byte("kind: ClusterConfig\n")})
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: eksctl.io/v1alpha5\n", config)
+	assert.Equal(t, []string{"eksctl", "create", "cluster", "-f", "-", "--dry-run", "--without-nodegroup"}, args)
+}
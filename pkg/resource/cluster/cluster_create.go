@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,34 +15,80 @@ import (
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 )
 
-func (m *Manager) createCluster(d *schema.ResourceData) (*ClusterSet, error) {
+func (m *Manager) createCluster(d *schema.ResourceData, p *resource.ProviderConfig) (*ClusterSet, error) {
 	id := newClusterID()
 
 	log.Printf("[DEBUG] creating eksctl cluster with id %q", id)
 
-	set, err := m.PrepareClusterSet(d, id)
+	defer closeTunnels()
+
+	set, err := m.PrepareClusterSet(d, p, id)
 	if err != nil {
 		return nil, err
 	}
 
 	cluster := set.Cluster
 
+	if err := recordClusterConfig(d, set); err != nil {
+		return nil, err
+	}
+
+	// Drop anything read on plan, as the remote state is going to change
+	invalidateRemoteReadCache(cluster)
+	defer invalidateRemoteReadCache(cluster)
+
 	if err := createVPCResourceTags(cluster, set.ClusterName); err != nil {
 		return nil, err
 	}
 
-	cmd, err := newEksctlCommandWithAWSProfile(cluster, "create", "cluster", "-f", "-")
-	if err != nil {
-		return nil, fmt.Errorf("creating eksctl-create command: %w", err)
+	if err := doFixSubnetTags(cluster); err != nil {
+		return nil, err
 	}
 
-	cmd.Stdin = bytes.NewReader(set.ClusterConfig)
+	createCluster := func() error {
+		args := append([]string{"create", "cluster", "-f", "-"}, cluster.devicePluginArgs()...)
+
+		if !cluster.ManageNodeGroups {
+			args = append(args, "--without-nodegroup")
+		}
+
+		cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+		if err != nil {
+			return fmt.Errorf("creating eksctl-create command: %w", err)
+		}
 
-	if err := resource.Create(cmd, d, id); err != nil {
-		return nil, fmt.Errorf("running `eksctl create cluster: %w: USED CLUSTER CONFIG:\n%s", err, string(set.ClusterConfig))
+		cmd.Stdin = bytes.NewReader(set.ClusterConfig)
+
+		if err := resource.Create(cmd, d, id); err != nil {
+			return fmt.Errorf("running `eksctl create cluster: %w: USED CLUSTER CONFIG:\n%s", err, string(set.ClusterConfig))
+		}
+
+		return nil
 	}
 
-	if err := doWriteKubeconfig(d, string(set.ClusterName), cluster.Region); err != nil {
+	// The cluster exists when only nodegroup stacks are rolled back, so only nodegroups are created on retries
+	createNodeGroups := func() error {
+		args := append([]string{"create", "nodegroup", "-f", "-"}, cluster.devicePluginArgs()...)
+
+		cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+		if err != nil {
+			return fmt.Errorf("creating eksctl-create-nodegroup command: %w", err)
+		}
+
+		cmd.Stdin = bytes.NewReader(set.ClusterConfig)
+
+		if err := resource.Create(cmd, d, id); err != nil {
+			return fmt.Errorf("running `eksctl create nodegroup: %w: USED CLUSTER CONFIG:\n%s", err, string(set.ClusterConfig))
+		}
+
+		return nil
+	}
+
+	if err := withNodeGroupRollbackRecovery(cluster, set.ClusterName, createCluster, createNodeGroups); err != nil {
+		return nil, withStackFailures(err, cluster, set.ClusterName)
+	}
+
+	if err := doWriteKubeconfig(d, cluster.Provider, string(set.ClusterName), cluster.Region); err != nil {
 		return nil, err
 	}
 
@@ -49,10 +96,26 @@ func (m *Manager) createCluster(d *schema.ResourceData) (*ClusterSet, error) {
 		return nil, err
 	}
 
+	if err := doDeployClusterAutoscaler(cluster, set.ClusterName); err != nil {
+		return nil, err
+	}
+
+	if err := doInstallAWSLoadBalancerController(cluster, set.ClusterName); err != nil {
+		return nil, err
+	}
+
 	if err := doAttachAutoScalingGroupsToTargetGroups(set); err != nil {
 		return nil, err
 	}
 
+	if err := doBindTargetGroups(set); err != nil {
+		return nil, err
+	}
+
+	if err := reconcileTargetGroupAttachments(cluster, set.ClusterName, nil, cluster.TargetGroupARNs); err != nil {
+		return nil, err
+	}
+
 	if err := doCheckPodsReadiness(cluster, id); err != nil {
 		return nil, err
 	}
@@ -61,6 +124,14 @@ func (m *Manager) createCluster(d *schema.ResourceData) (*ClusterSet, error) {
 		return nil, err
 	}
 
+	if err := runHooks("create", cluster, set.ClusterName, cluster.CreateHooks); err != nil {
+		return nil, err
+	}
+
+	if err := setSpecChecksum(d, set.ClusterConfig); err != nil {
+		return nil, err
+	}
+
 	return set, nil
 }
 
@@ -71,45 +142,127 @@ func (m *Manager) doPlanKubeconfig(d *DiffReadWrite) error {
 		path = v.(string)
 	}
 
-	if path == "" {
+	if path == "" && !kubeconfigStateOnly(d) {
 		d.SetNewComputed(KeyKubeconfigPath)
 	}
 
 	return nil
 }
 
-func doWriteKubeconfig(d ReadWrite, clusterName, region string) error {
+func doWriteKubeconfig(d ReadWrite, p *resource.ProviderConfig, clusterName, region string) error {
+	if kubeconfigStateOnly(d) {
+		return doLoadKubeconfigToState(d, p, clusterName, region)
+	}
+
 	var path string
 
 	if v := d.Get(KeyKubeconfigPath); v != nil {
 		path = v.(string)
 	}
 
+	merge := kubeconfigMerge(d)
+
+	if path == "" && merge {
+		p, err := defaultMergedKubeconfigPath()
+		if err != nil {
+			return err
+		}
+
+		path = p
+
+		d.Set(KeyKubeconfigPath, path)
+	}
+
 	if path == "" {
-		kubeconfig, err := ioutil.TempFile(os.TempDir(), "tf-eksctl-kubeconfig")
+		tmp, err := p.TempFile("tf-eksctl-kubeconfig")
 		if err != nil {
-			return fmt.Errorf("failed generating kubeconfig path: %w", err)
+			return err
 		}
-		_ = kubeconfig.Close()
 
-		path = kubeconfig.Name()
+		path = tmp
 
 		d.Set(KeyKubeconfigPath, path)
 	}
 
-	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, "utils", "write-kubeconfig", "--cluster", clusterName)
+	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, p, "utils", "write-kubeconfig", "--cluster", clusterName)
 	if err != nil {
 		return fmt.Errorf("creating eksctl-utils-write-kubeconfig command: %w", err)
 	}
 
-	cmd.Env = append(cmd.Env, os.Environ()...)
-	cmd.Env = append(cmd.Env, "KUBECONFIG="+path)
+	env := cmd.Env
+
+	// eksctl writes to a copy of the kubeconfig that then atomically replaces the kubeconfig while holding the lock,
+	// so that resources and applies sharing the path never leave it truncated.
+	// When merging, eksctl writes a standalone kubeconfig that is then merged into the path under the context name.
+	writeKubeconfig := func(writePath string) error {
+		bin, _ := d.Get(KeyBin).(string)
+		version, _ := d.Get(KeyEksctlVersion).(string)
 
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed running %s %s: %vw: COMBINED OUTPUT:\n%s", cmd.Path, strings.Join(cmd.Args, " "), err, string(out))
+		if !eksctlAvailable(bin, version) {
+			return writeKubeconfigWithSDK(d, p, clusterName, region, writePath)
+		}
+
+		cmd.Env = append(env, "KUBECONFIG="+writePath)
+
+		if _, err := resource.Run(cmd); err != nil {
+			return fmt.Errorf("writing kubeconfig: %w", err)
+		}
+
+		log.Printf("Ran `%s %s` with KUBECONFIG=%s", cmd.Path, strings.Join(cmd.Args, " "), writePath)
+
+		return nil
 	}
 
-	log.Printf("Ran `%s %s` with KUBECONFIG=%s", cmd.Path, strings.Join(cmd.Args, " "), path)
+	var kubectlArgs []string
+
+	if merge {
+		writePath, err := p.TempFile("tf-eksctl-kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		defer p.RemoveTempFile(writePath)
+
+		if err := writeKubeconfig(writePath); err != nil {
+			return err
+		}
+
+		contextName := kubeconfigContextName(d, clusterName)
+
+		if err := mergeKubeconfigFile(writePath, path, contextName); err != nil {
+			return fmt.Errorf("merging kubeconfig into %s: %w", path, err)
+		}
+
+		kubectlArgs = append(kubectlArgs, "--context", contextName)
+	} else if err := withKubeconfigLock(path, func() error {
+		existing, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading kubeconfig: %w", err)
+		}
+
+		// The copy is in the same directory as the kubeconfig, so that it can be renamed to the kubeconfig
+		tmp, err := ioutil.TempFile(filepath.Dir(path), "tf-eksctl-kubeconfig")
+		if err != nil {
+			return fmt.Errorf("failed generating kubeconfig path: %w", err)
+		}
+		_ = tmp.Close()
+
+		writePath := tmp.Name()
+
+		defer os.Remove(writePath)
+
+		if err := ioutil.WriteFile(writePath, existing, 0600); err != nil {
+			return fmt.Errorf("copying kubeconfig: %w", err)
+		}
+
+		if err := writeKubeconfig(writePath); err != nil {
+			return err
+		}
+
+		return os.Rename(writePath, path)
+	}); err != nil {
+		return err
+	}
 
 	kubectlBin := "kubectl"
 	if v := d.Get(KeyKubectlBin); v != nil {
@@ -122,16 +275,16 @@ func doWriteKubeconfig(d ReadWrite, clusterName, region string) error {
 	retries := 5
 	retryDelay := 5 * time.Second
 	for i := 0; i < retries; i++ {
-		kubectlVersion := exec.Command(kubectlBin, "version")
-		kubectlVersion.Env = append(cmd.Env, os.Environ()...)
-		kubectlVersion.Env = append(cmd.Env, "KUBECONFIG="+path)
+		kubectlVersion := exec.Command(kubectlBin, append([]string{"version"}, kubectlArgs...)...)
+		kubectlVersion.Env = append(env, "KUBECONFIG="+path)
+		kubectlVersion = resource.WithoutCredentialHelper(kubectlVersion)
 
-		out, err := kubectlVersion.CombinedOutput()
+		_, err := resource.Run(kubectlVersion)
 		if err == nil {
 			break
 		}
 
-		log.Printf("Retrying kubectl version error with KUBECONFIG=%s: %v: COMBINED OUTPUT:\n%s", path, err, string(out))
+		log.Printf("Retrying kubectl version error with KUBECONFIG=%s: %v", path, err)
 		time.Sleep(retryDelay)
 	}
 
@@ -139,6 +292,10 @@ func doWriteKubeconfig(d ReadWrite, clusterName, region string) error {
 }
 
 func createIAMIdentityMapping(d *schema.ResourceData, cluster *Cluster) error {
+	if !cluster.ManageAWSAuth {
+		return nil
+	}
+
 	iams, err := runGetIAMIdentityMapping(d, cluster)
 	if err != nil {
 		return fmt.Errorf("can not get iamidentitymapping from eks cluster: %w", err)
@@ -147,7 +304,7 @@ func createIAMIdentityMapping(d *schema.ResourceData, cluster *Cluster) error {
 	if len(iams) == 0 {
 		log.Printf("no data from eksctl get iamidentitymapping")
 	} else {
-		if err := d.Set(KeyAWSAuthConfigMap, iams); err != nil {
+		if err := d.Set(KeyAWSAuthConfigMap, flattenAWSAuthMappings(iams)); err != nil {
 			return fmt.Errorf("set aws-auth-configmap from iamidentitymapping : %w", err)
 		}
 	}
@@ -167,6 +324,8 @@ func createIAMIdentityMapping(d *schema.ResourceData, cluster *Cluster) error {
 }
 
 func runCreateIAMIdentityMapping(d *schema.ResourceData, s *schema.Set, cluster *Cluster) error {
+	defer invalidateRemoteReadCache(cluster)
+
 	values := s.List()
 	for _, v := range values {
 		ele := v.(map[string]interface{})
@@ -189,7 +348,7 @@ func runCreateIAMIdentityMapping(d *schema.ResourceData, s *schema.Set, cluster
 		}
 		args = append(args, g2...)
 
-		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, args...)
+		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, args...)
 
 		if err != nil {
 			return fmt.Errorf("creating create imaidentitymapping command: %w", err)
@@ -206,6 +365,8 @@ func runCreateIAMIdentityMapping(d *schema.ResourceData, s *schema.Set, cluster
 }
 
 func runDeleteIAMIdentityMapping(d *schema.ResourceData, s *schema.Set, cluster *Cluster) error {
+	defer invalidateRemoteReadCache(cluster)
+
 	values := s.List()
 	for _, v := range values {
 		ele := v.(map[string]interface{})
@@ -218,7 +379,7 @@ func runDeleteIAMIdentityMapping(d *schema.ResourceData, s *schema.Set, cluster
 			ele["iamarn"].(string),
 		}
 
-		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, args...)
+		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, args...)
 
 		if err != nil {
 			return fmt.Errorf("creating create imaidentitymapping command: %w", err)
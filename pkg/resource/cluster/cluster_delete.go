@@ -8,16 +8,25 @@ import (
 	"log"
 )
 
-func (m *Manager) deleteCluster(d *schema.ResourceData) error {
-	log.Printf("[DEBUG] deleting eksctl cluster with id %q", d.Id())
+func (m *Manager) deleteCluster(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	return m.deleteClusterWithID(d, p, d.Id())
+}
+
+// deleteClusterWithID deletes the cluster with the id, which can be other than the resource's, like a retained previous cluster
+func (m *Manager) deleteClusterWithID(d *schema.ResourceData, p *resource.ProviderConfig, id string) error {
+	log.Printf("[DEBUG] deleting eksctl cluster with id %q", id)
 
-	set, err := m.PrepareClusterSet(d)
+	defer closeTunnels()
+
+	set, err := m.PrepareClusterSet(d, p, id)
 	if err != nil {
 		return err
 	}
 
 	cluster := set.Cluster
 
+	defer invalidateRemoteReadCache(cluster)
+
 	args := []string{
 		"delete",
 		"cluster",
@@ -25,7 +34,17 @@ func (m *Manager) deleteCluster(d *schema.ResourceData) error {
 		"--wait",
 	}
 
-	if err := doDeleteKubernetesResourcesBeforeDestroy(cluster, d.Id()); err != nil {
+	args = append(args, cluster.NodeGroupDrain.deleteClusterArgs()...)
+
+	if err := doBackup(cluster, set.ClusterName); err != nil {
+		return fmt.Errorf("backing up cluster %s before deletion: %w", set.ClusterName, err)
+	}
+
+	if err := doDeleteKubernetesResourcesBeforeDestroy(cluster, id); err != nil {
+		return err
+	}
+
+	if err := runHooks("destroy", cluster, set.ClusterName, cluster.DestroyHooks); err != nil {
 		return err
 	}
 
@@ -37,13 +56,21 @@ func (m *Manager) deleteCluster(d *schema.ResourceData) error {
 	cmd.Stdin = bytes.NewReader(set.ClusterConfig)
 
 	if err := resource.Delete(cmd, d); err != nil {
-		return err
+		return withStackFailures(err, cluster, set.ClusterName)
 	}
 
 	if err := deleteVPCResourceTags(cluster, set.ClusterName); err != nil {
 		return err
 	}
 
+	// The cluster is already gone at this point. Failing here would leave it in the state,
+	// so that the next apply fails trying to delete it again
+	if cluster.CleanupOrphanedResources {
+		if err := cleanupOrphanedResources(cluster, set.ClusterName); err != nil {
+			log.Printf("[WARN] %v. Delete them manually if they block deleting the VPC", err)
+		}
+	}
+
 	// TODO Delete target groups
 	// TODO Delete ALB listener rule
 
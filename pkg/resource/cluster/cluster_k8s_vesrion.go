@@ -15,12 +15,12 @@ type LiveClusterInfo struct {
 	Revision          int
 }
 
-func getLiveClusterInfo(d *schema.ResourceData) (*LiveClusterInfo, error) {
+func getLiveClusterInfo(d *schema.ResourceData, p *resource.ProviderConfig) (*LiveClusterInfo, error) {
 	log.Printf("[DEBUG] getting eksctl cluster k8s version with id %q", d.Id())
 
 	m := &Manager{}
 
-	set, err := m.PrepareClusterSet(d)
+	set, err := m.PrepareClusterSet(d, p)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +56,7 @@ func getLiveClusterInfo(d *schema.ResourceData) (*LiveClusterInfo, error) {
 
 	var data []ClusterData
 
-	if err := json.Unmarshal([]byte(res.Output), &data); err != nil {
+	if err := json.Unmarshal([]byte(res.Stdout), &data); err != nil {
 		return nil, err
 	}
 
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
@@ -46,8 +47,10 @@ func (d *DiffReadWrite) Id() string {
 	return d.D.Id()
 }
 
-func (m *Manager) readCluster(d ReadWrite) (*Cluster, error) {
-	cluster, err := m.readClusterInternal(d)
+func (m *Manager) readCluster(d ReadWrite, p *resource.ProviderConfig) (*Cluster, error) {
+	defer closeTunnels()
+
+	cluster, err := m.readClusterInternal(d, p)
 
 	if err != nil {
 		return nil, fmt.Errorf("reading cluster: %w", err)
@@ -66,28 +69,46 @@ func (m *Manager) readCluster(d ReadWrite) (*Cluster, error) {
 	if path != "" {
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			log.Printf("running customdiff: no kubeconfig file found at kubeconfig_path=%s: recreating it", path)
-			if err := doWriteKubeconfig(d, string(m.getClusterName(cluster, d.Id())), cluster.Region); err != nil {
+			if err := doWriteKubeconfig(d, cluster.Provider, string(m.getClusterName(cluster, d.Id())), cluster.Region); err != nil {
 				return nil, fmt.Errorf("writing missing kubeconfig on plan: %w", err)
 			}
 		}
 	}
+	if _, planning := d.(*DiffReadWrite); !planning && d.Id() != "" && kubeconfigStateOnly(d) {
+		if err := doLoadKubeconfigToState(d, cluster.Provider, string(m.getClusterName(cluster, d.Id())), cluster.Region); err != nil {
+			return nil, fmt.Errorf("reading kubeconfig: %w", err)
+		}
+	}
+
 	if err := readIAMIdentityMapping(d, cluster); err != nil {
-		return nil, fmt.Errorf("reading aws-auth via eksctl get iamidentitymaping: %w", err)
+		return nil, fmt.Errorf("reading aws-auth: %w", err)
 	}
 
 	return cluster, nil
 }
 
-func (m *Manager) readClusterInternal(d ReadWrite) (*Cluster, error) {
-	clusterNamePrefix := d.Get("name").(string)
+func (m *Manager) readClusterInternal(d ReadWrite, p *resource.ProviderConfig) (*Cluster, error) {
+	c, err := ReadCluster(d, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// On plan, target_group_arns is left as configured so that the difference from the current attachments is planned
+	if _, planning := d.(*DiffReadWrite); planning || d.Id() == "" {
+		return c, nil
+	}
 
-	sess := resource.AWSSessionFromResourceData(d)
+	clusterName := m.getClusterName(c, d.Id())
 
-	arns, err := getTargetGroupARNs(sess, clusterNamePrefix)
+	cached, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(c.Profile, c.AssumeRoles), c.Region, c.Name, "target-group-attachments/"+string(clusterName)), func() (interface{}, error) {
+		return readTargetGroupAttachments(c, clusterName)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("reading cluster: %w", err)
 	}
 
+	arns := cached.([]string)
+
 	var v []interface{}
 
 	for _, arn := range arns {
@@ -95,19 +116,16 @@ func (m *Manager) readClusterInternal(d ReadWrite) (*Cluster, error) {
 	}
 
 	if err := d.Set(KeyTargetGroupARNs, v); err != nil {
-		log.Printf("setting resource data value for key %v: %w", KeyTargetGroupARNs, err)
+		log.Printf("setting resource data value for key %v: %v", KeyTargetGroupARNs, err)
 	}
 
-	c, err := ReadCluster(d)
-	if err != nil {
-		return nil, err
-	}
+	c.TargetGroupARNs = arns
 
-	return c, err
+	return c, nil
 }
 
-func (m *Manager) planCluster(d *DiffReadWrite) error {
-	_, err := m.readClusterInternal(d)
+func (m *Manager) planCluster(d *DiffReadWrite, p *resource.ProviderConfig) error {
+	_, err := m.readClusterInternal(d, p)
 	if err != nil {
 		return err
 	}
@@ -120,6 +138,10 @@ func (m *Manager) planCluster(d *DiffReadWrite) error {
 }
 
 func readIAMIdentityMapping(d ReadWrite, cluster *Cluster) error {
+	if !cluster.ManageAWSAuth {
+		return nil
+	}
+
 	iamWithOIDCEnabled, err := cluster.IAMWithOIDCEnabled()
 	if err != nil {
 		return fmt.Errorf("reading iam.withOIDC setting from cluster.yaml: %w", err)
@@ -140,19 +162,48 @@ func readIAMIdentityMapping(d ReadWrite, cluster *Cluster) error {
 
 	// sort for diff
 	sort.Slice(current, func(i, j int) bool { return current[i]["iamarn"].(string) < current[j]["iamarn"].(string) })
-	sort.Slice(iams, func(i, j int) bool { return iams[i]["iamarn"].(string) < iams[j]["iamarn"].(string) })
 
-	if diff := cmp.Diff(iams, current); diff != "" {
+	remote := flattenAWSAuthMappings(iams)
+
+	if diff := cmp.Diff(remote, current); diff != "" {
 		log.Printf("aws-auth diff remote (-remote +current):\n%s", diff)
 	} else {
-		log.Printf("have diff between remote source and param")
+		log.Printf("no diff between remote source and param")
+	}
+
+	// Reflect the remote mappings including group memberships on refresh, so that the changes made outside of Terraform show up in diffs
+	if _, planning := d.(*DiffReadWrite); !planning {
+		if err := d.Set(KeyAWSAuthConfigMap, remote); err != nil {
+			return fmt.Errorf("setting %s: %w", KeyAWSAuthConfigMap, err)
+		}
 	}
 
 	return nil
 }
 
-func runGetIAMIdentityMapping(d Read, cluster *Cluster) ([]map[string]interface{}, error) {
+func runGetIAMIdentityMapping(d Read, cluster *Cluster) ([]awsAuthMapping, error) {
+	v, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(cluster.Profile, cluster.AssumeRoles), cluster.Region, cluster.Name, "iamidentitymapping"), func() (interface{}, error) {
+		return doRunGetIAMIdentityMapping(d, cluster)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]awsAuthMapping), nil
+}
+
+func doRunGetIAMIdentityMapping(d Read, cluster *Cluster) ([]awsAuthMapping, error) {
+	iams, err := getAWSAuthConfigMapMappings(d, cluster)
+	if err == nil {
+		return iams, nil
+	}
 
+	log.Printf("Falling back to eksctl get iamidentitymapping: %v", err)
+
+	return runEksctlGetIAMIdentityMapping(d, cluster)
+}
+
+func runEksctlGetIAMIdentityMapping(d Read, cluster *Cluster) ([]awsAuthMapping, error) {
 	//get iamidentitymapping
 	args := []string{
 		"get",
@@ -162,7 +213,7 @@ func runGetIAMIdentityMapping(d Read, cluster *Cluster) ([]map[string]interface{
 		"-o",
 		"json",
 	}
-	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, args...)
+	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("creating get imaidentitymapping command: %w", err)
@@ -173,16 +224,7 @@ func runGetIAMIdentityMapping(d Read, cluster *Cluster) ([]map[string]interface{
 	if err != nil {
 		return nil, fmt.Errorf("running get iamidentitymapping : %w", err)
 	}
-	//replace rolearn and userarn to iamarn
-	iamJson1 := strings.Replace(iamJson.Output, "rolearn", "iamarn", -1)
-	iamJson2 := strings.Replace(iamJson1, "userarn", "iamarn", -1)
-
-	var iams []map[string]interface{}
-	if err := json.Unmarshal([]byte(iamJson2), &iams); err != nil {
-		return nil, fmt.Errorf("parse iamidentitymapping : %w", err)
-	}
-
-	return iams, nil
+	return parseEksctlGetIAMIdentityMappingOutput(iamJson.Stdout)
 }
 
 func loadOIDCProviderURLAndARN(d ReadWrite, cluster *Cluster) error {
@@ -205,38 +247,93 @@ func loadOIDCProviderURLAndARN(d ReadWrite, cluster *Cluster) error {
 	return nil
 }
 
+// loadClusterVersion sets the Kubernetes and platform versions of the remote cluster,
+// so that upgrades made outside of Terraform, like via the console or the end-of-support auto-upgrades, show up in `terraform plan`.
+func loadClusterVersion(d ReadWrite, cluster *Cluster) error {
+	state, err := runGetCluster(d, cluster)
+	if err != nil {
+		return fmt.Errorf("reading cluster version: %w", err)
+	}
+
+	if state.Version != "" {
+		d.Set(KeyVersion, state.Version)
+	}
+
+	d.Set(KeyPlatformVersion, state.PlatformVersion)
+
+	return nil
+}
+
 type ClusterState struct {
-	Name               string             `json:"Name"`
-	Identity           Identity           `json:"Identity"`
-	RoleArn            string             `json:"RoleArn"`
-	ResourcesVpcConfig ResourcesVpcConfig `json:"ResourcesVpcConfig"`
+	Name                 string               `json:"Name"`
+	Arn                  string               `json:"Arn"`
+	Version              string               `json:"Version"`
+	PlatformVersion      string               `json:"PlatformVersion"`
+	Endpoint             string               `json:"Endpoint"`
+	CertificateAuthority CertificateAuthority `json:"CertificateAuthority"`
+	Status               string               `json:"Status"`
+	Identity             Identity             `json:"Identity"`
+	RoleArn              string               `json:"RoleArn"`
+	ResourcesVpcConfig   ResourcesVpcConfig   `json:"ResourcesVpcConfig"`
+
+	// OutpostConfig is set only for local clusters on AWS Outposts
+	OutpostConfig *OutpostConfig `json:"OutpostConfig,omitempty"`
+}
+
+type CertificateAuthority struct {
+	Data string `json:"Data"`
 }
 
 type ResourcesVpcConfig struct {
 	ClusterSecurityGroupId string   `json:"ClusterSecurityGroupId"`
 	SecurityGroupIds       []string `json:"SecurityGroupIds"`
+	SubnetIds              []string `json:"SubnetIds"`
+	VpcId                  string   `json:"VpcId"`
+}
+
+// IsLocalCluster returns true when the cluster is an EKS local cluster on AWS Outposts
+func (s *ClusterState) IsLocalCluster() bool {
+	return s.OutpostConfig != nil && len(s.OutpostConfig.OutpostArns) > 0
 }
 
 func (s *ClusterState) GetOIDCProviderARN() string {
+	// Local clusters on Outposts have no OIDC issuer
+	if s.Identity.Oidc.Issuer == "" {
+		return ""
+	}
+
 	// RoleArn is like
-	//   arn:aws:iam::ACCOUNT:role/eksctl-CLUSTERNAME-cluster-ServiceRole-O7YWRVENASZV
+	//   arn:PARTITION:iam::ACCOUNT:role/eksctl-CLUSTERNAME-cluster-ServiceRole-O7YWRVENASZV
 	// Identity.Oidc.Issuer is like
 	//   https://oidc.eks.REGION.amazonaws.com/id/ISSUER_ID
+	// or https://oidc.eks.REGION.amazonaws.com.cn/id/ISSUER_ID in the China partition.
 	// Use those to generate OIDCProviderARN like:
-	//   arn:aws:iam::ACCOUNT:oidc-provider/oidc.eks.REGION.amazonaws.com/id/ISSUE_ID
-	account := strings.Split(
-		strings.TrimPrefix(s.RoleArn, "arn:aws:iam::"),
-		":",
-	)[0]
+	//   arn:PARTITION:iam::ACCOUNT:oidc-provider/oidc.eks.REGION.amazonaws.com/id/ISSUE_ID
+	var partition, account string
+
+	if parts := strings.Split(s.RoleArn, ":"); len(parts) > 4 {
+		partition, account = parts[1], parts[4]
+	}
+
+	if partition == "" {
+		region := strings.Split(
+			strings.TrimPrefix(s.Identity.Oidc.Issuer, "https://oidc.eks."),
+			".",
+		)[0]
 
-	region := strings.Split(
-		strings.TrimPrefix(s.Identity.Oidc.Issuer, "https://oidc.eks."),
-		".",
-	)[0]
+		partition = partitionForRegion(region)
+	}
 
-	id := s.Identity.Oidc.Issuer[strings.LastIndex(s.Identity.Oidc.Issuer, "/")+1:]
+	return fmt.Sprintf("arn:%s:iam::%s:oidc-provider/%s", partition, account, strings.TrimPrefix(s.Identity.Oidc.Issuer, "https://"))
+}
 
-	return fmt.Sprintf("arn:aws:iam::%s:oidc-provider/oidc.eks.%s.amazonaws.com/id/%s", account, region, id)
+// partitionForRegion returns the partition like aws, aws-cn, and aws-us-gov for the region
+func partitionForRegion(region string) string {
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return p.ID()
+	}
+
+	return endpoints.AwsPartitionID
 }
 
 func (s *ClusterState) GetSecurityGroupIDs() []string {
@@ -252,6 +349,33 @@ type Oidc struct {
 }
 
 func runGetCluster(d Read, cluster *Cluster) (*ClusterState, error) {
+	v, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(cluster.Profile, cluster.AssumeRoles), cluster.Region, cluster.Name, "cluster"), func() (interface{}, error) {
+		return doRunGetCluster(d, cluster)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*ClusterState), nil
+}
+
+func doRunGetCluster(d Read, cluster *Cluster) (*ClusterState, error) {
+	// The AWS SDK we use doesn't support OutpostConfig of local clusters yet
+	if local, err := cluster.LocalClusterEnabled(); err == nil && local {
+		return runEksctlGetCluster(d, cluster)
+	}
+
+	state, err := describeClusterWithSDK(cluster)
+	if err == nil {
+		return state, nil
+	}
+
+	log.Printf("Falling back to eksctl get cluster: %v", err)
+
+	return runEksctlGetCluster(d, cluster)
+}
+
+func runEksctlGetCluster(d Read, cluster *Cluster) (*ClusterState, error) {
 	args := []string{
 		"get",
 		"cluster",
@@ -260,7 +384,7 @@ func runGetCluster(d Read, cluster *Cluster) (*ClusterState, error) {
 		"-o",
 		"json",
 	}
-	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, args...)
+	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("creating get imaidentitymapping command: %w", err)
@@ -272,11 +396,11 @@ func runGetCluster(d Read, cluster *Cluster) (*ClusterState, error) {
 	}
 
 	var states []*ClusterState
-	if err := json.Unmarshal([]byte(run.Output), &states); err != nil {
+	if err := json.Unmarshal([]byte(run.Stdout), &states); err != nil {
 		return nil, fmt.Errorf("parsing get-cluster output as json : %w", err)
 	}
 
-	log.Printf("parsed cluster state: %s", run.Output)
+	log.Printf("parsed cluster state: %s", resource.Redact(run.Stdout))
 
 	var state *ClusterState
 
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// describeClusterWithSDK reads the cluster state via the EKS API.
+// This is much faster than `eksctl get cluster` and works without eksctl being installed.
+func describeClusterWithSDK(cluster *Cluster) (*ClusterState, error) {
+	svc := eks.New(AWSSessionFromCluster(cluster))
+
+	r, err := svc.DescribeCluster(&eks.DescribeClusterInput{
+		Name: aws.String(cluster.Name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing cluster %s: %w", cluster.Name, err)
+	}
+
+	if r.Cluster == nil {
+		return nil, fmt.Errorf("describing cluster %s: no cluster found", cluster.Name)
+	}
+
+	return clusterStateFromEKSCluster(r.Cluster), nil
+}
+
+func clusterStateFromEKSCluster(c *eks.Cluster) *ClusterState {
+	state := &ClusterState{
+		Name:            aws.StringValue(c.Name),
+		Arn:             aws.StringValue(c.Arn),
+		Version:         aws.StringValue(c.Version),
+		PlatformVersion: aws.StringValue(c.PlatformVersion),
+		Status:          aws.StringValue(c.Status),
+		RoleArn:         aws.StringValue(c.RoleArn),
+	}
+
+	state.Endpoint = aws.StringValue(c.Endpoint)
+
+	if c.CertificateAuthority != nil {
+		state.CertificateAuthority.Data = aws.StringValue(c.CertificateAuthority.Data)
+	}
+
+	if c.Identity != nil && c.Identity.Oidc != nil {
+		state.Identity.Oidc.Issuer = aws.StringValue(c.Identity.Oidc.Issuer)
+	}
+
+	if v := c.ResourcesVpcConfig; v != nil {
+		state.ResourcesVpcConfig = ResourcesVpcConfig{
+			ClusterSecurityGroupId: aws.StringValue(v.ClusterSecurityGroupId),
+			SecurityGroupIds:       aws.StringValueSlice(v.SecurityGroupIds),
+			SubnetIds:              aws.StringValueSlice(v.SubnetIds),
+			VpcId:                  aws.StringValue(v.VpcId),
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClusterStateFromEKSCluster(t *testing.T) {
+	state := clusterStateFromEKSCluster(&eks.Cluster{
+		Name:    aws.String("mycluster"),
+		RoleArn: aws.String("arn:aws:iam::123456789012:role/eksctl-mycluster-cluster-ServiceRole-O7YWRVENASZV"),
+		Identity: &eks.Identity{
+			Oidc: &eks.OIDC{
+				Issuer: aws.String("https://oidc.eks.us-east-2.amazonaws.com/id/ABCDEF"),
+			},
+		},
+		ResourcesVpcConfig: &eks.VpcConfigResponse{
+			SecurityGroupIds: aws.StringSlice([]string{"sg-1", "sg-2"}),
+			VpcId:            aws.String("vpc-1"),
+		},
+	})
+
+	assert.Equal(t, "mycluster", state.Name)
+	assert.Equal(t, "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/ABCDEF", state.GetOIDCProviderARN())
+	assert.Equal(t, []string{"sg-1", "sg-2"}, state.GetSecurityGroupIDs())
+	assert.Equal(t, "vpc-1", state.ResourcesVpcConfig.VpcId)
+}
+
+func TestGetOIDCProviderARN_partitions(t *testing.T) {
+	govcloud := &ClusterState{
+		RoleArn:  "arn:aws-us-gov:iam::123456789012:role/eks",
+		Identity: Identity{Oidc: Oidc{Issuer: "https://oidc.eks.us-gov-west-1.amazonaws.com/id/ABCDEF"}},
+	}
+	assert.Equal(t, "arn:aws-us-gov:iam::123456789012:oidc-provider/oidc.eks.us-gov-west-1.amazonaws.com/id/ABCDEF", govcloud.GetOIDCProviderARN())
+
+	china := &ClusterState{
+		RoleArn:  "arn:aws-cn:iam::123456789012:role/eks",
+		Identity: Identity{Oidc: Oidc{Issuer: "https://oidc.eks.cn-north-1.amazonaws.com.cn/id/ABCDEF"}},
+	}
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:oidc-provider/oidc.eks.cn-north-1.amazonaws.com.cn/id/ABCDEF", china.GetOIDCProviderARN())
+
+	assert.Equal(t, "aws-cn", partitionForRegion("cn-northwest-1"))
+	assert.Equal(t, "aws-us-gov", partitionForRegion("us-gov-east-1"))
+	assert.Equal(t, "aws", partitionForRegion("unknown-region"))
+}
@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadClusterVersion(t *testing.T) {
+	cluster := &Cluster{Name: "fake-version-drift-abc", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	// The cluster was upgraded outside of Terraform
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{Version: "1.19", PlatformVersion: "eks.3"})
+
+	d := schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:    "fake-version-drift",
+		KeyVersion: "1.18",
+	})
+
+	require.NoError(t, loadClusterVersion(d, cluster))
+	assert.Equal(t, "1.19", d.Get(KeyVersion))
+	assert.Equal(t, "eks.3", d.Get(KeyPlatformVersion))
+}
+
+func TestLoadClusterVersion_unknownVersion(t *testing.T) {
+	cluster := &Cluster{Name: "fake-no-version-abc", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{})
+
+	d := schema.TestResourceDataRaw(t, ResourceCluster().Schema, map[string]interface{}{
+		KeyName:    "fake-no-version",
+		KeyVersion: "1.18",
+	})
+
+	// The version in the state is kept when the remote version is unknown
+	require.NoError(t, loadClusterVersion(d, cluster))
+	assert.Equal(t, "1.18", d.Get(KeyVersion))
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	KeyClusterName         = "cluster_name"
+	KeyPreviousClusterID   = "previous_cluster_id"
+	KeyPreviousClusterName = "previous_cluster_name"
+)
+
+// setPreviousCluster records the cluster the deployment has just switched over from,
+// so that dashboards and log queries can refer to both the current and the previous clusters.
+func setPreviousCluster(d *schema.ResourceData, id string, name ClusterName) {
+	d.Set(KeyPreviousClusterID, id)
+	d.Set(KeyPreviousClusterName, string(name))
+}
+
+type clusterNamesDiff interface {
+	Id() string
+	HasChange(string) bool
+	SetNewComputed(string) error
+}
+
+// planClusterNames marks the generated cluster names as unknown when the apply results in a blue-green cluster deployment,
+// so that the resources referencing them are updated in the same apply.
+func planClusterNames(d clusterNamesDiff) error {
+	if d.Id() == "" || !(d.HasChange(KeyVersion) || d.HasChange(KeyRevision)) {
+		return nil
+	}
+
+	for _, k := range []string{KeyClusterName, KeyPreviousClusterID, KeyPreviousClusterName} {
+		if err := d.SetNewComputed(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
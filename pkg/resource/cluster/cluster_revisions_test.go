@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClusterNamesDiff struct {
+	id       string
+	changed  map[string]bool
+	computed []string
+}
+
+func (d *fakeClusterNamesDiff) Id() string {
+	return d.id
+}
+
+func (d *fakeClusterNamesDiff) HasChange(k string) bool {
+	return d.changed[k]
+}
+
+func (d *fakeClusterNamesDiff) SetNewComputed(k string) error {
+	d.computed = append(d.computed, k)
+
+	return nil
+}
+
+func TestPlanClusterNames(t *testing.T) {
+	// The cluster names are known on plan until the first apply creates the cluster
+	d := &fakeClusterNamesDiff{changed: map[string]bool{KeyRevision: true}}
+	assert.NoError(t, planClusterNames(d))
+	assert.Empty(t, d.computed)
+
+	d = &fakeClusterNamesDiff{id: "mycluster", changed: map[string]bool{KeyNodeGroup: true}}
+	assert.NoError(t, planClusterNames(d))
+	assert.Empty(t, d.computed)
+
+	for _, k := range []string{KeyVersion, KeyRevision} {
+		d = &fakeClusterNamesDiff{id: "mycluster", changed: map[string]bool{k: true}}
+		assert.NoError(t, planClusterNames(d))
+		assert.Equal(t, []string{KeyClusterName, KeyPreviousClusterID, KeyPreviousClusterName}, d.computed, "changing %s", k)
+	}
+}
+
+func TestSetPreviousCluster(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, ResourceClusterDeployment().Schema, map[string]interface{}{
+		KeyName: "mycluster",
+	})
+
+	setPreviousCluster(d, "previd", ClusterName("mycluster-blue"))
+
+	assert.Equal(t, "previd", d.Get(KeyPreviousClusterID))
+	assert.Equal(t, "mycluster-blue", d.Get(KeyPreviousClusterName))
+}
@@ -0,0 +1,227 @@
+package cluster
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// sharedClusterSchema returns the attributes that eksctl_cluster and eksctl_cluster_deployment have in common,
+// so that the two resources can't drift apart
+func sharedClusterSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		// service_role_arn is the pre-created service role set to `iam.serviceRoleARN` in the spec,
+		// for when eksctl isn't allowed to create IAM roles
+		KeyServiceRoleARN: serviceRoleARNSchema(),
+		// kubeconfig_mode = "state_only" never writes the kubeconfig to disk,
+		// and exposes the content and the exec-auth parameters via `kubeconfig` and `exec_auth` instead.
+		KeyKubeconfigMode: kubeconfigModeSchema(),
+		KeyKubeconfig:     kubeconfigSchema(),
+		KeyExecAuth:       execAuthSchema(),
+		// kubeconfig_merge merges the cluster into the kubeconfig at `kubeconfig_path`, or ~/.kube/config by default,
+		// under the context named `context_name`, which defaults to the cluster name.
+		KeyKubeconfigMerge: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		KeyContextName: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		// host, cluster_ca_certificate and token are meant to be fed into the kubernetes and helm providers.
+		// token is short-lived and regenerated on every read.
+		KeyHost: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		KeyClusterCACertificate: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		KeyToken: {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+		// spec_vars are the variables used for rendering `spec` as a Go template, like `{{ .env }}`.
+		KeySpecVars: {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		// spec_json is the eksctl cluster.yaml given as JSON, typically built with `jsonencode`.
+		// The provider converts it to YAML before passing it to eksctl.
+		KeySpecJSON: specJSONSchema(),
+		// specs is the ordered list of cluster.yaml documents that are deep-merged before being passed to eksctl,
+		// so that a base spec can be shared across clusters with per-environment overlays.
+		KeySpecs: specsSchema(),
+		// spec_patches are JSON 6902 or strategic merge patches applied to the spec in order.
+		// The result is computed on plan into `patched_spec`, so that the diff shows what is passed to eksctl.
+		KeySpecPatches: specPatchesSchema(),
+		KeyPatchedSpec: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		// spec_source lets the provider fetch the spec from a Git repository on plan, instead of `spec`.
+		// The fetched spec and the commit SHA are stored in `resolved_spec` and `spec_source_commit` respectively.
+		KeySpecSource: specSourceSchema(),
+		KeyResolvedSpec: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		KeySpecSourceCommit: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		// target_group_selector selects the target groups for `target_group_arns` by tags or exact names on plan.
+		KeyTargetGroupSelector: targetGroupSelectorSchema(),
+		// target_group_arns are the target groups to which the autoscaling groups of the cluster's nodegroups are attached.
+		// Adding or removing ARNs attaches or detaches the autoscaling groups in place.
+		KeyTargetGroupARNs: {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		// max_create_retries is the max number of retries of nodegroup creations whose CloudFormation stacks are rolled back.
+		// The rolled back stacks are deleted before retries.
+		KeyMaxCreateRetries: {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		// cleanup_orphaned_resources deletes the ENIs and the cluster security group left after `eksctl delete cluster`,
+		// which often block deleting the VPC
+		KeyCleanupOrphanedResources: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// server_side_apply applies the manifests, the cluster-autoscaler, and the target group bindings with `kubectl apply --server-side`
+		// and the field manager "terraform-provider-eksctl", so that GitOps controllers can adopt them later without ownership conflicts
+		KeyServerSideApply: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		// upgrade_addons updates kube-proxy, aws-node, and coredns after the control plane is upgraded to the new `version`
+		KeyUpgradeAddons: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// nodegroup_upgrade upgrades the managed nodegroups to the new `version` after the control plane is upgraded
+		KeyNodeGroupUpgrade: nodeGroupUpgradeSchema(),
+		// subnet_tagging validates on plan that the existing subnets in the spec have the load balancer role tags with "validate",
+		// or adds the missing tags on apply with "fix"
+		KeySubnetTagging: {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      SubnetTaggingValidate,
+			ValidateFunc: validation.StringInSlice([]string{SubnetTaggingNone, SubnetTaggingValidate, SubnetTaggingFix}, false),
+		},
+		// connection makes kubectl, helm, and hooks access the private-only cluster endpoint through an SSM port-forwarding session or an SSH bastion
+		KeyConnection: connectionSchema(),
+		// nodegroup_blue_green names nodegroups after the hashes of their specs, so that changed nodegroups are replaced
+		// by new ones, and the old ones are drained and deleted only after the new nodes and pods become ready
+		KeyNodeGroupBlueGreen: nodeGroupBlueGreenSchema(),
+		// manage_aws_auth = false skips reading and reconciling iamidentitymappings, for clusters whose aws-auth ConfigMap is owned by e.g. GitOps
+		KeyManageAWSAuth: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		// last_run_log is the transcript of the commands run in the last operation, with the credentials redacted
+		resource.KeyLastRunLog: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		// run_log_path is the file to which the transcripts are appended as JSON lines, which survives failed applies in CI
+		resource.KeyRunLogPath: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		// iam_preflight simulates the IAM actions required by eksctl on plan, and either reports
+		// the missing ones via iam_missing_actions or fails the plan
+		KeyIAMPreflight:      iamPreflightSchema(),
+		KeyIAMMissingActions: iamMissingActionsSchema(),
+		// assume_role is the chain of roles assumed in order on top of the provider's ones,
+		// so that a single provider configuration can manage clusters in multiple accounts
+		resource.KeyAssumeRole: resource.AssumeRoleSchema(),
+		// vpc, nodegroup, managed_nodegroup, and iam_service_account are typed alternatives to
+		// the corresponding sections of the spec, which are rendered into the spec
+		KeyVPC:               typedVPCSchema(),
+		KeyNodeGroup:         typedNodeGroupSchema(false),
+		KeyManagedNodeGroup:  typedNodeGroupSchema(true),
+		KeyIAMServiceAccount: typedIAMServiceAccountSchema(),
+		// cluster_config_dir is the directory where the cluster.yaml passed to eksctl is written on each create and update
+		// as `<cluster name>.yaml`, along with `<cluster name>.dry-run.yaml` when cluster_config_dry_run is true
+		KeyClusterConfigDir: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		// cluster_config_dry_run records the output of `eksctl create cluster --dry-run`, which includes the defaults filled by eksctl
+		KeyClusterConfigDryRun: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// cluster_config is the exact cluster.yaml passed to eksctl in the last create or update,
+		// and cluster_config_with_defaults is the output of `eksctl create cluster --dry-run` for it
+		KeyClusterConfig:             computedStringSchema(),
+		KeyClusterConfigWithDefaults: computedStringSchema(),
+		// spec_checksum is the SHA256 of the normalized cluster.yaml applied in the last successful create or update
+		KeySpecChecksum: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		// deletion_protection makes `terraform destroy` fail until it is set to false in a prior apply
+		KeyDeletionProtection: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// install_nvidia_plugin and install_neuron_plugin make eksctl install the device plugins
+		// so that GPU and Inferentia nodegroups are usable right after apply
+		KeyInstallNvidiaPlugin: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		KeyInstallNeuronPlugin: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		// nodegroup_drain configures how nodes are drained on nodegroup and cluster deletions and `drain_node_groups`
+		KeyNodeGroupDrain: nodeGroupDrainSchema(),
+		// autoscaler bootstraps cluster-autoscaler with the IRSA role and the auto-discovery tags on nodegroups
+		KeyAutoscaler: autoscalerSchema(),
+		// aws_load_balancer_controller installs the controller with helm so that the new cluster can serve ingresses right after creation
+		KeyAWSLoadBalancerController: awsLoadBalancerControllerSchema(),
+		// create_hooks are commands run after the cluster is created and ready, with KUBECONFIG pointing to the cluster.
+		// Each hook can be retried and timed out, and its failure can be made a warning with `failure_policy = "warn"`.
+		KeyCreateHooks: hooksSchema(),
+		// destroy_hooks are commands run before `eksctl delete cluster`, with KUBECONFIG pointing to the cluster.
+		// Useful for e.g. deregistering the cluster from Argo CD or service meshes, or taking Velero backups.
+		KeyDestroyHooks: hooksSchema(),
+		// backup takes a Velero backup, or runs an arbitrary backup command, and waits for it to complete before the cluster is deleted
+		KeyBackup: backupSchema(),
+	}
+}
+
+// withSharedClusterSchema adds the attributes from sharedClusterSchema to the resource-specific schema
+func withSharedClusterSchema(s map[string]*schema.Schema) map[string]*schema.Schema {
+	for k, v := range sharedClusterSchema() {
+		s[k] = v
+	}
+
+	return s
+}
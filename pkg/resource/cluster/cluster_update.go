@@ -10,16 +10,30 @@ import (
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 )
 
-func (m *Manager) updateCluster(d *schema.ResourceData) (*ClusterSet, error) {
+func (m *Manager) updateCluster(d *schema.ResourceData, p *resource.ProviderConfig) (*ClusterSet, error) {
 	log.Printf("[DEBUG] updating eksctl cluster with id %q", d.Id())
 
-	set, err := m.PrepareClusterSet(d)
+	defer closeTunnels()
+
+	set, err := m.PrepareClusterSet(d, p)
 	if err != nil {
 		return nil, err
 	}
 
 	cluster, clusterConfig := set.Cluster, set.ClusterConfig
 
+	if err := recordClusterConfig(d, set); err != nil {
+		return nil, err
+	}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	// eksctl commands driven solely by the cluster config are no-ops when the config is unchanged since the last successful apply
+	specModified := specChecksumChanged(d, clusterConfig)
+	if !specModified {
+		log.Printf("[DEBUG] skipping eksctl commands driven by the cluster config as %s is unchanged", KeySpecChecksum)
+	}
+
 	updateBy := func(args []string, harmlessErrors []string) func() error {
 		return func() error {
 			eksctlCmdToLog := fmt.Sprintf("eksctl-%s", strings.Join(args, "-"))
@@ -179,7 +193,25 @@ func (m *Manager) updateCluster(d *schema.ResourceData) (*ClusterSet, error) {
 
 	writeKubeconfig := func() func() error {
 		return func() error {
-			return doWriteKubeconfig(d, string(set.ClusterName), cluster.Region)
+			return doWriteKubeconfig(d, cluster.Provider, string(set.ClusterName), cluster.Region)
+		}
+	}
+
+	deployClusterAutoscaler := func() func() error {
+		return func() error {
+			return doDeployClusterAutoscaler(cluster, set.ClusterName)
+		}
+	}
+
+	installAWSLoadBalancerController := func() func() error {
+		return func() error {
+			return doInstallAWSLoadBalancerController(cluster, set.ClusterName)
+		}
+	}
+
+	fixSubnetTags := func() func() error {
+		return func() error {
+			return doFixSubnetTags(cluster)
 		}
 	}
 
@@ -189,6 +221,18 @@ func (m *Manager) updateCluster(d *schema.ResourceData) (*ClusterSet, error) {
 		}
 	}
 
+	updateTargetGroupAttachments := func() func() error {
+		return func() error {
+			if !d.HasChange(KeyTargetGroupARNs) {
+				return nil
+			}
+
+			a, b := d.GetChange(KeyTargetGroupARNs)
+
+			return reconcileTargetGroupAttachments(cluster, set.ClusterName, toStrings(a), toStrings(b))
+		}
+	}
+
 	id := d.Id()
 
 	clusterName := string(set.ClusterName)
@@ -214,6 +258,8 @@ func (m *Manager) updateCluster(d *schema.ResourceData) (*ClusterSet, error) {
 
 				if v == false {
 					opt = append(opt, "--undo")
+				} else {
+					opt = append(opt, cluster.NodeGroupDrain.drainNodeGroupArgs()...)
 				}
 				cmd, err := newEksctlCommandWithAWSProfile(cluster, opt...)
 
@@ -231,6 +277,81 @@ func (m *Manager) updateCluster(d *schema.ResourceData) (*ClusterSet, error) {
 
 	}
 
+	// upgradeControlPlane upgrades the control plane only when the version is changed.
+	// Otherwise `eksctl upgrade cluster` is run without `--approve` to just log the plan.
+	upgradeControlPlane := func() func() error {
+		return func() error {
+			args := []string{"upgrade", "cluster"}
+
+			if d.HasChange(KeyVersion) {
+				args = append(args, "--approve")
+			}
+
+			return updateBy(args, nil)()
+		}
+	}
+
+	upgradeAddons := func() func() error {
+		return func() error {
+			if !cluster.UpgradeAddons || !d.HasChange(KeyVersion) {
+				return nil
+			}
+
+			cmds, err := cluster.postUpgradeCommands()
+			if err != nil {
+				return err
+			}
+
+			for _, args := range cmds {
+				if err := updateBy(args, nil)(); err != nil {
+					return fmt.Errorf("updating addons after upgrading cluster to %s: %w", cluster.Version, err)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	scaleNodeGroups := func() func() error {
+		return func() error {
+			return doScaleNodeGroups(d, cluster, set.ClusterName)
+		}
+	}
+
+	reconcileAddonDrift := func() func() error {
+		return func() error {
+			return doReconcileAddonDrift(d, cluster, clusterConfig)
+		}
+	}
+
+	replaceDriftedNodeGroups := func() func() error {
+		return func() error {
+			return doReplaceDriftedNodeGroups(d, cluster, set.ClusterName)
+		}
+	}
+
+	upgradeNodeGroups := func() func() error {
+		return func() error {
+			if !d.HasChange(KeyVersion) {
+				return nil
+			}
+
+			return doUpgradeNodeGroups(cluster, set.ClusterName)
+		}
+	}
+
+	blueGreenNodeGroups := func() func() error {
+		return func() error {
+			return doBlueGreenNodeGroups(d, cluster, set.ClusterName, clusterConfig)
+		}
+	}
+
+	withRollbackRecovery := func(f func() error) func() error {
+		return func() error {
+			return withNodeGroupRollbackRecovery(cluster, set.ClusterName, f, f)
+		}
+	}
+
 	whenIAMWithOIDCEnabled := func(f func() error) func() error {
 		return func() error {
 			iamWithOIDCEnabled, err := cluster.IAMWithOIDCEnabled()
@@ -244,51 +365,76 @@ func (m *Manager) updateCluster(d *schema.ResourceData) (*ClusterSet, error) {
 		}
 	}
 
-	updateIAMIdentityMapping := func() func() error {
+	// whenNodeGroupsManaged skips f when the nodegroups are managed by eksctl_nodegroup resources,
+	// so that e.g. `eksctl delete nodegroup --only-missing` doesn't delete them
+	whenNodeGroupsManaged := func(f func() error) func() error {
 		return func() error {
-			d.HasChange(KeyIAMIdentityMapping)
-			a, b := d.GetChange(KeyIAMIdentityMapping)
-
-			if err := runCreateIAMIdentityMapping(d, b.(*schema.Set).Difference(a.(*schema.Set)), cluster); err != nil {
-				return fmt.Errorf("CreateIAMIdentityMapping Error: %v", err)
+			if !cluster.ManageNodeGroups {
+				return nil
 			}
 
-			if err := runDeleteIAMIdentityMapping(d, a.(*schema.Set).Difference(b.(*schema.Set)), cluster); err != nil {
-				return fmt.Errorf("DeleteIAMIdentityMapping Error: %v", err)
+			return f()
+		}
+	}
+
+	updateIAMIdentityMapping := func() func() error {
+		return func() error {
+			return updateAWSAuthMappings(d, cluster)
+		}
+	}
+
+	whenSpecModified := func(f func() error) func() error {
+		return func() error {
+			if !specModified {
+				return nil
 			}
 
-			return nil
+			return f()
 		}
 	}
 
 	tasks := []func() error{
 		// See https://eksctl.io/usage/cluster-upgrade/ for the cluster upgrade process
-		updateBy([]string{"upgrade", "cluster"}, nil),
-		updateBy([]string{"utils", "update-kube-proxy"}, nil),
-		updateBy([]string{"utils", "update-aws-node"}, nil),
-		updateBy([]string{"utils", "update-coredns"}, nil),
-		createNew("nodegroup", nil, nil),
-		whenIAMWithOIDCEnabled(associateIAMOIDCProvider()),
-		whenIAMWithOIDCEnabled(createNew("iamserviceaccount", []string{"--approve"}, nil)),
-		createNew("fargateprofile", nil, harmlessFargateProfileCreationErrors),
-		enableRepo(),
-		draineNodegroup(),
+		whenSpecModified(upgradeControlPlane()),
+		whenSpecModified(updateBy([]string{"utils", "update-kube-proxy"}, nil)),
+		whenSpecModified(updateBy([]string{"utils", "update-aws-node"}, nil)),
+		whenSpecModified(updateBy([]string{"utils", "update-coredns"}, nil)),
+		upgradeAddons(),
+		reconcileAddonDrift(),
+		whenNodeGroupsManaged(upgradeNodeGroups()),
+		whenNodeGroupsManaged(replaceDriftedNodeGroups()),
+		whenSpecModified(whenNodeGroupsManaged(withRollbackRecovery(createNew("nodegroup", cluster.devicePluginArgs(), nil)))),
+		whenNodeGroupsManaged(scaleNodeGroups()),
+		whenSpecModified(whenIAMWithOIDCEnabled(associateIAMOIDCProvider())),
+		whenSpecModified(whenIAMWithOIDCEnabled(createNew("iamserviceaccount", []string{"--approve"}, nil))),
+		whenSpecModified(createNew("fargateprofile", nil, harmlessFargateProfileCreationErrors)),
+		whenSpecModified(enableRepo()),
+		whenNodeGroupsManaged(draineNodegroup()),
 		updateIAMIdentityMapping(),
-		deleteMissing("nodegroup", []string{"--drain", "--approve"}, nil),
-		whenIAMWithOIDCEnabled(deleteMissing("iamserviceaccount", []string{"--approve"}, nil)),
+		whenNodeGroupsManaged(blueGreenNodeGroups()),
+		whenSpecModified(whenNodeGroupsManaged(deleteMissing("nodegroup", append(cluster.NodeGroupDrain.deleteNodeGroupArgs(), "--approve"), nil))),
+		whenSpecModified(whenIAMWithOIDCEnabled(deleteMissing("iamserviceaccount", []string{"--approve"}, nil))),
 		// eksctl delete fargate profile doens't has --only-missing command
 		//deleteMissing("fargateprofile", nil, []string{"Error: invalid Fargate profile: empty name"}),
+		fixSubnetTags(),
 		applyKubernetesManifests(id),
+		deployClusterAutoscaler(),
+		installAWSLoadBalancerController(),
 		attachNodeGroupsToTargetGroups(),
+		updateTargetGroupAttachments(),
 		checkPodsReadiness(id),
 		writeKubeconfig(),
 	}
 
 	for _, t := range tasks {
 		if err := t(); err != nil {
-			return nil, err
+			return nil, withStackFailures(err, cluster, set.ClusterName)
 		}
 	}
 
+	if err := setSpecChecksum(d, clusterConfig); err != nil {
+		return nil, err
+	}
+
 	return set, nil
 }
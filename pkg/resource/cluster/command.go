@@ -4,9 +4,10 @@ import (
 	"fmt"
 	resource2 "github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"os/exec"
+	"strings"
 )
 
-func newEksctlCommandFromResourceWithRegionAndProfile(resource Read, args ...string) (*exec.Cmd, error) {
+func newEksctlCommandFromResourceWithRegionAndProfile(resource Read, p *resource2.ProviderConfig, args ...string) (*exec.Cmd, error) {
 	eksctlBin := resource.Get(KeyBin).(string)
 	eksctlVersion := resource.Get(KeyEksctlVersion).(string)
 
@@ -15,19 +16,32 @@ func newEksctlCommandFromResourceWithRegionAndProfile(resource Read, args ...str
 		return nil, fmt.Errorf("preparing eksctl binary: %w", err)
 	}
 
-	region, profile := resource2.GetAWSRegionAndProfile(resource)
+	region, profile := resource2.GetAWSRegionAndProfile(resource, p)
+	roles := resource2.GetAssumeRoles(resource)
 
 	if region != "" {
 		args = append(args, "--region", region)
 	}
 
-	if profile != "" {
+	// The temporary credentials obtained by assuming roles are passed via envvars instead
+	if profile != "" && !p.AWSConfig().AssumesRoles(roles) {
 		args = append(args, "--profile", profile)
 	}
 
+	env, err := p.Environ(region, profile, roles)
+	if err != nil {
+		return nil, err
+	}
+
 	cmd := exec.Command(*bin, args...)
+	cmd.Env = env
+
+	cmd = p.WithCredentialHelper(cmd)
 
-	return cmd, nil
+	// Resources like eksctl_labels have no name, and are audited without a resource ID
+	name, _ := resource.Get(KeyName).(string)
+
+	return p.WithAuditLog(cmd, name), nil
 }
 
 func newEksctlCommand(cluster *Cluster, args ...string) (*exec.Cmd, error) {
@@ -36,18 +50,63 @@ func newEksctlCommand(cluster *Cluster, args ...string) (*exec.Cmd, error) {
 		return nil, fmt.Errorf("creating eksctl command: %w", err)
 	}
 
+	env, err := cluster.Provider.Environ(cluster.Region, cluster.Profile, cluster.AssumeRoles)
+	if err != nil {
+		return nil, err
+	}
+
 	cmd := exec.Command(*eksctlBin, args...)
+	cmd.Env = env
+
+	cmd = cluster.Provider.WithCredentialHelper(cmd)
 
-	return cmd, nil
+	return cluster.Provider.WithAuditLog(cmd, cluster.Name), nil
 }
 
 // We don't add `--region` flag as this provider prefers metadata.region in cluster.yaml to specify the region
 func newEksctlCommandWithAWSProfile(cluster *Cluster, args ...string) (*exec.Cmd, error) {
 	_, profile := cluster.Region, cluster.Profile
 
-	if profile != "" {
+	// The temporary credentials obtained by assuming roles are passed via envvars instead
+	if profile != "" && !cluster.Provider.AWSConfig().AssumesRoles(cluster.AssumeRoles) {
 		args = append(args, "--profile", profile)
 	}
 
 	return newEksctlCommand(cluster, args...)
 }
+
+// newKubectlCommand creates a kubectl command that operates on the cluster with the kubeconfig.
+func newKubectlCommand(cluster *Cluster, kubeconfigPath string, args ...string) (*exec.Cmd, error) {
+	kubectlBin := cluster.KubectlBin
+	if kubectlBin == "" {
+		kubectlBin = "kubectl"
+	}
+
+	return newKubeconfigCommand(cluster, kubectlBin, kubeconfigPath, args...)
+}
+
+// newKubeconfigCommand creates a command like kubectl and helm that operates on the cluster with the kubeconfig.
+// The kubeconfig is rewritten to go through the tunnel when the connection is configured.
+func newKubeconfigCommand(cluster *Cluster, bin, kubeconfigPath string, args ...string) (*exec.Cmd, error) {
+	env, err := cluster.Provider.Environ(cluster.Region, cluster.Profile, cluster.AssumeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfigPath, err = tunneledKubeconfig(cluster, kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(bin, args...)
+
+	for _, e := range env {
+		if !strings.HasPrefix(e, "KUBECONFIG=") {
+			cmd.Env = append(cmd.Env, e)
+		}
+	}
+
+	cmd.Env = append(cmd.Env, "KUBECONFIG="+kubeconfigPath)
+
+	return cluster.Provider.WithAuditLog(cmd, cluster.Name), nil
+}
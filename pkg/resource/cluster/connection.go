@@ -0,0 +1,351 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+const KeyConnection = "tunnel"
+
+const (
+	ConnectionTypeSSM = "ssm"
+	ConnectionTypeSSH = "ssh"
+)
+
+// tunnelReadyTimeout is how long the provider waits for the tunnel to accept connections
+const tunnelReadyTimeout = 60 * time.Second
+
+// Connection configures the tunnel to the private-only cluster endpoint through which kubectl, helm, and hooks access the cluster,
+// for Terraform runners outside of the VPC.
+type Connection struct {
+	// Type is either "ssm" for an SSM port-forwarding session or "ssh" for an SSH bastion
+	Type string
+	// Target is the instance ID for "ssm" or the bastion host for "ssh"
+	Target string
+	// User and PrivateKeyPath and Port are used only for "ssh"
+	User           string
+	PrivateKeyPath string
+	Port           int
+	// LocalPort is the local port of the tunnel. A free port is chosen when it is 0
+	LocalPort int
+}
+
+func connectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice([]string{ConnectionTypeSSM, ConnectionTypeSSH}, false),
+				},
+				"target": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"user": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "ec2-user",
+				},
+				"private_key_path": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"port": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  22,
+				},
+				"local_port": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  0,
+				},
+			},
+		},
+	}
+}
+
+func readConnection(v interface{}) *Connection {
+	conns := v.([]interface{})
+	if len(conns) == 0 || conns[0] == nil {
+		return nil
+	}
+
+	m := conns[0].(map[string]interface{})
+
+	return &Connection{
+		Type:           m["type"].(string),
+		Target:         m["target"].(string),
+		User:           m["user"].(string),
+		PrivateKeyPath: m["private_key_path"].(string),
+		Port:           m["port"].(int),
+		LocalPort:      m["local_port"].(int),
+	}
+}
+
+type tunnel struct {
+	cmd       *exec.Cmd
+	localPort int
+}
+
+var (
+	tunnelsMu sync.Mutex
+	// tunnels are the running tunnels keyed by the cluster endpoint hosts
+	tunnels = map[string]*tunnel{}
+	// tunneledKubeconfigs are the rewritten kubeconfigs to be removed on closeTunnels
+	tunneledKubeconfigs []string
+)
+
+// tunneledKubeconfig returns the path to the copy of the kubeconfig whose server points to the tunnel to the cluster endpoint.
+// The tunnel is started on first use, and reused until closeTunnels is called.
+// The kubeconfig is returned as-is when no connection is configured.
+func tunneledKubeconfig(cluster *Cluster, kubeconfigPath string) (string, error) {
+	conn := cluster.Connection
+	if conn == nil {
+		return kubeconfigPath, nil
+	}
+
+	data, err := ioutil.ReadFile(kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("reading kubeconfig: %w", err)
+	}
+
+	host, err := kubeconfigServerHost(data)
+	if err != nil {
+		return "", err
+	}
+
+	tunnelsMu.Lock()
+	defer tunnelsMu.Unlock()
+
+	t, ok := tunnels[host]
+	if !ok {
+		t, err = startTunnel(cluster, conn, host)
+		if err != nil {
+			return "", fmt.Errorf("starting %s tunnel to %s via %s: %w", conn.Type, host, conn.Target, err)
+		}
+
+		tunnels[host] = t
+	}
+
+	rewritten, err := rewriteKubeconfigServer(data, host, t.localPort)
+	if err != nil {
+		return "", err
+	}
+
+	path := kubeconfigPath + ".tunnel"
+
+	if err := ioutil.WriteFile(path, rewritten, 0600); err != nil {
+		return "", fmt.Errorf("writing tunneled kubeconfig: %w", err)
+	}
+
+	tunneledKubeconfigs = append(tunneledKubeconfigs, path)
+
+	return path, nil
+}
+
+// closeTunnels stops all the tunnels and removes the rewritten kubeconfigs.
+// This is called at the end of each operation so that no tunnel process outlives the provider.
+func closeTunnels() {
+	tunnelsMu.Lock()
+	defer tunnelsMu.Unlock()
+
+	for host, t := range tunnels {
+		log.Printf("Closing tunnel to %s", host)
+
+		if t.cmd.Process != nil {
+			_ = t.cmd.Process.Kill()
+			_ = t.cmd.Wait()
+		}
+	}
+
+	tunnels = map[string]*tunnel{}
+
+	for _, p := range tunneledKubeconfigs {
+		_ = os.Remove(p)
+	}
+
+	tunneledKubeconfigs = nil
+}
+
+func startTunnel(cluster *Cluster, conn *Connection, host string) (*tunnel, error) {
+	localPort := conn.LocalPort
+	if localPort == 0 {
+		p, err := freeLocalPort()
+		if err != nil {
+			return nil, err
+		}
+
+		localPort = p
+	}
+
+	var cmd *exec.Cmd
+
+	switch conn.Type {
+	case ConnectionTypeSSM:
+		cmd = exec.Command("aws", "ssm", "start-session",
+			"--target", conn.Target,
+			"--document-name", "AWS-StartPortForwardingSessionToRemoteHost",
+			"--parameters", fmt.Sprintf("host=%s,portNumber=443,localPortNumber=%d", host, localPort),
+			"--region", cluster.Region,
+		)
+
+		env, err := cluster.Provider.Environ(cluster.Region, cluster.Profile, cluster.AssumeRoles)
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Env = env
+	case ConnectionTypeSSH:
+		args := []string{
+			"-N",
+			"-o", "ExitOnForwardFailure=yes",
+			"-o", "StrictHostKeyChecking=accept-new",
+			"-L", fmt.Sprintf("127.0.0.1:%d:%s:443", localPort, host),
+			"-p", strconv.Itoa(conn.Port),
+		}
+
+		if conn.PrivateKeyPath != "" {
+			args = append(args, "-i", conn.PrivateKeyPath)
+		}
+
+		args = append(args, conn.User+"@"+conn.Target)
+
+		cmd = exec.Command("ssh", args...)
+	default:
+		return nil, fmt.Errorf("unsupported connection type %q", conn.Type)
+	}
+
+	var out bytes.Buffer
+
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	log.Printf("Starting tunnel to %s on 127.0.0.1:%d: %v", host, localPort, cmd.Args)
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan error, 1)
+
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.Now().Add(tunnelReadyTimeout)
+
+	for {
+		select {
+		case err := <-exited:
+			return nil, fmt.Errorf("tunnel exited: %v\nOUTPUT:\n%s", err, out.String())
+		default:
+		}
+
+		c, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", localPort), time.Second)
+		if err == nil {
+			c.Close()
+
+			return &tunnel{cmd: cmd, localPort: localPort}, nil
+		}
+
+		if time.Now().After(deadline) {
+			_ = cmd.Process.Kill()
+
+			return nil, fmt.Errorf("timed out waiting for the tunnel to be ready after %v\nOUTPUT:\n%s", tunnelReadyTimeout, out.String())
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("finding free local port: %w", err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// kubeconfigServerHost returns the host of the API server in the kubeconfig written by `eksctl utils write-kubeconfig`
+func kubeconfigServerHost(kubeconfig []byte) (string, error) {
+	var config struct {
+		Clusters []struct {
+			Cluster struct {
+				Server string `yaml:"server"`
+			} `yaml:"cluster"`
+		} `yaml:"clusters"`
+	}
+
+	if err := yaml.Unmarshal(kubeconfig, &config); err != nil {
+		return "", fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+
+	if len(config.Clusters) == 0 {
+		return "", fmt.Errorf("no cluster found in kubeconfig")
+	}
+
+	u, err := url.Parse(config.Clusters[0].Cluster.Server)
+	if err != nil {
+		return "", fmt.Errorf("parsing server url in kubeconfig: %w", err)
+	}
+
+	return u.Hostname(), nil
+}
+
+// rewriteKubeconfigServer points the servers for the host in the kubeconfig to the local port of the tunnel.
+// tls-server-name is set so that the server certificate is still verified against the original host.
+func rewriteKubeconfigServer(kubeconfig []byte, host string, localPort int) ([]byte, error) {
+	var config map[string]interface{}
+
+	if err := yaml.Unmarshal(kubeconfig, &config); err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+
+	clusters, _ := config["clusters"].([]interface{})
+
+	for _, c := range clusters {
+		entry, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		cluster, ok := entry["cluster"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		server, _ := cluster["server"].(string)
+
+		u, err := url.Parse(server)
+		if err != nil || u.Hostname() != host {
+			continue
+		}
+
+		cluster["server"] = fmt.Sprintf("https://127.0.0.1:%d", localPort)
+		cluster["tls-server-name"] = host
+	}
+
+	return yaml.Marshal(config)
+}
@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRewriteKubeconfigServer(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Y2E=
+    server: https://ABCDEF.gr7.us-east-1.eks.amazonaws.com
+  name: prod.us-east-1.eksctl.io
+contexts:
+- context:
+    cluster: prod.us-east-1.eksctl.io
+    user: admin@prod.us-east-1.eksctl.io
+  name: admin@prod.us-east-1.eksctl.io
+current-context: admin@prod.us-east-1.eksctl.io
+kind: Config
+`)
+
+	host, err := kubeconfigServerHost(kubeconfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEF.gr7.us-east-1.eks.amazonaws.com", host)
+
+	rewritten, err := rewriteKubeconfigServer(kubeconfig, host, 18443)
+
+	assert.NoError(t, err)
+
+	var config struct {
+		Clusters []struct {
+			Cluster map[string]string `yaml:"cluster"`
+		} `yaml:"clusters"`
+		CurrentContext string `yaml:"current-context"`
+	}
+
+	assert.NoError(t, yaml.Unmarshal(rewritten, &config))
+	assert.Equal(t, map[string]string{
+		"certificate-authority-data": "Y2E=",
+		"server":                     "https://127.0.0.1:18443",
+		"tls-server-name":            "ABCDEF.gr7.us-east-1.eks.amazonaws.com",
+	}, config.Clusters[0].Cluster)
+	assert.Equal(t, "admin@prod.us-east-1.eksctl.io", config.CurrentContext)
+}
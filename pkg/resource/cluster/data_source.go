@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// dataSourceSchema returns the schema shared by data sources that look up an existing cluster by name,
+// merged with the data-source-specific attributes.
+func dataSourceSchema(attrs map[string]*schema.Schema) map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		KeyName: {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		KeyRegion: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		KeyProfile: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		KeyBin: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "eksctl",
+		},
+		KeyEksctlVersion: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		KeyKubectlBin: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "kubectl",
+		},
+	}
+
+	for k, v := range attrs {
+		s[k] = v
+	}
+
+	return s
+}
+
+// readDataSourceCluster builds a Cluster that is sufficient for reading the remote state of an existing cluster
+// that isn't managed by the current Terraform configuration.
+func readDataSourceCluster(d Read, p *resource.ProviderConfig) *Cluster {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	return &Cluster{
+		Name:          d.Get(KeyName).(string),
+		Region:        region,
+		Profile:       profile,
+		Provider:      p,
+		EksctlBin:     d.Get(KeyBin).(string),
+		EksctlVersion: d.Get(KeyEksctlVersion).(string),
+		KubectlBin:    d.Get(KeyKubectlBin).(string),
+	}
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// DataSourceClusterAuth is the equivalent of `aws_eks_cluster_auth`, which returns a fresh token for the cluster
+// using the provider's credential chain including `assume_role`, so that the kubernetes and helm providers can
+// authenticate without the aws provider configured identically.
+// Unlike eksctl_kubeconfig, it only calls the AWS APIs and never runs eksctl.
+func DataSourceClusterAuth() *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+			cluster := &Cluster{
+				Name:        d.Get(KeyName).(string),
+				Region:      region,
+				Profile:     profile,
+				AssumeRoles: resource.GetAssumeRoles(d),
+				Provider:    p,
+			}
+
+			if err := loadClusterAuth(d, cluster, ClusterName(cluster.Name)); err != nil {
+				return fmt.Errorf("loading cluster auth for %s: %w", cluster.Name, err)
+			}
+
+			d.Set(KeyRegion, region)
+			d.SetId(fmt.Sprintf("%s/%s", region, cluster.Name))
+
+			return nil
+		},
+		Schema: dataSourceSchema(map[string]*schema.Schema{
+			// assume_role is assumed on top of the provider's assume_role, for clusters in another account
+			resource.KeyAssumeRole: resource.AssumeRoleSchema(),
+			KeyHost: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyClusterCACertificate: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyToken: {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		}),
+	}
+}
@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceClusterAuth(t *testing.T) {
+	defer setFakeAWSCredentials(t)()
+
+	cluster := &Cluster{Name: "fake-cluster-auth", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{
+		Endpoint:             "https://ABCDEF.gr7.us-east-2.eks.amazonaws.com",
+		CertificateAuthority: CertificateAuthority{Data: base64.StdEncoding.EncodeToString([]byte("fake-ca"))},
+	})
+
+	r := DataSourceClusterAuth()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName:   cluster.Name,
+		KeyRegion: cluster.Region,
+	})
+
+	require.NoError(t, r.Read(d, nil))
+	assert.Equal(t, "us-east-2/fake-cluster-auth", d.Id())
+
+	assert.Equal(t, "https://ABCDEF.gr7.us-east-2.eks.amazonaws.com", d.Get(KeyHost))
+	assert.Equal(t, "fake-ca", d.Get(KeyClusterCACertificate))
+	assert.True(t, strings.HasPrefix(d.Get(KeyToken).(string), "k8s-aws-v1."), "token: %s", d.Get(KeyToken))
+}
+
+func TestDataSourceClusterAuth_providerRegion(t *testing.T) {
+	defer setFakeAWSCredentials(t)()
+
+	cluster := &Cluster{Name: "fake-cluster-auth-default-region", Region: "eu-west-1"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{
+		Endpoint:             "https://ABCDEF.gr7.eu-west-1.eks.amazonaws.com",
+		CertificateAuthority: CertificateAuthority{Data: base64.StdEncoding.EncodeToString([]byte("fake-ca"))},
+	})
+
+	r := DataSourceClusterAuth()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName: cluster.Name,
+	})
+
+	require.NoError(t, r.Read(d, &resource.ProviderConfig{Region: "eu-west-1"}))
+	assert.Equal(t, "eu-west-1/fake-cluster-auth-default-region", d.Id())
+	assert.Equal(t, "eu-west-1", d.Get(KeyRegion))
+	assert.Equal(t, "https://ABCDEF.gr7.eu-west-1.eks.amazonaws.com", d.Get(KeyHost))
+}
+
+func TestDataSourceClusterAuth_invalidCertificateAuthority(t *testing.T) {
+	defer setFakeAWSCredentials(t)()
+
+	cluster := &Cluster{Name: "fake-cluster-auth-invalid-ca", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{
+		Endpoint:             "https://ABCDEF.gr7.us-east-2.eks.amazonaws.com",
+		CertificateAuthority: CertificateAuthority{Data: "not base64"},
+	})
+
+	r := DataSourceClusterAuth()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName:   cluster.Name,
+		KeyRegion: cluster.Region,
+	})
+
+	err := r.Read(d, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading cluster auth for fake-cluster-auth-invalid-ca")
+	assert.Empty(t, d.Id())
+}
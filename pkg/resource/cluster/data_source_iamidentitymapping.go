@@ -0,0 +1,81 @@
+package cluster
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"sort"
+)
+
+const KeyMappings = "mappings"
+
+// DataSourceIAMIdentityMapping returns the current aws-auth identity mappings of an existing cluster,
+// so that other stacks can audit or build on the existing access without owning the cluster resource.
+func DataSourceIAMIdentityMapping() *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readDataSourceCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			iams, err := runGetIAMIdentityMapping(d, cluster)
+			if err != nil {
+				return fmt.Errorf("reading iamidentitymapping of cluster %s: %w", cluster.Name, err)
+			}
+
+			if err := d.Set(KeyMappings, flattenIAMIdentityMappings(iams)); err != nil {
+				return fmt.Errorf("setting %s: %w", KeyMappings, err)
+			}
+
+			d.Set(KeyRegion, cluster.Region)
+			d.SetId(cluster.Name)
+
+			return nil
+		},
+		Schema: dataSourceSchema(map[string]*schema.Schema{
+			KeyMappings: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"iamarn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						// kind is either "role" or "user", depending on whether the mapping is in mapRoles or mapUsers
+						"kind": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"groups": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		}),
+	}
+}
+
+// flattenIAMIdentityMappings converts the mappings into the value of `mappings`, sorted by the ARN like `aws_auth_configmap`.
+// Unlike flattenAWSAuthMappings, every mapping has the kind, as roles and users can't be told apart by the ARN alone.
+func flattenIAMIdentityMappings(iams []awsAuthMapping) []map[string]interface{} {
+	sorted := make([]awsAuthMapping, len(iams))
+	copy(sorted, iams)
+
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ARN() < sorted[j].ARN() })
+
+	mappings := flattenAWSAuthMappings(sorted)
+
+	for i := range mappings {
+		mappings[i]["kind"] = sorted[i].Kind()
+	}
+
+	return mappings
+}
@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFlattenIAMIdentityMappings(t *testing.T) {
+	iams := []awsAuthMapping{
+		{
+			UserARN:  "arn:aws:iam::123456789012:user/admin",
+			Username: "admin",
+			Groups:   []string{"system:masters"},
+		},
+		{
+			RoleARN:  "arn:aws:iam::123456789012:role/node",
+			Username: "system:node:{{EC2PrivateDNSName}}",
+			Groups:   []string{"system:bootstrappers", "system:nodes"},
+		},
+	}
+
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"iamarn":   "arn:aws:iam::123456789012:role/node",
+			"kind":     "role",
+			"username": "system:node:{{EC2PrivateDNSName}}",
+			"groups":   []interface{}{"system:bootstrappers", "system:nodes"},
+		},
+		{
+			"iamarn":   "arn:aws:iam::123456789012:user/admin",
+			"kind":     "user",
+			"username": "admin",
+			"groups":   []interface{}{"system:masters"},
+		},
+	}, flattenIAMIdentityMappings(iams))
+
+	// The mappings read from the cache must be kept as is
+	assert.Equal(t, "arn:aws:iam::123456789012:user/admin", iams[0].ARN())
+}
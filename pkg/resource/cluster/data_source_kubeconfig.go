@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// DataSourceKubeconfig generates the kubeconfig for an existing cluster on demand, so that configurations that only
+// deploy workloads onto the cluster depend on neither the eksctl_cluster resource nor its kubeconfig_path.
+func DataSourceKubeconfig() *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+			cluster := &Cluster{
+				Name:     d.Get(KeyName).(string),
+				Region:   region,
+				Profile:  profile,
+				Provider: p,
+			}
+
+			clusterName := ClusterName(cluster.Name)
+
+			if err := doLoadKubeconfigToState(d, cluster.Provider, cluster.Name, region); err != nil {
+				return fmt.Errorf("generating kubeconfig for %s: %w", cluster.Name, err)
+			}
+
+			if err := loadClusterAuth(d, cluster, clusterName); err != nil {
+				return fmt.Errorf("loading cluster auth for %s: %w", cluster.Name, err)
+			}
+
+			d.Set(KeyRegion, region)
+			d.SetId(fmt.Sprintf("%s/%s", region, cluster.Name))
+
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			KeyName: {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			KeyRegion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			KeyProfile: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyBin: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "eksctl",
+			},
+			KeyEksctlVersion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyKubeconfig: {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			KeyExecAuth: execAuthSchema(),
+			KeyHost: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyClusterCACertificate: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyToken: {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setFakeAWSCredentials makes the AWS SDK presign requests without looking up the real credentials
+func setFakeAWSCredentials(t *testing.T) func() {
+	t.Helper()
+
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKIAFAKE",
+		"AWS_SECRET_ACCESS_KEY": "fake",
+		"AWS_SESSION_TOKEN":     "",
+		"AWS_PROFILE":           "",
+	}
+
+	var restore []func()
+
+	for k, v := range env {
+		k := k
+
+		if prev, ok := os.LookupEnv(k); ok {
+			restore = append(restore, func() { os.Setenv(k, prev) })
+		} else {
+			restore = append(restore, func() { os.Unsetenv(k) })
+		}
+
+		if v == "" {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, v)
+		}
+	}
+
+	return func() {
+		for _, r := range restore {
+			r()
+		}
+	}
+}
+
+func TestDataSourceKubeconfig(t *testing.T) {
+	defer setFakeAWSCredentials(t)()
+
+	cluster := &Cluster{Name: "fake-kubeconfig", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{
+		Endpoint:             "https://ABCDEF.gr7.us-east-2.eks.amazonaws.com",
+		CertificateAuthority: CertificateAuthority{Data: base64.StdEncoding.EncodeToString([]byte("fake-ca"))},
+	})
+
+	r := DataSourceKubeconfig()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName:   cluster.Name,
+		KeyRegion: cluster.Region,
+	})
+
+	require.NoError(t, r.Read(d, nil))
+	assert.Equal(t, "us-east-2/fake-kubeconfig", d.Id())
+
+	kubeconfig := d.Get(KeyKubeconfig).(string)
+	assert.Contains(t, kubeconfig, "server: https://ABCDEF.gr7.us-east-2.eks.amazonaws.com")
+	assert.Contains(t, kubeconfig, "command: aws")
+
+	assert.Equal(t, "aws", d.Get(KeyExecAuth+".0.command"))
+	assert.Equal(t, []interface{}{"eks", "get-token", "--cluster-name", "fake-kubeconfig", "--region", "us-east-2"}, d.Get(KeyExecAuth+".0.args"))
+
+	assert.Equal(t, "https://ABCDEF.gr7.us-east-2.eks.amazonaws.com", d.Get(KeyHost))
+	assert.Equal(t, "fake-ca", d.Get(KeyClusterCACertificate))
+	assert.True(t, strings.HasPrefix(d.Get(KeyToken).(string), "k8s-aws-v1."), "token: %s", d.Get(KeyToken))
+}
+
+func TestDataSourceKubeconfig_invalidCertificateAuthority(t *testing.T) {
+	defer setFakeAWSCredentials(t)()
+
+	cluster := &Cluster{Name: "fake-kubeconfig-invalid-ca", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{
+		Endpoint:             "https://ABCDEF.gr7.us-east-2.eks.amazonaws.com",
+		CertificateAuthority: CertificateAuthority{Data: "not base64"},
+	})
+
+	r := DataSourceKubeconfig()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName:   cluster.Name,
+		KeyRegion: cluster.Region,
+	})
+
+	err := r.Read(d, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading cluster auth for fake-kubeconfig-invalid-ca")
+	assert.Empty(t, d.Id())
+}
@@ -0,0 +1,124 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+type launchTemplateRef struct {
+	ID      string
+	Version string
+}
+
+// DataSourceNodeGroups lists the nodegroups of an existing cluster with their autoscaling groups, launch templates, and scaling config,
+// so that monitoring stacks and autoscaling schedules can target them without manual lookups.
+func DataSourceNodeGroups() *schema.Resource {
+	nodegroups := nodeGroupsSchema()
+
+	attrs := nodegroups.Elem.(*schema.Resource).Schema
+
+	attrs["launch_template_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	attrs["launch_template_version"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readDataSourceCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			summaries, err := runGetNodeGroups(d, cluster, ClusterName(cluster.Name))
+			if err != nil {
+				return fmt.Errorf("reading nodegroups of cluster %s: %w", cluster.Name, err)
+			}
+
+			var asgNames []string
+
+			for _, s := range summaries {
+				if s.AutoScalingGroupName != "" {
+					asgNames = append(asgNames, s.AutoScalingGroupName)
+				}
+			}
+
+			launchTemplates, err := getAutoScalingGroupLaunchTemplates(cluster, asgNames)
+			if err != nil {
+				return err
+			}
+
+			ngs := flattenNodeGroupSummaries(summaries)
+
+			for i, s := range summaries {
+				lt := launchTemplates[s.AutoScalingGroupName]
+
+				ng := ngs[i].(map[string]interface{})
+				ng["launch_template_id"] = lt.ID
+				ng["launch_template_version"] = lt.Version
+			}
+
+			if err := d.Set(KeyNodeGroups, ngs); err != nil {
+				return fmt.Errorf("setting %s: %w", KeyNodeGroups, err)
+			}
+
+			d.Set(KeyRegion, cluster.Region)
+			d.SetId(cluster.Name)
+
+			return nil
+		},
+		Schema: dataSourceSchema(map[string]*schema.Schema{
+			KeyNodeGroups: nodegroups,
+		}),
+	}
+}
+
+// getAutoScalingGroupLaunchTemplates returns the launch templates of the autoscaling groups keyed by the group names.
+// Groups with launch configurations are omitted.
+func getAutoScalingGroupLaunchTemplates(cluster *Cluster, asgNames []string) (map[string]launchTemplateRef, error) {
+	launchTemplates := map[string]launchTemplateRef{}
+
+	if len(asgNames) == 0 {
+		return launchTemplates, nil
+	}
+
+	return describeAutoScalingGroupLaunchTemplates(autoscaling.New(AWSSessionFromCluster(cluster)), asgNames)
+}
+
+func describeAutoScalingGroupLaunchTemplates(svc autoscalingiface.AutoScalingAPI, asgNames []string) (map[string]launchTemplateRef, error) {
+	launchTemplates := map[string]launchTemplateRef{}
+
+	err := svc.DescribeAutoScalingGroupsPages(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice(asgNames),
+	}, func(o *autoscaling.DescribeAutoScalingGroupsOutput, lastPage bool) bool {
+		for _, g := range o.AutoScalingGroups {
+			spec := g.LaunchTemplate
+
+			if spec == nil && g.MixedInstancesPolicy != nil && g.MixedInstancesPolicy.LaunchTemplate != nil {
+				spec = g.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification
+			}
+
+			if spec == nil {
+				continue
+			}
+
+			launchTemplates[aws.StringValue(g.AutoScalingGroupName)] = launchTemplateRef{
+				ID:      aws.StringValue(spec.LaunchTemplateId),
+				Version: aws.StringValue(spec.Version),
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing autoscaling groups %v: %w", asgNames, err)
+	}
+
+	return launchTemplates, nil
+}
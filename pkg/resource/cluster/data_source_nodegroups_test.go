@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type autoScalingGroupsMock struct {
+	autoscalingiface.AutoScalingAPI
+
+	pages [][]*autoscaling.Group
+}
+
+func (m *autoScalingGroupsMock) DescribeAutoScalingGroupsPages(in *autoscaling.DescribeAutoScalingGroupsInput, f func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool) error {
+	for i, groups := range m.pages {
+		if !f(&autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: groups}, i == len(m.pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func TestDescribeAutoScalingGroupLaunchTemplates(t *testing.T) {
+	api := &autoScalingGroupsMock{pages: [][]*autoscaling.Group{
+		{
+			{
+				AutoScalingGroupName: aws.String("asg-lt"),
+				LaunchTemplate:       &autoscaling.LaunchTemplateSpecification{LaunchTemplateId: aws.String("lt-1"), Version: aws.String("3")},
+			},
+		},
+		{
+			{
+				AutoScalingGroupName: aws.String("asg-mixed"),
+				MixedInstancesPolicy: &autoscaling.MixedInstancesPolicy{
+					LaunchTemplate: &autoscaling.LaunchTemplate{
+						LaunchTemplateSpecification: &autoscaling.LaunchTemplateSpecification{LaunchTemplateId: aws.String("lt-2"), Version: aws.String("$Latest")},
+					},
+				},
+			},
+			{
+				AutoScalingGroupName:    aws.String("asg-lc"),
+				LaunchConfigurationName: aws.String("lc-1"),
+			},
+		},
+	}}
+
+	lts, err := describeAutoScalingGroupLaunchTemplates(api, []string{"asg-lt", "asg-mixed", "asg-lc"})
+	require.NoError(t, err)
+
+	// Groups with launch configurations are omitted
+	assert.Equal(t, map[string]launchTemplateRef{
+		"asg-lt":    {ID: "lt-1", Version: "3"},
+		"asg-mixed": {ID: "lt-2", Version: "$Latest"},
+	}, lts)
+}
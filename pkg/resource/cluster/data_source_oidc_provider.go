@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// DataSourceOIDCProvider returns the OIDC issuer URL and the IAM OIDC provider ARN of an existing cluster,
+// so that IRSA role trust policies can be built in stacks that don't manage the cluster.
+func DataSourceOIDCProvider() *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readDataSourceCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			state, err := runGetCluster(d, cluster)
+			if err != nil {
+				return fmt.Errorf("reading cluster %s: %w", cluster.Name, err)
+			}
+
+			if state.Identity.Oidc.Issuer == "" {
+				return fmt.Errorf("cluster %s has no OIDC issuer", cluster.Name)
+			}
+
+			d.Set(KeyOIDCProviderURL, state.Identity.Oidc.Issuer)
+			d.Set(KeyOIDCProviderARN, state.GetOIDCProviderARN())
+
+			d.Set(KeyRegion, cluster.Region)
+			d.SetId(cluster.Name)
+
+			return nil
+		},
+		Schema: dataSourceSchema(map[string]*schema.Schema{
+			KeyOIDCProviderURL: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyOIDCProviderARN: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		}),
+	}
+}
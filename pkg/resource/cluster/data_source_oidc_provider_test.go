@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedRemoteReadCache makes the next remote read of the kind return v without calling AWS or eksctl
+func seedRemoteReadCache(t *testing.T, cluster *Cluster, kind string, v interface{}) {
+	t.Helper()
+
+	_, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(cluster.Profile, cluster.AssumeRoles), cluster.Region, cluster.Name, kind), func() (interface{}, error) {
+		return v, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDataSourceOIDCProvider(t *testing.T) {
+	cluster := &Cluster{Name: "fake-oidc", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{
+		RoleArn:  "arn:aws:iam::123456789012:role/eks",
+		Identity: Identity{Oidc: Oidc{Issuer: "https://oidc.eks.us-east-2.amazonaws.com/id/ABCDEF"}},
+	})
+
+	r := DataSourceOIDCProvider()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName:   cluster.Name,
+		KeyRegion: cluster.Region,
+	})
+
+	require.NoError(t, r.Read(d, nil))
+	assert.Equal(t, cluster.Name, d.Id())
+	assert.Equal(t, "https://oidc.eks.us-east-2.amazonaws.com/id/ABCDEF", d.Get(KeyOIDCProviderURL))
+	assert.Equal(t, "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/ABCDEF", d.Get(KeyOIDCProviderARN))
+}
+
+func TestDataSourceOIDCProvider_noIssuer(t *testing.T) {
+	cluster := &Cluster{Name: "fake-no-oidc", Region: "us-east-2"}
+
+	defer invalidateRemoteReadCache(cluster)
+
+	seedRemoteReadCache(t, cluster, "cluster", &ClusterState{RoleArn: "arn:aws:iam::123456789012:role/eks"})
+
+	r := DataSourceOIDCProvider()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		KeyName:   cluster.Name,
+		KeyRegion: cluster.Region,
+	})
+
+	err := r.Read(d, nil)
+	assert.EqualError(t, err, "cluster fake-no-oidc has no OIDC issuer")
+}
@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KeyVersions       = "versions"
+	KeyLatestVersion  = "latest_version"
+	KeyDefaultVersion = "default_version"
+)
+
+// eksOptimizedAMIParameterFormats are the SSM public parameters of the EKS optimized AMIs.
+// A version is considered available in the region when any of the AMIs are published there.
+var eksOptimizedAMIParameterFormats = []string{
+	"/aws/service/eks/optimized-ami/%s/amazon-linux-2/recommended/image_id",
+	"/aws/service/eks/optimized-ami/%s/amazon-linux-2023/x86_64/standard/recommended/image_id",
+}
+
+// DataSourceVersions returns the Kubernetes versions supported by both eksctl and EKS in the region, along with the latest one
+// and the one eksctl uses by default, so that modules can pick or assert the version of clusters to be created.
+func DataSourceVersions() *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+			cluster := &Cluster{
+				Region:        region,
+				Profile:       profile,
+				Provider:      p,
+				EksctlBin:     d.Get(KeyBin).(string),
+				EksctlVersion: d.Get(KeyEksctlVersion).(string),
+			}
+
+			supported, err := getEksctlSupportedVersions(cluster)
+			if err != nil {
+				return fmt.Errorf("reading versions supported by eksctl: %w", err)
+			}
+
+			available, err := getVersionsAvailableInRegion(cluster, supported)
+			if err != nil {
+				return fmt.Errorf("reading versions available in %s: %w", region, err)
+			}
+
+			if len(available) == 0 {
+				return fmt.Errorf("no version supported by eksctl is available in %s: eksctl supports %s", region, strings.Join(supported, ", "))
+			}
+
+			latest := available[len(available)-1]
+
+			defaultVersion, err := getEksctlDefaultVersion(cluster)
+			if err != nil {
+				log.Printf("Using the latest version %s as the default version: %v", latest, err)
+
+				defaultVersion = latest
+			}
+
+			d.Set(KeyVersions, available)
+			d.Set(KeyLatestVersion, latest)
+			d.Set(KeyDefaultVersion, defaultVersion)
+
+			d.Set(KeyRegion, region)
+			d.SetId(region)
+
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			KeyRegion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			KeyProfile: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyBin: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "eksctl",
+			},
+			KeyEksctlVersion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyVersions: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			KeyLatestVersion: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyDefaultVersion: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func getEksctlSupportedVersions(cluster *Cluster) ([]string, error) {
+	cmd, err := newEksctlCommand(cluster, "version", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Run(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseEksctlVersionOutput(res.Stdout)
+}
+
+func parseEksctlVersionOutput(out string) ([]string, error) {
+	var info struct {
+		EKSServerSupportedVersions []string `json:"EKSServerSupportedVersions"`
+	}
+
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		return nil, fmt.Errorf("parsing eksctl version output: %w\nOUTPUT:\n%s", err, out)
+	}
+
+	if len(info.EKSServerSupportedVersions) == 0 {
+		return nil, fmt.Errorf("eksctl version output has no EKSServerSupportedVersions. Upgrade eksctl to a newer version\nOUTPUT:\n%s", out)
+	}
+
+	return sortKubernetesVersions(info.EKSServerSupportedVersions), nil
+}
+
+// getEksctlDefaultVersion returns the version that eksctl creates clusters with when the version is omitted,
+// by reading metadata.version of the cluster config generated by `eksctl create cluster --dry-run`.
+func getEksctlDefaultVersion(cluster *Cluster) (string, error) {
+	cmd, err := newEksctlCommand(cluster, "create", "cluster", "--dry-run", "--name", "default-version-probe", "--region", cluster.Region)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := resource.Run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	// Skip any log lines that precede the cluster config
+	out := res.Stdout
+	if i := strings.Index(out, "apiVersion:"); i >= 0 {
+		out = out[i:]
+	}
+
+	var config struct {
+		Metadata struct {
+			Version string `yaml:"version"`
+		} `yaml:"metadata"`
+	}
+
+	if err := yaml.Unmarshal([]byte(out), &config); err != nil {
+		return "", fmt.Errorf("parsing eksctl create cluster --dry-run output: %w", err)
+	}
+
+	if config.Metadata.Version == "" {
+		return "", fmt.Errorf("eksctl create cluster --dry-run output has no metadata.version")
+	}
+
+	return config.Metadata.Version, nil
+}
+
+// getVersionsAvailableInRegion returns the versions whose EKS optimized AMIs are published in the region
+func getVersionsAvailableInRegion(cluster *Cluster, versions []string) ([]string, error) {
+	svc := ssm.New(AWSSessionFromCluster(cluster))
+
+	paramToVersion := map[string]string{}
+
+	var names []string
+
+	for _, v := range versions {
+		for _, f := range eksOptimizedAMIParameterFormats {
+			n := fmt.Sprintf(f, v)
+			paramToVersion[n] = v
+			names = append(names, n)
+		}
+	}
+
+	available := map[string]bool{}
+
+	// GetParameters accepts up to 10 names at once
+	for i := 0; i < len(names); i += 10 {
+		end := i + 10
+		if end > len(names) {
+			end = len(names)
+		}
+
+		out, err := svc.GetParameters(&ssm.GetParametersInput{
+			Names: aws.StringSlice(names[i:end]),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range out.Parameters {
+			available[paramToVersion[aws.StringValue(p.Name)]] = true
+		}
+	}
+
+	var vs []string
+
+	for _, v := range versions {
+		if available[v] {
+			vs = append(vs, v)
+		}
+	}
+
+	return vs, nil
+}
+
+// sortKubernetesVersions sorts versions like 1.9 and 1.10 numerically
+func sortKubernetesVersions(versions []string) []string {
+	sorted := append([]string{}, versions...)
+
+	parse := func(v string) []int {
+		var nums []int
+
+		for _, s := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(s)
+			nums = append(nums, n)
+		}
+
+		return nums
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := parse(sorted[i]), parse(sorted[j])
+
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+
+		return len(a) < len(b)
+	})
+
+	return sorted
+}
@@ -0,0 +1,18 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEksctlVersionOutput(t *testing.T) {
+	versions, err := parseEksctlVersionOutput(`{"Version":"0.150.0","PreReleaseID":"","Metadata":{"BuildDate":"2023-07-21T10:32:14Z","GitCommit":"abcdef"},"EKSServerSupportedVersions":["1.9","1.27","1.10","1.25"]}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.9", "1.10", "1.25", "1.27"}, versions)
+
+	_, err = parseEksctlVersionOutput(`{"Version":"0.30.0"}`)
+
+	assert.Error(t, err)
+}
@@ -2,10 +2,7 @@ package cluster
 
 import (
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
-	"io/ioutil"
 	"log"
-	"os"
-	"os/exec"
 	"strings"
 )
 
@@ -14,16 +11,12 @@ func doDeleteKubernetesResourcesBeforeDestroy(cluster *Cluster, id string) error
 		return nil
 	}
 
-	kubeconfig, err := ioutil.TempFile("", "terraform-provider-eksctl-kubeconfig-")
+	kubeconfigPath, err := cluster.Provider.TempFile("terraform-provider-eksctl-kubeconfig-")
 	if err != nil {
 		return err
 	}
 
-	kubeconfigPath := kubeconfig.Name()
-
-	if err := kubeconfig.Close(); err != nil {
-		return err
-	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
 
 	clusterName := cluster.Name + "-" + id
 
@@ -37,19 +30,14 @@ func doDeleteKubernetesResourcesBeforeDestroy(cluster *Cluster, id string) error
 	}
 
 	for _, d := range cluster.DeleteKubernetesResourcesBeforeDestroy {
-		kubectlCmd := exec.Command(cluster.KubectlBin, "delete", "-n", d.Namespace, d.Kind, d.Name)
-
-		for _, env := range os.Environ() {
-			if !strings.HasPrefix(env, "KUBECONFIG=") {
-				kubectlCmd.Env = append(kubectlCmd.Env, env)
-			}
+		kubectlCmd, err := newKubectlCommand(cluster, kubeconfigPath, "delete", "-n", d.Namespace, d.Kind, d.Name)
+		if err != nil {
+			return err
 		}
 
-		kubectlCmd.Env = append(kubectlCmd.Env, "KUBECONFIG="+kubeconfigPath)
-
 		if _, err := resource.Run(kubectlCmd); err != nil {
 			if strings.Contains(err.Error(), "not found") {
-				log.Printf("Ignoring `kubectl delete` error %w. %s/%s/%s seems already deleted. Perhaps it is a stale cluster that was in the middle of deletion process?", err, d.Namespace, d.Kind, d.Name)
+				log.Printf("Ignoring `kubectl delete` error %v. %s/%s/%s seems already deleted. Perhaps it is a stale cluster that was in the middle of deletion process?", err, d.Namespace, d.Kind, d.Name)
 				continue
 			}
 			return err
@@ -0,0 +1,17 @@
+package cluster
+
+import (
+	"fmt"
+)
+
+const KeyDeletionProtection = "deletion_protection"
+
+// checkDeletionProtection fails the destroy when `deletion_protection` is enabled.
+// Delete sees the value in the state, so the flag has to be set to false in a prior apply before the cluster can be destroyed.
+func checkDeletionProtection(d ReadWrite) error {
+	if v, ok := d.Get(KeyDeletionProtection).(bool); ok && v {
+		return fmt.Errorf("refusing to destroy cluster %s as %s is enabled: set %s = false and run `terraform apply` first", d.Get(KeyName), KeyDeletionProtection, KeyDeletionProtection)
+	}
+
+	return nil
+}
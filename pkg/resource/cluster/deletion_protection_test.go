@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDeletionProtection(t *testing.T) {
+	s := ResourceCluster().Schema
+
+	blocked := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		KeyName:               "prod",
+		KeyDeletionProtection: true,
+	})
+
+	assert.EqualError(t, checkDeletionProtection(blocked), "refusing to destroy cluster prod as deletion_protection is enabled: set deletion_protection = false and run `terraform apply` first")
+
+	allowed := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		KeyName:               "prod",
+		KeyDeletionProtection: false,
+	})
+
+	assert.NoError(t, checkDeletionProtection(allowed))
+
+	// The protection is opt-in
+	unset := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		KeyName: "prod",
+	})
+
+	assert.NoError(t, checkDeletionProtection(unset))
+}
+
+func TestResourceDelete_deletionProtection(t *testing.T) {
+	for _, r := range []*schema.Resource{ResourceCluster(), ResourceClusterDeployment()} {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			KeyName:               "prod",
+			KeyDeletionProtection: true,
+		})
+		d.SetId("prod-id")
+
+		// The destroy fails before running eksctl, and the cluster is kept in the state
+		assert.Error(t, r.Delete(d, nil))
+		assert.Equal(t, "prod-id", d.Id())
+	}
+}
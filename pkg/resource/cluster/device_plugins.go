@@ -0,0 +1,29 @@
+package cluster
+
+import (
+	"fmt"
+)
+
+const (
+	KeyInstallNvidiaPlugin = "install_nvidia_plugin"
+	KeyInstallNeuronPlugin = "install_neuron_plugin"
+)
+
+// devicePluginArgs returns the flags for `eksctl create cluster` and `eksctl create nodegroup`
+// to control the installation of the NVIDIA and Neuron device plugins for GPU and Inferentia nodegroups.
+//
+// eksctl installs the plugins by default, so the flags are added only when disabled,
+// which keeps the commands working with eksctl versions that don't have the flags.
+func (c Cluster) devicePluginArgs() []string {
+	var args []string
+
+	if !c.InstallNvidiaPlugin {
+		args = append(args, fmt.Sprintf("--install-nvidia-plugin=%t", c.InstallNvidiaPlugin))
+	}
+
+	if !c.InstallNeuronPlugin {
+		args = append(args, fmt.Sprintf("--install-neuron-plugin=%t", c.InstallNeuronPlugin))
+	}
+
+	return args
+}
@@ -0,0 +1,16 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevicePluginArgs(t *testing.T) {
+	// No flags by default, so that eksctl versions without them keep working
+	assert.Empty(t, Cluster{InstallNvidiaPlugin: true, InstallNeuronPlugin: true}.devicePluginArgs())
+
+	assert.Equal(t, []string{"--install-nvidia-plugin=false"}, Cluster{InstallNeuronPlugin: true}.devicePluginArgs())
+	assert.Equal(t, []string{"--install-neuron-plugin=false"}, Cluster{InstallNvidiaPlugin: true}.devicePluginArgs())
+	assert.Equal(t, []string{"--install-nvidia-plugin=false", "--install-neuron-plugin=false"}, Cluster{}.devicePluginArgs())
+}
@@ -0,0 +1,248 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	HookFailurePolicyFail = "fail"
+	HookFailurePolicyWarn = "warn"
+)
+
+// hookRetryInterval is the delay before the first retry of a hook. It doubles on every retry up to hookMaxRetryInterval
+var (
+	hookRetryInterval    = 5 * time.Second
+	hookMaxRetryInterval = time.Minute
+)
+
+// Hook is an arbitrary command that is run by the provider at a specific point of the cluster lifecycle.
+// The command is run with KUBECONFIG pointing to a kubeconfig file for the cluster.
+type Hook struct {
+	Command     string
+	Args        []string
+	WorkingDir  string
+	Environment map[string]string
+
+	// Retries is the number of times the command is retried after the first failed attempt
+	Retries int
+	// Timeout is the timeout per attempt. Zero means no timeout
+	Timeout time.Duration
+	// FailurePolicy is either "fail" or "warn". "warn" only logs the failure and lets the apply continue
+	FailurePolicy string
+}
+
+func hooksSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:       schema.TypeList,
+		Optional:   true,
+		ConfigMode: schema.SchemaConfigModeBlock,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"command": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"args": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"working_dir": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"environment": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"retries": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"timeout_sec": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"failure_policy": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      HookFailurePolicyFail,
+					ValidateFunc: validation.StringInSlice([]string{HookFailurePolicyFail, HookFailurePolicyWarn}, false),
+				},
+			},
+		},
+	}
+}
+
+func readHooks(v interface{}) []Hook {
+	var hooks []Hook
+
+	for _, r := range v.([]interface{}) {
+		m := r.(map[string]interface{})
+
+		h := Hook{
+			Command:       m["command"].(string),
+			WorkingDir:    m["working_dir"].(string),
+			Environment:   map[string]string{},
+			Retries:       m["retries"].(int),
+			Timeout:       time.Duration(m["timeout_sec"].(int)) * time.Second,
+			FailurePolicy: m["failure_policy"].(string),
+		}
+
+		if args, ok := m["args"].([]interface{}); ok {
+			for _, a := range args {
+				h.Args = append(h.Args, a.(string))
+			}
+		}
+
+		if env, ok := m["environment"].(map[string]interface{}); ok {
+			for k, v := range env {
+				h.Environment[k] = v.(string)
+			}
+		}
+
+		hooks = append(hooks, h)
+	}
+
+	return hooks
+}
+
+func runHooks(kind string, cluster *Cluster, clusterName ClusterName, hooks []Hook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	kubeconfigPath, err := writeTempKubeconfig(cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig for %s hooks: %w", kind, err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	for i, h := range hooks {
+		if err := runHook(h, cluster, clusterName, kubeconfigPath); err != nil {
+			if h.FailurePolicy == HookFailurePolicyWarn {
+				log.Printf("Ignoring failed %s hook %d due to failure_policy=%s: %v", kind, i, h.FailurePolicy, err)
+
+				continue
+			}
+
+			return fmt.Errorf("running %s hook %d: %w", kind, i, err)
+		}
+	}
+
+	return nil
+}
+
+func runHook(h Hook, cluster *Cluster, clusterName ClusterName, kubeconfigPath string) error {
+	var err error
+
+	delay := hookRetryInterval
+
+	for attempt := 0; attempt <= h.Retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("Retrying hook %q in %v (attempt %d of %d): %v", h.Command, delay, attempt+1, h.Retries+1, err)
+
+			time.Sleep(delay)
+
+			if delay *= 2; delay > hookMaxRetryInterval {
+				delay = hookMaxRetryInterval
+			}
+		}
+
+		log.Printf("Running hook for cluster %s: %s %s", clusterName, h.Command, strings.Join(h.Args, " "))
+
+		err = runHookOnce(h, cluster, clusterName, kubeconfigPath)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func runHookOnce(h Hook, cluster *Cluster, clusterName ClusterName, kubeconfigPath string) error {
+	cmd, err := newHookCommand(h, cluster, clusterName, kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := resource.RunWithTimeout(cmd, h.Timeout); err != nil {
+		if errors.Is(err, resource.ErrTimedOut) {
+			return fmt.Errorf("timed out after %v: %w", h.Timeout, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func newHookCommand(h Hook, cluster *Cluster, clusterName ClusterName, kubeconfigPath string) (*exec.Cmd, error) {
+	environ, err := cluster.Provider.Environ(cluster.Region, cluster.Profile, cluster.AssumeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfigPath, err = tunneledKubeconfig(cluster, kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(h.Command, h.Args...)
+
+	cmd.Dir = h.WorkingDir
+
+	for _, env := range environ {
+		if !strings.HasPrefix(env, "KUBECONFIG=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+
+	cmd.Env = append(cmd.Env,
+		"KUBECONFIG="+kubeconfigPath,
+		"EKSCTL_CLUSTER_NAME="+string(clusterName),
+		"AWS_REGION="+cluster.Region,
+	)
+
+	for k, v := range h.Environment {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	return cluster.Provider.WithAuditLog(cmd, cluster.Name), nil
+}
+
+func writeTempKubeconfig(cluster *Cluster, clusterName ClusterName) (string, error) {
+	kubeconfigPath, err := cluster.Provider.TempFile("terraform-provider-eksctl-kubeconfig-")
+	if err != nil {
+		return "", err
+	}
+
+	writeKubeconfigCmd, err := newEksctlCommandWithAWSProfile(cluster, "utils", "write-kubeconfig", "--kubeconfig", kubeconfigPath, "--cluster", string(clusterName), "--region", cluster.Region)
+	if err != nil {
+		cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+		return "", fmt.Errorf("creating eksctl-utils-write-kubeconfig command: %w", err)
+	}
+
+	if _, err := resource.Run(writeKubeconfigCmd); err != nil {
+		cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+		return "", err
+	}
+
+	return kubeconfigPath, nil
+}
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHook_retries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	counter := filepath.Join(dir, "counter")
+
+	// Fails on the first two attempts and succeeds on the third
+	h := Hook{
+		Command: "bash",
+		Args:    []string{"-c", `echo x >> "$COUNTER"; [ $(wc -l < "$COUNTER") -ge 3 ]`},
+		Environment: map[string]string{
+			"COUNTER": counter,
+		},
+		Retries: 2,
+	}
+
+	defer func(d time.Duration) { hookRetryInterval = d }(hookRetryInterval)
+
+	hookRetryInterval = 50 * time.Millisecond
+
+	cluster := &Cluster{Region: "us-east-2"}
+
+	start := time.Now()
+
+	assert.NoError(t, runHook(h, cluster, "mycluster", "/dev/null"))
+
+	// The retries are delayed by 50ms and then 100ms
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "retried without backoff")
+
+	h.Retries = 0
+
+	assert.NoError(t, os.Remove(counter))
+	assert.Error(t, runHook(h, cluster, "mycluster", "/dev/null"))
+}
+
+func TestRunHook_env(t *testing.T) {
+	h := Hook{
+		Command: "bash",
+		Args:    []string{"-c", `[ "$KUBECONFIG" = /tmp/kubeconfig ] && [ "$EKSCTL_CLUSTER_NAME" = mycluster ] && [ "$FOO" = bar ]`},
+		Environment: map[string]string{
+			"FOO": "bar",
+		},
+	}
+
+	assert.NoError(t, runHook(h, &Cluster{}, "mycluster", "/tmp/kubeconfig"))
+}
+
+func TestRunHook_timeout(t *testing.T) {
+	h := Hook{
+		Command: "sleep",
+		Args:    []string{"10"},
+		Timeout: 100 * time.Millisecond,
+	}
+
+	start := time.Now()
+
+	err := runHook(h, &Cluster{}, "mycluster", "/dev/null")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out after 100ms")
+	}
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestRunHook_commandRunner(t *testing.T) {
+	var (
+		args    []string
+		timeout time.Duration
+	)
+
+	prev := resource.SetCommandRunner(resource.CommandRunnerFunc(func(cmd *exec.Cmd, t time.Duration) (*resource.CommandResult, error) {
+		args = cmd.Args
+		timeout = t
+
+		return resource.NewCommandResult(), nil
+	}))
+	defer resource.SetCommandRunner(prev)
+
+	h := Hook{
+		Command: "deregister-cluster",
+		Args:    []string{"--cluster", "mycluster"},
+		Timeout: time.Minute,
+	}
+
+	assert.NoError(t, runHook(h, &Cluster{}, "mycluster", "/dev/null"))
+	assert.Equal(t, []string{"deregister-cluster", "--cluster", "mycluster"}, args)
+	assert.Equal(t, time.Minute, timeout)
+}
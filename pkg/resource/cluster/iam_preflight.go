@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	KeyIAMPreflight      = "iam_preflight"
+	KeyIAMMissingActions = "iam_missing_actions"
+)
+
+const (
+	// IAMPreflightWarn reports the missing permissions via `iam_missing_actions` and the provider log
+	IAMPreflightWarn = "warn"
+	// IAMPreflightError fails the plan when any permission is missing
+	IAMPreflightError = "error"
+)
+
+// eksctlRequiredActions are the key IAM actions run by eksctl and the provider while creating, updating, and deleting
+// clusters. It isn't exhaustive, but covers the actions whose absence makes `eksctl create cluster` fail midway.
+var eksctlRequiredActions = []string{
+	"cloudformation:CreateStack",
+	"cloudformation:DeleteStack",
+	"cloudformation:DescribeStacks",
+	"cloudformation:DescribeStackEvents",
+	"cloudformation:ListStacks",
+	"cloudformation:UpdateStack",
+	"ec2:CreateLaunchTemplate",
+	"ec2:CreateSecurityGroup",
+	"ec2:CreateSubnet",
+	"ec2:CreateVpc",
+	"ec2:DescribeImages",
+	"ec2:DescribeSubnets",
+	"ec2:DescribeVpcs",
+	"ec2:RunInstances",
+	"eks:CreateCluster",
+	"eks:CreateNodegroup",
+	"eks:DeleteCluster",
+	"eks:DeleteNodegroup",
+	"eks:DescribeCluster",
+	"eks:ListNodegroups",
+	"eks:UpdateClusterVersion",
+	"iam:AttachRolePolicy",
+	"iam:CreateInstanceProfile",
+	"iam:CreateOpenIDConnectProvider",
+	"iam:CreateRole",
+	"iam:DeleteRole",
+	"iam:GetRole",
+	"iam:PassRole",
+	"elasticloadbalancing:DescribeListeners",
+	"elasticloadbalancing:DescribeRules",
+	"elasticloadbalancing:DescribeTargetGroups",
+	"elasticloadbalancing:DescribeTargetHealth",
+	"elasticloadbalancing:ModifyListener",
+	"elasticloadbalancing:ModifyRule",
+}
+
+func iamPreflightSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validation.StringInSlice([]string{"", IAMPreflightWarn, IAMPreflightError}, false),
+	}
+}
+
+func iamMissingActionsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// planIAMPreflight simulates eksctlRequiredActions for the caller with iam:SimulatePrincipalPolicy on plan,
+// so that permission failures are caught before a long-running partial create.
+//
+// The simulation runs only when the cluster is created or its spec is changed, so that changes in IAM policies
+// never result in diffs on their own.
+func planIAMPreflight(d *schema.ResourceDiff, p *resource.ProviderConfig) error {
+	mode, _ := d.Get(KeyIAMPreflight).(string)
+	if mode == "" {
+		return nil
+	}
+
+	if d.Id() != "" && !specChanged(d) {
+		return nil
+	}
+
+	missing, err := simulateRequiredActions(d, p)
+	if err != nil {
+		// The preflight is best-effort, as the caller may not be allowed to simulate its own policies
+		log.Printf("[WARN] skipping IAM preflight: %v", err)
+
+		return nil
+	}
+
+	if len(missing) > 0 {
+		if mode == IAMPreflightError {
+			return fmt.Errorf("IAM preflight: missing permissions for %s", strings.Join(missing, ", "))
+		}
+
+		log.Printf("[WARN] IAM preflight: missing permissions for %s", strings.Join(missing, ", "))
+	}
+
+	return d.SetNew(KeyIAMMissingActions, missing)
+}
+
+func simulateRequiredActions(d Read, p *resource.ProviderConfig) ([]string, error) {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	v, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(profile, resource.GetAssumeRoles(d)), region, "", "iam-preflight"), func() (interface{}, error) {
+		sess := resource.AWSSessionFromResourceData(d, p)
+
+		r, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, fmt.Errorf("getting caller identity: %w", err)
+		}
+
+		principal, ok := iamPrincipalARN(aws.StringValue(r.Arn))
+		if !ok {
+			return nil, fmt.Errorf("policies of %s can't be simulated", aws.StringValue(r.Arn))
+		}
+
+		var results []*iam.EvaluationResult
+
+		err = iam.New(sess).SimulatePrincipalPolicyPages(&iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(principal),
+			ActionNames:     aws.StringSlice(eksctlRequiredActions),
+		}, func(out *iam.SimulatePolicyResponse, lastPage bool) bool {
+			results = append(results, out.EvaluationResults...)
+
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("simulating policies of %s: %w", principal, err)
+		}
+
+		return deniedActions(results), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]string), nil
+}
+
+func deniedActions(results []*iam.EvaluationResult) []string {
+	missing := []string{}
+
+	for _, r := range results {
+		if aws.StringValue(r.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+			missing = append(missing, aws.StringValue(r.EvalActionName))
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+var assumedRoleARNPattern = regexp.MustCompile(`^arn:([^:]+):sts::(\d+):assumed-role/([^/]+)/.+$`)
+
+// iamPrincipalARN returns the ARN of the IAM user or role for the caller identity ARN, which is accepted by
+// iam:SimulatePrincipalPolicy. The root user and federated users can't be simulated.
+//
+// Roles with paths are unsupported, as the path is missing in the assumed role ARN.
+func iamPrincipalARN(callerARN string) (string, bool) {
+	if m := assumedRoleARNPattern.FindStringSubmatch(callerARN); m != nil {
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", m[1], m[2], m[3]), true
+	}
+
+	if strings.Contains(callerARN, ":iam::") && strings.Contains(callerARN, ":user/") {
+		return callerARN, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIAMPrincipalARN(t *testing.T) {
+	arn, ok := iamPrincipalARN("arn:aws:sts::123456789012:assumed-role/terraform/session")
+	assert.True(t, ok)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/terraform", arn)
+
+	arn, ok = iamPrincipalARN("arn:aws-cn:sts::123456789012:assumed-role/terraform/session")
+	assert.True(t, ok)
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:role/terraform", arn)
+
+	arn, ok = iamPrincipalARN("arn:aws:iam::123456789012:user/ci")
+	assert.True(t, ok)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/ci", arn)
+
+	_, ok = iamPrincipalARN("arn:aws:iam::123456789012:root")
+	assert.False(t, ok)
+}
+
+func TestDeniedActions(t *testing.T) {
+	assert.Equal(t, []string{"eks:CreateCluster", "iam:PassRole"}, deniedActions([]*iam.EvaluationResult{
+		{EvalActionName: aws.String("iam:PassRole"), EvalDecision: aws.String(iam.PolicyEvaluationDecisionTypeImplicitDeny)},
+		{EvalActionName: aws.String("ec2:CreateVpc"), EvalDecision: aws.String(iam.PolicyEvaluationDecisionTypeAllowed)},
+		{EvalActionName: aws.String("eks:CreateCluster"), EvalDecision: aws.String(iam.PolicyEvaluationDecisionTypeExplicitDeny)},
+	}))
+
+	assert.Equal(t, []string{}, deniedActions(nil))
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"log"
+)
+
+// addWellKnownPolicyServiceAccount adds the IRSA service account with the eksctl's well-known policy to `iam.serviceAccounts`
+// in the cluster.yaml, unless the service account is already declared by the user.
+func addWellKnownPolicyServiceAccount(c *EksctlClusterConfig, namespace, name, wellKnownPolicy string) {
+	c.IAM.WithOIDC = true
+
+	if c.IAM.Rest == nil {
+		c.IAM.Rest = map[string]interface{}{}
+	}
+
+	serviceAccounts, _ := c.IAM.Rest["serviceAccounts"].([]interface{})
+
+	if hasServiceAccount(serviceAccounts, namespace, name) {
+		log.Printf("Skipped adding the service account %s/%s as it is already in cluster.yaml", namespace, name)
+
+		return
+	}
+
+	c.IAM.Rest["serviceAccounts"] = append(serviceAccounts, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"wellKnownPolicies": map[string]interface{}{
+			wellKnownPolicy: true,
+		},
+	})
+}
+
+func hasServiceAccount(serviceAccounts []interface{}, namespace, name string) bool {
+	for _, sa := range serviceAccounts {
+		m, ok := sa.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		md, ok := m["metadata"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		ns, _ := md["namespace"].(string)
+		if ns == "" {
+			ns = "default"
+		}
+
+		if md["name"] == name && ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
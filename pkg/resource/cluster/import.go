@@ -4,10 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"strings"
 )
 
-func (m *Manager) importCluster(d *schema.ResourceData) (*schema.ResourceData, error) {
+func (m *Manager) importCluster(d *schema.ResourceData, p *resource.ProviderConfig) (*schema.ResourceData, error) {
 	clusterName := d.Id()
 
 	d.Set(KeyName, clusterName)
@@ -15,7 +16,7 @@ func (m *Manager) importCluster(d *schema.ResourceData) (*schema.ResourceData, e
 
 	d.SetId(newClusterID())
 
-	getCluster, err := newEksctlCommandFromResourceWithRegionAndProfile(d, "get", "cluster", "-o", "json", "--name", clusterName)
+	getCluster, err := newEksctlCommandFromResourceWithRegionAndProfile(d, p, "get", "cluster", "-o", "json", "--name", clusterName)
 	if err != nil {
 		return nil, fmt.Errorf("getting cluster %s:: %w", clusterName, err)
 	}
@@ -33,10 +34,10 @@ func (m *Manager) importCluster(d *schema.ResourceData) (*schema.ResourceData, e
 
 	var clusters []cluster
 
-	if getClusterOut, err := getCluster.CombinedOutput(); err != nil {
-		return nil, fmt.Errorf("failed running %s %s: %vw: COMBINED OUTPUT:\n%s", getCluster.Path, strings.Join(getCluster.Args, " "), err, string(getClusterOut))
-	} else if err := json.Unmarshal(getClusterOut, &clusters); err != nil {
-		return nil, fmt.Errorf("parsing json: %w: INPUT:\n%s", err, string(getClusterOut))
+	if getClusterOut, err := resource.Run(getCluster); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal([]byte(getClusterOut.Stdout), &clusters); err != nil {
+		return nil, fmt.Errorf("parsing json: %w: INPUT:\n%s", err, getClusterOut.Stdout)
 	}
 
 	var found *cluster
@@ -57,16 +58,14 @@ func (m *Manager) importCluster(d *schema.ResourceData) (*schema.ResourceData, e
 		return nil, fmt.Errorf("found no cluster named %s in %v", clusterName, candidates)
 	}
 
-	expectedPrefix := "arn:aws:eks:"
-	if !strings.HasPrefix(found.Arn, expectedPrefix) {
-		return nil, fmt.Errorf("validating cluster arn: Arn %q must start with %q. This provider does not support this Arn yet", found.Arn, expectedPrefix)
-	}
-
-	colonSeparatedRegionAccountKindName := strings.TrimPrefix(found.Arn, expectedPrefix)
+	// Arn is like arn:PARTITION:eks:REGION:ACCOUNT:cluster/NAME, where PARTITION is aws, aws-cn, or aws-us-gov
+	partitionServiceRegionAccountKindName := strings.Split(found.Arn, ":")
 
-	regionAccountKindName := strings.Split(colonSeparatedRegionAccountKindName, ":")
+	if len(partitionServiceRegionAccountKindName) < 6 || partitionServiceRegionAccountKindName[0] != "arn" || partitionServiceRegionAccountKindName[2] != "eks" {
+		return nil, fmt.Errorf("validating cluster arn: Arn %q must be like arn:PARTITION:eks:REGION:ACCOUNT:cluster/NAME. This provider does not support this Arn yet", found.Arn)
+	}
 
-	region := regionAccountKindName[0]
+	region := partitionServiceRegionAccountKindName[3]
 
 	d.Set(KeyVPCID, found.ResourceVpcConfig.VpcId)
 	d.Set(KeyRegion, region)
@@ -0,0 +1,233 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// instanceTypesConfig is the part of cluster.yaml that determines the instance types and the AZs of nodegroups
+type instanceTypesConfig struct {
+	AvailabilityZones []string                       `yaml:"availabilityZones"`
+	NodeGroups        []instanceTypesNodeGroupConfig `yaml:"nodeGroups"`
+	ManagedNodeGroups []instanceTypesNodeGroupConfig `yaml:"managedNodeGroups"`
+}
+
+type instanceTypesNodeGroupConfig struct {
+	Name                  string   `yaml:"name"`
+	InstanceType          string   `yaml:"instanceType"`
+	InstanceTypes         []string `yaml:"instanceTypes"`
+	AvailabilityZones     []string `yaml:"availabilityZones"`
+	InstancesDistribution struct {
+		InstanceTypes []string `yaml:"instanceTypes"`
+	} `yaml:"instancesDistribution"`
+}
+
+// nodeGroupInstanceTypes is the instance types that need to be offered in the AZs for the nodegroup to be created.
+// Empty AvailabilityZones means that eksctl chooses the AZs so that the instance types need to be offered somewhere in the region.
+type nodeGroupInstanceTypes struct {
+	Name              string
+	InstanceTypes     []string
+	AvailabilityZones []string
+}
+
+func parseNodeGroupInstanceTypes(spec *yaml.Node) ([]nodeGroupInstanceTypes, error) {
+	var c instanceTypesConfig
+
+	if err := spec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	var nodeGroups []nodeGroupInstanceTypes
+
+	for _, ng := range append(c.NodeGroups, c.ManagedNodeGroups...) {
+		var types []string
+
+		// eksctl uses "mixed" as the instance type of nodegroups with instancesDistribution
+		if ng.InstanceType != "" && ng.InstanceType != "mixed" {
+			types = append(types, ng.InstanceType)
+		}
+
+		types = append(types, ng.InstanceTypes...)
+		types = append(types, ng.InstancesDistribution.InstanceTypes...)
+
+		if len(types) == 0 {
+			continue
+		}
+
+		azs := ng.AvailabilityZones
+		if len(azs) == 0 {
+			azs = c.AvailabilityZones
+		}
+
+		nodeGroups = append(nodeGroups, nodeGroupInstanceTypes{
+			Name:              ng.Name,
+			InstanceTypes:     types,
+			AvailabilityZones: azs,
+		})
+	}
+
+	return nodeGroups, nil
+}
+
+// findUnavailableInstanceTypes returns the human-readable descriptions of the instance types not offered in the nodegroups' AZs.
+// offerings is the instance types offered per location, where the region-wide offerings are keyed by the empty string.
+func findUnavailableInstanceTypes(nodeGroups []nodeGroupInstanceTypes, offerings map[string]map[string]bool) []string {
+	var unavailable []string
+
+	for _, ng := range nodeGroups {
+		for _, t := range ng.InstanceTypes {
+			if len(ng.AvailabilityZones) == 0 {
+				if !offerings[""][t] {
+					unavailable = append(unavailable, fmt.Sprintf("nodegroup %q: %s is not offered in the region", ng.Name, t))
+				}
+
+				continue
+			}
+
+			var azs []string
+
+			for _, az := range ng.AvailabilityZones {
+				if !offerings[az][t] {
+					azs = append(azs, az)
+				}
+			}
+
+			if len(azs) > 0 {
+				unavailable = append(unavailable, fmt.Sprintf("nodegroup %q: %s is not offered in %s", ng.Name, t, strings.Join(azs, ", ")))
+			}
+		}
+	}
+
+	return unavailable
+}
+
+// validateInstanceTypeOfferings validates on plan that the instance types of the nodegroups are offered in their AZs,
+// so that an apply does not fail after minutes of waiting for the nodegroup stack to roll back.
+// spec is the one returned by parsePlannedSpec.
+func validateInstanceTypeOfferings(d Read, p *resource.ProviderConfig, spec *yaml.Node) error {
+	if spec == nil {
+		return nil
+	}
+
+	nodeGroups, err := parseNodeGroupInstanceTypes(spec)
+	if err != nil {
+		return err
+	}
+
+	if len(nodeGroups) == 0 {
+		return nil
+	}
+
+	var (
+		types    []string
+		azs      []string
+		regional bool
+	)
+
+	for _, ng := range nodeGroups {
+		types = append(types, ng.InstanceTypes...)
+		azs = append(azs, ng.AvailabilityZones...)
+
+		if len(ng.AvailabilityZones) == 0 {
+			regional = true
+		}
+	}
+
+	types, azs = uniqueSortedStrings(types), uniqueSortedStrings(azs)
+
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	key := remoteReadCacheKey(remoteReadCacheAccount(profile, resource.GetAssumeRoles(d)), region, "", fmt.Sprintf("instance-type-offerings/%s/%s/%t", strings.Join(types, ","), strings.Join(azs, ","), regional))
+
+	v, err := remoteReadCache.getOrLoad(key, func() (interface{}, error) {
+		svc := ec2.New(resource.AWSSessionFromResourceData(d, p))
+
+		offerings := map[string]map[string]bool{}
+
+		describe := func(locationType string, locations []string) error {
+			filters := []*ec2.Filter{
+				{
+					Name:   aws.String("instance-type"),
+					Values: aws.StringSlice(types),
+				},
+			}
+
+			if len(locations) > 0 {
+				filters = append(filters, &ec2.Filter{
+					Name:   aws.String("location"),
+					Values: aws.StringSlice(locations),
+				})
+			}
+
+			return svc.DescribeInstanceTypeOfferingsPages(&ec2.DescribeInstanceTypeOfferingsInput{
+				LocationType: aws.String(locationType),
+				Filters:      filters,
+			}, func(o *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) bool {
+				for _, offering := range o.InstanceTypeOfferings {
+					location := aws.StringValue(offering.Location)
+					if locationType == ec2.LocationTypeRegion {
+						location = ""
+					}
+
+					if offerings[location] == nil {
+						offerings[location] = map[string]bool{}
+					}
+
+					offerings[location][aws.StringValue(offering.InstanceType)] = true
+				}
+
+				return true
+			})
+		}
+
+		if len(azs) > 0 {
+			if err := describe(ec2.LocationTypeAvailabilityZone, azs); err != nil {
+				return nil, fmt.Errorf("describing instance type offerings in %s: %w", strings.Join(azs, ", "), err)
+			}
+		}
+
+		if regional {
+			if err := describe(ec2.LocationTypeRegion, nil); err != nil {
+				return nil, fmt.Errorf("describing instance type offerings in %s: %w", region, err)
+			}
+		}
+
+		return offerings, nil
+	})
+	if err != nil {
+		// Don't block plans of users who lack the permission to describe the offerings
+		log.Printf("Skipped validating instance type offerings: %v", err)
+
+		return nil
+	}
+
+	if unavailable := findUnavailableInstanceTypes(nodeGroups, v.(map[string]map[string]bool)); len(unavailable) > 0 {
+		return fmt.Errorf("validating instance types: %s", strings.Join(unavailable, "; "))
+	}
+
+	return nil
+}
+
+func uniqueSortedStrings(ss []string) []string {
+	m := map[string]bool{}
+
+	var unique []string
+
+	for _, s := range ss {
+		if !m[s] {
+			m[s] = true
+			unique = append(unique, s)
+		}
+	}
+
+	sort.Strings(unique)
+
+	return unique
+}
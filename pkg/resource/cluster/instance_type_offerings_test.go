@@ -0,0 +1,43 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindUnavailableInstanceTypes(t *testing.T) {
+	spec, err := parsePlannedSpec(mapRead{KeySpec: `
+availabilityZones:
+- us-east-1a
+- us-east-1b
+nodeGroups:
+- name: ng1
+  instanceType: m6id.large
+- name: ng2
+  instanceType: mixed
+  availabilityZones:
+  - us-east-1a
+  instancesDistribution:
+    instanceTypes:
+    - m5.large
+    - m6id.large
+managedNodeGroups:
+- name: mng1
+  instanceTypes:
+  - c5.large
+`})
+	assert.NoError(t, err)
+
+	nodeGroups, err := parseNodeGroupInstanceTypes(spec)
+	assert.NoError(t, err)
+
+	unavailable := findUnavailableInstanceTypes(nodeGroups, map[string]map[string]bool{
+		"us-east-1a": {"m5.large": true, "m6id.large": true, "c5.large": true},
+		"us-east-1b": {"m5.large": true, "c5.large": true},
+	})
+
+	assert.Equal(t, []string{
+		`nodegroup "ng1": m6id.large is not offered in us-east-1b`,
+	}, unavailable)
+}
@@ -4,9 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
-	"io/ioutil"
-	"os"
-	"os/exec"
 	"strings"
 )
 
@@ -15,16 +12,12 @@ func doApplyKubernetesManifests(cluster *Cluster, id string) error {
 		return nil
 	}
 
-	kubeconfig, err := ioutil.TempFile("", "terraform-provider-eksctl-kubeconfig-")
+	kubeconfigPath, err := cluster.Provider.TempFile("terraform-provider-eksctl-kubeconfig-")
 	if err != nil {
 		return err
 	}
 
-	kubeconfigPath := kubeconfig.Name()
-
-	if err := kubeconfig.Close(); err != nil {
-		return err
-	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
 
 	clusterName := cluster.Name + "-" + id
 
@@ -39,16 +32,11 @@ func doApplyKubernetesManifests(cluster *Cluster, id string) error {
 
 	all := strings.Join(cluster.Manifests, "\n---\n")
 
-	kubectlCmd := exec.Command(cluster.KubectlBin, "apply", "-f", "-")
-
-	for _, env := range os.Environ() {
-		if !strings.HasPrefix(env, "KUBECONFIG=") {
-			kubectlCmd.Env = append(kubectlCmd.Env, env)
-		}
+	kubectlCmd, err := newKubectlCommand(cluster, kubeconfigPath, kubectlApplyArgs(cluster)...)
+	if err != nil {
+		return err
 	}
 
-	kubectlCmd.Env = append(kubectlCmd.Env, "KUBECONFIG="+kubeconfigPath)
-
 	kubectlCmd.Stdin = bytes.NewBufferString(all)
 
 	if _, err := resource.Run(kubectlCmd); err != nil {
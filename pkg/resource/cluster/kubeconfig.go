@@ -0,0 +1,247 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KeyKubeconfigMode = "kubeconfig_mode"
+	KeyKubeconfig     = "kubeconfig"
+	KeyExecAuth       = "exec_auth"
+)
+
+const (
+	// KubeconfigModeFile writes the kubeconfig to `kubeconfig_path`
+	KubeconfigModeFile = "file"
+	// KubeconfigModeStateOnly never writes the kubeconfig to disk and exposes the content via `kubeconfig` and `exec_auth` instead
+	KubeconfigModeStateOnly = "state_only"
+)
+
+const execAuthAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// kubeconfigExec is the exec credential plugin config that obtains the token with `aws eks get-token`
+type kubeconfigExec struct {
+	APIVersion string
+	Command    string
+	Args       []string
+	Env        map[string]string
+}
+
+func newKubeconfigExec(clusterName, region, profile string) kubeconfigExec {
+	e := kubeconfigExec{
+		APIVersion: execAuthAPIVersion,
+		Command:    "aws",
+		Args:       []string{"eks", "get-token", "--cluster-name", clusterName, "--region", region},
+		Env:        map[string]string{},
+	}
+
+	if profile != "" {
+		e.Env["AWS_PROFILE"] = profile
+	}
+
+	return e
+}
+
+func (e kubeconfigExec) flatten() []interface{} {
+	var args []interface{}
+
+	for _, a := range e.Args {
+		args = append(args, a)
+	}
+
+	env := map[string]interface{}{}
+
+	for k, v := range e.Env {
+		env[k] = v
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"api_version": e.APIVersion,
+			"command":     e.Command,
+			"args":        args,
+			"env":         env,
+		},
+	}
+}
+
+func kubeconfigModeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      KubeconfigModeFile,
+		ValidateFunc: validation.StringInSlice([]string{KubeconfigModeFile, KubeconfigModeStateOnly}, false),
+	}
+}
+
+func kubeconfigSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:      schema.TypeString,
+		Computed:  true,
+		Sensitive: true,
+	}
+}
+
+func execAuthSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"api_version": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"command": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"args": {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"env": {
+					Type:     schema.TypeMap,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func kubeconfigStateOnly(d Read) bool {
+	v, _ := d.Get(KeyKubeconfigMode).(string)
+
+	return v == KubeconfigModeStateOnly
+}
+
+// renderKubeconfig generates the same kubeconfig as `eksctl utils write-kubeconfig` does, without writing it to disk
+func renderKubeconfig(clusterName, region, endpoint, caData string, exec kubeconfigExec) (string, error) {
+	name := fmt.Sprintf("%s.%s.eksctl.io", clusterName, region)
+	user := "terraform-provider-eksctl@" + name
+
+	type namedValue struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	}
+
+	var env []namedValue
+
+	for _, k := range sortedKeys(exec.Env) {
+		env = append(env, namedValue{Name: k, Value: exec.Env[k]})
+	}
+
+	config := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Config",
+		"clusters": []interface{}{
+			map[string]interface{}{
+				"name": name,
+				"cluster": map[string]interface{}{
+					"server":                     endpoint,
+					"certificate-authority-data": caData,
+				},
+			},
+		},
+		"contexts": []interface{}{
+			map[string]interface{}{
+				"name": user,
+				"context": map[string]interface{}{
+					"cluster": name,
+					"user":    user,
+				},
+			},
+		},
+		"current-context": user,
+		"preferences":     map[string]interface{}{},
+		"users": []interface{}{
+			map[string]interface{}{
+				"name": user,
+				"user": map[string]interface{}{
+					"exec": map[string]interface{}{
+						"apiVersion": exec.APIVersion,
+						"command":    exec.Command,
+						"args":       exec.Args,
+						"env":        env,
+					},
+				},
+			},
+		},
+	}
+
+	bs, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("generating kubeconfig: %w", err)
+	}
+
+	return string(bs), nil
+}
+
+// writeKubeconfigWithSDK writes the kubeconfig generated from the cluster endpoint and CA read via the AWS SDK,
+// in place of `eksctl utils write-kubeconfig`, for machines without eksctl.
+func writeKubeconfigWithSDK(d Read, p *resource.ProviderConfig, clusterName, region, path string) error {
+	_, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	cluster := &Cluster{
+		Name:     clusterName,
+		Region:   region,
+		Profile:  profile,
+		Provider: p,
+	}
+
+	state, err := describeClusterWithSDK(cluster)
+	if err != nil {
+		return fmt.Errorf("reading cluster endpoint: %w", err)
+	}
+
+	kubeconfig, err := renderKubeconfig(clusterName, region, state.Endpoint, state.CertificateAuthority.Data, newKubeconfigExec(clusterName, region, profile))
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		return fmt.Errorf("writing kubeconfig: %w", err)
+	}
+
+	log.Printf("Wrote kubeconfig for %s to %s with AWS SDK", clusterName, path)
+
+	return nil
+}
+
+// doLoadKubeconfigToState sets `kubeconfig` and `exec_auth` generated from the cluster endpoint and CA, in place of writing the kubeconfig file.
+func doLoadKubeconfigToState(d ReadWrite, p *resource.ProviderConfig, clusterName, region string) error {
+	_, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	cluster := &Cluster{
+		Name:     clusterName,
+		Region:   region,
+		Profile:  profile,
+		Provider: p,
+	}
+
+	state, err := runGetCluster(d, cluster)
+	if err != nil {
+		return fmt.Errorf("reading cluster endpoint: %w", err)
+	}
+
+	exec := newKubeconfigExec(clusterName, region, profile)
+
+	kubeconfig, err := renderKubeconfig(clusterName, region, state.Endpoint, state.CertificateAuthority.Data, exec)
+	if err != nil {
+		return err
+	}
+
+	d.Set(KeyKubeconfig, kubeconfig)
+	d.Set(KeyExecAuth, exec.flatten())
+
+	return nil
+}
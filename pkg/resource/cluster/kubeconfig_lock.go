@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// kubeconfigMutexes serializes writes to the same kubeconfig within the provider process, keyed by the absolute path.
+// The advisory file lock taken in addition serializes writes across processes, like parallel applies in CI.
+var kubeconfigMutexes sync.Map
+
+// withKubeconfigLock runs f while holding the locks for the kubeconfig at path.
+//
+// The lock file is a hidden file next to the kubeconfig, rather than `<path>.lock`,
+// which is created and removed by kubectl and client-go for their own locking.
+func withKubeconfigLock(path string, f func() error) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("locking kubeconfig %s: %w", path, err)
+	}
+
+	mu, _ := kubeconfigMutexes.LoadOrStore(abs, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	dir := filepath.Dir(abs)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating directory for kubeconfig: %w", err)
+	}
+
+	lockPath := filepath.Join(dir, "."+filepath.Base(abs)+".tf-eksctl-lock")
+
+	lf, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("opening kubeconfig lock file: %w", err)
+	}
+	defer lf.Close()
+
+	if err := lockFile(lf); err != nil {
+		return fmt.Errorf("locking kubeconfig %s: %w", path, err)
+	}
+	defer unlockFile(lf)
+
+	return f()
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory and renames it to path,
+// so that readers never see a truncated or partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithKubeconfigLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".kube", "config")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, withKubeconfigLock(path, func() error {
+				bs, err := ioutil.ReadFile(path)
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+
+				return writeFileAtomic(path, append(bs, "line\n"...), 0600)
+			}))
+		}()
+	}
+
+	wg.Wait()
+
+	bs, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, strings.Count(string(bs), "line\n"))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Only the kubeconfig and the lock file are left
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+}
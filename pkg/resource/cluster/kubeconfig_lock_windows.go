@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package cluster
+
+import (
+	"os"
+)
+
+// Writes are serialized only within the provider process on Windows, as there's no flock
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KeyKubeconfigMerge = "kubeconfig_merge"
+	KeyContextName     = "context_name"
+)
+
+func kubeconfigMerge(d Read) bool {
+	v, _ := d.Get(KeyKubeconfigMerge).(bool)
+
+	return v
+}
+
+// kubeconfigContextName returns the name of the context merged into the kubeconfig, which defaults to the cluster name
+func kubeconfigContextName(d Read, clusterName string) string {
+	if v, _ := d.Get(KeyContextName).(string); v != "" {
+		return v
+	}
+
+	return clusterName
+}
+
+// defaultMergedKubeconfigPath returns the path to the kubeconfig the cluster is merged into when `kubeconfig_path` is empty
+func defaultMergedKubeconfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory for ~/.kube/config: %w", err)
+	}
+
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// mergeKubeconfigFile merges the kubeconfig generated by `eksctl utils write-kubeconfig` into the kubeconfig at path
+// under the context name, so that the existing clusters and contexts are kept intact.
+func mergeKubeconfigFile(generatedPath, path, contextName string) error {
+	generated, err := ioutil.ReadFile(generatedPath)
+	if err != nil {
+		return fmt.Errorf("reading generated kubeconfig: %w", err)
+	}
+
+	return withKubeconfigLock(path, func() error {
+		existing, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading kubeconfig to merge into: %w", err)
+		}
+
+		merged, err := mergeKubeconfig(existing, generated, contextName)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFileAtomic(path, merged, 0600); err != nil {
+			return fmt.Errorf("writing merged kubeconfig: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// mergeKubeconfig merges the cluster, the user, and the context in the generated kubeconfig into the existing one.
+// The context is renamed to contextName, and the entries with the same names are replaced.
+// The current context is set to the merged one only when the existing kubeconfig has none.
+func mergeKubeconfig(existing, generated []byte, contextName string) ([]byte, error) {
+	var gen map[string]interface{}
+
+	if err := yaml.Unmarshal(generated, &gen); err != nil {
+		return nil, fmt.Errorf("parsing generated kubeconfig: %w", err)
+	}
+
+	contexts, _ := gen["contexts"].([]interface{})
+	if len(contexts) != 1 {
+		return nil, fmt.Errorf("expected exactly one context in generated kubeconfig, but got %d", len(contexts))
+	}
+
+	if c, ok := contexts[0].(map[string]interface{}); ok {
+		c["name"] = contextName
+	}
+
+	config := map[string]interface{}{}
+
+	if len(existing) > 0 {
+		if err := yaml.Unmarshal(existing, &config); err != nil {
+			return nil, fmt.Errorf("parsing kubeconfig to merge into: %w", err)
+		}
+	}
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+
+	if _, ok := config["apiVersion"]; !ok {
+		config["apiVersion"] = "v1"
+		config["kind"] = "Config"
+	}
+
+	for _, key := range []string{"clusters", "users", "contexts"} {
+		items, _ := config[key].([]interface{})
+		newItems, _ := gen[key].([]interface{})
+
+		for _, n := range newItems {
+			name := kubeconfigEntryName(n)
+
+			replaced := false
+
+			for i, item := range items {
+				if kubeconfigEntryName(item) == name {
+					items[i] = n
+					replaced = true
+				}
+			}
+
+			if !replaced {
+				items = append(items, n)
+			}
+		}
+
+		config[key] = items
+	}
+
+	if v, _ := config["current-context"].(string); v == "" {
+		config["current-context"] = contextName
+	}
+
+	return yaml.Marshal(config)
+}
+
+func kubeconfigEntryName(v interface{}) string {
+	m, _ := v.(map[string]interface{})
+	name, _ := m["name"].(string)
+
+	return name
+}
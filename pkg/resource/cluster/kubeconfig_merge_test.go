@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const generatedKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod.us-east-2.eksctl.io
+  cluster:
+    server: https://new.example.com
+users:
+- name: admin@prod.us-east-2.eksctl.io
+  user: {}
+contexts:
+- name: admin@prod.us-east-2.eksctl.io
+  context:
+    cluster: prod.us-east-2.eksctl.io
+    user: admin@prod.us-east-2.eksctl.io
+current-context: admin@prod.us-east-2.eksctl.io
+`
+
+func TestMergeKubeconfig(t *testing.T) {
+	existing := `apiVersion: v1
+kind: Config
+clusters:
+- name: minikube
+  cluster:
+    server: https://192.168.0.1:8443
+- name: prod.us-east-2.eksctl.io
+  cluster:
+    server: https://old.example.com
+users:
+- name: minikube
+  user: {}
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    user: minikube
+current-context: minikube
+`
+
+	merged, err := mergeKubeconfig([]byte(existing), []byte(generatedKubeconfig), "prod")
+	assert.NoError(t, err)
+
+	var config struct {
+		Clusters []struct {
+			Name    string
+			Cluster struct {
+				Server string
+			}
+		}
+		Users []struct {
+			Name string
+		}
+		Contexts []struct {
+			Name string
+		}
+		CurrentContext string `yaml:"current-context"`
+	}
+
+	assert.NoError(t, yaml.Unmarshal(merged, &config))
+
+	assert.Len(t, config.Clusters, 2)
+	assert.Equal(t, "minikube", config.Clusters[0].Name)
+	assert.Equal(t, "https://new.example.com", config.Clusters[1].Cluster.Server)
+	assert.Len(t, config.Users, 2)
+	assert.Len(t, config.Contexts, 2)
+	assert.Equal(t, "prod", config.Contexts[1].Name)
+	assert.Equal(t, "minikube", config.CurrentContext)
+}
+
+func TestMergeKubeconfig_Empty(t *testing.T) {
+	merged, err := mergeKubeconfig(nil, []byte(generatedKubeconfig), "prod")
+	assert.NoError(t, err)
+
+	var config map[string]interface{}
+
+	assert.NoError(t, yaml.Unmarshal(merged, &config))
+
+	assert.Equal(t, "prod", config["current-context"])
+	assert.Equal(t, "Config", config["kind"])
+}
@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderKubeconfig(t *testing.T) {
+	kubeconfig, err := renderKubeconfig("prod", "us-east-1", "https://ABCDEF.gr7.us-east-1.eks.amazonaws.com", "Y2E=", newKubeconfigExec("prod", "us-east-1", "ops"))
+	assert.NoError(t, err)
+
+	var config struct {
+		Clusters []struct {
+			Name    string            `yaml:"name"`
+			Cluster map[string]string `yaml:"cluster"`
+		} `yaml:"clusters"`
+		CurrentContext string `yaml:"current-context"`
+		Users          []struct {
+			Name string `yaml:"name"`
+			User struct {
+				Exec struct {
+					APIVersion string              `yaml:"apiVersion"`
+					Command    string              `yaml:"command"`
+					Args       []string            `yaml:"args"`
+					Env        []map[string]string `yaml:"env"`
+				} `yaml:"exec"`
+			} `yaml:"user"`
+		} `yaml:"users"`
+	}
+
+	assert.NoError(t, yaml.Unmarshal([]byte(kubeconfig), &config))
+
+	assert.Equal(t, "prod.us-east-1.eksctl.io", config.Clusters[0].Name)
+	assert.Equal(t, map[string]string{
+		"server":                     "https://ABCDEF.gr7.us-east-1.eks.amazonaws.com",
+		"certificate-authority-data": "Y2E=",
+	}, config.Clusters[0].Cluster)
+	assert.Equal(t, config.Users[0].Name, config.CurrentContext)
+
+	exec := config.Users[0].User.Exec
+
+	assert.Equal(t, "client.authentication.k8s.io/v1beta1", exec.APIVersion)
+	assert.Equal(t, "aws", exec.Command)
+	assert.Equal(t, []string{"eks", "get-token", "--cluster-name", "prod", "--region", "us-east-1"}, exec.Args)
+	assert.Equal(t, []map[string]string{{"name": "AWS_PROFILE", "value": "ops"}}, exec.Env)
+}
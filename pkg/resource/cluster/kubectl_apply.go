@@ -0,0 +1,18 @@
+package cluster
+
+const KeyServerSideApply = "server_side_apply"
+
+// KubectlFieldManager is the field manager of the objects applied by the provider.
+// It's fixed so that GitOps controllers can take over the fields from it without ownership conflicts.
+const KubectlFieldManager = "terraform-provider-eksctl"
+
+// kubectlApplyArgs returns the args of `kubectl apply` that reads the manifests from the stdin.
+// Conflicts are never forced, so that the fields taken over by other field managers are left as they are
+// and reported as errors instead of being silently overwritten.
+func kubectlApplyArgs(cluster *Cluster) []string {
+	if !cluster.ServerSideApply {
+		return []string{"apply", "-f", "-"}
+	}
+
+	return []string{"apply", "--server-side", "--field-manager", KubectlFieldManager, "-f", "-"}
+}
@@ -0,0 +1,12 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubectlApplyArgs(t *testing.T) {
+	assert.Equal(t, []string{"apply", "--server-side", "--field-manager", "terraform-provider-eksctl", "-f", "-"}, kubectlApplyArgs(&Cluster{ServerSideApply: true}))
+	assert.Equal(t, []string{"apply", "-f", "-"}, kubectlApplyArgs(&Cluster{}))
+}
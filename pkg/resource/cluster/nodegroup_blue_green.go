@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KeyNodeGroupBlueGreen = "nodegroup_blue_green"
+
+	// NodeGroupNameLabel is the node label eksctl sets to the name of the nodegroup, for both managed and unmanaged nodegroups
+	NodeGroupNameLabel = "alpha.eksctl.io/nodegroup-name"
+
+	nodeGroupHashLength = 8
+)
+
+// NodeGroupBlueGreen configures blue/green replacement of nodegroups within the cluster.
+// Each nodegroup is named after the hash of its spec, so that any change to a nodegroup results in a new nodegroup,
+// and the old one is drained and deleted only after the nodes and the workloads on the new one become ready.
+type NodeGroupBlueGreen struct {
+	// Timeout is how long to wait for the new nodes and the pods to become ready
+	Timeout time.Duration
+}
+
+func nodeGroupBlueGreenSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"timeout_sec": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  600,
+				},
+			},
+		},
+	}
+}
+
+func readNodeGroupBlueGreen(v interface{}) *NodeGroupBlueGreen {
+	bgs := v.([]interface{})
+	if len(bgs) == 0 {
+		return nil
+	}
+
+	bg := &NodeGroupBlueGreen{
+		Timeout: 600 * time.Second,
+	}
+
+	if bgs[0] == nil {
+		return bg
+	}
+
+	m := bgs[0].(map[string]interface{})
+
+	bg.Timeout = time.Duration(m["timeout_sec"].(int)) * time.Second
+
+	return bg
+}
+
+// suffixNodeGroupNamesWithHash renames each of nodeGroups and managedNodeGroups in the spec to `<name>-<hash of the nodegroup>`
+func suffixNodeGroupNamesWithHash(spec map[string]interface{}) error {
+	for _, key := range []string{"nodeGroups", "managedNodeGroups"} {
+		ngs, ok := spec[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for i, ng := range ngs {
+			m, ok := ng.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("unexpected type of %s[%d]: %T", key, i, ng)
+			}
+
+			name, _ := m["name"].(string)
+
+			hash, err := nodeGroupSpecHash(m)
+			if err != nil {
+				return fmt.Errorf("hashing %s[%d]: %w", key, i, err)
+			}
+
+			m["name"] = name + "-" + hash
+		}
+	}
+
+	return nil
+}
+
+// nodeGroupSpecHash returns the hash of the nodegroup spec, which is stable across runs as yaml.v3 sorts map keys
+func nodeGroupSpecHash(ng map[string]interface{}) (string, error) {
+	bs, err := yaml.Marshal(ng)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(bs)
+
+	return hex.EncodeToString(sum[:])[:nodeGroupHashLength], nil
+}
+
+func getNodeGroupNames(clusterConfig []byte) ([]string, error) {
+	var config struct {
+		NodeGroups []struct {
+			Name string `yaml:"name"`
+		} `yaml:"nodeGroups"`
+		ManagedNodeGroups []struct {
+			Name string `yaml:"name"`
+		} `yaml:"managedNodeGroups"`
+	}
+
+	if err := yaml.Unmarshal(clusterConfig, &config); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	var names []string
+
+	for _, ng := range config.NodeGroups {
+		names = append(names, ng.Name)
+	}
+
+	for _, ng := range config.ManagedNodeGroups {
+		names = append(names, ng.Name)
+	}
+
+	return names, nil
+}
+
+// doBlueGreenNodeGroups drains the nodegroups that are replaced by the newly created ones, and verifies that the nodes
+// and the workloads are ready on the new nodegroups, so that the old ones can be deleted safely afterwards.
+// The old nodegroups are uncordoned when the verification fails, so that they keep serving.
+func doBlueGreenNodeGroups(d Read, cluster *Cluster, clusterName ClusterName, clusterConfig []byte) error {
+	bg := cluster.NodeGroupBlueGreen
+	if bg == nil {
+		return nil
+	}
+
+	desired, err := getNodeGroupNames(clusterConfig)
+	if err != nil {
+		return err
+	}
+
+	invalidateRemoteReadCache(cluster)
+
+	existing, err := runGetNodeGroups(d, cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("listing nodegroups: %w", err)
+	}
+
+	olds := findReplacedNodeGroups(existing, desired)
+	if len(olds) == 0 {
+		return nil
+	}
+
+	log.Printf("Replacing nodegroups %v with %v", olds, desired)
+
+	for _, ng := range olds {
+		if err := runDrainNodeGroup(cluster, clusterName, ng, false); err != nil {
+			return err
+		}
+	}
+
+	if err := verifyNodeGroups(cluster, clusterName, desired, bg.Timeout); err != nil {
+		for _, ng := range olds {
+			if err := runDrainNodeGroup(cluster, clusterName, ng, true); err != nil {
+				log.Printf("Failed uncordoning nodegroup %s: %v", ng, err)
+			}
+		}
+
+		return fmt.Errorf("verifying new nodegroups: old nodegroups %v are kept and uncordoned: %w", olds, err)
+	}
+
+	return nil
+}
+
+func findReplacedNodeGroups(existing []NodeGroupSummary, desired []string) []string {
+	d := map[string]bool{}
+
+	for _, n := range desired {
+		d[n] = true
+	}
+
+	var olds []string
+
+	for _, ng := range existing {
+		if !d[ng.Name] {
+			olds = append(olds, ng.Name)
+		}
+	}
+
+	return olds
+}
+
+func runDrainNodeGroup(cluster *Cluster, clusterName ClusterName, nodeGroup string, undo bool) error {
+	args := []string{"drain", "nodegroup", "--cluster=" + string(clusterName), "-n", nodeGroup}
+
+	if undo {
+		args = append(args, "--undo")
+	} else {
+		args = append(args, cluster.NodeGroupDrain.drainNodeGroupArgs()...)
+	}
+
+	cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+	if err != nil {
+		return fmt.Errorf("creating eksctl-drain-nodegroup command: %w", err)
+	}
+
+	if _, err := resource.Run(cmd); err != nil {
+		return fmt.Errorf("draining nodegroup %s: %w", nodeGroup, err)
+	}
+
+	return nil
+}
+
+// verifyNodeGroups waits for the nodes of the nodegroups and the pods specified in pods_readiness_check to become ready
+func verifyNodeGroups(cluster *Cluster, clusterName ClusterName, nodeGroups []string, timeout time.Duration) error {
+	kubeconfigPath, err := writeTempKubeconfig(cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig: %w", err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	for _, ng := range nodeGroups {
+		cmd, err := newKubectlCommand(cluster, kubeconfigPath, "wait", "node", "--for", "condition=ready",
+			"-l", NodeGroupNameLabel+"="+ng, "--timeout", fmt.Sprintf("%ds", int(timeout.Seconds())))
+		if err != nil {
+			return err
+		}
+
+		if _, err := resource.Run(cmd); err != nil {
+			return fmt.Errorf("waiting for nodes in nodegroup %s to become ready: %w", ng, err)
+		}
+	}
+
+	return waitForPodsReadiness(cluster, kubeconfigPath)
+}
@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func parseSpec(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+
+	spec := map[string]interface{}{}
+
+	if err := yaml.Unmarshal([]byte(s), spec); err != nil {
+		t.Fatal(err)
+	}
+
+	return spec
+}
+
+func TestSuffixNodeGroupNamesWithHash(t *testing.T) {
+	const v1 = `
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+managedNodeGroups:
+- name: mng1
+  instanceType: m5.large
+`
+
+	const v2 = `
+nodeGroups:
+- name: ng1
+  instanceType: m5.xlarge
+managedNodeGroups:
+- name: mng1
+  instanceType: m5.large
+`
+
+	names := func(s string) []string {
+		spec := parseSpec(t, s)
+
+		assert.NoError(t, suffixNodeGroupNamesWithHash(spec))
+
+		bs, err := yaml.Marshal(spec)
+		assert.NoError(t, err)
+
+		names, err := getNodeGroupNames(bs)
+		assert.NoError(t, err)
+
+		return names
+	}
+
+	a, b, c := names(v1), names(v1), names(v2)
+
+	assert.Len(t, a, 2)
+	assert.True(t, strings.HasPrefix(a[0], "ng1-"))
+	assert.Len(t, a[0], len("ng1-")+nodeGroupHashLength)
+	assert.True(t, strings.HasPrefix(a[1], "mng1-"))
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a[0], c[0])
+	assert.Equal(t, a[1], c[1])
+}
+
+func TestFindReplacedNodeGroups(t *testing.T) {
+	existing := []NodeGroupSummary{
+		{Name: "ng1-aaaaaaaa"},
+		{Name: "ng1-bbbbbbbb"},
+		{Name: "mng1-cccccccc"},
+	}
+
+	assert.Equal(t, []string{"ng1-aaaaaaaa"}, findReplacedNodeGroups(existing, []string{"ng1-bbbbbbbb", "mng1-cccccccc"}))
+	assert.Empty(t, findReplacedNodeGroups(existing, []string{"ng1-aaaaaaaa", "ng1-bbbbbbbb", "mng1-cccccccc"}))
+}
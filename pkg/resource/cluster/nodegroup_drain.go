@@ -0,0 +1,114 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+const KeyNodeGroupDrain = "nodegroup_drain"
+
+// NodeGroupDrain configures how nodes are drained when nodegroups are deleted or drained,
+// so that stuck PodDisruptionBudgets don't block deletions forever.
+type NodeGroupDrain struct {
+	// Enabled drains nodes before deleting nodegroups. Disabling it deletes nodegroups without evicting pods.
+	Enabled bool
+	// DisableEviction deletes pods instead of evicting them, which bypasses PodDisruptionBudgets.
+	DisableEviction bool
+	// Parallel is the number of nodes drained in parallel.
+	Parallel int
+}
+
+func defaultNodeGroupDrain() NodeGroupDrain {
+	return NodeGroupDrain{
+		Enabled:  true,
+		Parallel: 1,
+	}
+}
+
+func nodeGroupDrainSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"enabled": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"disable_eviction": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"parallel": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntBetween(1, 25),
+				},
+			},
+		},
+	}
+}
+
+func readNodeGroupDrain(v interface{}) NodeGroupDrain {
+	drain := defaultNodeGroupDrain()
+
+	drains := v.([]interface{})
+	if len(drains) == 0 || drains[0] == nil {
+		return drain
+	}
+
+	m := drains[0].(map[string]interface{})
+
+	drain.Enabled = m["enabled"].(bool)
+	drain.DisableEviction = m["disable_eviction"].(bool)
+	drain.Parallel = m["parallel"].(int)
+
+	return drain
+}
+
+// deleteNodeGroupArgs returns the flags for `eksctl delete nodegroup`
+func (d NodeGroupDrain) deleteNodeGroupArgs() []string {
+	args := []string{fmt.Sprintf("--drain=%t", d.Enabled)}
+
+	if d.Enabled {
+		args = append(args, d.drainNodeGroupArgs()...)
+	}
+
+	return args
+}
+
+// drainNodeGroupArgs returns the flags for `eksctl drain nodegroup`
+func (d NodeGroupDrain) drainNodeGroupArgs() []string {
+	var args []string
+
+	if d.DisableEviction {
+		args = append(args, "--disable-eviction")
+	}
+
+	if d.Parallel > 1 {
+		args = append(args, fmt.Sprintf("--parallel=%d", d.Parallel))
+	}
+
+	return args
+}
+
+// deleteClusterArgs returns the flags for `eksctl delete cluster`
+func (d NodeGroupDrain) deleteClusterArgs() []string {
+	var args []string
+
+	if d.DisableEviction {
+		args = append(args, "--disable-nodegroup-eviction")
+	}
+
+	if d.Parallel > 1 {
+		args = append(args, fmt.Sprintf("--parallel=%d", d.Parallel))
+	}
+
+	return args
+}
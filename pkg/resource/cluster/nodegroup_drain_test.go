@@ -0,0 +1,35 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadNodeGroupDrain(t *testing.T) {
+	assert.Equal(t, NodeGroupDrain{Enabled: true, Parallel: 1}, readNodeGroupDrain([]interface{}{}))
+
+	assert.Equal(t, NodeGroupDrain{Enabled: true, DisableEviction: true, Parallel: 5}, readNodeGroupDrain([]interface{}{
+		map[string]interface{}{"enabled": true, "disable_eviction": true, "parallel": 5},
+	}))
+}
+
+func TestNodeGroupDrainArgs(t *testing.T) {
+	// The defaults keep the flags eksctl had been run with
+	d := defaultNodeGroupDrain()
+
+	assert.Equal(t, []string{"--drain=true"}, d.deleteNodeGroupArgs())
+	assert.Empty(t, d.drainNodeGroupArgs())
+	assert.Empty(t, d.deleteClusterArgs())
+
+	d = NodeGroupDrain{Enabled: true, DisableEviction: true, Parallel: 5}
+
+	assert.Equal(t, []string{"--drain=true", "--disable-eviction", "--parallel=5"}, d.deleteNodeGroupArgs())
+	assert.Equal(t, []string{"--disable-eviction", "--parallel=5"}, d.drainNodeGroupArgs())
+	assert.Equal(t, []string{"--disable-nodegroup-eviction", "--parallel=5"}, d.deleteClusterArgs())
+
+	// Disabling the drain ignores the other drain flags
+	d = NodeGroupDrain{Enabled: false, DisableEviction: true, Parallel: 5}
+
+	assert.Equal(t, []string{"--drain=false"}, d.deleteNodeGroupArgs())
+}
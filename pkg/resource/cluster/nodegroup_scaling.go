@@ -0,0 +1,181 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// The remotely observed scaling values and instance types of the nodegroups, keyed by the nodegroup names.
+// Unlike `nodegroups`, each of them is an individually addressable attribute,
+// so that e.g. `nodegroup_desired_capacity` can be put in `ignore_changes` for nodegroups scaled by
+// cluster-autoscaler, while drift in the instance types is still shown in the plan.
+const (
+	KeyNodeGroupDesiredCapacity = "nodegroup_desired_capacity"
+	KeyNodeGroupMinSize         = "nodegroup_min_size"
+	KeyNodeGroupMaxSize         = "nodegroup_max_size"
+	KeyNodeGroupInstanceTypes   = "nodegroup_instance_types"
+)
+
+// nodeGroupScalingFlags are the flags of `eksctl scale nodegroup` for each of the scaling attributes
+var nodeGroupScalingFlags = []struct {
+	key, flag string
+}{
+	{KeyNodeGroupDesiredCapacity, "--nodes"},
+	{KeyNodeGroupMinSize, "--nodes-min"},
+	{KeyNodeGroupMaxSize, "--nodes-max"},
+}
+
+func nodeGroupSizesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeInt},
+	}
+}
+
+// nodeGroupInstanceTypesSchema is the map from the nodegroup names to the comma-separated instance types
+func nodeGroupInstanceTypesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// flattenNodeGroupScaling converts the nodegroup summaries into the values of the scaling and instance type attributes
+func flattenNodeGroupScaling(summaries []NodeGroupSummary) map[string]map[string]interface{} {
+	values := map[string]map[string]interface{}{
+		KeyNodeGroupDesiredCapacity: {},
+		KeyNodeGroupMinSize:         {},
+		KeyNodeGroupMaxSize:         {},
+		KeyNodeGroupInstanceTypes:   {},
+	}
+
+	for _, s := range summaries {
+		values[KeyNodeGroupDesiredCapacity][s.Name] = s.DesiredCapacity
+		values[KeyNodeGroupMinSize][s.Name] = s.MinSize
+		values[KeyNodeGroupMaxSize][s.Name] = s.MaxSize
+
+		var instanceTypes []string
+
+		for _, t := range strings.Split(s.InstanceType, ",") {
+			if t = strings.TrimSpace(t); t != "" && t != "-" {
+				instanceTypes = append(instanceTypes, t)
+			}
+		}
+
+		values[KeyNodeGroupInstanceTypes][s.Name] = strings.Join(instanceTypes, ",")
+	}
+
+	return values
+}
+
+func setNodeGroupScaling(d ReadWrite, summaries []NodeGroupSummary) error {
+	for k, v := range flattenNodeGroupScaling(summaries) {
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("setting %s: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+// nodeGroupScaleArgs returns the `eksctl scale nodegroup` args for each nodegroup whose desired scaling values are changed.
+// The unchanged values of the nodegroup are passed as well, as eksctl validates them together.
+func nodeGroupScaleArgs(d interface {
+	GetChange(string) (interface{}, interface{})
+}, clusterName ClusterName) [][]string {
+	changed := map[string]bool{}
+
+	for _, f := range nodeGroupScalingFlags {
+		o, n := d.GetChange(f.key)
+		old, _ := o.(map[string]interface{})
+		desired, _ := n.(map[string]interface{})
+
+		for ng, v := range desired {
+			if ov, ok := old[ng]; !ok || fmt.Sprint(ov) != fmt.Sprint(v) {
+				changed[ng] = true
+			}
+		}
+	}
+
+	var names []string
+
+	for ng := range changed {
+		names = append(names, ng)
+	}
+
+	sort.Strings(names)
+
+	var cmds [][]string
+
+	for _, ng := range names {
+		args := []string{"scale", "nodegroup", "--cluster", string(clusterName), "--name", ng}
+
+		for _, f := range nodeGroupScalingFlags {
+			_, n := d.GetChange(f.key)
+			desired, _ := n.(map[string]interface{})
+
+			if v, ok := desired[ng]; ok {
+				args = append(args, f.flag, fmt.Sprint(v))
+			}
+		}
+
+		cmds = append(cmds, args)
+	}
+
+	return cmds
+}
+
+func doScaleNodeGroups(d *schema.ResourceData, cluster *Cluster, clusterName ClusterName) error {
+	for _, args := range nodeGroupScaleArgs(d, clusterName) {
+		cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+		if err != nil {
+			return fmt.Errorf("creating eksctl-scale-nodegroup command: %w", err)
+		}
+
+		if err := resource.Update(cmd, d); err != nil {
+			return fmt.Errorf("scaling nodegroup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateNodeGroupInstanceTypes fails the plan when the desired instance types of an existing nodegroup differ from
+// the running ones, as they can't be changed in place.
+func validateNodeGroupInstanceTypes(d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.HasChange(KeyNodeGroupInstanceTypes) {
+		return nil
+	}
+
+	o, n := d.GetChange(KeyNodeGroupInstanceTypes)
+
+	return diffNodeGroupInstanceTypes(toStringMap(o), toStringMap(n))
+}
+
+func diffNodeGroupInstanceTypes(running, desired map[string]string) error {
+	var names []string
+
+	for ng := range desired {
+		names = append(names, ng)
+	}
+
+	sort.Strings(names)
+
+	for _, ng := range names {
+		// The instance types of the nodegroups read via the AWS SDK can be unknown
+		if r, ok := running[ng]; ok && r != "" && r != desired[ng] {
+			return fmt.Errorf("%s: instance types of nodegroup %s can't be changed in place from %q to %q. "+
+				"Rename the nodegroup in the spec, or enable %s, to replace it", KeyNodeGroupInstanceTypes, ng, r, desired[ng], KeyNodeGroupBlueGreen)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type changeRead map[string][2]interface{}
+
+func (c changeRead) GetChange(k string) (interface{}, interface{}) {
+	return c[k][0], c[k][1]
+}
+
+func TestFlattenNodeGroupScaling(t *testing.T) {
+	values := flattenNodeGroupScaling([]NodeGroupSummary{
+		{Name: "ng1", InstanceType: "m5.large", DesiredCapacity: 2, MinSize: 1, MaxSize: 3},
+		{Name: "mng1", InstanceType: "m5.large, m5a.large", DesiredCapacity: 0, MinSize: 0, MaxSize: 5},
+	})
+
+	assert.Equal(t, map[string]interface{}{"ng1": 2, "mng1": 0}, values[KeyNodeGroupDesiredCapacity])
+	assert.Equal(t, map[string]interface{}{"ng1": 1, "mng1": 0}, values[KeyNodeGroupMinSize])
+	assert.Equal(t, map[string]interface{}{"ng1": 3, "mng1": 5}, values[KeyNodeGroupMaxSize])
+	assert.Equal(t, map[string]interface{}{"ng1": "m5.large", "mng1": "m5.large,m5a.large"}, values[KeyNodeGroupInstanceTypes])
+}
+
+func TestNodeGroupScaleArgs(t *testing.T) {
+	d := changeRead{
+		KeyNodeGroupDesiredCapacity: {
+			map[string]interface{}{"ng1": 2, "ng2": 1},
+			map[string]interface{}{"ng1": 2, "ng2": 3},
+		},
+		KeyNodeGroupMinSize: {
+			map[string]interface{}{"ng1": 1, "ng2": 1},
+			map[string]interface{}{"ng1": 1, "ng2": 1},
+		},
+		KeyNodeGroupMaxSize: {
+			map[string]interface{}{"ng1": 3},
+			map[string]interface{}{"ng1": 3},
+		},
+	}
+
+	assert.Equal(t, [][]string{
+		{"scale", "nodegroup", "--cluster", "primary", "--name", "ng2", "--nodes", "3", "--nodes-min", "1"},
+	}, nodeGroupScaleArgs(d, "primary"))
+}
+
+func TestDiffNodeGroupInstanceTypes(t *testing.T) {
+	running := map[string]string{"ng1": "m5.large"}
+
+	assert.NoError(t, diffNodeGroupInstanceTypes(running, map[string]string{"ng1": "m5.large", "ng2": "c5.large"}))
+	assert.EqualError(t,
+		diffNodeGroupInstanceTypes(running, map[string]string{"ng1": "m5.xlarge"}),
+		`nodegroup_instance_types: instance types of nodegroup ng1 can't be changed in place from "m5.large" to "m5.xlarge". Rename the nodegroup in the spec, or enable nodegroup_blue_green, to replace it`,
+	)
+}
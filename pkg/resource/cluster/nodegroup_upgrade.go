@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+const KeyNodeGroupUpgrade = "nodegroup_upgrade"
+
+// NodeGroupUpgrade configures upgrading managed nodegroups to the new `version` after the control plane upgrade,
+// so that the kubelet version skew stays within the supported bounds in a single apply.
+type NodeGroupUpgrade struct {
+	// Parallelism is the number of nodegroups upgraded at once
+	Parallelism int
+	// ForceUpgrade upgrades nodes even when pods can't be drained due to PodDisruptionBudgets
+	ForceUpgrade bool
+}
+
+func nodeGroupUpgradeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"parallelism": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntBetween(1, 10),
+				},
+				"force_upgrade": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+			},
+		},
+	}
+}
+
+func readNodeGroupUpgrade(v interface{}) *NodeGroupUpgrade {
+	upgrades := v.([]interface{})
+	if len(upgrades) == 0 {
+		return nil
+	}
+
+	upgrade := &NodeGroupUpgrade{
+		Parallelism: 1,
+	}
+
+	if upgrades[0] == nil {
+		return upgrade
+	}
+
+	m := upgrades[0].(map[string]interface{})
+
+	upgrade.Parallelism = m["parallelism"].(int)
+	upgrade.ForceUpgrade = m["force_upgrade"].(bool)
+
+	return upgrade
+}
+
+// upgradeNodeGroupArgs returns the args of `eksctl upgrade nodegroup` for the managed nodegroup
+func (u NodeGroupUpgrade) upgradeNodeGroupArgs(cluster *Cluster, clusterName ClusterName, nodeGroup string) []string {
+	args := []string{
+		"upgrade", "nodegroup",
+		"--cluster", string(clusterName),
+		"--name", nodeGroup,
+		"--region", cluster.Region,
+		"--kubernetes-version", cluster.Version,
+	}
+
+	if u.ForceUpgrade {
+		args = append(args, "--force-upgrade")
+	}
+
+	return args
+}
+
+func getManagedNodeGroupNames(spec string) ([]string, error) {
+	var config struct {
+		ManagedNodeGroups []struct {
+			Name string `yaml:"name"`
+		} `yaml:"managedNodeGroups"`
+	}
+
+	if err := yaml.Unmarshal([]byte(spec), &config); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w\nCONTENT:\n%s", err, spec)
+	}
+
+	var names []string
+
+	for _, ng := range config.ManagedNodeGroups {
+		names = append(names, ng.Name)
+	}
+
+	return names, nil
+}
+
+// doUpgradeNodeGroups upgrades the managed nodegroups declared in the spec to the cluster version
+func doUpgradeNodeGroups(cluster *Cluster, clusterName ClusterName) error {
+	u := cluster.NodeGroupUpgrade
+	if u == nil {
+		return nil
+	}
+
+	names, err := getManagedNodeGroupNames(cluster.Spec)
+	if err != nil {
+		return err
+	}
+
+	var tasks []func() error
+
+	for _, n := range names {
+		n := n
+
+		tasks = append(tasks, func() error {
+			log.Printf("Upgrading nodegroup %s of cluster %s to %s", n, clusterName, cluster.Version)
+
+			cmd, err := newEksctlCommandWithAWSProfile(cluster, u.upgradeNodeGroupArgs(cluster, clusterName, n)...)
+			if err != nil {
+				return fmt.Errorf("creating eksctl-upgrade-nodegroup command: %w", err)
+			}
+
+			if _, err := resource.Run(cmd); err != nil {
+				return fmt.Errorf("upgrading nodegroup %s: %w", n, err)
+			}
+
+			return nil
+		})
+	}
+
+	return runWithParallelism(u.Parallelism, tasks)
+}
+
+// runWithParallelism runs the tasks with up to n tasks at once and returns the first error.
+// Once a task fails, the tasks that are not started yet are skipped.
+func runWithParallelism(n int, tasks []func() error) error {
+	if n < 1 {
+		n = 1
+	}
+
+	var g errgroup.Group
+
+	sem := make(chan struct{}, n)
+	failed := make(chan struct{})
+
+	var once sync.Once
+
+	for _, t := range tasks {
+		t := t
+
+		sem <- struct{}{}
+
+		select {
+		case <-failed:
+			<-sem
+
+			return g.Wait()
+		default:
+		}
+
+		g.Go(func() error {
+			defer func() { <-sem }()
+
+			if err := t(); err != nil {
+				once.Do(func() { close(failed) })
+
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
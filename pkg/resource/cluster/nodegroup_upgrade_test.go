@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"errors"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoUpgradeNodeGroups(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		upgrades []string
+	)
+
+	prev := resource.SetCommandRunner(resource.CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*resource.CommandResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		upgrades = append(upgrades, strings.Join(cmd.Args[1:], " "))
+
+		return &resource.CommandResult{}, nil
+	}))
+	defer resource.SetCommandRunner(prev)
+
+	cluster := &Cluster{
+		EksctlBin:        "eksctl",
+		Region:           "us-east-2",
+		Version:          "1.19",
+		NodeGroupUpgrade: &NodeGroupUpgrade{Parallelism: 2, ForceUpgrade: true},
+		Spec: `
+nodeGroups:
+- name: unmanaged
+managedNodeGroups:
+- name: mng1
+- name: mng2
+`,
+	}
+
+	require.NoError(t, doUpgradeNodeGroups(cluster, "prod-abc"))
+
+	sort.Strings(upgrades)
+
+	// Only the managed nodegroups are upgraded
+	assert.Equal(t, []string{
+		"upgrade nodegroup --cluster prod-abc --name mng1 --region us-east-2 --kubernetes-version 1.19 --force-upgrade",
+		"upgrade nodegroup --cluster prod-abc --name mng2 --region us-east-2 --kubernetes-version 1.19 --force-upgrade",
+	}, upgrades)
+}
+
+func TestRunWithParallelism(t *testing.T) {
+	var running, maxRunning int32
+
+	var tasks []func() error
+
+	for i := 0; i < 6; i++ {
+		tasks = append(tasks, func() error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			return nil
+		})
+	}
+
+	require.NoError(t, runWithParallelism(2, tasks))
+	assert.Equal(t, int32(2), maxRunning)
+}
+
+func TestRunWithParallelism_failure(t *testing.T) {
+	var started int32
+
+	tasks := []func() error{
+		func() error {
+			atomic.AddInt32(&started, 1)
+
+			return errors.New("upgrade failed")
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		tasks = append(tasks, func() error {
+			atomic.AddInt32(&started, 1)
+
+			return nil
+		})
+	}
+
+	// The tasks not started yet are skipped once a task fails
+	assert.EqualError(t, runWithParallelism(1, tasks), "upgrade failed")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&started))
+}
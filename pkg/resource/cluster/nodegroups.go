@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const KeyNodeGroups = "nodegroups"
+
+// NodeGroupSummary is an entry in the output of `eksctl get nodegroup -o json`
+type NodeGroupSummary struct {
+	Name                 string `json:"Name"`
+	Status               string `json:"Status"`
+	InstanceType         string `json:"InstanceType"`
+	DesiredCapacity      int    `json:"DesiredCapacity"`
+	MinSize              int    `json:"MinSize"`
+	MaxSize              int    `json:"MaxSize"`
+	AutoScalingGroupName string `json:"AutoScalingGroupName"`
+	NodeInstanceRoleARN  string `json:"NodeInstanceRoleARN"`
+}
+
+func nodeGroupsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"status": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"instance_types": {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"desired_capacity": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"min_size": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"max_size": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"autoscaling_group_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"node_role_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func runGetNodeGroups(d Read, cluster *Cluster, clusterName ClusterName) ([]NodeGroupSummary, error) {
+	v, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(cluster.Profile, cluster.AssumeRoles), cluster.Region, cluster.Name, "nodegroups/"+string(clusterName)), func() (interface{}, error) {
+		if !eksctlAvailable(cluster.EksctlBin, cluster.EksctlVersion) {
+			return getNodeGroupsWithSDK(cluster, clusterName)
+		}
+
+		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, "get", "nodegroup", "--cluster", string(clusterName), "-o", "json")
+		if err != nil {
+			return nil, fmt.Errorf("creating eksctl-get-nodegroup command: %w", err)
+		}
+
+		run, err := resource.Run(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("running get-nodegroup: %w", err)
+		}
+
+		return parseNodeGroupSummaries(run.Stdout)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]NodeGroupSummary), nil
+}
+
+func parseNodeGroupSummaries(output string) ([]NodeGroupSummary, error) {
+	var summaries []NodeGroupSummary
+
+	if err := json.Unmarshal([]byte(output), &summaries); err != nil {
+		return nil, fmt.Errorf("parsing get-nodegroup output as json: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// flattenNodeGroupSummaries converts the nodegroup summaries into the value of `nodegroups`
+func flattenNodeGroupSummaries(summaries []NodeGroupSummary) []interface{} {
+	var nodegroups []interface{}
+
+	for _, s := range summaries {
+		var instanceTypes []interface{}
+
+		// eksctl shows a managed nodegroup with multiple instance types as a comma-separated list
+		for _, t := range strings.Split(s.InstanceType, ",") {
+			if t = strings.TrimSpace(t); t != "" && t != "-" {
+				instanceTypes = append(instanceTypes, t)
+			}
+		}
+
+		nodegroups = append(nodegroups, map[string]interface{}{
+			"name":                   s.Name,
+			"status":                 s.Status,
+			"instance_types":         instanceTypes,
+			"desired_capacity":       s.DesiredCapacity,
+			"min_size":               s.MinSize,
+			"max_size":               s.MaxSize,
+			"autoscaling_group_name": s.AutoScalingGroupName,
+			"node_role_arn":          s.NodeInstanceRoleARN,
+		})
+	}
+
+	return nodegroups
+}
+
+func loadNodeGroups(d ReadWrite, cluster *Cluster, clusterName ClusterName) error {
+	summaries, err := runGetNodeGroups(d, cluster, clusterName)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set(KeyNodeGroups, flattenNodeGroupSummaries(summaries)); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyNodeGroups, err)
+	}
+
+	if err := setNodeGroupScaling(d, summaries); err != nil {
+		return err
+	}
+
+	return loadAMIDrift(d, cluster, summaries)
+}
@@ -0,0 +1,227 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+const (
+	eksctlTagClusterName   = "alpha.eksctl.io/cluster-name"
+	eksctlTagNodeGroupName = "alpha.eksctl.io/nodegroup-name"
+	eksctlTagNodeGroupType = "alpha.eksctl.io/nodegroup-type"
+)
+
+// eksctlAvailable returns true when the eksctl binary is either installed or going to be installed for the eksctl_version.
+// Reads fall back to the AWS SDK otherwise, so that `terraform plan` works on machines without eksctl.
+func eksctlAvailable(eksctlBin, eksctlVersion string) bool {
+	if eksctlVersion != "" {
+		return true
+	}
+
+	if eksctlBin == "" {
+		eksctlBin = "eksctl"
+	}
+
+	if _, err := exec.LookPath(eksctlBin); err != nil {
+		log.Printf("eksctl binary %q not found, reading with AWS SDK instead: %v", eksctlBin, err)
+
+		return false
+	}
+
+	return true
+}
+
+// getNodeGroupsWithSDK returns the same nodegroup summaries as `eksctl get nodegroup` does, by reading the EKS API
+// for managed nodegroups and the eksctl CloudFormation stacks for unmanaged ones.
+func getNodeGroupsWithSDK(cluster *Cluster, clusterName ClusterName) ([]NodeGroupSummary, error) {
+	sess := AWSSessionFromCluster(cluster)
+
+	managed, err := getManagedNodeGroupsWithSDK(sess, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	unmanaged, err := getUnmanagedNodeGroupsWithSDK(sess, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(unmanaged, managed...), nil
+}
+
+func getManagedNodeGroupsWithSDK(sess *session.Session, clusterName ClusterName) ([]NodeGroupSummary, error) {
+	svc := eks.New(sess)
+
+	var names []string
+
+	err := svc.ListNodegroupsPages(&eks.ListNodegroupsInput{
+		ClusterName: aws.String(string(clusterName)),
+	}, func(o *eks.ListNodegroupsOutput, lastPage bool) bool {
+		names = append(names, aws.StringValueSlice(o.Nodegroups)...)
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing managed nodegroups of %s: %w", clusterName, err)
+	}
+
+	var summaries []NodeGroupSummary
+
+	for _, name := range names {
+		res, err := svc.DescribeNodegroup(&eks.DescribeNodegroupInput{
+			ClusterName:   aws.String(string(clusterName)),
+			NodegroupName: aws.String(name),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing managed nodegroup %s: %w", name, err)
+		}
+
+		summaries = append(summaries, managedNodeGroupSummary(res.Nodegroup))
+	}
+
+	return summaries, nil
+}
+
+func managedNodeGroupSummary(ng *eks.Nodegroup) NodeGroupSummary {
+	s := NodeGroupSummary{
+		Name:                aws.StringValue(ng.NodegroupName),
+		Status:              aws.StringValue(ng.Status),
+		InstanceType:        strings.Join(aws.StringValueSlice(ng.InstanceTypes), ","),
+		NodeInstanceRoleARN: aws.StringValue(ng.NodeRole),
+	}
+
+	if c := ng.ScalingConfig; c != nil {
+		s.DesiredCapacity = int(aws.Int64Value(c.DesiredSize))
+		s.MinSize = int(aws.Int64Value(c.MinSize))
+		s.MaxSize = int(aws.Int64Value(c.MaxSize))
+	}
+
+	if r := ng.Resources; r != nil && len(r.AutoScalingGroups) > 0 {
+		s.AutoScalingGroupName = aws.StringValue(r.AutoScalingGroups[0].Name)
+	}
+
+	return s
+}
+
+func getUnmanagedNodeGroupsWithSDK(sess *session.Session, clusterName ClusterName) ([]NodeGroupSummary, error) {
+	cfn := cloudformation.New(sess)
+
+	var summaries []NodeGroupSummary
+
+	err := cfn.DescribeStacksPages(&cloudformation.DescribeStacksInput{}, func(o *cloudformation.DescribeStacksOutput, lastPage bool) bool {
+		for _, stack := range o.Stacks {
+			if s, ok := unmanagedNodeGroupSummary(stack, clusterName); ok {
+				summaries = append(summaries, s)
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing nodegroup stacks of %s: %w", clusterName, err)
+	}
+
+	for i := range summaries {
+		if err := loadUnmanagedNodeGroupScaling(sess, cfn, clusterName, &summaries[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return summaries, nil
+}
+
+// unmanagedNodeGroupSummary returns the summary of the nodegroup read from the tags and the outputs of the eksctl stack
+func unmanagedNodeGroupSummary(stack *cloudformation.Stack, clusterName ClusterName) (NodeGroupSummary, bool) {
+	tags := map[string]string{}
+
+	for _, t := range stack.Tags {
+		tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+	}
+
+	name, ok := tags[eksctlTagNodeGroupName]
+	if !ok || tags[eksctlTagClusterName] != string(clusterName) || tags[eksctlTagNodeGroupType] == "managed" {
+		return NodeGroupSummary{}, false
+	}
+
+	s := NodeGroupSummary{
+		Name:   name,
+		Status: aws.StringValue(stack.StackStatus),
+	}
+
+	for _, o := range stack.Outputs {
+		if aws.StringValue(o.OutputKey) == "InstanceRoleARN" {
+			s.NodeInstanceRoleARN = aws.StringValue(o.OutputValue)
+		}
+	}
+
+	return s, true
+}
+
+// loadUnmanagedNodeGroupScaling reads the sizes and the instance types of the nodegroup from its autoscaling group
+func loadUnmanagedNodeGroupScaling(sess *session.Session, cfn *cloudformation.CloudFormation, clusterName ClusterName, s *NodeGroupSummary) error {
+	stackName := fmt.Sprintf("eksctl-%s-nodegroup-%s", clusterName, s.Name)
+
+	res, err := cfn.DescribeStackResource(&cloudformation.DescribeStackResourceInput{
+		StackName:         aws.String(stackName),
+		LogicalResourceId: aws.String("NodeGroup"),
+	})
+	if err != nil {
+		return fmt.Errorf("describing autoscaling group of nodegroup %s: %w", s.Name, err)
+	}
+
+	s.AutoScalingGroupName = aws.StringValue(res.StackResourceDetail.PhysicalResourceId)
+
+	asgs, err := autoscaling.New(sess).DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice([]string{s.AutoScalingGroupName}),
+	})
+	if err != nil {
+		return fmt.Errorf("describing autoscaling group %s: %w", s.AutoScalingGroupName, err)
+	}
+
+	if len(asgs.AutoScalingGroups) == 0 {
+		return nil
+	}
+
+	g := asgs.AutoScalingGroups[0]
+
+	s.DesiredCapacity = int(aws.Int64Value(g.DesiredCapacity))
+	s.MinSize = int(aws.Int64Value(g.MinSize))
+	s.MaxSize = int(aws.Int64Value(g.MaxSize))
+
+	if p := g.MixedInstancesPolicy; p != nil && p.LaunchTemplate != nil && len(p.LaunchTemplate.Overrides) > 0 {
+		var types []string
+
+		for _, o := range p.LaunchTemplate.Overrides {
+			types = append(types, aws.StringValue(o.InstanceType))
+		}
+
+		s.InstanceType = strings.Join(types, ",")
+
+		return nil
+	}
+
+	if lt := g.LaunchTemplate; lt != nil {
+		versions, err := ec2.New(sess).DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: lt.LaunchTemplateId,
+			Versions:         aws.StringSlice([]string{aws.StringValue(lt.Version)}),
+		})
+		if err != nil {
+			return fmt.Errorf("describing launch template of nodegroup %s: %w", s.Name, err)
+		}
+
+		if len(versions.LaunchTemplateVersions) > 0 && versions.LaunchTemplateVersions[0].LaunchTemplateData != nil {
+			s.InstanceType = aws.StringValue(versions.LaunchTemplateVersions[0].LaunchTemplateData.InstanceType)
+		}
+	}
+
+	return nil
+}
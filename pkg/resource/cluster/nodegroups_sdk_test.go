@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEksctlAvailable(t *testing.T) {
+	assert.True(t, eksctlAvailable("no-such-eksctl", "0.30.0"))
+	assert.False(t, eksctlAvailable("no-such-eksctl", ""))
+}
+
+func TestManagedNodeGroupSummary(t *testing.T) {
+	s := managedNodeGroupSummary(&eks.Nodegroup{
+		NodegroupName: aws.String("ng1"),
+		Status:        aws.String("ACTIVE"),
+		InstanceTypes: aws.StringSlice([]string{"m5.large", "m5a.large"}),
+		NodeRole:      aws.String("arn:aws:iam::123456789012:role/ng1"),
+		ScalingConfig: &eks.NodegroupScalingConfig{
+			DesiredSize: aws.Int64(2),
+			MinSize:     aws.Int64(1),
+			MaxSize:     aws.Int64(3),
+		},
+		Resources: &eks.NodegroupResources{
+			AutoScalingGroups: []*eks.AutoScalingGroup{{Name: aws.String("eks-ng1")}},
+		},
+	})
+
+	assert.Equal(t, NodeGroupSummary{
+		Name:                 "ng1",
+		Status:               "ACTIVE",
+		InstanceType:         "m5.large,m5a.large",
+		DesiredCapacity:      2,
+		MinSize:              1,
+		MaxSize:              3,
+		AutoScalingGroupName: "eks-ng1",
+		NodeInstanceRoleARN:  "arn:aws:iam::123456789012:role/ng1",
+	}, s)
+}
+
+func TestUnmanagedNodeGroupSummary(t *testing.T) {
+	stack := func(cluster, nodeGroupType string) *cloudformation.Stack {
+		return &cloudformation.Stack{
+			StackStatus: aws.String("CREATE_COMPLETE"),
+			Tags: []*cloudformation.Tag{
+				{Key: aws.String(eksctlTagClusterName), Value: aws.String(cluster)},
+				{Key: aws.String(eksctlTagNodeGroupName), Value: aws.String("ng1")},
+				{Key: aws.String(eksctlTagNodeGroupType), Value: aws.String(nodeGroupType)},
+			},
+			Outputs: []*cloudformation.Output{
+				{OutputKey: aws.String("InstanceRoleARN"), OutputValue: aws.String("arn:aws:iam::123456789012:role/ng1")},
+			},
+		}
+	}
+
+	s, ok := unmanagedNodeGroupSummary(stack("primary", "unmanaged"), "primary")
+	assert.True(t, ok)
+	assert.Equal(t, NodeGroupSummary{
+		Name:                "ng1",
+		Status:              "CREATE_COMPLETE",
+		NodeInstanceRoleARN: "arn:aws:iam::123456789012:role/ng1",
+	}, s)
+
+	_, ok = unmanagedNodeGroupSummary(stack("primary", "managed"), "primary")
+	assert.False(t, ok)
+
+	_, ok = unmanagedNodeGroupSummary(stack("secondary", "unmanaged"), "primary")
+	assert.False(t, ok)
+}
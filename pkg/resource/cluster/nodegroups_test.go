@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenNodeGroupSummaries(t *testing.T) {
+	summaries, err := parseNodeGroupSummaries(`[
+  {
+    "StackName": "eksctl-prod-nodegroup-ng1",
+    "Cluster": "prod",
+    "Name": "ng1",
+    "Status": "CREATE_COMPLETE",
+    "MaxSize": 3,
+    "MinSize": 1,
+    "DesiredCapacity": 2,
+    "InstanceType": "m5.large,m5a.large",
+    "ImageID": "ami-0123",
+    "NodeInstanceRoleARN": "arn:aws:iam::123456789012:role/ng1",
+    "AutoScalingGroupName": "eksctl-prod-nodegroup-ng1-NodeGroup-ABC"
+  }
+]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name":                   "ng1",
+			"status":                 "CREATE_COMPLETE",
+			"instance_types":         []interface{}{"m5.large", "m5a.large"},
+			"desired_capacity":       2,
+			"min_size":               1,
+			"max_size":               3,
+			"autoscaling_group_name": "eksctl-prod-nodegroup-ng1-NodeGroup-ABC",
+			"node_role_arn":          "arn:aws:iam::123456789012:role/ng1",
+		},
+	}, flattenNodeGroupSummaries(summaries))
+}
@@ -0,0 +1,275 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const KeyNotification = "notification"
+
+const (
+	NotificationOperationCreate     = "create"
+	NotificationOperationUpdate     = "update"
+	NotificationOperationDelete     = "delete"
+	NotificationOperationSwitchover = "switchover"
+
+	notificationWebhookTimeout = 10 * time.Second
+)
+
+var notificationOperations = []string{
+	NotificationOperationCreate,
+	NotificationOperationUpdate,
+	NotificationOperationDelete,
+	NotificationOperationSwitchover,
+}
+
+// Notification is the destination of the messages posted after the cluster is changed,
+// so that platform channels learn about the changes automatically
+type Notification struct {
+	SNSTopicARN string
+	// WebhookURL receives a JSON payload whose `text` is compatible with Slack incoming webhooks
+	WebhookURL string
+	// Operations are the operations to notify. Empty means all
+	Operations []string
+}
+
+// NotificationEvent is the payload of the notification
+type NotificationEvent struct {
+	Text            string `json:"text"`
+	Cluster         string `json:"cluster"`
+	Operation       string `json:"operation"`
+	OldVersion      string `json:"old_version,omitempty"`
+	NewVersion      string `json:"new_version,omitempty"`
+	DurationSeconds int    `json:"duration_seconds"`
+	Result          string `json:"result"`
+	Error           string `json:"error,omitempty"`
+}
+
+func notificationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"sns_topic_arn": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				// webhook_url is sensitive, as Slack webhook URLs are credentials
+				"webhook_url": {
+					Type:      schema.TypeString,
+					Optional:  true,
+					Default:   "",
+					Sensitive: true,
+				},
+				"operations": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validation.StringInSlice(notificationOperations, false),
+					},
+				},
+			},
+		},
+	}
+}
+
+func readNotifications(v interface{}) ([]Notification, error) {
+	blocks, _ := v.([]interface{})
+
+	var notifications []Notification
+
+	for i, b := range blocks {
+		if b == nil {
+			continue
+		}
+
+		m := b.(map[string]interface{})
+
+		n := Notification{
+			SNSTopicARN: m["sns_topic_arn"].(string),
+			WebhookURL:  m["webhook_url"].(string),
+			Operations:  toStrings(m["operations"]),
+		}
+
+		if (n.SNSTopicARN == "") == (n.WebhookURL == "") {
+			return nil, fmt.Errorf("%s.%d: exactly one of sns_topic_arn and webhook_url must be set", KeyNotification, i)
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+func (n Notification) accepts(op string) bool {
+	if len(n.Operations) == 0 {
+		return true
+	}
+
+	for _, o := range n.Operations {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notifyOperation is deferred by Create, Update, and Delete so that the result is notified regardless of the outcome
+func notifyOperation(d *schema.ResourceData, p *resource.ProviderConfig, op string, start time.Time, err *error) {
+	notify(d, p, op, start, *err)
+}
+
+// notify posts the result of the operation to the notifications of the resource.
+// Failed notifications are only logged, as they must never fail the apply that has already changed the cluster.
+func notify(d *schema.ResourceData, p *resource.ProviderConfig, op string, start time.Time, opErr error) {
+	notifications, err := readNotifications(d.Get(KeyNotification))
+	if err != nil {
+		log.Printf("[WARN] skipping notifications: %v", err)
+
+		return
+	}
+
+	if len(notifications) == 0 {
+		return
+	}
+
+	o, n := d.GetChange(KeyVersion)
+
+	oldVersion, _ := o.(string)
+	newVersion, _ := n.(string)
+
+	switch op {
+	case NotificationOperationCreate:
+		oldVersion = ""
+	case NotificationOperationDelete:
+		oldVersion, newVersion = newVersion, ""
+	}
+
+	event := newNotificationEvent(d.Get(KeyName).(string), op, oldVersion, newVersion, time.Since(start), opErr)
+
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	for _, n := range notifications {
+		if !n.accepts(op) {
+			continue
+		}
+
+		var err error
+
+		if n.SNSTopicARN != "" {
+			topicRegion := region
+			if a, err := arn.Parse(n.SNSTopicARN); err == nil && a.Region != "" {
+				topicRegion = a.Region
+			}
+
+			sess := p.NewAWSSession(topicRegion, profile, resource.GetAssumeRoles(d))
+
+			err = publishNotification(sns.New(sess), n.SNSTopicARN, event)
+		} else {
+			err = postNotification(&http.Client{Timeout: notificationWebhookTimeout}, n.WebhookURL, event)
+		}
+
+		if err != nil {
+			log.Printf("[WARN] failed notifying %s of cluster %s: %v", op, event.Cluster, err)
+		}
+	}
+}
+
+func newNotificationEvent(clusterName, op, oldVersion, newVersion string, duration time.Duration, err error) NotificationEvent {
+	e := NotificationEvent{
+		Cluster:         clusterName,
+		Operation:       op,
+		OldVersion:      oldVersion,
+		NewVersion:      newVersion,
+		DurationSeconds: int(duration / time.Second),
+		Result:          "succeeded",
+	}
+
+	if err != nil {
+		e.Result = "failed"
+		e.Error = err.Error()
+	}
+
+	text := fmt.Sprintf("eksctl: %s of cluster %s %s in %v", op, clusterName, e.Result, duration.Round(time.Second))
+
+	switch {
+	case oldVersion != "" && newVersion != "" && oldVersion != newVersion:
+		text += fmt.Sprintf(" (version %s -> %s)", oldVersion, newVersion)
+	case newVersion != "":
+		text += fmt.Sprintf(" (version %s)", newVersion)
+	case oldVersion != "":
+		text += fmt.Sprintf(" (version %s)", oldVersion)
+	}
+
+	if err != nil {
+		// Only the first line, as eksctl errors contain the whole output of the command
+		text += ": " + strings.SplitN(e.Error, "\n", 2)[0]
+	}
+
+	e.Text = text
+
+	return e
+}
+
+func postNotification(client *http.Client, url string, event NotificationEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The URL is omitted as it's a credential for e.g. Slack
+		return fmt.Errorf("posting to webhook: %s", strings.ReplaceAll(err.Error(), url, "<webhook_url>"))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(res.Body)
+
+		return fmt.Errorf("posting to webhook: %s: %s", res.Status, msg)
+	}
+
+	return nil
+}
+
+func publishNotification(svc snsiface.SNSAPI, topicARN string, event NotificationEvent) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// SNS subjects are limited to 100 characters
+	subject := fmt.Sprintf("eksctl: %s of cluster %s %s", event.Operation, event.Cluster, event.Result)
+	if len(subject) > 100 {
+		subject = subject[:100]
+	}
+
+	if _, err := svc.Publish(&sns.PublishInput{
+		TopicArn: aws.String(topicARN),
+		Subject:  aws.String(subject),
+		Message:  aws.String(string(message)),
+	}); err != nil {
+		return fmt.Errorf("publishing to %s: %w", topicARN, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotificationEvent(t *testing.T) {
+	e := newNotificationEvent("primary", NotificationOperationUpdate, "1.17", "1.18", 12*time.Minute+3*time.Second, nil)
+	assert.Equal(t, NotificationEvent{
+		Text:            "eksctl: update of cluster primary succeeded in 12m3s (version 1.17 -> 1.18)",
+		Cluster:         "primary",
+		Operation:       "update",
+		OldVersion:      "1.17",
+		NewVersion:      "1.18",
+		DurationSeconds: 723,
+		Result:          "succeeded",
+	}, e)
+
+	e = newNotificationEvent("primary", NotificationOperationDelete, "1.18", "", time.Minute, errors.New("running eksctl delete cluster: exit status 1\nCOMBINED OUTPUT:\n..."))
+	assert.Equal(t, "eksctl: delete of cluster primary failed in 1m0s (version 1.18): running eksctl delete cluster: exit status 1", e.Text)
+	assert.Equal(t, "failed", e.Result)
+}
+
+func TestReadNotifications(t *testing.T) {
+	ns, err := readNotifications([]interface{}{
+		map[string]interface{}{"sns_topic_arn": "arn:aws:sns:us-east-2:123456789012:eks", "webhook_url": "", "operations": []interface{}{}},
+		map[string]interface{}{"sns_topic_arn": "", "webhook_url": "https://hooks.slack.com/services/x", "operations": []interface{}{"switchover"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, ns, 2)
+	assert.True(t, ns[0].accepts(NotificationOperationDelete))
+	assert.False(t, ns[1].accepts(NotificationOperationDelete))
+	assert.True(t, ns[1].accepts(NotificationOperationSwitchover))
+
+	_, err = readNotifications([]interface{}{
+		map[string]interface{}{"sns_topic_arn": "", "webhook_url": "", "operations": []interface{}{}},
+	})
+	assert.EqualError(t, err, "notification.0: exactly one of sns_topic_arn and webhook_url must be set")
+}
+
+func TestPostNotification(t *testing.T) {
+	var received NotificationEvent
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+	}))
+	defer srv.Close()
+
+	e := newNotificationEvent("primary", NotificationOperationCreate, "", "1.18", time.Minute, nil)
+
+	require.NoError(t, postNotification(srv.Client(), srv.URL, e))
+	assert.Equal(t, e, received)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	assert.EqualError(t, postNotification(failing.Client(), failing.URL, e), "posting to webhook: 400 Bad Request: invalid_payload\n")
+}
+
+type snsPublishMock struct {
+	snsiface.SNSAPI
+
+	inputs []*sns.PublishInput
+}
+
+func (m *snsPublishMock) Publish(in *sns.PublishInput) (*sns.PublishOutput, error) {
+	m.inputs = append(m.inputs, in)
+
+	return &sns.PublishOutput{}, nil
+}
+
+func TestPublishNotification(t *testing.T) {
+	svc := &snsPublishMock{}
+
+	e := newNotificationEvent("primary", NotificationOperationSwitchover, "1.17", "1.18", time.Minute, nil)
+
+	require.NoError(t, publishNotification(svc, "arn:aws:sns:us-east-2:123456789012:eks", e))
+	require.Len(t, svc.inputs, 1)
+	assert.Equal(t, "eksctl: switchover of cluster primary succeeded", aws.StringValue(svc.inputs[0].Subject))
+
+	var message NotificationEvent
+
+	require.NoError(t, json.Unmarshal([]byte(aws.StringValue(svc.inputs[0].Message)), &message))
+	assert.Equal(t, e, message)
+}
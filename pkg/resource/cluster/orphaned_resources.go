@@ -0,0 +1,178 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const KeyCleanupOrphanedResources = "cleanup_orphaned_resources"
+
+const (
+	orphanedResourcesCleanupTimeout  = 10 * time.Minute
+	orphanedResourcesCleanupInterval = 15 * time.Second
+)
+
+// cleanupOrphanedResources deletes the ENIs left by the VPC CNI and the EKS control plane, and the cluster security group,
+// which often block deleting the VPC after `eksctl delete cluster`.
+//
+// Deletions failing due to dependencies, like an ENI still being detached, are retried until the timeout.
+func cleanupOrphanedResources(cluster *Cluster, clusterName ClusterName) error {
+	svc := ec2.New(AWSSessionFromCluster(cluster))
+
+	err := retryOnDependencyViolation(func() error {
+		return deleteOrphanedResources(svc, clusterName)
+	}, orphanedResourcesCleanupTimeout, orphanedResourcesCleanupInterval)
+	if err != nil {
+		return fmt.Errorf("cleaning up orphaned resources of cluster %s: %w", clusterName, err)
+	}
+
+	return nil
+}
+
+// retryOnDependencyViolation calls f until it succeeds, fails with an error other than a dependency violation, or the timeout elapses
+func retryOnDependencyViolation(f func() error, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		if !isDependencyViolation(err) || time.Now().After(deadline) {
+			return err
+		}
+
+		log.Printf("Retrying orphaned resources cleanup in %s: %v", interval, err)
+
+		time.Sleep(interval)
+	}
+}
+
+func deleteOrphanedResources(svc *ec2.EC2, clusterName ClusterName) error {
+	enis, err := getOrphanedENIs(svc, clusterName)
+	if err != nil {
+		return err
+	}
+
+	for _, eni := range enis {
+		log.Printf("Deleting orphaned ENI %s: %s", eni, clusterName)
+
+		if _, err := svc.DeleteNetworkInterface(&ec2.DeleteNetworkInterfaceInput{
+			NetworkInterfaceId: aws.String(eni),
+		}); err != nil && !isNotFound(err) {
+			return fmt.Errorf("deleting network interface %s: %w", eni, err)
+		}
+	}
+
+	sgs, err := svc.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:aws:eks:cluster-name"),
+				Values: aws.StringSlice([]string{string(clusterName)}),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("describing cluster security groups: %w", err)
+	}
+
+	for _, sg := range sgs.SecurityGroups {
+		id := aws.StringValue(sg.GroupId)
+
+		log.Printf("Deleting orphaned cluster security group %s: %s", id, clusterName)
+
+		if _, err := svc.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{
+			GroupId: sg.GroupId,
+		}); err != nil && !isNotFound(err) {
+			return fmt.Errorf("deleting security group %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// getOrphanedENIs returns the IDs of the detached ENIs created by the VPC CNI and the EKS control plane for the cluster
+func getOrphanedENIs(svc *ec2.EC2, clusterName ClusterName) ([]string, error) {
+	filterSets := [][]*ec2.Filter{
+		{
+			{
+				Name:   aws.String("tag:cluster.k8s.amazonaws.com/name"),
+				Values: aws.StringSlice([]string{string(clusterName)}),
+			},
+		},
+		{
+			{
+				Name:   aws.String("description"),
+				Values: aws.StringSlice([]string{fmt.Sprintf("Amazon EKS %s", clusterName)}),
+			},
+		},
+	}
+
+	var ids []string
+
+	seen := map[string]bool{}
+
+	for _, filters := range filterSets {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("status"),
+			Values: aws.StringSlice([]string{ec2.NetworkInterfaceStatusAvailable}),
+		})
+
+		err := svc.DescribeNetworkInterfacesPages(&ec2.DescribeNetworkInterfacesInput{
+			Filters: filters,
+		}, func(o *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+			for _, eni := range o.NetworkInterfaces {
+				id := aws.StringValue(eni.NetworkInterfaceId)
+
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing network interfaces: %w", err)
+		}
+	}
+
+	return ids, nil
+}
+
+func isDependencyViolation(err error) bool {
+	var aerr awserr.Error
+
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case "DependencyViolation", "InvalidNetworkInterface.InUse":
+		return true
+	}
+
+	return false
+}
+
+func isNotFound(err error) bool {
+	var aerr awserr.Error
+
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case "InvalidNetworkInterfaceID.NotFound", "InvalidGroup.NotFound":
+		return true
+	}
+
+	return false
+}
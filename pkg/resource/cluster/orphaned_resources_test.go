@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDependencyViolation(t *testing.T) {
+	assert.True(t, isDependencyViolation(awserr.New("DependencyViolation", "resource sg-1 has a dependent object", nil)))
+	assert.True(t, isDependencyViolation(awserr.New("InvalidNetworkInterface.InUse", "eni-1 is in use", nil)))
+	assert.True(t, isDependencyViolation(fmt.Errorf("deleting security group sg-1: %w", awserr.New("DependencyViolation", "", nil))))
+
+	assert.False(t, isDependencyViolation(awserr.New("UnauthorizedOperation", "", nil)))
+	assert.False(t, isDependencyViolation(errors.New("DependencyViolation")))
+}
+
+func TestRetryOnDependencyViolation(t *testing.T) {
+	violation := awserr.New("DependencyViolation", "", nil)
+
+	t.Run("retries until success", func(t *testing.T) {
+		var calls int
+
+		err := retryOnDependencyViolation(func() error {
+			calls++
+			if calls < 3 {
+				return violation
+			}
+			return nil
+		}, time.Minute, time.Millisecond)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		var calls int
+
+		unauthorized := awserr.New("UnauthorizedOperation", "", nil)
+
+		err := retryOnDependencyViolation(func() error {
+			calls++
+			return unauthorized
+		}, time.Minute, time.Millisecond)
+
+		assert.Equal(t, unauthorized, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		err := retryOnDependencyViolation(func() error {
+			return violation
+		}, 10*time.Millisecond, time.Millisecond)
+
+		assert.Equal(t, violation, err)
+	})
+}
@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var outpostARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:outposts:[a-z0-9-]+:[0-9]{12}:outpost/op-[0-9a-f]+$`)
+
+// Outpost is the `outpost` section of cluster.yaml for EKS local clusters on AWS Outposts
+type Outpost struct {
+	ControlPlaneOutpostARN   string                 `yaml:"controlPlaneOutpostARN"`
+	ControlPlaneInstanceType string                 `yaml:"controlPlaneInstanceType,omitempty"`
+	Rest                     map[string]interface{} `yaml:",inline"`
+}
+
+// OutpostConfig is the OutpostConfig of a local cluster in the `eksctl get cluster` output
+type OutpostConfig struct {
+	OutpostArns              []string `json:"OutpostArns"`
+	ControlPlaneInstanceType string   `json:"ControlPlaneInstanceType"`
+}
+
+// validateOutpost validates the cluster.yaml for a local cluster, which supports only a subset of the eksctl features.
+func validateOutpost(config EksctlClusterConfig) error {
+	if config.Outpost == nil {
+		return nil
+	}
+
+	if !outpostARNPattern.MatchString(config.Outpost.ControlPlaneOutpostARN) {
+		return fmt.Errorf("outpost.controlPlaneOutpostARN must be an Outpost ARN like arn:aws:outposts:REGION:ACCOUNT:outpost/op-ID, but got %q", config.Outpost.ControlPlaneOutpostARN)
+	}
+
+	for _, unsupported := range []string{"managedNodeGroups", "fargateProfiles"} {
+		if _, ok := config.Rest[unsupported]; ok {
+			return fmt.Errorf("%s is not supported for local clusters on Outposts", unsupported)
+		}
+	}
+
+	if config.IAM.WithOIDC {
+		return fmt.Errorf("iam.withOIDC is not supported for local clusters on Outposts")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestValidateOutpost(t *testing.T) {
+	parse := func(s string) EksctlClusterConfig {
+		var config EksctlClusterConfig
+
+		if err := yaml.Unmarshal([]byte(s), &config); err != nil {
+			t.Fatal(err)
+		}
+
+		return config
+	}
+
+	assert.NoError(t, validateOutpost(parse(`
+outpost:
+  controlPlaneOutpostARN: arn:aws:outposts:us-west-2:123456789012:outpost/op-0123456789abcdef0
+  controlPlaneInstanceType: m5d.large
+nodeGroups:
+- name: ng1
+`)))
+
+	assert.NoError(t, validateOutpost(parse(`
+managedNodeGroups:
+- name: ng1
+`)))
+
+	assert.Error(t, validateOutpost(parse(`
+outpost:
+  controlPlaneOutpostARN: op-0123456789abcdef0
+`)))
+
+	assert.Error(t, validateOutpost(parse(`
+outpost:
+  controlPlaneOutpostARN: arn:aws:outposts:us-west-2:123456789012:outpost/op-0123456789abcdef0
+managedNodeGroups:
+- name: ng1
+`)))
+}
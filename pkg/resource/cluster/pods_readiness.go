@@ -3,9 +3,6 @@ package cluster
 import (
 	"fmt"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
-	"io/ioutil"
-	"os"
-	"os/exec"
 	"strings"
 )
 
@@ -14,16 +11,12 @@ func doCheckPodsReadiness(cluster *Cluster, id string) error {
 		return nil
 	}
 
-	kubeconfig, err := ioutil.TempFile("", "terraform-provider-eksctl-kubeconfig-")
+	kubeconfigPath, err := cluster.Provider.TempFile("terraform-provider-eksctl-kubeconfig-")
 	if err != nil {
 		return err
 	}
 
-	kubeconfigPath := kubeconfig.Name()
-
-	if err := kubeconfig.Close(); err != nil {
-		return err
-	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
 
 	clusterName := cluster.Name + "-" + id
 
@@ -36,6 +29,11 @@ func doCheckPodsReadiness(cluster *Cluster, id string) error {
 		return err
 	}
 
+	return waitForPodsReadiness(cluster, kubeconfigPath)
+}
+
+// waitForPodsReadiness waits for the pods specified in pods_readiness_check to become ready
+func waitForPodsReadiness(cluster *Cluster, kubeconfigPath string) error {
 	for _, r := range cluster.CheckPodsReadinessConfigs {
 		args := []string{"wait", "--namespace", r.namespace, "--for", "condition=ready", "pod",
 			"--timeout", fmt.Sprintf("%ds", r.timeoutSec),
@@ -52,18 +50,13 @@ func doCheckPodsReadiness(cluster *Cluster, id string) error {
 
 		args = append(args, selectorArgs...)
 
-		kubectlCmd := exec.Command(cluster.KubectlBin, args...)
-
-		for _, env := range os.Environ() {
-			if !strings.HasPrefix(env, "KUBECONFIG=") {
-				kubectlCmd.Env = append(kubectlCmd.Env, env)
-			}
+		kubectlCmd, err := newKubectlCommand(cluster, kubeconfigPath, args...)
+		if err != nil {
+			return err
 		}
 
-		kubectlCmd.Env = append(kubectlCmd.Env, "KUBECONFIG="+kubeconfigPath)
-
 		if _, err := resource.Run(kubectlCmd); err != nil {
-			return err
+			return fmt.Errorf("%w\n\nNOT READY PODS IN NAMESPACE %s:\n%s", err, r.namespace, resource.Redact(diagnosePodsReadiness(cluster, kubeconfigPath, r, strings.Join(matches, ","))))
 		}
 	}
 
@@ -0,0 +1,209 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	// maxDiagnosedPods is the max number of not-ready pods to be described in the readiness failure
+	maxDiagnosedPods = 10
+	// maxEventsPerPod is the max number of the most recent events shown per pod
+	maxEventsPerPod = 5
+)
+
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type pod struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Phase             string            `json:"phase"`
+		Reason            string            `json:"reason"`
+		Message           string            `json:"message"`
+		Conditions        []podCondition    `json:"conditions"`
+		ContainerStatuses []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type podCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type containerStatus struct {
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	RestartCount int    `json:"restartCount"`
+	State        map[string]struct {
+		Reason   string `json:"reason"`
+		Message  string `json:"message"`
+		ExitCode *int   `json:"exitCode"`
+	} `json:"state"`
+}
+
+type eventList struct {
+	Items []event `json:"items"`
+}
+
+type event struct {
+	InvolvedObject struct {
+		Name string `json:"name"`
+	} `json:"involvedObject"`
+	Type          string `json:"type"`
+	Reason        string `json:"reason"`
+	Message       string `json:"message"`
+	Count         int    `json:"count"`
+	LastTimestamp string `json:"lastTimestamp"`
+}
+
+func (p pod) ready() bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == "Ready" {
+			return c.Status == "True"
+		}
+	}
+
+	return false
+}
+
+// diagnosePodsReadiness describes the not-ready pods matching the readiness check in a `kubectl describe`-like format,
+// so that failed readiness checks and switchovers can be debugged from CI logs.
+func diagnosePodsReadiness(cluster *Cluster, kubeconfigPath string, r CheckPodsReadiness, selector string) string {
+	kubectl := func(args ...string) (string, error) {
+		cmd, err := newKubectlCommand(cluster, kubeconfigPath, args...)
+		if err != nil {
+			return "", err
+		}
+
+		res, err := resource.Run(cmd)
+		if err != nil {
+			return "", err
+		}
+
+		return res.Stdout, nil
+	}
+
+	pods, err := kubectl("get", "pods", "--namespace", r.namespace, "-l", selector, "-o", "json")
+	if err != nil {
+		return fmt.Sprintf("failed getting pods for diagnostics: %v", err)
+	}
+
+	events, err := kubectl("get", "events", "--namespace", r.namespace, "--field-selector", "involvedObject.kind=Pod", "-o", "json")
+	if err != nil {
+		events = "{}"
+	}
+
+	d, err := formatPodsReadinessDiagnostics(pods, events)
+	if err != nil {
+		return fmt.Sprintf("failed formatting diagnostics: %v", err)
+	}
+
+	return d
+}
+
+func formatPodsReadinessDiagnostics(podsJSON, eventsJSON string) (string, error) {
+	var pods podList
+
+	if err := json.Unmarshal([]byte(podsJSON), &pods); err != nil {
+		return "", fmt.Errorf("parsing pods: %w", err)
+	}
+
+	var events eventList
+
+	if err := json.Unmarshal([]byte(eventsJSON), &events); err != nil {
+		return "", fmt.Errorf("parsing events: %w", err)
+	}
+
+	eventsByPod := map[string][]event{}
+
+	for _, e := range events.Items {
+		eventsByPod[e.InvolvedObject.Name] = append(eventsByPod[e.InvolvedObject.Name], e)
+	}
+
+	var notReady []pod
+
+	for _, p := range pods.Items {
+		if !p.ready() {
+			notReady = append(notReady, p)
+		}
+	}
+
+	if len(notReady) == 0 {
+		return fmt.Sprintf("%d pods matched but no not-ready pod found", len(pods.Items)), nil
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d of %d pods are not ready", len(notReady), len(pods.Items))
+
+	for i, p := range notReady {
+		if i >= maxDiagnosedPods {
+			fmt.Fprintf(&b, "\n\n... and %d more not-ready pods", len(notReady)-maxDiagnosedPods)
+			break
+		}
+
+		fmt.Fprintf(&b, "\n\nName:   %s\nPhase:  %s", p.Metadata.Name, p.Status.Phase)
+
+		if p.Status.Reason != "" || p.Status.Message != "" {
+			fmt.Fprintf(&b, "\nReason: %s %s", p.Status.Reason, p.Status.Message)
+		}
+
+		for _, c := range p.Status.Conditions {
+			if c.Status != "True" {
+				fmt.Fprintf(&b, "\nCondition %s=%s: %s %s", c.Type, c.Status, c.Reason, c.Message)
+			}
+		}
+
+		if len(p.Status.ContainerStatuses) > 0 {
+			b.WriteString("\nContainers:")
+		}
+
+		for _, c := range p.Status.ContainerStatuses {
+			fmt.Fprintf(&b, "\n  %s: ready=%t restarts=%d", c.Name, c.Ready, c.RestartCount)
+
+			for state, s := range c.State {
+				fmt.Fprintf(&b, " state=%s", state)
+
+				if s.Reason != "" {
+					fmt.Fprintf(&b, " reason=%s", s.Reason)
+				}
+
+				if s.ExitCode != nil {
+					fmt.Fprintf(&b, " exitCode=%d", *s.ExitCode)
+				}
+
+				if s.Message != "" {
+					fmt.Fprintf(&b, " message=%q", s.Message)
+				}
+			}
+		}
+
+		podEvents := eventsByPod[p.Metadata.Name]
+
+		sort.SliceStable(podEvents, func(i, j int) bool { return podEvents[i].LastTimestamp < podEvents[j].LastTimestamp })
+
+		if len(podEvents) > maxEventsPerPod {
+			podEvents = podEvents[len(podEvents)-maxEventsPerPod:]
+		}
+
+		if len(podEvents) > 0 {
+			b.WriteString("\nEvents:")
+		}
+
+		for _, e := range podEvents {
+			fmt.Fprintf(&b, "\n  %s  %s  %s (x%d): %s", e.LastTimestamp, e.Type, e.Reason, e.Count, e.Message)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPodsReadinessDiagnostics(t *testing.T) {
+	pods := `{"items": [
+  {"metadata": {"name": "web-1"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}},
+  {"metadata": {"name": "web-2"}, "status": {
+    "phase": "Running",
+    "conditions": [{"type": "Ready", "status": "False", "reason": "ContainersNotReady", "message": "containers with unready status: [web]"}],
+    "containerStatuses": [{"name": "web", "ready": false, "restartCount": 3, "state": {"waiting": {"reason": "CrashLoopBackOff", "message": "back-off 40s"}}}]
+  }}
+]}`
+
+	events := `{"items": [
+  {"involvedObject": {"name": "web-1"}, "type": "Normal", "reason": "Started", "message": "Started container web", "count": 1, "lastTimestamp": "2020-09-01T00:00:00Z"},
+  {"involvedObject": {"name": "web-2"}, "type": "Warning", "reason": "BackOff", "message": "Back-off restarting failed container", "count": 3, "lastTimestamp": "2020-09-01T00:01:00Z"}
+]}`
+
+	d, err := formatPodsReadinessDiagnostics(pods, events)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `1 of 2 pods are not ready
+
+Name:   web-2
+Phase:  Running
+Condition Ready=False: ContainersNotReady containers with unready status: [web]
+Containers:
+  web: ready=false restarts=3 state=waiting reason=CrashLoopBackOff message="back-off 40s"
+Events:
+  2020-09-01T00:01:00Z  Warning  BackOff (x3): Back-off restarting failed container`, d)
+}
@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+// preflightDiff is the part of schema.ResourceDiff used by validateAWSRegionAndCredentials
+type preflightDiff interface {
+	Read
+
+	Id() string
+	HasChange(string) bool
+	NewValueKnown(string) bool
+}
+
+// validateAWSRegionAndCredentials validates on plan that the region is set and the resolved AWS credentials work,
+// so that misconfigurations fail fast instead of in the middle of `eksctl create cluster`.
+// It runs only on create or when the region or the profile changes, so that a plan for an existing cluster doesn't call STS.
+func validateAWSRegionAndCredentials(d preflightDiff, p *resource.ProviderConfig) error {
+	if !d.NewValueKnown(KeyRegion) || !d.NewValueKnown(KeyProfile) {
+		return nil
+	}
+
+	if d.Id() != "" && !d.HasChange(KeyRegion) && !d.HasChange(KeyProfile) {
+		return nil
+	}
+
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	if err := validateEKSRegion(region); err != nil {
+		return err
+	}
+
+	_, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(profile, resource.GetAssumeRoles(d)), region, "", "caller-identity"), func() (interface{}, error) {
+		sess := resource.AWSSessionFromResourceData(d, p)
+
+		r, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, fmt.Errorf("validating AWS credentials for region %q and profile %q: %w", region, profile, err)
+		}
+
+		log.Printf("Using AWS credentials of %s", aws.StringValue(r.Arn))
+
+		return r, nil
+	})
+
+	return err
+}
+
+// validateEKSRegion fails when the region is missing. A region unknown to the bundled AWS SDK is only warned about,
+// as new regions are added to EKS before the SDK is updated.
+func validateEKSRegion(region string) error {
+	if region == "" {
+		return fmt.Errorf("validating region: %s", resource.RegionNotFoundMessage)
+	}
+
+	var known []string
+
+	for _, p := range endpoints.DefaultPartitions() {
+		regions, ok := endpoints.RegionsForService(endpoints.DefaultPartitions(), p.ID(), eks.EndpointsID)
+		if !ok {
+			continue
+		}
+
+		if _, ok := regions[region]; ok {
+			return nil
+		}
+
+		for r := range regions {
+			known = append(known, r)
+		}
+	}
+
+	sort.Strings(known)
+
+	log.Printf("[WARN] %q is not a known EKS region. Known regions are: %s", region, strings.Join(known, ", "))
+
+	return nil
+}
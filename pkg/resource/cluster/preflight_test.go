@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestValidateEKSRegion(t *testing.T) {
+	assert.NoError(t, validateEKSRegion("us-east-2"))
+	// Regions unknown to the bundled AWS SDK are only warned about
+	assert.NoError(t, validateEKSRegion("us-east-9"))
+	assert.Error(t, validateEKSRegion(""))
+}
+
+type fakePreflightDiff struct {
+	mapRead
+
+	id      string
+	changed map[string]bool
+	unknown map[string]bool
+}
+
+func (d *fakePreflightDiff) Id() string {
+	return d.id
+}
+
+func (d *fakePreflightDiff) HasChange(k string) bool {
+	return d.changed[k]
+}
+
+func (d *fakePreflightDiff) NewValueKnown(k string) bool {
+	return !d.unknown[k]
+}
+
+func TestValidateAWSRegionAndCredentials_skipped(t *testing.T) {
+	for _, k := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if v, ok := os.LookupEnv(k); ok {
+			os.Unsetenv(k)
+			defer os.Setenv(k, v)
+		}
+	}
+
+	// An empty region would fail the validation if it was not skipped
+	d := mapRead{KeyRegion: "", KeyProfile: ""}
+
+	// The region is known only after apply, like when it's given from another resource
+	assert.NoError(t, validateAWSRegionAndCredentials(&fakePreflightDiff{mapRead: d, unknown: map[string]bool{KeyRegion: true}}, nil))
+
+	// Plans for existing clusters don't call STS unless the region or the profile changes
+	assert.NoError(t, validateAWSRegionAndCredentials(&fakePreflightDiff{mapRead: d, id: "mycluster"}, nil))
+
+	assert.Error(t, validateAWSRegionAndCredentials(&fakePreflightDiff{mapRead: d, id: "mycluster", changed: map[string]bool{KeyRegion: true}}, nil))
+	assert.Error(t, validateAWSRegionAndCredentials(&fakePreflightDiff{mapRead: d}, nil))
+}
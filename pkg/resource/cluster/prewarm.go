@@ -0,0 +1,441 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const KeyPrewarm = "prewarm"
+
+const (
+	DefaultPrewarmTimeout      = 10 * time.Minute
+	DefaultPrewarmInterval     = 10 * time.Second
+	DefaultWarmupRequests      = 100
+	DefaultWarmupConcurrency   = 10
+	DefaultWarmupRequestPath   = "/"
+	DefaultWarmupRequestScheme = "http"
+)
+
+// Prewarm makes the traffic shift wait for the new cluster to be ready for the production load,
+// so that the cutover doesn't result in 5xx spikes due to cold starts.
+type Prewarm struct {
+	HPAs     []PrewarmHPA
+	Timeout  time.Duration
+	Interval time.Duration
+
+	// WarmupRequest, when non-nil, sends synthetic requests to the targets of the new target groups
+	WarmupRequest *WarmupRequest
+}
+
+// PrewarmHPA is the HorizontalPodAutoscaler whose current replicas must reach MinReplicas before the traffic shift
+type PrewarmHPA struct {
+	Namespace   string
+	Name        string
+	MinReplicas int
+}
+
+type WarmupRequest struct {
+	Scheme string
+	Path   string
+	// Host is sent as the Host header, so that the application serves the requests as if they came through the ALB
+	Host        string
+	Requests    int
+	Concurrency int
+}
+
+func prewarmSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"hpa": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"namespace": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "default",
+							},
+							"name": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"min_replicas": {
+								Type:         schema.TypeInt,
+								Required:     true,
+								ValidateFunc: validation.IntAtLeast(1),
+							},
+						},
+					},
+				},
+				"warmup_request": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"scheme": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								Default:      DefaultWarmupRequestScheme,
+								ValidateFunc: validation.StringInSlice([]string{"http", "https"}, false),
+							},
+							"path": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  DefaultWarmupRequestPath,
+							},
+							"host": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "",
+							},
+							"requests": {
+								Type:         schema.TypeInt,
+								Optional:     true,
+								Default:      DefaultWarmupRequests,
+								ValidateFunc: validation.IntAtLeast(1),
+							},
+							"concurrency": {
+								Type:         schema.TypeInt,
+								Optional:     true,
+								Default:      DefaultWarmupConcurrency,
+								ValidateFunc: validation.IntAtLeast(1),
+							},
+						},
+					},
+				},
+				"timeout_sec": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  int(DefaultPrewarmTimeout / time.Second),
+				},
+				"interval_sec": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  int(DefaultPrewarmInterval / time.Second),
+				},
+			},
+		},
+	}
+}
+
+func readPrewarm(v interface{}) *Prewarm {
+	blocks := v.([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	p := &Prewarm{Timeout: DefaultPrewarmTimeout, Interval: DefaultPrewarmInterval}
+
+	if blocks[0] == nil {
+		return p
+	}
+
+	m := blocks[0].(map[string]interface{})
+
+	for _, h := range m["hpa"].([]interface{}) {
+		hm := h.(map[string]interface{})
+
+		p.HPAs = append(p.HPAs, PrewarmHPA{
+			Namespace:   hm["namespace"].(string),
+			Name:        hm["name"].(string),
+			MinReplicas: hm["min_replicas"].(int),
+		})
+	}
+
+	if rs := m["warmup_request"].([]interface{}); len(rs) > 0 && rs[0] != nil {
+		rm := rs[0].(map[string]interface{})
+
+		p.WarmupRequest = &WarmupRequest{
+			Scheme:      rm["scheme"].(string),
+			Path:        rm["path"].(string),
+			Host:        rm["host"].(string),
+			Requests:    rm["requests"].(int),
+			Concurrency: rm["concurrency"].(int),
+		}
+	}
+
+	if sec := m["timeout_sec"].(int); sec > 0 {
+		p.Timeout = time.Duration(sec) * time.Second
+	}
+
+	if sec := m["interval_sec"].(int); sec > 0 {
+		p.Interval = time.Duration(sec) * time.Second
+	}
+
+	return p
+}
+
+// doPrewarm waits for the HPAs in the new cluster to scale to the minimum replicas,
+// and then sends the warm-up requests to the healthy targets of the new target groups.
+func doPrewarm(set *ClusterSet, svc elbv2iface.ELBV2API) error {
+	p := set.Cluster.Prewarm
+	if p == nil {
+		return nil
+	}
+
+	if len(p.HPAs) > 0 {
+		if err := waitForHPAs(set.Cluster, set.ClusterName, p); err != nil {
+			return err
+		}
+	}
+
+	if p.WarmupRequest == nil {
+		return nil
+	}
+
+	for _, l := range set.ListenerStatuses {
+		if l.DesiredTG == nil {
+			continue
+		}
+
+		tgARN := *l.DesiredTG.TargetGroupArn
+
+		addrs, err := healthyTargetAddresses(set.Cluster, svc, tgARN)
+		if err != nil {
+			return err
+		}
+
+		if len(addrs) == 0 {
+			return fmt.Errorf("sending warm-up requests to target group %s: no healthy targets", tgARN)
+		}
+
+		if err := sendWarmupRequests(http.DefaultClient, *p.WarmupRequest, addrs); err != nil {
+			return fmt.Errorf("sending warm-up requests to target group %s: %w", tgARN, err)
+		}
+	}
+
+	return nil
+}
+
+func waitForHPAs(cluster *Cluster, clusterName ClusterName, p *Prewarm) error {
+	kubeconfigPath, err := writeTempKubeconfig(cluster, clusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig for prewarming: %w", err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	deadline := time.Now().Add(p.Timeout)
+
+	for _, h := range p.HPAs {
+		for {
+			cmd, err := newKubectlCommand(cluster, kubeconfigPath, "get", "hpa", h.Name, "--namespace", h.Namespace, "-o", "jsonpath={.status.currentReplicas}")
+			if err != nil {
+				return err
+			}
+
+			res, err := resource.Run(cmd)
+			if err != nil {
+				return fmt.Errorf("getting hpa %s/%s: %w", h.Namespace, h.Name, err)
+			}
+
+			current, err := parseHPAReplicas(res.Stdout)
+			if err != nil {
+				return fmt.Errorf("reading current replicas of hpa %s/%s: %w", h.Namespace, h.Name, err)
+			}
+
+			if current >= h.MinReplicas {
+				log.Printf("HPA %s/%s scaled to %d replicas", h.Namespace, h.Name, current)
+
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("waiting for hpa %s/%s to scale to %d replicas: %d replicas after %v", h.Namespace, h.Name, h.MinReplicas, current, p.Timeout)
+			}
+
+			log.Printf("Waiting for HPA %s/%s to scale to %d replicas: currently %d replicas", h.Namespace, h.Name, h.MinReplicas, current)
+
+			time.Sleep(p.Interval)
+		}
+	}
+
+	return nil
+}
+
+// parseHPAReplicas parses the output of kubectl, which is empty until the HPA controller updates the status
+func parseHPAReplicas(out string) (int, error) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(out)
+}
+
+// healthyTargetAddresses returns host:port of the healthy targets of the target group.
+// The private IP addresses of instance targets are looked up, as the provider sends the requests to the nodes directly.
+func healthyTargetAddresses(cluster *Cluster, svc elbv2iface.ELBV2API, tgARN string) ([]string, error) {
+	r, err := svc.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(tgARN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing target health for %s: %w", tgARN, err)
+	}
+
+	var instanceIDs []string
+
+	for _, d := range r.TargetHealthDescriptions {
+		if id := aws.StringValue(d.Target.Id); strings.HasPrefix(id, "i-") {
+			instanceIDs = append(instanceIDs, id)
+		}
+	}
+
+	ips := map[string]string{}
+
+	if len(instanceIDs) > 0 {
+		ec2svc := ec2.New(AWSSessionFromCluster(cluster))
+
+		err := ec2svc.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+			InstanceIds: aws.StringSlice(instanceIDs),
+		}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, res := range page.Reservations {
+				for _, i := range res.Instances {
+					ips[aws.StringValue(i.InstanceId)] = aws.StringValue(i.PrivateIpAddress)
+				}
+			}
+
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing instances of target group %s: %w", tgARN, err)
+		}
+	}
+
+	return targetAddresses(r.TargetHealthDescriptions, ips), nil
+}
+
+func targetAddresses(descs []*elbv2.TargetHealthDescription, instanceIPs map[string]string) []string {
+	var addrs []string
+
+	for _, d := range descs {
+		if d.TargetHealth == nil || aws.StringValue(d.TargetHealth.State) != elbv2.TargetHealthStateEnumHealthy {
+			continue
+		}
+
+		host := aws.StringValue(d.Target.Id)
+
+		if ip, ok := instanceIPs[host]; ok {
+			host = ip
+		}
+
+		if host == "" {
+			continue
+		}
+
+		addrs = append(addrs, fmt.Sprintf("%s:%d", host, aws.Int64Value(d.Target.Port)))
+	}
+
+	return addrs
+}
+
+// sendWarmupRequests sends r.Requests requests in total, spread over the addresses in round-robin.
+// Requests resulting in errors are only logged as long as any of them succeeds, as the purpose is warming up the application.
+func sendWarmupRequests(client *http.Client, r WarmupRequest, addrs []string) error {
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultWarmupConcurrency
+	}
+
+	reqs := make(chan string)
+
+	var (
+		mu        sync.Mutex
+		succeeded int
+		lastErr   error
+	)
+
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for addr := range reqs {
+				err := sendWarmupRequest(client, r, addr)
+
+				mu.Lock()
+				if err != nil {
+					lastErr = err
+				} else {
+					succeeded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < r.Requests; i++ {
+		reqs <- addrs[i%len(addrs)]
+	}
+
+	close(reqs)
+
+	wg.Wait()
+
+	log.Printf("Sent %d warm-up requests to %d targets: %d succeeded", r.Requests, len(addrs), succeeded)
+
+	if succeeded == 0 && lastErr != nil {
+		return fmt.Errorf("all the warm-up requests failed: %w", lastErr)
+	}
+
+	if lastErr != nil {
+		log.Printf("Some warm-up requests failed: %v", lastErr)
+	}
+
+	return nil
+}
+
+func sendWarmupRequest(client *http.Client, r WarmupRequest, addr string) error {
+	path := r.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	url := fmt.Sprintf("%s://%s%s", r.Scheme, addr, path)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if r.Host != "" {
+		req.Host = r.Host
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode >= 500 {
+		return fmt.Errorf("GET %s: unexpected status %d", url, res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHPAReplicas(t *testing.T) {
+	n, err := parseHPAReplicas("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = parseHPAReplicas("3\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = parseHPAReplicas("Error from server (NotFound)")
+	assert.Error(t, err)
+}
+
+func TestTargetAddresses(t *testing.T) {
+	target := func(id string, port int64, state string) *elbv2.TargetHealthDescription {
+		return &elbv2.TargetHealthDescription{
+			Target:       &elbv2.TargetDescription{Id: aws.String(id), Port: aws.Int64(port)},
+			TargetHealth: &elbv2.TargetHealth{State: aws.String(state)},
+		}
+	}
+
+	addrs := targetAddresses([]*elbv2.TargetHealthDescription{
+		target("i-1", 30080, elbv2.TargetHealthStateEnumHealthy),
+		target("i-2", 30080, elbv2.TargetHealthStateEnumUnhealthy),
+		target("10.0.1.10", 8080, elbv2.TargetHealthStateEnumHealthy),
+	}, map[string]string{"i-1": "10.0.0.1", "i-2": "10.0.0.2"})
+
+	assert.Equal(t, []string{"10.0.0.1:30080", "10.0.1.10:8080"}, addrs)
+}
+
+func TestSendWarmupRequests(t *testing.T) {
+	var count int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+
+		assert.Equal(t, "app.example.com", r.Host)
+		assert.Equal(t, "/healthz", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	err := sendWarmupRequests(srv.Client(), WarmupRequest{
+		Scheme:      "http",
+		Path:        "healthz",
+		Host:        "app.example.com",
+		Requests:    20,
+		Concurrency: 4,
+	}, []string{addr, addr})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(20), atomic.LoadInt32(&count))
+
+	t.Run("all failed", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+
+		err := sendWarmupRequests(failing.Client(), WarmupRequest{
+			Scheme:   "http",
+			Path:     "/",
+			Requests: 3,
+		}, []string{strings.TrimPrefix(failing.URL, "http://")})
+
+		assert.Error(t, err)
+	})
+}
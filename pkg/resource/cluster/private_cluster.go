@@ -0,0 +1,107 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// privateClusterEndpointServices are the services fully-private clusters need the VPC endpoints for,
+// so that nodes can pull images, join the cluster, and ship logs without the internet access.
+var privateClusterEndpointServices = []string{
+	"ec2",
+	"ecr.api",
+	"ecr.dkr",
+	"s3",
+	"sts",
+	"logs",
+}
+
+type privateClusterConfig struct {
+	PrivateCluster struct {
+		Enabled              bool `yaml:"enabled"`
+		SkipEndpointCreation bool `yaml:"skipEndpointCreation"`
+	} `yaml:"privateCluster"`
+}
+
+// findMissingVPCEndpoints returns the services whose VPC endpoints are missing.
+// serviceNames are the service names of the existing endpoints like com.amazonaws.us-east-1.ecr.api.
+func findMissingVPCEndpoints(region string, services, serviceNames []string) []string {
+	existing := map[string]bool{}
+
+	for _, n := range serviceNames {
+		// Service names are prefixed differently per partition, like cn.com.amazonaws in China regions
+		if i := strings.Index(n, "."+region+"."); i >= 0 {
+			existing[n[i+len(region)+2:]] = true
+		}
+	}
+
+	var missing []string
+
+	for _, s := range services {
+		if !existing[s] {
+			missing = append(missing, s)
+		}
+	}
+
+	return missing
+}
+
+// validatePrivateClusterEndpoints validates on plan that the existing VPC of a fully-private cluster has the VPC endpoints required by the nodes.
+// eksctl creates the endpoints unless `privateCluster.skipEndpointCreation` is set,
+// in which case missing endpoints make eksctl fail only after a long wait for the nodes to join.
+// spec is the one returned by parsePlannedSpec.
+func validatePrivateClusterEndpoints(d Read, p *resource.ProviderConfig, spec *yaml.Node) error {
+	vpcID, _ := d.Get(KeyVPCID).(string)
+	if vpcID == "" || spec == nil {
+		return nil
+	}
+
+	var config privateClusterConfig
+
+	if err := spec.Decode(&config); err != nil {
+		return fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	if !config.PrivateCluster.Enabled || !config.PrivateCluster.SkipEndpointCreation {
+		return nil
+	}
+
+	region, _ := resource.GetAWSRegionAndProfile(d, p)
+
+	svc := ec2.New(resource.AWSSessionFromResourceData(d, p))
+
+	var serviceNames []string
+
+	err := svc.DescribeVpcEndpointsPages(&ec2.DescribeVpcEndpointsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: aws.StringSlice([]string{vpcID}),
+			},
+			{
+				Name:   aws.String("vpc-endpoint-state"),
+				Values: aws.StringSlice([]string{"available", "pending"}),
+			},
+		},
+	}, func(o *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+		for _, e := range o.VpcEndpoints {
+			serviceNames = append(serviceNames, aws.StringValue(e.ServiceName))
+		}
+
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("describing vpc endpoints of %s: %w", vpcID, err)
+	}
+
+	if missing := findMissingVPCEndpoints(region, privateClusterEndpointServices, serviceNames); len(missing) > 0 {
+		return fmt.Errorf("validating private cluster: VPC %s is missing the VPC endpoints for: %s", vpcID, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
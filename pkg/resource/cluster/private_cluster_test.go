@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingVPCEndpoints(t *testing.T) {
+	missing := findMissingVPCEndpoints("us-east-1", privateClusterEndpointServices, []string{
+		"com.amazonaws.us-east-1.ec2",
+		"com.amazonaws.us-east-1.ecr.api",
+		"com.amazonaws.us-east-1.s3",
+		"com.amazonaws.us-east-1.sts",
+	})
+
+	assert.Equal(t, []string{"ecr.dkr", "logs"}, missing)
+
+	missing = findMissingVPCEndpoints("cn-north-1", []string{"ecr.api", "ecr.dkr"}, []string{
+		"cn.com.amazonaws.cn-north-1.ecr.api",
+		"com.amazonaws.cn-north-1.ecr.dkr",
+	})
+
+	assert.Empty(t, missing)
+}
+
+func TestValidatePrivateClusterEndpoints_skipped(t *testing.T) {
+	d := mapRead{KeyVPCID: "vpc-1"}
+
+	// Nothing is validated unless eksctl skips creating the endpoints
+	for _, s := range []string{"", "privateCluster:\n  enabled: true\n", "privateCluster:\n  skipEndpointCreation: true\n"} {
+		spec, err := parsePlannedSpec(mapRead{KeySpec: s})
+		assert.NoError(t, err)
+
+		assert.NoError(t, validatePrivateClusterEndpoints(d, nil, spec))
+	}
+
+	spec, err := parsePlannedSpec(mapRead{KeySpec: "privateCluster: true\n"})
+	assert.NoError(t, err)
+
+	assert.Error(t, validatePrivateClusterEndpoints(d, nil, spec))
+}
@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+// DefaultRemoteReadCacheTTL is how long the results of remote reads like `eksctl get cluster` are reused.
+// It is long enough to cover a single `terraform plan` or `apply` that reads the same cluster several times
+// across Read and CustomizeDiff.
+const DefaultRemoteReadCacheTTL = 5 * time.Minute
+
+// remoteReadCache is the in-process cache for remote reads.
+// Entries for a cluster are invalidated whenever the provider modifies the cluster.
+var remoteReadCache = newReadCache(DefaultRemoteReadCacheTTL)
+
+type readCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]readCacheEntry
+}
+
+type readCacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+func newReadCache(ttl time.Duration) *readCache {
+	return &readCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]readCacheEntry{},
+	}
+}
+
+// remoteReadCacheAccount returns the profile part of cache keys, which includes the roles assumed for the resource,
+// so that clusters with the same name in different accounts accessed via the same profile never share entries.
+func remoteReadCacheAccount(profile string, roles []awsclicompat.AssumeRole) string {
+	for _, r := range roles {
+		profile += "+" + r.RoleARN
+	}
+
+	return profile
+}
+
+func remoteReadCacheKeyPrefix(profile, region, clusterName string) string {
+	return fmt.Sprintf("%s/%s/%s/", profile, region, clusterName)
+}
+
+func remoteReadCacheKey(profile, region, clusterName, kind string) string {
+	return remoteReadCacheKeyPrefix(profile, region, clusterName) + kind
+}
+
+// getOrLoad returns the cached value for the key if it isn't expired yet. Otherwise it calls load and caches the result.
+// Errors are never cached.
+func (c *readCache) getOrLoad(key string, load func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && c.now().Before(e.expiresAt) {
+		log.Printf("Using cached result for %s", key)
+
+		return e.value, nil
+	}
+
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = readCacheEntry{value: v, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return v, nil
+}
+
+func (c *readCache) invalidate(keyPrefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		if strings.HasPrefix(k, keyPrefix) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+func invalidateRemoteReadCache(cluster *Cluster) {
+	remoteReadCache.invalidate(remoteReadCacheKeyPrefix(remoteReadCacheAccount(cluster.Profile, cluster.AssumeRoles), cluster.Region, cluster.Name))
+}
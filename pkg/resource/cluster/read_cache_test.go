@@ -0,0 +1,71 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+func TestReadCache(t *testing.T) {
+	now := time.Now()
+
+	c := newReadCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	var loads int
+
+	load := func() (interface{}, error) {
+		loads++
+		return loads, nil
+	}
+
+	get := func(key string) int {
+		v, err := c.getOrLoad(key, load)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return v.(int)
+	}
+
+	key := remoteReadCacheKey("", "us-east-2", "mycluster", "cluster")
+
+	if v := get(key); v != 1 {
+		t.Errorf("unexpected value: want 1, got %d", v)
+	}
+
+	if v := get(key); v != 1 {
+		t.Errorf("unexpected value from the cache: want 1, got %d", v)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if v := get(key); v != 2 {
+		t.Errorf("unexpected value after expiration: want 2, got %d", v)
+	}
+
+	c.invalidate(remoteReadCacheKeyPrefix("", "us-east-2", "mycluster"))
+
+	if v := get(key); v != 3 {
+		t.Errorf("unexpected value after invalidation: want 3, got %d", v)
+	}
+
+	c.invalidate(remoteReadCacheKeyPrefix("", "us-east-2", "othercluster"))
+
+	if v := get(key); v != 3 {
+		t.Errorf("unexpected value after invalidating another cluster: want 3, got %d", v)
+	}
+}
+
+func TestRemoteReadCacheAccount(t *testing.T) {
+	if a := remoteReadCacheAccount("prod", nil); a != "prod" {
+		t.Errorf("unexpected account without roles: %s", a)
+	}
+
+	a := remoteReadCacheAccount("prod", []awsclicompat.AssumeRole{{RoleARN: "arn:aws:iam::111111111111:role/spoke"}})
+	b := remoteReadCacheAccount("prod", []awsclicompat.AssumeRole{{RoleARN: "arn:aws:iam::222222222222:role/spoke"}})
+
+	if a == b {
+		t.Errorf("clusters in different accounts must not share cache entries: %s", a)
+	}
+}
@@ -3,9 +3,9 @@ package cluster
 import (
 	"fmt"
 	"log"
-	"regexp"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
@@ -18,13 +18,20 @@ func ResourceCluster() *schema.Resource {
 	}
 	return &schema.Resource{
 		Create: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			defer notifyOperation(d, p, NotificationOperationCreate, time.Now(), &finalErr)
+
 			defer func() {
 				if err := recover(); err != nil {
 					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
 				}
 			}()
 
-			set, err := m.createCluster(d)
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
+			set, err := m.createCluster(d, p)
 			if err != nil {
 				return fmt.Errorf("creating cluster: %w", err)
 			}
@@ -35,16 +42,95 @@ func ResourceCluster() *schema.Resource {
 				return fmt.Errorf("loading oidc issuer url: %w", err)
 			}
 
+			if err := loadNodeGroups(d, set.Cluster, set.ClusterName); err != nil {
+				return fmt.Errorf("loading nodegroups: %w", err)
+			}
+
+			if err := loadClusterAuth(d, set.Cluster, set.ClusterName); err != nil {
+				return fmt.Errorf("loading cluster auth: %w", err)
+			}
+
+			if err := loadAddonDrift(d, set.Cluster); err != nil {
+				return err
+			}
+
 			return nil
 		},
 		CustomizeDiff: func(d *schema.ResourceDiff, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
 			defer func() {
 				if err := recover(); err != nil {
 					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
 				}
 			}()
 
-			if err := m.planCluster(&DiffReadWrite{D: d}); err != nil {
+			if err := validateAWSRegionAndCredentials(d, p); err != nil {
+				return err
+			}
+
+			if err := resource.PlanAWSRegion(d, p); err != nil {
+				return err
+			}
+
+			if err := planIAMPreflight(d, p); err != nil {
+				return err
+			}
+
+			if err := validateSpecKeys(d); err != nil {
+				return err
+			}
+
+			if err := planSpecSource(d, p); err != nil {
+				return fmt.Errorf("diffing spec_source: %w", err)
+			}
+
+			if err := planSpecPatches(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeySpecPatches, err)
+			}
+
+			spec, err := parsePlannedSpec(d)
+			if err != nil {
+				return err
+			}
+
+			if err := validateInstanceTypeOfferings(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validateSubnetTags(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validatePrivateClusterEndpoints(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validateServiceRoleARN(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validateNodeGroupInstanceTypes(d); err != nil {
+				return err
+			}
+
+			if err := planAMIDrift(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeyNodeGroupAMIDrift, err)
+			}
+
+			if err := planAddonDrift(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeyAddonDrift, err)
+			}
+
+			if err := planSpecChecksum(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeySpecChecksum, err)
+			}
+
+			if err := planTargetGroupSelector(&DiffReadWrite{D: d}, p); err != nil {
+				return fmt.Errorf("diffing target_group_selector: %w", err)
+			}
+
+			if err := m.planCluster(&DiffReadWrite{D: d}, p); err != nil {
 				return fmt.Errorf("diffing cluster: %w", err)
 			}
 
@@ -56,10 +142,22 @@ func ResourceCluster() *schema.Resource {
 				kp = v.(string)
 			}
 
-			if d.Id() == "" || kp == "" {
+			if (d.Id() == "" || kp == "") && !kubeconfigStateOnly(d) {
 				d.SetNewComputed(KeyKubeconfigPath)
 			}
 
+			if err := validateManageAWSAuth(d); err != nil {
+				return err
+			}
+
+			if _, err := readNotifications(d.Get(KeyNotification)); err != nil {
+				return err
+			}
+
+			if err := planAWSAuthSources(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeyAWSAuthConfigMap, err)
+			}
+
 			if err := validateDrainNodeGroups(d); err != nil {
 				return fmt.Errorf("drain error: %s", err)
 			}
@@ -67,15 +165,37 @@ func ResourceCluster() *schema.Resource {
 			return nil
 		},
 		Update: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			defer notifyOperation(d, p, NotificationOperationUpdate, time.Now(), &finalErr)
+
 			defer func() {
 				if err := recover(); err != nil {
 					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
 				}
 			}()
 
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
+			if onlyAWSAuthChanged(d, ResourceCluster().Schema) {
+				log.Printf("updating aws-auth mappings only...")
+
+				cluster, err := ReadCluster(d, p)
+				if err != nil {
+					return err
+				}
+
+				if err := updateAWSAuthMappings(d, cluster); err != nil {
+					return fmt.Errorf("updating aws-auth mappings: %w", err)
+				}
+
+				return nil
+			}
+
 			log.Printf("udapting existing cluster...")
 
-			set, err := m.updateCluster(d)
+			set, err := m.updateCluster(d, p)
 			if err != nil {
 				return fmt.Errorf("updating cluster: %w", err)
 			}
@@ -84,16 +204,39 @@ func ResourceCluster() *schema.Resource {
 				return fmt.Errorf("loading oidc issuer url: %w", err)
 			}
 
+			if err := loadNodeGroups(d, set.Cluster, set.ClusterName); err != nil {
+				return fmt.Errorf("loading nodegroups: %w", err)
+			}
+
+			if err := loadClusterAuth(d, set.Cluster, set.ClusterName); err != nil {
+				return fmt.Errorf("loading cluster auth: %w", err)
+			}
+
+			if err := loadAddonDrift(d, set.Cluster); err != nil {
+				return err
+			}
+
 			return nil
 		},
 		Delete: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			defer notifyOperation(d, p, NotificationOperationDelete, time.Now(), &finalErr)
+
 			defer func() {
 				if err := recover(); err != nil {
 					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
 				}
 			}()
 
-			if err := m.deleteCluster(d); err != nil {
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
+			if err := checkDeletionProtection(d); err != nil {
+				return err
+			}
+
+			if err := m.deleteCluster(d, p); err != nil {
 				return err
 			}
 
@@ -102,13 +245,15 @@ func ResourceCluster() *schema.Resource {
 			return nil
 		},
 		Read: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
 			defer func() {
 				if err := recover(); err != nil {
 					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
 				}
 			}()
 
-			cluster, err := m.readCluster(d)
+			cluster, err := m.readCluster(d, p)
 			if err != nil {
 				return fmt.Errorf("reading cluster: %w", err)
 			}
@@ -117,11 +262,31 @@ func ResourceCluster() *schema.Resource {
 				return fmt.Errorf("loading oidc issuer url: %w", err)
 			}
 
+			if err := loadNodeGroups(d, cluster, m.getClusterName(cluster, d.Id())); err != nil {
+				return fmt.Errorf("loading nodegroups: %w", err)
+			}
+
+			if err := loadClusterAuth(d, cluster, m.getClusterName(cluster, d.Id())); err != nil {
+				return fmt.Errorf("loading cluster auth: %w", err)
+			}
+
+			if err := loadAddonDrift(d, cluster); err != nil {
+				return err
+			}
+
+			if err := loadClusterVersion(d, cluster); err != nil {
+				return err
+			}
+
+			if err := loadServiceRoleARN(d, cluster); err != nil {
+				return err
+			}
+
 			return nil
 		},
 		Importer: &schema.ResourceImporter{
 			State: func(data *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
-				data, err := m.importCluster(data)
+				data, err := m.importCluster(data, resource.ProviderConfigFromMeta(i))
 				if err != nil {
 					return nil, fmt.Errorf("importing cluster: %w", err)
 				}
@@ -129,16 +294,18 @@ func ResourceCluster() *schema.Resource {
 				return []*schema.ResourceData{data}, nil
 			},
 		},
-		Schema: map[string]*schema.Schema{
+		Schema: withSharedClusterSchema(map[string]*schema.Schema{
 			// "ForceNew" fields
 			//
 			// the provider does not support zero-downtime updates of these fields so they are set to `ForceNew`,
 			// which results recreating cluster without traffic management.
+			// region defaults to the region of the provider instance, and then AWS_REGION and AWS_DEFAULT_REGION.
+			// It is resolved on the plan for creating the cluster, and recorded in the state afterwards.
 			KeyRegion: {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				DefaultFunc: schema.EnvDefaultFunc("AWS_DEFAULT_REGION", nil),
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
 			},
 			KeyProfile: {
 				Type:     schema.TypeString,
@@ -164,12 +331,18 @@ func ResourceCluster() *schema.Resource {
 			// TODO EksctlVersion: {...}
 
 			// Version is the K8s version (e.g. 1.15, 1.16) that EKS supports
-			// Changing this results in zero-downtime blue-green cluster upgrade.
+			// Changing this upgrades the control plane in place.
+			// It is refreshed from the remote cluster so that upgrades made outside of Terraform are detected.
 			KeyVersion: {
 				Type:     schema.TypeString,
 				Optional: true,
 				Default:  DefaultVersion,
 			},
+			// platform_version is the EKS platform version of the cluster, like `eks.3`
+			KeyPlatformVersion: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			// Tags is the metadata.tags in the cluster config
 			KeyTags: {
 				Type:     schema.TypeMap,
@@ -210,11 +383,17 @@ func ResourceCluster() *schema.Resource {
 			// Over time the provider adds HCL-native syntax for any of cluster.yaml items.
 			// Until then, this is the primary place you configure the cluster as you like.
 			KeySpec: {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentSpecDiff,
 				ValidateFunc: func(v interface{}, name string) ([]string, []error) {
 					s := v.(string)
 
+					// Templated specs are validated after being rendered with spec_vars
+					if isTemplatedSpec(s) {
+						return nil, nil
+					}
+
 					if strings.TrimSpace(s) == "" {
 						return nil, nil
 					}
@@ -230,6 +409,10 @@ func ResourceCluster() *schema.Resource {
 						return nil, []error{fmt.Errorf("validating eksctl_cluster's \"spec\": vpc.id must not be set within the spec yaml. use \"vpc_id\" attribute instead, becaues the provider uses it for generating the final eksctl cluster config yaml")}
 					}
 
+					if err := validateOutpost(configForVaildation); err != nil {
+						return nil, []error{fmt.Errorf("validating eksctl_cluster's \"spec\": %w", err)}
+					}
+
 					return nil, nil
 				},
 			},
@@ -287,6 +470,18 @@ func ResourceCluster() *schema.Resource {
 					},
 				},
 			},
+			// aws_auth_sources are merged in order and de-duplicated by ARN into aws_auth_configmap on plan,
+			// so that platform and team-level access definitions can be composed
+			KeyAWSAuthSources: awsAuthSourcesSchema(),
+			// manage_nodegroups = false creates the cluster without nodegroups, and never creates, upgrades, or deletes nodegroups,
+			// so that the nodegroups are managed exclusively by eksctl_nodegroup resources
+			KeyManageNodeGroups: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			// notification posts the results of create, update, and delete to SNS topics or webhooks like Slack
+			KeyNotification: notificationSchema(),
 			resource.KeyOutput: {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -306,7 +501,33 @@ func ResourceCluster() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
-		},
+			// nodegroups are the details of the nodegroups read from `eksctl get nodegroup`,
+			// like the autoscaling group names and the node role ARNs to be referenced from other resources.
+			KeyNodeGroups: nodeGroupsSchema(),
+			// nodegroup_desired_capacity, nodegroup_min_size, and nodegroup_max_size are the scaling values of the nodegroups
+			// keyed by the nodegroup names. Setting them scales the nodegroups in place.
+			KeyNodeGroupDesiredCapacity: nodeGroupSizesSchema(),
+			KeyNodeGroupMinSize:         nodeGroupSizesSchema(),
+			KeyNodeGroupMaxSize:         nodeGroupSizesSchema(),
+			// nodegroup_instance_types are the comma-separated instance types of the nodegroups keyed by the nodegroup names
+			KeyNodeGroupInstanceTypes: nodeGroupInstanceTypesSchema(),
+			// ami_drift looks up the latest EKS-optimized AMIs for the nodegroups on each read with "warn" or "replace",
+			// and "replace" upgrades the managed nodegroups running outdated AMIs on apply
+			KeyAMIDrift: amiDriftSchema(),
+			// nodegroup_ami_drift is the latest AMIs keyed by the names of the nodegroups running outdated AMIs
+			KeyNodeGroupAMIDrift: {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			// addon_drift is the differences of the installed EKS addons from the ones declared in `addons` of the spec,
+			// keyed by the addon names. The addons are brought back to the spec on apply.
+			KeyAddonDrift: {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		}),
 	}
 }
 
@@ -314,16 +535,25 @@ func validateDrainNodeGroups(d *schema.ResourceDiff) error {
 
 	if v, ok := d.GetOk(KeyDrainNodeGroups); ok {
 
-		spec := ""
+		spec, err := getSpec(d)
+		if err != nil {
+			return err
+		}
+
+		names, err := getNodeGroupNames([]byte(spec))
+		if err != nil {
+			return err
+		}
+
+		declared := map[string]bool{}
 
-		if s, ok := d.GetOk(KeySpec); ok {
-			spec = s.(string)
+		for _, n := range names {
+			declared[n] = true
 		}
 
 		nodegroups := v.(map[string]interface{})
 		for k := range nodegroups {
-			reg := regexp.MustCompile(`- name: ` + k)
-			if !reg.MatchString(spec) {
+			if !declared[k] {
 				return fmt.Errorf("no such nodegroup to drain '%s'", k)
 			}
 		}
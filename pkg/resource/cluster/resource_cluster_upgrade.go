@@ -0,0 +1,457 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	KeyTargetVersion     = "target_version"
+	KeyEKSAddons         = "eks_addons"
+	KeyManagedNodeGroups = "managed_nodegroups"
+	KeyCompletedPhases   = "completed_phases"
+)
+
+// The phases of eksctl_cluster_upgrade, run in this order
+const (
+	UpgradePhaseControlPlane = "control_plane"
+	UpgradePhaseAddons       = "addons"
+	UpgradePhaseNodeGroups   = "nodegroups"
+	UpgradePhaseValidation   = "validation"
+)
+
+// upgradePhaseTimeouts are the attributes for the timeouts of the phases, along with the defaults in seconds
+var upgradePhaseTimeouts = []struct {
+	phase, key string
+	defaultSec int
+}{
+	{UpgradePhaseControlPlane, "control_plane_timeout_sec", 3600},
+	{UpgradePhaseAddons, "addons_timeout_sec", 1200},
+	{UpgradePhaseNodeGroups, "nodegroups_timeout_sec", 3600},
+	{UpgradePhaseValidation, "validation_timeout_sec", 600},
+}
+
+// upgradeValidationInterval is how often the validation phase polls the cluster and the nodegroups
+var upgradeValidationInterval = 15 * time.Second
+
+var kubernetesVersionPattern = regexp.MustCompile(`^1\.\d+$`)
+
+// ResourceClusterUpgrade upgrades an existing cluster to `target_version` in a single ordered run,
+// control plane, addons, managed nodegroups, and then validation checks, each with its own timeout.
+//
+// It's a one-shot resource that is separate from eksctl_cluster, so that an upgrade can be reviewed and applied as its own change.
+// Any change to the target or the upgraded components results in a new upgrade, and destroying it never downgrades the cluster.
+func ResourceClusterUpgrade() *schema.Resource {
+	s := map[string]*schema.Schema{
+		KeyCluster: {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		KeyRegion: {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: true,
+			Computed: true,
+		},
+		KeyProfile: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		resource.KeyAssumeRole: resource.AssumeRoleSchema(),
+		KeyBin: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "eksctl",
+		},
+		KeyEksctlVersion: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		// target_version is the Kubernetes version to upgrade to, which must be the current or the next minor version
+		KeyTargetVersion: {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringMatch(kubernetesVersionPattern, "must be a Kubernetes minor version like 1.17"),
+		},
+		// upgrade_addons updates kube-proxy, aws-node, and coredns, and the EKS addons in eks_addons, after the control plane
+		KeyUpgradeAddons: {
+			Type:     schema.TypeBool,
+			Optional: true,
+			ForceNew: true,
+			Default:  true,
+		},
+		// eks_addons are the EKS addons updated to their latest versions. The default components not listed here
+		// are updated with `eksctl utils`.
+		KeyEKSAddons: {
+			Type:     schema.TypeList,
+			Optional: true,
+			ForceNew: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		// managed_nodegroups are the managed nodegroups upgraded after the addons
+		KeyManagedNodeGroups: {
+			Type:     schema.TypeList,
+			Optional: true,
+			ForceNew: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"parallelism": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntBetween(1, 10),
+		},
+		"force_upgrade": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// completed_phases are the phases completed so far, which tells where a failed upgrade stopped
+		KeyCompletedPhases: {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		resource.KeyLastRunLog: {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		resource.KeyRunLogPath: {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+	}
+
+	for _, t := range upgradePhaseTimeouts {
+		s[t.key] = &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      t.defaultSec,
+			ValidateFunc: validation.IntAtLeast(1),
+		}
+	}
+
+	return &schema.Resource{
+		Create: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			defer func() {
+				if err := recover(); err != nil {
+					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
+				}
+			}()
+
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
+			// The ID is set before the upgrade, so that a failed upgrade is recorded as tainted along with the completed phases
+			d.SetId(fmt.Sprintf("%s/%s", d.Get(KeyCluster).(string), d.Get(KeyTargetVersion).(string)))
+			d.Set(KeyCompletedPhases, []string{})
+
+			if err := doClusterUpgrade(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("upgrading cluster: %w", err)
+			}
+
+			return nil
+		},
+		CustomizeDiff: func(d *schema.ResourceDiff, meta interface{}) (finalErr error) {
+			defer func() {
+				if err := recover(); err != nil {
+					finalErr = fmt.Errorf("unhandled error: %v\n%s", err, debug.Stack())
+				}
+			}()
+
+			p := resource.ProviderConfigFromMeta(meta)
+
+			if err := validateAWSRegionAndCredentials(d, p); err != nil {
+				return err
+			}
+
+			return resource.PlanAWSRegion(d, p)
+		},
+		// Only the timeouts and how to run eksctl can be updated, which take effect on the next upgrade
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			return nil
+		},
+		// The upgrade is a one-shot operation, so that there's nothing to read back
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return nil
+		},
+		Delete: func(d *schema.ResourceData, meta interface{}) error {
+			log.Printf("[INFO] removing upgrade %s from state. The cluster is left as is", d.Id())
+
+			d.SetId("")
+
+			return nil
+		},
+		Schema: s,
+	}
+}
+
+func readUpgradeCluster(d Read, p *resource.ProviderConfig) *Cluster {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	return &Cluster{
+		Name:          d.Get(KeyCluster).(string),
+		Region:        region,
+		Profile:       profile,
+		AssumeRoles:   resource.GetAssumeRoles(d),
+		Provider:      p,
+		EksctlBin:     d.Get(KeyBin).(string),
+		EksctlVersion: d.Get(KeyEksctlVersion).(string),
+		Version:       d.Get(KeyTargetVersion).(string),
+	}
+}
+
+func doClusterUpgrade(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	cluster := readUpgradeCluster(d, p)
+
+	defer invalidateRemoteReadCache(cluster)
+
+	nodeGroups := toStrings(d.Get(KeyManagedNodeGroups))
+
+	phases := map[string]func(deadline time.Time) error{
+		UpgradePhaseControlPlane: func(deadline time.Time) error {
+			return upgradeControlPlaneTo(d, cluster, deadline)
+		},
+		UpgradePhaseAddons: func(deadline time.Time) error {
+			if !d.Get(KeyUpgradeAddons).(bool) {
+				log.Printf("Skipping addons as %s is false", KeyUpgradeAddons)
+
+				return nil
+			}
+
+			for _, args := range upgradeAddonArgs(cluster.Name, toStrings(d.Get(KeyEKSAddons))) {
+				if err := runUpgradeCommand(d, cluster.Provider, deadline, args...); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		UpgradePhaseNodeGroups: func(deadline time.Time) error {
+			u := NodeGroupUpgrade{
+				Parallelism:  d.Get("parallelism").(int),
+				ForceUpgrade: d.Get("force_upgrade").(bool),
+			}
+
+			var tasks []func() error
+
+			for _, n := range nodeGroups {
+				n := n
+
+				tasks = append(tasks, func() error {
+					log.Printf("Upgrading nodegroup %s of cluster %s to %s", n, cluster.Name, cluster.Version)
+
+					cmd, err := newEksctlCommandWithAWSProfile(cluster, u.upgradeNodeGroupArgs(cluster, ClusterName(cluster.Name), n)...)
+					if err != nil {
+						return fmt.Errorf("creating eksctl-upgrade-nodegroup command: %w", err)
+					}
+
+					if _, err := runBefore(cmd, deadline); err != nil {
+						return fmt.Errorf("upgrading nodegroup %s: %w", n, err)
+					}
+
+					return nil
+				})
+			}
+
+			return runWithParallelism(u.Parallelism, tasks)
+		},
+		UpgradePhaseValidation: func(deadline time.Time) error {
+			return waitForUpgraded(cluster, nodeGroups, deadline)
+		},
+	}
+
+	var completed []string
+
+	for _, t := range upgradePhaseTimeouts {
+		timeout := time.Duration(d.Get(t.key).(int)) * time.Second
+
+		log.Printf("Running phase %s of upgrading cluster %s to %s with timeout %s", t.phase, cluster.Name, cluster.Version, timeout)
+
+		if err := phases[t.phase](time.Now().Add(timeout)); err != nil {
+			return fmt.Errorf("phase %s: %w", t.phase, err)
+		}
+
+		completed = append(completed, t.phase)
+
+		if err := d.Set(KeyCompletedPhases, completed); err != nil {
+			return fmt.Errorf("setting %s: %w", KeyCompletedPhases, err)
+		}
+	}
+
+	return nil
+}
+
+// upgradeControlPlaneTo upgrades the control plane by one minor version, or does nothing when it's already at the target.
+func upgradeControlPlaneTo(d Read, cluster *Cluster, deadline time.Time) error {
+	state, err := doRunGetCluster(d, cluster)
+	if err != nil {
+		return err
+	}
+
+	skew, err := minorVersionSkew(state.Version, cluster.Version)
+	if err != nil {
+		return err
+	}
+
+	switch skew {
+	case 0:
+		log.Printf("Control plane of cluster %s is already at %s", cluster.Name, cluster.Version)
+
+		return nil
+	case 1:
+		return runUpgradeCommand(d, cluster.Provider, deadline, "upgrade", "cluster", "--name", cluster.Name, "--version", cluster.Version, "--approve")
+	}
+
+	return fmt.Errorf("can't upgrade cluster %s from %s to %s: the control plane can only be upgraded by one minor version at a time", cluster.Name, state.Version, cluster.Version)
+}
+
+// minorVersionSkew returns the number of minor versions from current to target
+func minorVersionSkew(current, target string) (int, error) {
+	minor := func(v string) (int, error) {
+		parts := strings.Split(v, ".")
+		if len(parts) < 2 || parts[0] != "1" {
+			return 0, fmt.Errorf("unsupported Kubernetes version %q", v)
+		}
+
+		return strconv.Atoi(parts[1])
+	}
+
+	c, err := minor(current)
+	if err != nil {
+		return 0, err
+	}
+
+	t, err := minor(target)
+	if err != nil {
+		return 0, err
+	}
+
+	return t - c, nil
+}
+
+// upgradeAddonArgs returns the eksctl args for updating the default components not managed as EKS addons,
+// followed by the ones for updating the EKS addons to their latest versions.
+func upgradeAddonArgs(clusterName string, eksAddons []string) [][]string {
+	managed := map[string]bool{}
+
+	for _, a := range eksAddons {
+		managed[a] = true
+	}
+
+	var cmds [][]string
+
+	for _, u := range defaultAddonUtils {
+		if !managed[u.Addon] {
+			cmds = append(cmds, []string{"utils", u.Util, "--cluster", clusterName, "--approve"})
+		}
+	}
+
+	for _, a := range eksAddons {
+		cmds = append(cmds, []string{"update", "addon", "--cluster", clusterName, "--name", a, "--version", "latest"})
+	}
+
+	return cmds
+}
+
+func runUpgradeCommand(d Read, p *resource.ProviderConfig, deadline time.Time, args ...string) error {
+	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, p, args...)
+	if err != nil {
+		return fmt.Errorf("creating eksctl-%s command: %w", strings.Join(args[:2], "-"), err)
+	}
+
+	_, err = runBefore(cmd, deadline)
+
+	return err
+}
+
+// runBefore runs the command with the time left until the deadline of the phase as the timeout
+func runBefore(cmd *exec.Cmd, deadline time.Time) (*resource.CommandResult, error) {
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return nil, fmt.Errorf("running %s: %w", strings.Join(cmd.Args, " "), resource.ErrTimedOut)
+	}
+
+	return resource.RunWithTimeout(cmd, timeout)
+}
+
+// nodeGroupVersion is the version and the status of a managed nodegroup
+type nodeGroupVersion struct {
+	Name, Version, Status string
+}
+
+// waitForUpgraded waits until the control plane and the managed nodegroups are active at the target version
+func waitForUpgraded(cluster *Cluster, nodeGroups []string, deadline time.Time) error {
+	svc := eks.New(AWSSessionFromCluster(cluster))
+
+	for {
+		err := func() error {
+			c, err := svc.DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(cluster.Name)})
+			if err != nil {
+				return fmt.Errorf("describing cluster %s: %w", cluster.Name, err)
+			}
+
+			var ngs []nodeGroupVersion
+
+			for _, n := range nodeGroups {
+				r, err := svc.DescribeNodegroup(&eks.DescribeNodegroupInput{
+					ClusterName:   aws.String(cluster.Name),
+					NodegroupName: aws.String(n),
+				})
+				if err != nil {
+					return fmt.Errorf("describing nodegroup %s: %w", n, err)
+				}
+
+				ngs = append(ngs, nodeGroupVersion{
+					Name:    n,
+					Version: aws.StringValue(r.Nodegroup.Version),
+					Status:  aws.StringValue(r.Nodegroup.Status),
+				})
+			}
+
+			return checkUpgraded(cluster.Version, aws.StringValue(c.Cluster.Version), aws.StringValue(c.Cluster.Status), ngs)
+		}()
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(upgradeValidationInterval).After(deadline) {
+			return fmt.Errorf("%w: %v", resource.ErrTimedOut, err)
+		}
+
+		log.Printf("Waiting for cluster %s to be upgraded: %v", cluster.Name, err)
+
+		time.Sleep(upgradeValidationInterval)
+	}
+}
+
+func checkUpgraded(target, clusterVersion, clusterStatus string, nodeGroups []nodeGroupVersion) error {
+	if clusterVersion != target || clusterStatus != eks.ClusterStatusActive {
+		return fmt.Errorf("control plane is %s at %s", clusterStatus, clusterVersion)
+	}
+
+	for _, ng := range nodeGroups {
+		if ng.Version != target || ng.Status != eks.NodegroupStatusActive {
+			return fmt.Errorf("nodegroup %s is %s at %s", ng.Name, ng.Status, ng.Version)
+		}
+	}
+
+	return nil
+}
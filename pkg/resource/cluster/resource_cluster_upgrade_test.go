@@ -0,0 +1,37 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinorVersionSkew(t *testing.T) {
+	skew, err := minorVersionSkew("1.9", "1.10")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, skew)
+
+	skew, err = minorVersionSkew("1.17", "1.17")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, skew)
+
+	_, err = minorVersionSkew("", "1.17")
+	assert.EqualError(t, err, `unsupported Kubernetes version ""`)
+}
+
+func TestUpgradeAddonArgs(t *testing.T) {
+	assert.Equal(t, [][]string{
+		{"utils", "update-kube-proxy", "--cluster", "primary", "--approve"},
+		{"utils", "update-coredns", "--cluster", "primary", "--approve"},
+		{"update", "addon", "--cluster", "primary", "--name", "vpc-cni", "--version", "latest"},
+	}, upgradeAddonArgs("primary", []string{"vpc-cni"}))
+}
+
+func TestCheckUpgraded(t *testing.T) {
+	assert.NoError(t, checkUpgraded("1.17", "1.17", "ACTIVE", []nodeGroupVersion{{Name: "ng1", Version: "1.17", Status: "ACTIVE"}}))
+	assert.EqualError(t, checkUpgraded("1.17", "1.16", "UPDATING", nil), "control plane is UPDATING at 1.16")
+	assert.EqualError(t,
+		checkUpgraded("1.17", "1.17", "ACTIVE", []nodeGroupVersion{{Name: "ng1", Version: "1.16", Status: "UPDATING"}}),
+		"nodegroup ng1 is UPDATING at 1.16",
+	)
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"gopkg.in/yaml.v3"
 	"log"
+	"math"
+	"time"
 )
 
 func ResourceClusterDeployment() *schema.Resource {
@@ -51,18 +54,97 @@ func ResourceClusterDeployment() *schema.Resource {
 	m := &Manager{}
 
 	return &schema.Resource{
-		Create: func(d *schema.ResourceData, meta interface{}) error {
-			set, err := m.createCluster(d)
+		Create: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			defer notifyOperation(d, p, NotificationOperationCreate, time.Now(), &finalErr)
+
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
+			set, err := m.createCluster(d, p)
 			if err != nil {
 				return err
 			}
 
 			d.SetId(set.ClusterID)
+			d.Set(KeyClusterName, string(set.ClusterName))
+
+			if err := loadClusterAuth(d, set.Cluster, set.ClusterName); err != nil {
+				return fmt.Errorf("loading cluster auth: %w", err)
+			}
 
 			return nil
 		},
 		CustomizeDiff: func(d *schema.ResourceDiff, meta interface{}) error {
-			_, _ = m.readCluster(&DiffReadWrite{D: d})
+			p := resource.ProviderConfigFromMeta(meta)
+
+			if err := validateAWSRegionAndCredentials(d, p); err != nil {
+				return err
+			}
+
+			if err := resource.PlanAWSRegion(d, p); err != nil {
+				return err
+			}
+
+			if err := planIAMPreflight(d, p); err != nil {
+				return err
+			}
+
+			if err := validateSpecKeys(d); err != nil {
+				return err
+			}
+
+			if err := planSpecSource(d, p); err != nil {
+				return fmt.Errorf("diffing spec_source: %w", err)
+			}
+
+			if err := planSpecPatches(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeySpecPatches, err)
+			}
+
+			spec, err := parsePlannedSpec(d)
+			if err != nil {
+				return err
+			}
+
+			if err := validateInstanceTypeOfferings(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validateSubnetTags(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validatePrivateClusterEndpoints(d, p, spec); err != nil {
+				return err
+			}
+
+			if err := validateServiceRoleARN(d, p, spec); err != nil {
+				return err
+			}
+
+			if _, err := readNotifications(d.Get(KeyNotification)); err != nil {
+				return err
+			}
+
+			if err := planSpecChecksum(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeySpecChecksum, err)
+			}
+
+			if err := planTargetGroupSelector(&DiffReadWrite{D: d}, p); err != nil {
+				return fmt.Errorf("diffing target_group_selector: %w", err)
+			}
+
+			if err := planClusterNames(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeyClusterName, err)
+			}
+
+			if err := planRetainedClusters(d); err != nil {
+				return fmt.Errorf("diffing %s: %w", KeyRetainedClusters, err)
+			}
+
+			_, _ = m.readCluster(&DiffReadWrite{D: d}, p)
 
 			v := d.Get(KeyKubeconfigPath)
 
@@ -72,17 +154,24 @@ func ResourceClusterDeployment() *schema.Resource {
 				kp = v.(string)
 			}
 
-			if d.Id() == "" || kp == "" {
+			if (d.Id() == "" || kp == "") && !kubeconfigStateOnly(d) {
 				d.SetNewComputed(KeyKubeconfigPath)
 			}
 
 			return nil
 		},
-		Update: func(d *schema.ResourceData, meta interface{}) error {
+		Update: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			defer notifyOperation(d, p, NotificationOperationUpdate, time.Now(), &finalErr)
+
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
 			// TODO shift back 100% traffic to the current cluster before update so that you can use `terraform apply` to
 			// cancel previous canary deployment that hang in the middle of the process.
 
-			info, err := getLiveClusterInfo(d)
+			info, err := getLiveClusterInfo(d, p)
 			if err != nil {
 				return err
 			}
@@ -98,37 +187,77 @@ func ResourceClusterDeployment() *schema.Resource {
 			if k8sVerCurrent != k8sVerDesired || revisionCurrent != revisionDesired {
 				log.Printf("creating new cluster...")
 
-				set, err := m.createCluster(d)
+				set, err := m.createCluster(d, p)
+				if err != nil {
+					return err
+				}
+
+				shiftStart := time.Now()
+
+				err = graduallyShiftTraffic(set, set.CanaryOpts)
+
+				notify(d, p, NotificationOperationSwitchover, shiftStart, err)
+
 				if err != nil {
 					return err
 				}
 
-				if err := graduallyShiftTraffic(set, set.CanaryOpts); err != nil {
+				if err := m.retireCluster(d, set.Cluster); err != nil {
 					return err
 				}
 
-				if err := m.deleteCluster(d); err != nil {
+				setPreviousCluster(d, d.Id(), m.getClusterName(set.Cluster, d.Id()))
+
+				d.SetId(set.ClusterID)
+				d.Set(KeyClusterName, string(set.ClusterName))
+
+				if err := m.pruneRetainedClusters(d, p, false); err != nil {
 					return err
 				}
 
 				// TODO If requested, delete remaining stray clusters that didn't complete previous canary deployments
 
-				d.SetId(set.ClusterID)
+				if err := loadClusterAuth(d, set.Cluster, set.ClusterName); err != nil {
+					return fmt.Errorf("loading cluster auth: %w", err)
+				}
 
 				return nil
 			}
 
 			log.Printf("udapting existing cluster...")
 
-			_, err = m.updateCluster(d)
+			set, err := m.updateCluster(d, p)
 			if err != nil {
 				return err
 			}
 
+			if err := m.pruneRetainedClusters(d, p, false); err != nil {
+				return err
+			}
+
+			if err := loadClusterAuth(d, set.Cluster, set.ClusterName); err != nil {
+				return fmt.Errorf("loading cluster auth: %w", err)
+			}
+
 			return nil
 		},
-		Delete: func(d *schema.ResourceData, meta interface{}) error {
-			if err := m.deleteCluster(d); err != nil {
+		Delete: func(d *schema.ResourceData, meta interface{}) (finalErr error) {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			defer notifyOperation(d, p, NotificationOperationDelete, time.Now(), &finalErr)
+
+			t := resource.StartTranscript()
+			defer resource.SaveTranscript(t, d)
+
+			if err := checkDeletionProtection(d); err != nil {
+				return err
+			}
+
+			if err := m.deleteCluster(d, p); err != nil {
+				return err
+			}
+
+			if err := m.pruneRetainedClusters(d, p, true); err != nil {
 				return err
 			}
 
@@ -137,20 +266,33 @@ func ResourceClusterDeployment() *schema.Resource {
 			return nil
 		},
 		Read: func(d *schema.ResourceData, meta interface{}) error {
-			_, err := m.readCluster(d)
+			p := resource.ProviderConfigFromMeta(meta)
+
+			cluster, err := m.readCluster(d, p)
+			if err != nil {
+				return err
+			}
+
+			d.Set(KeyClusterName, string(m.getClusterName(cluster, d.Id())))
+
+			if err := loadClusterAuth(d, cluster, m.getClusterName(cluster, d.Id())); err != nil {
+				return fmt.Errorf("loading cluster auth: %w", err)
+			}
 
-			return err
+			return nil
 		},
-		Schema: map[string]*schema.Schema{
+		Schema: withSharedClusterSchema(map[string]*schema.Schema{
 			// "ForceNew" fields
 			//
 			// the provider does not support zero-downtime updates of these fields so they are set to `ForceNew`,
 			// which results recreating cluster without traffic management.
+			// region defaults to the region of the provider instance, and then AWS_REGION and AWS_DEFAULT_REGION.
+			// It is resolved on the plan for creating the cluster, and recorded in the state afterwards.
 			KeyRegion: {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				DefaultFunc: schema.EnvDefaultFunc("AWS_DEFAULT_REGION", nil),
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
 			},
 			KeyProfile: {
 				Type:     schema.TypeString,
@@ -209,15 +351,35 @@ func ResourceClusterDeployment() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			// cluster_name is the generated name of the current cluster, and previous_cluster_id and previous_cluster_name
+			// are the ones of the cluster it has switched over from in the last blue-green cluster deployment.
+			KeyClusterName: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyPreviousClusterID: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			KeyPreviousClusterName: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			// spec is the string containing the part of eksctl cluster.yaml
 			// Over time the provider adds HCL-native syntax for any of cluster.yaml items.
 			// Until then, this is the primary place you configure the cluster as you like.
 			KeySpec: {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentSpecDiff,
 				ValidateFunc: func(v interface{}, name string) ([]string, []error) {
 					s := v.(string)
 
+					// Templated specs are validated after being rendered with spec_vars
+					if isTemplatedSpec(s) {
+						return nil, nil
+					}
+
 					configForVaildation := EksctlClusterConfig{
 						Rest: map[string]interface{}{},
 					}
@@ -229,6 +391,10 @@ func ResourceClusterDeployment() *schema.Resource {
 						return nil, []error{fmt.Errorf("validating attribute \"spec\": vpc.id must not be set within the spec yaml. use \"vpc_id\" attribute instead, becaues the provider uses it for generating the final eksctl cluster config yaml")}
 					}
 
+					if err := validateOutpost(configForVaildation); err != nil {
+						return nil, []error{fmt.Errorf("validating attribute \"spec\": %w", err)}
+					}
+
 					return nil, nil
 				},
 			},
@@ -288,9 +454,11 @@ func ResourceClusterDeployment() *schema.Resource {
 				Optional:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						// node_group_name and node_port are required for target_type = "instance"
 						"node_group_name": {
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
+							Default:  "",
 						},
 						"weight": {
 							Type:     schema.TypeInt,
@@ -307,10 +475,33 @@ func ResourceClusterDeployment() *schema.Resource {
 						},
 						"node_port": {
 							Type:     schema.TypeInt,
-							Required: true,
+							Optional: true,
+							Default:  0,
+						},
+						// target_type = "ip" registers the IPs of the pods behind the service to the target group,
+						// via a TargetGroupBinding managed by aws_load_balancer_controller
+						"target_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      TargetTypeInstance,
+							ValidateFunc: validation.StringInSlice([]string{TargetTypeInstance, TargetTypeIP}, false),
+						},
+						// service_name and service_port are required for target_type = "ip"
+						"service_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"service_namespace": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "default",
+						},
+						"service_port": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  0,
 						},
-						// TODO Expose matching pods IPs via target group. Maybe require the provider to deploy a
-						// operator for that.
 						"pod_labels": {
 							Type:     schema.TypeMap,
 							Optional: true,
@@ -454,25 +645,230 @@ produces:
 				},
 			},
 			KeyMetrics: metrics,
-			KeyManifests: {
+			// The provider polls the desired target group(s) before and after shifting traffic, and fails the deployment
+			// while rolling back the traffic when less than `min_healthy_ratio` of the targets are reported healthy.
+			KeyTargetHealthCheck: {
+				Type:       schema.TypeList,
+				Optional:   true,
+				MaxItems:   1,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min_healthy_ratio": {
+							Type:         schema.TypeFloat,
+							Optional:     true,
+							Default:      1.0,
+							ValidateFunc: validation.FloatBetween(0, 1),
+						},
+						"timeout_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  300,
+						},
+						"interval_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  10,
+						},
+					},
+				},
+			},
+			// grpc_health_check calls grpc.health.v1.Health/Check on the new cluster's backend before each traffic-shift step
+			KeyGRPCHealthCheck: {
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"service": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"tls": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"timeout_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  5,
+						},
+					},
+				},
+			},
+			// prometheus_gate evaluates a PromQL query before each traffic-shift step, and pauses or rolls back the shift on violation.
+			// max and min default to the largest and the smallest float so that 0 can be used as a threshold.
+			KeyPrometheusGate: {
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"query": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"max": {
+							Type:     schema.TypeFloat,
+							Optional: true,
+							Default:  math.MaxFloat64,
+						},
+						"min": {
+							Type:     schema.TypeFloat,
+							Optional: true,
+							Default:  -math.MaxFloat64,
+						},
+						"on_violation": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      courier.PrometheusGateOnViolationRollback,
+							ValidateFunc: validation.StringInSlice([]string{courier.PrometheusGateOnViolationRollback, courier.PrometheusGateOnViolationPause}, false),
+						},
+						"pause_timeout_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  600,
+						},
+						"interval_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  10,
+						},
+					},
+				},
+			},
+			// steps is the list of weights of the new cluster at each traffic-shift step, like [5, 25, 50, 100]
+			KeySteps: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(1, 100),
+				},
+			},
+			// bake_duration is how long each traffic-shift step lasts before advancing to the next one, like "5m"
+			KeyBakeDuration: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			// approval pauses the traffic shift at `weight` until a file exists, an SSM parameter has the value, or an HTTP endpoint returns 200
+			KeyApproval: {
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"weight": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(1, 99),
+						},
+						"type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{courier.ApprovalTypeFile, courier.ApprovalTypeSSM, courier.ApprovalTypeHTTP}, false),
+						},
+						"file_path": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"ssm_parameter_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"ssm_parameter_value": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  courier.DefaultApprovalSSMParameterValue,
+						},
+						"url": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"timeout_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  3600,
+						},
+						"interval_sec": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  10,
+						},
+					},
+				},
+			},
+			// rollback_alarms are the names of existing CloudWatch alarms. The traffic is shifted back to the current cluster
+			// when any of them goes into ALARM during the traffic shift or within rollback_window after it.
+			KeyRollbackAlarms: {
 				Type:     schema.TypeList,
 				Optional: true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
-			KeyTargetGroupARNs: {
+			KeyRollbackWindow: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "5m",
+			},
+			// prewarm waits for HPAs in the new cluster to scale out and optionally sends warm-up requests
+			// to the new target groups before any traffic is shifted to the new cluster.
+			KeyPrewarm: prewarmSchema(),
+			// switchover_metrics publishes the traffic weight, the step number, and the health check results
+			// to CloudWatch under `namespace` during the traffic shift.
+			KeySwitchoverMetrics: {
+				Type:       schema.TypeList,
+				Optional:   true,
+				MaxItems:   1,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"namespace": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  courier.DefaultSwitchoverMetricsNamespace,
+						},
+						"dimensions": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			KeyManifests: {
 				Type:     schema.TypeList,
 				Optional: true,
-				Computed: true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			// See RevisionRetention for revision_retention and retained_clusters
+			KeyRevisionRetention: revisionRetentionSchema(),
+			KeyRetainedClusters:  retainedClustersSchema(),
+			// notification posts the results of create, update, delete, and switchover to SNS topics or webhooks like Slack
+			KeyNotification: notificationSchema(),
 			resource.KeyOutput: {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
-		},
+		}),
 	}
 }
@@ -0,0 +1,442 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	KeyCluster   = "cluster"
+	KeyNodeGroup = "nodegroup"
+	KeyLabels    = "labels"
+	KeyTaints    = "taints"
+)
+
+// NodeGroupLabelName is the node label eksctl adds to every node of both managed and unmanaged nodegroups
+const NodeGroupLabelName = "alpha.eksctl.io/nodegroup-name"
+
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+func (t Taint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// ResourceLabels manages labels and taints of an existing nodegroup in place,
+// so that operational labels and taints can be changed without replacing the nodegroup.
+//
+// Labels are managed with `eksctl set labels` and `eksctl unset labels`, so that they are persisted in the managed nodegroup
+// and applied to new nodes as well. Taints are managed with `kubectl taint` against the current nodes of the nodegroup.
+func ResourceLabels() *schema.Resource {
+	return &schema.Resource{
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			if err := applyNodeGroupLabels(d, resource.ProviderConfigFromMeta(meta), nil, nil); err != nil {
+				return err
+			}
+
+			d.SetId(fmt.Sprintf("%s/%s", d.Get(KeyCluster).(string), d.Get(KeyNodeGroup).(string)))
+
+			return nil
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			oldLabels, _ := d.GetChange(KeyLabels)
+			oldTaints, _ := d.GetChange(KeyTaints)
+
+			return applyNodeGroupLabels(d, resource.ProviderConfigFromMeta(meta), toStringMap(oldLabels), readTaints(oldTaints))
+		},
+		Delete: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readLabelsCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			if err := unsetNodeGroupLabels(d, cluster, sortedKeys(toStringMap(d.Get(KeyLabels)))); err != nil {
+				return err
+			}
+
+			return taintNodeGroup(d, cluster, nil, readTaints(d.Get(KeyTaints)))
+		},
+		CustomizeDiff: func(d *schema.ResourceDiff, meta interface{}) error {
+			return resource.PlanAWSRegion(d, resource.ProviderConfigFromMeta(meta))
+		},
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return readNodeGroupLabels(d, resource.ProviderConfigFromMeta(meta))
+		},
+		Schema: map[string]*schema.Schema{
+			KeyCluster: {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			KeyNodeGroup: {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			KeyRegion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+			KeyProfile: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyBin: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "eksctl",
+			},
+			KeyEksctlVersion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyKubectlBin: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "kubectl",
+			},
+			KeyLabels: {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			KeyTaints: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"effect": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"NoSchedule", "PreferNoSchedule", "NoExecute"}, false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func readLabelsCluster(d Read, p *resource.ProviderConfig) *Cluster {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	return &Cluster{
+		Name:          d.Get(KeyCluster).(string),
+		Region:        region,
+		Profile:       profile,
+		Provider:      p,
+		EksctlBin:     d.Get(KeyBin).(string),
+		EksctlVersion: d.Get(KeyEksctlVersion).(string),
+		KubectlBin:    d.Get(KeyKubectlBin).(string),
+	}
+}
+
+func readTaints(v interface{}) []Taint {
+	var taints []Taint
+
+	if v == nil {
+		return taints
+	}
+
+	for _, t := range v.([]interface{}) {
+		m := t.(map[string]interface{})
+
+		taints = append(taints, Taint{
+			Key:    m["key"].(string),
+			Value:  m["value"].(string),
+			Effect: m["effect"].(string),
+		})
+	}
+
+	return taints
+}
+
+func toStringMap(v interface{}) map[string]string {
+	m := map[string]string{}
+
+	if v == nil {
+		return m
+	}
+
+	for k, v := range v.(map[string]interface{}) {
+		m[k] = v.(string)
+	}
+
+	return m
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// applyNodeGroupLabels sets the desired labels and taints, and removes the ones that were previously managed but no longer desired.
+func applyNodeGroupLabels(d *schema.ResourceData, p *resource.ProviderConfig, oldLabels map[string]string, oldTaints []Taint) error {
+	cluster := readLabelsCluster(d, p)
+
+	labels := toStringMap(d.Get(KeyLabels))
+
+	var removedLabels []string
+
+	for k := range oldLabels {
+		if _, ok := labels[k]; !ok {
+			removedLabels = append(removedLabels, k)
+		}
+	}
+
+	sort.Strings(removedLabels)
+
+	if err := unsetNodeGroupLabels(d, cluster, removedLabels); err != nil {
+		return err
+	}
+
+	if len(labels) > 0 {
+		var kvs []string
+
+		for _, k := range sortedKeys(labels) {
+			kvs = append(kvs, k+"="+labels[k])
+		}
+
+		cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, "set", "labels", "--cluster", cluster.Name, "--nodegroup", d.Get(KeyNodeGroup).(string), "--labels", strings.Join(kvs, ","))
+		if err != nil {
+			return fmt.Errorf("creating eksctl-set-labels command: %w", err)
+		}
+
+		if _, err := resource.Run(cmd); err != nil {
+			return fmt.Errorf("setting labels on nodegroup %s: %w", d.Get(KeyNodeGroup).(string), err)
+		}
+	}
+
+	return taintNodeGroup(d, cluster, readTaints(d.Get(KeyTaints)), oldTaints)
+}
+
+func unsetNodeGroupLabels(d Read, cluster *Cluster, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, "unset", "labels", "--cluster", cluster.Name, "--nodegroup", d.Get(KeyNodeGroup).(string), "--labels", strings.Join(keys, ","))
+	if err != nil {
+		return fmt.Errorf("creating eksctl-unset-labels command: %w", err)
+	}
+
+	if _, err := resource.Run(cmd); err != nil {
+		return fmt.Errorf("unsetting labels on nodegroup %s: %w", d.Get(KeyNodeGroup).(string), err)
+	}
+
+	return nil
+}
+
+// taintNodeGroup taints all the current nodes of the nodegroup with the desired taints, and removes the previously managed taints that are no longer desired.
+func taintNodeGroup(d Read, cluster *Cluster, desired, old []Taint) error {
+	args := []string{"taint", "nodes", "-l", NodeGroupLabelName + "=" + d.Get(KeyNodeGroup).(string), "--overwrite"}
+	numFlags := len(args)
+
+	keep := map[string]bool{}
+
+	for _, t := range desired {
+		args = append(args, t.String())
+		keep[t.Key+":"+t.Effect] = true
+	}
+
+	for _, t := range old {
+		if !keep[t.Key+":"+t.Effect] {
+			args = append(args, t.Key+":"+t.Effect+"-")
+		}
+	}
+
+	if len(args) == numFlags {
+		return nil
+	}
+
+	return withKubeconfig(cluster, func(kubeconfigPath string) error {
+		cmd, err := newKubectlCommand(cluster, kubeconfigPath, args...)
+		if err != nil {
+			return err
+		}
+
+		if _, err := resource.Run(cmd); err != nil {
+			return fmt.Errorf("tainting nodes of nodegroup %s: %w", d.Get(KeyNodeGroup).(string), err)
+		}
+
+		return nil
+	})
+}
+
+func withKubeconfig(cluster *Cluster, f func(string) error) error {
+	kubeconfigPath, err := writeTempKubeconfig(cluster, ClusterName(cluster.Name))
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig: %w", err)
+	}
+	defer cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	return f(kubeconfigPath)
+}
+
+// readNodeGroupLabels detects drift in the managed labels and taints.
+// Labels and taints that aren't managed by this resource, like the ones in the cluster.yaml, are ignored.
+func readNodeGroupLabels(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	cluster := readLabelsCluster(d, p)
+	nodegroup := d.Get(KeyNodeGroup).(string)
+
+	cmd, err := newEksctlCommandFromResourceWithRegionAndProfile(d, cluster.Provider, "get", "labels", "--cluster", cluster.Name, "--nodegroup", nodegroup, "-o", "json")
+	if err != nil {
+		return fmt.Errorf("creating eksctl-get-labels command: %w", err)
+	}
+
+	run, err := resource.Run(cmd)
+	if err != nil {
+		return fmt.Errorf("getting labels of nodegroup %s: %w", nodegroup, err)
+	}
+
+	remoteLabels, err := parseEksctlGetLabelsOutput(run.Stdout, nodegroup)
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]interface{}{}
+
+	for k := range toStringMap(d.Get(KeyLabels)) {
+		if v, ok := remoteLabels[k]; ok {
+			labels[k] = v
+		}
+	}
+
+	if err := d.Set(KeyLabels, labels); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyLabels, err)
+	}
+
+	managed := readTaints(d.Get(KeyTaints))
+	if len(managed) == 0 {
+		return nil
+	}
+
+	var nodes string
+
+	if err := withKubeconfig(cluster, func(kubeconfigPath string) error {
+		cmd, err := newKubectlCommand(cluster, kubeconfigPath, "get", "nodes", "-l", NodeGroupLabelName+"="+nodegroup, "-o", "json")
+		if err != nil {
+			return err
+		}
+
+		run, err := resource.Run(cmd)
+		if err != nil {
+			return fmt.Errorf("getting nodes of nodegroup %s: %w", nodegroup, err)
+		}
+
+		nodes = run.Stdout
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	remoteTaints, err := parseNodeTaints(nodes)
+	if err != nil {
+		return err
+	}
+
+	var taints []interface{}
+
+	for _, t := range managed {
+		if !remoteTaints[t] {
+			log.Printf("Taint %s is missing on one or more nodes of nodegroup %s", t, nodegroup)
+			continue
+		}
+
+		taints = append(taints, map[string]interface{}{
+			"key":    t.Key,
+			"value":  t.Value,
+			"effect": t.Effect,
+		})
+	}
+
+	if err := d.Set(KeyTaints, taints); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyTaints, err)
+	}
+
+	return nil
+}
+
+func parseEksctlGetLabelsOutput(output, nodegroup string) (map[string]string, error) {
+	var summaries []struct {
+		NodeGroup string            `json:"NodeGroup"`
+		Labels    map[string]string `json:"Labels"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &summaries); err != nil {
+		return nil, fmt.Errorf("parsing eksctl get labels output: %w", err)
+	}
+
+	for _, s := range summaries {
+		if s.NodeGroup == nodegroup {
+			return s.Labels, nil
+		}
+	}
+
+	return map[string]string{}, nil
+}
+
+// parseNodeTaints parses `kubectl get nodes -o json` and returns the taints that exist on all the nodes.
+func parseNodeTaints(output string) (map[Taint]bool, error) {
+	var list struct {
+		Items []struct {
+			Spec struct {
+				Taints []Taint `json:"taints"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("parsing kubectl get nodes output: %w", err)
+	}
+
+	counts := map[Taint]int{}
+
+	for _, n := range list.Items {
+		for _, t := range n.Spec.Taints {
+			counts[t]++
+		}
+	}
+
+	taints := map[Taint]bool{}
+
+	for t, c := range counts {
+		if c == len(list.Items) {
+			taints[t] = true
+		}
+	}
+
+	return taints, nil
+}
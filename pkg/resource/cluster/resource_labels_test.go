@@ -0,0 +1,32 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEksctlGetLabelsOutput(t *testing.T) {
+	labels, err := parseEksctlGetLabelsOutput(`[
+  {"Cluster": "prod", "NodeGroup": "ng1", "Labels": {"team": "a", "alpha.eksctl.io/cluster-name": "prod"}},
+  {"Cluster": "prod", "NodeGroup": "ng2", "Labels": {"team": "b"}}
+]`, "ng2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "b"}, labels)
+}
+
+func TestParseNodeTaints(t *testing.T) {
+	taints, err := parseNodeTaints(`{"items": [
+  {"spec": {"taints": [{"key": "dedicated", "value": "gpu", "effect": "NoSchedule"}, {"key": "spot", "effect": "PreferNoSchedule"}]}},
+  {"spec": {"taints": [{"key": "dedicated", "value": "gpu", "effect": "NoSchedule"}]}}
+]}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[Taint]bool{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}: true}, taints)
+}
+
+func TestTaintString(t *testing.T) {
+	assert.Equal(t, "dedicated=gpu:NoSchedule", Taint{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}.String())
+	assert.Equal(t, "spot:PreferNoSchedule", Taint{Key: "spot", Effect: "PreferNoSchedule"}.String())
+}
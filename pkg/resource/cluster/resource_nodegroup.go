@@ -0,0 +1,210 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const KeyNodeGroupNames = "nodegroup_names"
+
+// ResourceNodeGroup manages nodegroups of an existing cluster outside of the cluster's spec,
+// which is the only way to add nodegroups to an `eksctl_cluster` with `manage_nodegroups = false`.
+//
+// Any change to the spec replaces the nodegroups, as eksctl can't update nodegroups in place.
+// Refer to the cluster by its name, so that Terraform creates the nodegroups after the control plane,
+// and deletes them before the control plane.
+func ResourceNodeGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readNodeGroupCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			config, names, err := renderNodeGroupClusterConfig(cluster.Name, cluster.Region, d.Get(KeySpec).(string))
+			if err != nil {
+				return err
+			}
+
+			cmd, err := newEksctlCommandWithAWSProfile(cluster, "create", "nodegroup", "-f", "-")
+			if err != nil {
+				return fmt.Errorf("creating eksctl-create-nodegroup command: %w", err)
+			}
+
+			cmd.Stdin = bytes.NewReader(config)
+
+			if err := resource.Create(cmd, d, ""); err != nil {
+				return fmt.Errorf("running `eksctl create nodegroup`: %w: USED CLUSTER CONFIG:\n%s", err, string(config))
+			}
+
+			d.SetId(fmt.Sprintf("%s/%s", cluster.Name, strings.Join(names, ",")))
+			d.Set(KeyNodeGroupNames, names)
+
+			return nil
+		},
+		Delete: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readNodeGroupCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			config, _, err := renderNodeGroupClusterConfig(cluster.Name, cluster.Region, d.Get(KeySpec).(string))
+			if err != nil {
+				return err
+			}
+
+			args := append([]string{"delete", "nodegroup", "-f", "-", "--approve", "--wait"}, cluster.NodeGroupDrain.deleteNodeGroupArgs()...)
+
+			cmd, err := newEksctlCommandWithAWSProfile(cluster, args...)
+			if err != nil {
+				return fmt.Errorf("creating eksctl-delete-nodegroup command: %w", err)
+			}
+
+			cmd.Stdin = bytes.NewReader(config)
+
+			return resource.Delete(cmd, d)
+		},
+		// Everything but how eksctl is run and how nodes are drained on deletion forces a new resource,
+		// so there's nothing to do on update other than persisting the new settings.
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			return nil
+		},
+		CustomizeDiff: func(d *schema.ResourceDiff, meta interface{}) error {
+			return resource.PlanAWSRegion(d, resource.ProviderConfigFromMeta(meta))
+		},
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			cluster := readNodeGroupCluster(d, resource.ProviderConfigFromMeta(meta))
+
+			summaries, err := runGetNodeGroups(d, cluster, ClusterName(cluster.Name))
+			if err != nil {
+				return err
+			}
+
+			existing := map[string]bool{}
+
+			for _, s := range summaries {
+				existing[s.Name] = true
+			}
+
+			for _, name := range d.Get(KeyNodeGroupNames).([]interface{}) {
+				if existing[name.(string)] {
+					return nil
+				}
+			}
+
+			log.Printf("[INFO] none of the nodegroups %s exist in cluster %s. Removing from state", d.Id(), cluster.Name)
+
+			d.SetId("")
+
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			KeyCluster: {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			KeyRegion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+			KeyProfile: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			KeyBin: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "eksctl",
+			},
+			KeyEksctlVersion: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			// spec is the part of cluster.yaml that contains `nodeGroups` and/or `managedNodeGroups`.
+			// `metadata` is generated from `cluster` and `region`.
+			KeySpec: {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			KeyNodeGroupDrain: nodeGroupDrainSchema(),
+			KeyNodeGroupNames: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			resource.KeyOutput: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func readNodeGroupCluster(d Read, p *resource.ProviderConfig) *Cluster {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	return &Cluster{
+		Name:           d.Get(KeyCluster).(string),
+		Region:         region,
+		Profile:        profile,
+		Provider:       p,
+		EksctlBin:      d.Get(KeyBin).(string),
+		EksctlVersion:  d.Get(KeyEksctlVersion).(string),
+		NodeGroupDrain: readNodeGroupDrain(d.Get(KeyNodeGroupDrain)),
+	}
+}
+
+// renderNodeGroupClusterConfig returns the cluster.yaml for `eksctl create nodegroup` and `eksctl delete nodegroup`,
+// along with the names of the nodegroups in the spec.
+func renderNodeGroupClusterConfig(clusterName, region, spec string) ([]byte, []string, error) {
+	config := map[string]interface{}{}
+
+	if err := yaml.Unmarshal([]byte(spec), &config); err != nil {
+		return nil, nil, fmt.Errorf("parsing nodegroup spec: %w", err)
+	}
+
+	var names []string
+
+	for _, key := range []string{"nodeGroups", "managedNodeGroups"} {
+		ngs, _ := config[key].([]interface{})
+
+		for i, ng := range ngs {
+			m, _ := ng.(map[string]interface{})
+
+			name, _ := m["name"].(string)
+			if name == "" {
+				return nil, nil, fmt.Errorf("%s[%d] in nodegroup spec: name is required", key, i)
+			}
+
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("nodegroup spec must contain at least one of nodeGroups and managedNodeGroups")
+	}
+
+	config["apiVersion"] = DefaultAPIVersion
+	config["kind"] = "ClusterConfig"
+	config["metadata"] = map[string]interface{}{
+		"name":   clusterName,
+		"region": region,
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(config); err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), names, nil
+}
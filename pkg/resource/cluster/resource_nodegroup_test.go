@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderNodeGroupClusterConfig(t *testing.T) {
+	config, names, err := renderNodeGroupClusterConfig("primary", "us-east-2", `
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+managedNodeGroups:
+- name: mng1
+  desiredCapacity: 2
+metadata:
+  name: ignored
+`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ng1", "mng1"}, names)
+	assert.Equal(t, `apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+managedNodeGroups:
+  - desiredCapacity: 2
+    name: mng1
+metadata:
+  name: primary
+  region: us-east-2
+nodeGroups:
+  - instanceType: m5.large
+    name: ng1
+`, string(config))
+
+	_, _, err = renderNodeGroupClusterConfig("primary", "us-east-2", `
+iam:
+  withOIDC: true
+`)
+	assert.Error(t, err)
+
+	_, _, err = renderNodeGroupClusterConfig("primary", "us-east-2", `
+nodeGroups:
+- instanceType: m5.large
+`)
+	assert.EqualError(t, err, "nodeGroups[0] in nodegroup spec: name is required")
+}
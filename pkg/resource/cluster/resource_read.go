@@ -3,17 +3,26 @@ package cluster
 import (
 	"fmt"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"time"
 )
 
-func ReadCluster(d Read) (*Cluster, error) {
+func ReadCluster(d Read, p *resource.ProviderConfig) (*Cluster, error) {
 	a := Cluster{}
 	a.EksctlBin = d.Get(KeyBin).(string)
 	a.EksctlVersion = d.Get(KeyEksctlVersion).(string)
 	a.KubectlBin = d.Get(KeyKubectlBin).(string)
 	a.Name = d.Get(KeyName).(string)
-	a.Region = d.Get(KeyRegion).(string)
-	a.Profile = d.Get(KeyProfile).(string)
-	a.Spec = d.Get(KeySpec).(string)
+	a.Region, a.Profile = resource.GetAWSRegionAndProfile(d, p)
+	a.AssumeRoles = resource.GetAssumeRoles(d)
+	a.Provider = p
+
+	spec, err := getSpec(d)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Spec = spec
 
 	a.APIVersion = d.Get(KeyAPIVersion).(string)
 	// For migration from older version of the provider that didn't had api_version attribute
@@ -29,6 +38,10 @@ func ReadCluster(d Read) (*Cluster, error) {
 
 	a.VPCID = d.Get(KeyVPCID).(string)
 
+	if v, ok := d.Get(KeyServiceRoleARN).(string); ok {
+		a.ServiceRoleARN = v
+	}
+
 	if v := d.Get(KeyPodsReadinessCheck); v != nil {
 		rawCheckPodsReadiness := v.([]interface{})
 		for _, r := range rawCheckPodsReadiness {
@@ -66,6 +79,23 @@ func ReadCluster(d Read) (*Cluster, error) {
 		}
 	}
 
+	if v := d.Get(KeyCreateHooks); v != nil {
+		a.CreateHooks = readHooks(v)
+	}
+
+	if v := d.Get(KeyDestroyHooks); v != nil {
+		a.DestroyHooks = readHooks(v)
+	}
+
+	if v := d.Get(KeyBackup); v != nil {
+		b, err := readBackup(v)
+		if err != nil {
+			return nil, err
+		}
+
+		a.Backup = b
+	}
+
 	if v := d.Get(KeyALBAttachment); v != nil {
 		albAttachments := v.([]interface{})
 		for _, r := range albAttachments {
@@ -90,19 +120,23 @@ func ReadCluster(d Read) (*Cluster, error) {
 			}
 
 			t := courier.ALBAttachment{
-				NodeGroupName: m["node_group_name"].(string),
-				Weght:         m["weight"].(int),
-				ListenerARN:   r.ListenerARN,
-				Protocol:      m["protocol"].(string),
-				NodePort:      m["node_port"].(int),
-				Priority:      r.Priority,
-				Hosts:         r.Hosts,
-				PathPatterns:  r.PathPatterns,
-				Methods:       r.Methods,
-				SourceIPs:     r.SourceIPs,
-				Headers:       r.Headers,
-				QueryStrings:  r.QueryStrings,
-				Metrics:       metrics,
+				NodeGroupName:    m["node_group_name"].(string),
+				Weght:            m["weight"].(int),
+				ListenerARN:      r.ListenerARN,
+				Protocol:         m["protocol"].(string),
+				NodePort:         m["node_port"].(int),
+				TargetType:       m["target_type"].(string),
+				ServiceName:      m["service_name"].(string),
+				ServiceNamespace: m["service_namespace"].(string),
+				ServicePort:      m["service_port"].(int),
+				Priority:         r.Priority,
+				Hosts:            r.Hosts,
+				PathPatterns:     r.PathPatterns,
+				Methods:          r.Methods,
+				SourceIPs:        r.SourceIPs,
+				Headers:          r.Headers,
+				QueryStrings:     r.QueryStrings,
+				Metrics:          metrics,
 			}
 
 			a.ALBAttachments = append(a.ALBAttachments, t)
@@ -134,6 +168,157 @@ func ReadCluster(d Read) (*Cluster, error) {
 		}
 	}
 
+	if v := d.Get(KeyTargetHealthCheck); v != nil {
+		for _, r := range v.([]interface{}) {
+			m := r.(map[string]interface{})
+
+			a.TargetHealthCheck = &courier.TargetHealthCheck{
+				MinHealthyRatio: m["min_healthy_ratio"].(float64),
+				Timeout:         time.Duration(m["timeout_sec"].(int)) * time.Second,
+				Interval:        time.Duration(m["interval_sec"].(int)) * time.Second,
+			}
+		}
+	}
+
+	if v := d.Get(KeyGRPCHealthCheck); v != nil {
+		a.GRPCHealthChecks = courier.LoadGRPCHealthChecks(v.([]interface{}))
+	}
+
+	if v := d.Get(KeyPrometheusGate); v != nil {
+		a.PrometheusGates = courier.LoadPrometheusGates(v.([]interface{}))
+	}
+
+	if v := d.Get(KeySteps); v != nil {
+		for _, w := range v.([]interface{}) {
+			a.Steps = append(a.Steps, w.(int))
+		}
+	}
+
+	if v, _ := d.Get(KeyBakeDuration).(string); v != "" {
+		bake, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s %q: %w", KeyBakeDuration, v, err)
+		}
+
+		a.BakeDuration = bake
+	}
+
+	if v := d.Get(KeyApproval); v != nil {
+		a.Approvals = courier.LoadApprovals(v.([]interface{}), a.Region, a.Profile)
+
+		for i := range a.Approvals {
+			a.Approvals[i].AssumeRoles = a.AssumeRoles
+			a.Approvals[i].AWS = p.AWSConfig()
+		}
+	}
+
+	if v := d.Get(KeyRollbackAlarms); v != nil && len(v.([]interface{})) > 0 {
+		r := &courier.AlarmRollback{
+			Region:      a.Region,
+			Profile:     a.Profile,
+			AssumeRoles: a.AssumeRoles,
+			AWS:         p.AWSConfig(),
+		}
+
+		for _, name := range v.([]interface{}) {
+			r.AlarmNames = append(r.AlarmNames, name.(string))
+		}
+
+		window, err := time.ParseDuration(d.Get(KeyRollbackWindow).(string))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", KeyRollbackWindow, err)
+		}
+
+		r.Window = window
+
+		a.AlarmRollback = r
+	}
+
+	if v := d.Get(KeySwitchoverMetrics); v != nil {
+		a.SwitchoverMetrics = courier.LoadSwitchoverMetrics(v.([]interface{}), a.Region, a.Profile)
+
+		if a.SwitchoverMetrics != nil {
+			a.SwitchoverMetrics.AssumeRoles = a.AssumeRoles
+			a.SwitchoverMetrics.AWS = p.AWSConfig()
+		}
+	}
+
+	if v := d.Get(KeyPrewarm); v != nil {
+		a.Prewarm = readPrewarm(v)
+	}
+
+	if v := d.Get(KeyAutoscaler); v != nil {
+		a.Autoscaler = readAutoscaler(v)
+	}
+
+	if v := d.Get(KeyAWSLoadBalancerController); v != nil {
+		a.AWSLoadBalancerController = readAWSLoadBalancerController(v)
+	}
+
+	a.InstallNvidiaPlugin, a.InstallNeuronPlugin = true, true
+
+	if v, ok := d.Get(KeyInstallNvidiaPlugin).(bool); ok {
+		a.InstallNvidiaPlugin = v
+	}
+
+	if v, ok := d.Get(KeyInstallNeuronPlugin).(bool); ok {
+		a.InstallNeuronPlugin = v
+	}
+
+	if v, ok := d.Get(KeyMaxCreateRetries).(int); ok {
+		a.MaxCreateRetries = v
+	}
+
+	if v, ok := d.Get(KeyUpgradeAddons).(bool); ok {
+		a.UpgradeAddons = v
+	}
+
+	if v, ok := d.Get(KeyServerSideApply).(bool); ok {
+		a.ServerSideApply = v
+	}
+
+	if v := d.Get(KeyNodeGroupUpgrade); v != nil {
+		a.NodeGroupUpgrade = readNodeGroupUpgrade(v)
+	}
+
+	if v, ok := d.Get(KeyAMIDrift).(string); ok {
+		a.AMIDrift = v
+	}
+
+	if v, ok := d.Get(KeySubnetTagging).(string); ok {
+		a.SubnetTagging = v
+	}
+
+	if v := d.Get(KeyConnection); v != nil {
+		a.Connection = readConnection(v)
+	}
+
+	if v := d.Get(KeyNodeGroupBlueGreen); v != nil {
+		a.NodeGroupBlueGreen = readNodeGroupBlueGreen(v)
+	}
+
+	if v, ok := d.Get(KeyCleanupOrphanedResources).(bool); ok {
+		a.CleanupOrphanedResources = v
+	}
+
+	a.ManageNodeGroups = true
+
+	if v := d.Get(KeyManageNodeGroups); v != nil {
+		a.ManageNodeGroups = v.(bool)
+	}
+
+	a.ManageAWSAuth = true
+
+	if v := d.Get(KeyManageAWSAuth); v != nil {
+		a.ManageAWSAuth = v.(bool)
+	}
+
+	a.NodeGroupDrain = defaultNodeGroupDrain()
+
+	if v := d.Get(KeyNodeGroupDrain); v != nil {
+		a.NodeGroupDrain = readNodeGroupDrain(v)
+	}
+
 	fmt.Printf("Read Cluster:\n%+v", a)
 
 	return &a, nil
@@ -0,0 +1,278 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	KeyRevisionRetention = "revision_retention"
+	KeyRetainedClusters  = "retained_clusters"
+)
+
+// RevisionRetention configures how long the previous clusters of a cluster_deployment are kept after switchover.
+// A retained cluster serves no traffic, but it can be brought back instantly by shifting the traffic back to it
+// during the bake period.
+type RevisionRetention struct {
+	// Keep is the maximum number of previous clusters to retain
+	Keep int
+	// DeleteAfter is how long a previous cluster is retained after the switchover
+	DeleteAfter time.Duration
+}
+
+// RetainedCluster is a previous cluster that is retained after switchover
+type RetainedCluster struct {
+	ID        string
+	Name      string
+	RetiredAt time.Time
+}
+
+func revisionRetentionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"keep": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"delete_after": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "72h",
+					ValidateFunc: validateDuration,
+				},
+			},
+		},
+	}
+}
+
+func retainedClustersSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"retired_at": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", k, err)}
+	}
+
+	return nil, nil
+}
+
+func readRevisionRetention(d Read) (*RevisionRetention, error) {
+	v := d.Get(KeyRevisionRetention)
+	if v == nil {
+		return nil, nil
+	}
+
+	rs := v.([]interface{})
+	if len(rs) == 0 {
+		return nil, nil
+	}
+
+	r := &RevisionRetention{
+		Keep:        1,
+		DeleteAfter: 72 * time.Hour,
+	}
+
+	if rs[0] == nil {
+		return r, nil
+	}
+
+	m := rs[0].(map[string]interface{})
+
+	r.Keep = m["keep"].(int)
+
+	deleteAfter, err := time.ParseDuration(m["delete_after"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s.delete_after: %w", KeyRevisionRetention, err)
+	}
+
+	r.DeleteAfter = deleteAfter
+
+	return r, nil
+}
+
+func readRetainedClusters(d Read) ([]RetainedCluster, error) {
+	var retained []RetainedCluster
+
+	v := d.Get(KeyRetainedClusters)
+	if v == nil {
+		return nil, nil
+	}
+
+	for _, item := range v.([]interface{}) {
+		m := item.(map[string]interface{})
+
+		retiredAt, err := time.Parse(time.RFC3339, m["retired_at"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s.retired_at: %w", KeyRetainedClusters, err)
+		}
+
+		retained = append(retained, RetainedCluster{
+			ID:        m["id"].(string),
+			Name:      m["name"].(string),
+			RetiredAt: retiredAt,
+		})
+	}
+
+	return retained, nil
+}
+
+func setRetainedClusters(d *schema.ResourceData, retained []RetainedCluster) error {
+	var vs []interface{}
+
+	for _, r := range retained {
+		vs = append(vs, map[string]interface{}{
+			"id":         r.ID,
+			"name":       r.Name,
+			"retired_at": r.RetiredAt.Format(time.RFC3339),
+		})
+	}
+
+	if err := d.Set(KeyRetainedClusters, vs); err != nil {
+		return fmt.Errorf("setting %s: %w", KeyRetainedClusters, err)
+	}
+
+	return nil
+}
+
+// partitionRetainedClusters splits the retained clusters, ordered from the oldest, into the ones to be deleted
+// for exceeding either the number of clusters to keep or the retention period, and the ones to be kept.
+func partitionRetainedClusters(retained []RetainedCluster, retention *RevisionRetention, now time.Time) ([]RetainedCluster, []RetainedCluster) {
+	var expired, kept []RetainedCluster
+
+	keep := 0
+	if retention != nil {
+		keep = retention.Keep
+	}
+
+	for i, r := range retained {
+		overflowed := len(retained)-i > keep
+		outdated := retention == nil || !now.Before(r.RetiredAt.Add(retention.DeleteAfter))
+
+		if overflowed || outdated {
+			expired = append(expired, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+
+	return expired, kept
+}
+
+// retireCluster either retains the current cluster of the deployment or deletes it, depending on revision_retention.
+// It must be called before the resource ID is updated to the new cluster's.
+func (m *Manager) retireCluster(d *schema.ResourceData, cluster *Cluster) error {
+	retention, err := readRevisionRetention(d)
+	if err != nil {
+		return err
+	}
+
+	if retention == nil {
+		return m.deleteCluster(d, cluster.Provider)
+	}
+
+	retained, err := readRetainedClusters(d)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	log.Printf("[DEBUG] retaining previous eksctl cluster with id %q", id)
+
+	retained = append(retained, RetainedCluster{
+		ID:        id,
+		Name:      string(m.getClusterName(cluster, id)),
+		RetiredAt: time.Now().UTC(),
+	})
+
+	return setRetainedClusters(d, retained)
+}
+
+// pruneRetainedClusters deletes the retained clusters that exceeded revision_retention.
+// All the retained clusters are deleted when revision_retention is removed or all is true.
+func (m *Manager) pruneRetainedClusters(d *schema.ResourceData, p *resource.ProviderConfig, all bool) error {
+	retention, err := readRevisionRetention(d)
+	if err != nil {
+		return err
+	}
+
+	if all {
+		retention = nil
+	}
+
+	retained, err := readRetainedClusters(d)
+	if err != nil {
+		return err
+	}
+
+	expired, kept := partitionRetainedClusters(retained, retention, time.Now())
+
+	for i, r := range expired {
+		log.Printf("[DEBUG] deleting retained eksctl cluster %q retired at %s", r.Name, r.RetiredAt)
+
+		if err := m.deleteClusterWithID(d, p, r.ID); err != nil {
+			if setErr := setRetainedClusters(d, append(expired[i:], kept...)); setErr != nil {
+				log.Printf("[WARN] %v", setErr)
+			}
+
+			return fmt.Errorf("deleting retained cluster %s: %w", r.Name, err)
+		}
+	}
+
+	return setRetainedClusters(d, kept)
+}
+
+// planRetainedClusters marks the retained clusters as changed when any of them is due to deletion,
+// so that the deletion happens on the next apply.
+func planRetainedClusters(d *schema.ResourceDiff) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	retention, err := readRevisionRetention(d)
+	if err != nil {
+		return err
+	}
+
+	retained, err := readRetainedClusters(d)
+	if err != nil {
+		return err
+	}
+
+	if expired, _ := partitionRetainedClusters(retained, retention, time.Now()); len(expired) > 0 {
+		return d.SetNewComputed(KeyRetainedClusters)
+	}
+
+	return nil
+}
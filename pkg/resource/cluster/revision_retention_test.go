@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionRetainedClusters(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	retained := []RetainedCluster{
+		{ID: "a", RetiredAt: now.Add(-80 * time.Hour)},
+		{ID: "b", RetiredAt: now.Add(-10 * time.Hour)},
+		{ID: "c", RetiredAt: now.Add(-1 * time.Hour)},
+	}
+
+	ids := func(rs []RetainedCluster) []string {
+		var ids []string
+		for _, r := range rs {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	testcases := []struct {
+		name      string
+		retention *RevisionRetention
+		expired   []string
+		kept      []string
+	}{
+		{
+			name:      "keep 1",
+			retention: &RevisionRetention{Keep: 1, DeleteAfter: 72 * time.Hour},
+			expired:   []string{"a", "b"},
+			kept:      []string{"c"},
+		},
+		{
+			name:      "keep 3",
+			retention: &RevisionRetention{Keep: 3, DeleteAfter: 72 * time.Hour},
+			expired:   []string{"a"},
+			kept:      []string{"b", "c"},
+		},
+		{
+			name:      "short retention",
+			retention: &RevisionRetention{Keep: 3, DeleteAfter: time.Hour},
+			expired:   []string{"a", "b", "c"},
+		},
+		{
+			name:    "retention removed",
+			expired: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			expired, kept := partitionRetainedClusters(retained, tc.retention, now)
+
+			assert.Equal(t, tc.expired, ids(expired))
+			assert.Equal(t, tc.kept, ids(kept))
+		})
+	}
+}
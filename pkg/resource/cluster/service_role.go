@@ -0,0 +1,199 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const KeyServiceRoleARN = "service_role_arn"
+
+const eksServicePrincipal = "eks.amazonaws.com"
+
+var serviceRoleARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/(.+/)?([^/]+)$`)
+
+func serviceRoleARNSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		Default:      "",
+		ValidateFunc: validation.StringMatch(regexp.MustCompile(`^$|`+serviceRoleARNPattern.String()), "must be the ARN of an IAM role"),
+	}
+}
+
+// setServiceRoleARN sets `iam.serviceRoleARN` in the cluster.yaml, so that eksctl uses the pre-created role
+// instead of creating one.
+func setServiceRoleARN(spec map[string]interface{}, arn string) error {
+	if arn == "" {
+		return nil
+	}
+
+	if _, ok := spec["iam"]; !ok {
+		spec["iam"] = map[string]interface{}{}
+	}
+
+	var iamConfig map[string]interface{}
+
+	switch v := spec["iam"].(type) {
+	case map[string]interface{}:
+		iamConfig = v
+	case map[interface{}]interface{}:
+		iamConfig = map[string]interface{}{}
+
+		for k, vv := range v {
+			iamConfig[fmt.Sprintf("%v", k)] = vv
+		}
+
+		spec["iam"] = iamConfig
+	default:
+		return fmt.Errorf("bug: failed to set iam.serviceRoleARN in cluster.yaml: type = %T, value = %v", v, v)
+	}
+
+	if existing, _ := iamConfig["serviceRoleARN"].(string); existing != "" && existing != arn {
+		return fmt.Errorf("%s %q conflicts with iam.serviceRoleARN %q in the spec", KeyServiceRoleARN, arn, existing)
+	}
+
+	iamConfig["serviceRoleARN"] = arn
+
+	return nil
+}
+
+// validateServiceRoleARN validates on plan that the service role exists and is assumable by EKS,
+// so that a typo or a missing trust policy doesn't fail `eksctl create cluster` after its CloudFormation stack is created.
+// The validation is skipped with a warning when the caller isn't allowed to get the role.
+// spec is the one returned by parsePlannedSpec.
+func validateServiceRoleARN(d Read, p *resource.ProviderConfig, spec *yaml.Node) error {
+	arn, _ := d.Get(KeyServiceRoleARN).(string)
+	if arn == "" {
+		return nil
+	}
+
+	if spec != nil {
+		m := map[string]interface{}{}
+
+		if err := spec.Decode(&m); err != nil {
+			return fmt.Errorf("parsing cluster.yaml: %w", err)
+		}
+
+		if err := setServiceRoleARN(m, arn); err != nil {
+			return err
+		}
+	}
+
+	match := serviceRoleARNPattern.FindStringSubmatch(arn)
+	if match == nil {
+		return fmt.Errorf("%s %q is not the ARN of an IAM role", KeyServiceRoleARN, arn)
+	}
+
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	_, err := remoteReadCache.getOrLoad(remoteReadCacheKey(remoteReadCacheAccount(profile, resource.GetAssumeRoles(d)), region, "", "service-role/"+arn), func() (interface{}, error) {
+		r, err := iam.New(resource.AWSSessionFromResourceData(d, p)).GetRole(&iam.GetRoleInput{RoleName: aws.String(match[2])})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+				return nil, fmt.Errorf("validating %s: role %q does not exist", KeyServiceRoleARN, arn)
+			}
+
+			log.Printf("[WARN] skipping validation of %s %q: %v", KeyServiceRoleARN, arn, err)
+
+			return nil, nil
+		}
+
+		ok, err := trustsService(aws.StringValue(r.Role.AssumeRolePolicyDocument), eksServicePrincipal)
+		if err != nil {
+			return nil, fmt.Errorf("validating %s: %w", KeyServiceRoleARN, err)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("validating %s: the trust policy of role %q doesn't allow %s to assume it", KeyServiceRoleARN, arn, eksServicePrincipal)
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// trustsService returns true when the URL-encoded trust policy document returned by iam:GetRole
+// allows the service principal to assume the role.
+func trustsService(doc, service string) (bool, error) {
+	decoded, err := url.QueryUnescape(doc)
+	if err != nil {
+		return false, fmt.Errorf("decoding trust policy: %w", err)
+	}
+
+	var policy struct {
+		Statement []struct {
+			Effect    string
+			Principal struct {
+				Service interface{}
+			}
+			Action interface{}
+		}
+	}
+
+	if err := json.Unmarshal([]byte(decoded), &policy); err != nil {
+		return false, fmt.Errorf("parsing trust policy: %w", err)
+	}
+
+	for _, s := range policy.Statement {
+		if s.Effect != "Allow" || !containsString(s.Action, "sts:AssumeRole") {
+			continue
+		}
+
+		if containsString(s.Principal.Service, service) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// containsString returns true when v, which is either a string or a list of strings in IAM policies, contains s
+func containsString(v interface{}, s string) bool {
+	switch t := v.(type) {
+	case string:
+		return t == s
+	case []interface{}:
+		for _, i := range t {
+			if str, _ := i.(string); str == s {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// loadServiceRoleARN refreshes `service_role_arn` from the cluster, so that a cluster recreated outside of Terraform
+// with another role shows up in the plan. It's left as is when unset, as the role is then managed by eksctl.
+func loadServiceRoleARN(d ReadWrite, cluster *Cluster) error {
+	if cluster.ServiceRoleARN == "" {
+		return nil
+	}
+
+	state, err := runGetCluster(d, cluster)
+	if err != nil {
+		return fmt.Errorf("reading cluster service role: %w", err)
+	}
+
+	if state.RoleArn != "" && !strings.EqualFold(state.RoleArn, cluster.ServiceRoleARN) {
+		log.Printf("[INFO] service role of cluster %s drifted from %s to %s", cluster.Name, cluster.ServiceRoleARN, state.RoleArn)
+
+		d.Set(KeyServiceRoleARN, state.RoleArn)
+	}
+
+	return nil
+}
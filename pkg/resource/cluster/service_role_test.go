@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSetServiceRoleARN(t *testing.T) {
+	arn := "arn:aws:iam::123456789012:role/eks-service-role"
+
+	spec := map[string]interface{}{}
+	assert.NoError(t, setServiceRoleARN(spec, arn))
+	assert.Equal(t, map[string]interface{}{"iam": map[string]interface{}{"serviceRoleARN": arn}}, spec)
+
+	spec = map[string]interface{}{"iam": map[string]interface{}{"withOIDC": true, "serviceRoleARN": arn}}
+	assert.NoError(t, setServiceRoleARN(spec, arn))
+	assert.Equal(t, map[string]interface{}{"iam": map[string]interface{}{"withOIDC": true, "serviceRoleARN": arn}}, spec)
+
+	spec = map[string]interface{}{"iam": map[string]interface{}{"serviceRoleARN": "arn:aws:iam::123456789012:role/other"}}
+	assert.Error(t, setServiceRoleARN(spec, arn))
+
+	spec = map[string]interface{}{}
+	assert.NoError(t, setServiceRoleARN(spec, ""))
+	assert.Equal(t, map[string]interface{}{}, spec)
+}
+
+func TestValidateServiceRoleARN_conflict(t *testing.T) {
+	var spec yaml.Node
+
+	require.NoError(t, yaml.Unmarshal([]byte(`iam:
+  serviceRoleARN: arn:aws:iam::123456789012:role/other
+`), &spec))
+
+	d := mapRead{KeyServiceRoleARN: "arn:aws:iam::123456789012:role/eks-service-role"}
+
+	// The conflict is detected in the spec parsed on plan, before calling IAM
+	assert.EqualError(t, validateServiceRoleARN(d, nil, &spec), `service_role_arn "arn:aws:iam::123456789012:role/eks-service-role" conflicts with iam.serviceRoleARN "arn:aws:iam::123456789012:role/other" in the spec`)
+
+	assert.NoError(t, validateServiceRoleARN(mapRead{KeyServiceRoleARN: ""}, nil, &spec))
+}
+
+func TestServiceRoleARNPattern(t *testing.T) {
+	m := serviceRoleARNPattern.FindStringSubmatch("arn:aws:iam::123456789012:role/path/to/eks-service-role")
+	if assert.NotNil(t, m) {
+		assert.Equal(t, "eks-service-role", m[2])
+	}
+
+	assert.NotNil(t, serviceRoleARNPattern.FindStringSubmatch("arn:aws-cn:iam::123456789012:role/eks"))
+	assert.Nil(t, serviceRoleARNPattern.FindStringSubmatch("arn:aws:iam::123456789012:user/eks"))
+}
+
+func TestTrustsService(t *testing.T) {
+	trusted := url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":["ec2.amazonaws.com","eks.amazonaws.com"]},"Action":"sts:AssumeRole"}]}`)
+
+	ok, err := trustsService(trusted, eksServicePrincipal)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	untrusted := url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":["sts:AssumeRole"]}]}`)
+
+	ok, err = trustsService(untrusted, eksServicePrincipal)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// KeySpecChecksum is the SHA256 of the normalized cluster.yaml that was last applied successfully
+const KeySpecChecksum = "spec_checksum"
+
+// specChecksum returns the hex-encoded SHA256 of the normalized cluster config,
+// so that reformatting the spec doesn't change the checksum.
+func specChecksum(clusterConfig []byte) string {
+	sum := sha256.Sum256([]byte(normalizeSpec(string(clusterConfig))))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// specChecksumChanged returns true when the cluster config differs from the one applied in the last successful create or update.
+// States stored before the checksum was introduced are always treated as changed.
+func specChecksumChanged(d *schema.ResourceData, clusterConfig []byte) bool {
+	applied, _ := d.GetChange(KeySpecChecksum)
+
+	a, _ := applied.(string)
+
+	return a == "" || a != specChecksum(clusterConfig)
+}
+
+func setSpecChecksum(d *schema.ResourceData, clusterConfig []byte) error {
+	if err := d.Set(KeySpecChecksum, specChecksum(clusterConfig)); err != nil {
+		return fmt.Errorf("setting %s: %w", KeySpecChecksum, err)
+	}
+
+	return nil
+}
+
+// planSpecChecksum marks the checksum as unknown when any input to the cluster config is changed.
+// The checksum can't be computed on plan, as rendering the cluster config requires the values known only on apply.
+func planSpecChecksum(d *schema.ResourceDiff) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	for _, k := range []string{KeyVersion, KeyVPCID, KeySpecVars, KeySpecJSON, KeySpecSourceCommit} {
+		if d.HasChange(k) {
+			return d.SetNewComputed(KeySpecChecksum)
+		}
+	}
+
+	if specChanged(d) {
+		return d.SetNewComputed(KeySpecChecksum)
+	}
+
+	return nil
+}
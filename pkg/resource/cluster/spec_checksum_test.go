@@ -0,0 +1,17 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecChecksum(t *testing.T) {
+	a := specChecksum([]byte("metadata:\n  name: primary\n  region: us-east-2\n"))
+	b := specChecksum([]byte("metadata:\n    region: us-east-2\n    name: primary\n"))
+	c := specChecksum([]byte("metadata:\n  name: primary\n  region: us-west-2\n"))
+
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
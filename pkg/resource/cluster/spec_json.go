@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+func specJSONSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{KeySpec, KeySpecs, KeySpecSource},
+		ValidateFunc:  validation.ValidateJsonString,
+		StateFunc: func(v interface{}) string {
+			s, _ := structure.NormalizeJsonString(v)
+			return s
+		},
+	}
+}
+
+// specJSONToYAML converts the cluster spec given as JSON to the YAML that eksctl expects.
+func specJSONToYAML(s string) (string, error) {
+	var v map[string]interface{}
+
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", KeySpecJSON, err)
+	}
+
+	bs, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("converting %s to yaml: %w", KeySpecJSON, err)
+	}
+
+	return string(bs), nil
+}
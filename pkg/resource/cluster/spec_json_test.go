@@ -0,0 +1,27 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSpec_specJSON(t *testing.T) {
+	spec, err := getSpec(mapRead{
+		KeySpec:     "",
+		KeySpecJSON: `{"apiVersion":"eksctl.io/v1alpha5","kind":"ClusterConfig","nodeGroups":[{"name":"ng1"}]}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+nodeGroups:
+  - name: ng1
+`, spec)
+}
+
+func TestSpecJSONToYAML_invalid(t *testing.T) {
+	_, err := specJSONToYAML(`{"kind":`)
+
+	assert.EqualError(t, err, "parsing spec_json: unexpected end of JSON input")
+}
@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// KeySpecs is the ordered list of cluster.yaml documents deep-merged into the spec
+const KeySpecs = "specs"
+
+func specsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		ConflictsWith: []string{KeySpec, KeySpecJSON, KeySpecSource},
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+}
+
+func readSpecs(d Read) []string {
+	v := d.Get(KeySpecs)
+	if v == nil {
+		return nil
+	}
+
+	var specs []string
+
+	for _, s := range v.([]interface{}) {
+		if s == nil {
+			continue
+		}
+
+		specs = append(specs, s.(string))
+	}
+
+	return specs
+}
+
+// mergeSpecs renders each of the specs with spec_vars and deep-merges them in order, so that later specs win.
+func mergeSpecs(specs []string, vars map[string]string) (string, error) {
+	var merged map[string]interface{}
+
+	for i, spec := range specs {
+		rendered, err := renderSpec(spec, vars)
+		if err != nil {
+			return "", fmt.Errorf("rendering %s.%d: %w", KeySpecs, i, err)
+		}
+
+		var m map[string]interface{}
+
+		if err := yaml.Unmarshal([]byte(rendered), &m); err != nil {
+			return "", fmt.Errorf("parsing %s.%d: %w", KeySpecs, i, err)
+		}
+
+		if merged == nil {
+			merged = m
+		} else if m != nil {
+			merged = mergeSpecMaps(merged, m)
+		}
+	}
+
+	if merged == nil {
+		return "", nil
+	}
+
+	bs, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("marshaling merged %s: %w", KeySpecs, err)
+	}
+
+	return string(bs), nil
+}
+
+// mergeSpecMaps deep-merges overlay into base.
+// A null in the overlay removes the field, and lists of named items like nodeGroups are merged by name.
+// Any other value in the overlay replaces the one in the base.
+func mergeSpecMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	for k, o := range overlay {
+		if o == nil {
+			delete(base, k)
+
+			continue
+		}
+
+		base[k] = mergeSpecValues(base[k], o)
+	}
+
+	return base
+}
+
+func mergeSpecValues(base, overlay interface{}) interface{} {
+	switch o := overlay.(type) {
+	case map[string]interface{}:
+		if b, ok := base.(map[string]interface{}); ok {
+			return mergeSpecMaps(b, o)
+		}
+	case []interface{}:
+		if b, ok := base.([]interface{}); ok && isNamedList(b) && isNamedList(o) {
+			return mergeNamedLists(b, o)
+		}
+	}
+
+	return overlay
+}
+
+func isNamedList(items []interface{}) bool {
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		if _, ok := m["name"].(string); !ok {
+			return false
+		}
+	}
+
+	return len(items) > 0
+}
+
+// mergeNamedLists merges the items with the same name, keeping the order in the base.
+// Items only in the overlay are appended.
+func mergeNamedLists(base, overlay []interface{}) []interface{} {
+	index := map[string]int{}
+
+	for i, item := range base {
+		index[item.(map[string]interface{})["name"].(string)] = i
+	}
+
+	for _, item := range overlay {
+		m := item.(map[string]interface{})
+
+		if i, ok := index[m["name"].(string)]; ok {
+			base[i] = mergeSpecMaps(base[i].(map[string]interface{}), m)
+		} else {
+			base = append(base, m)
+		}
+	}
+
+	return base
+}
@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSpecs(t *testing.T) {
+	base := `
+iam:
+  withOIDC: true
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+  desiredCapacity: 1
+- name: ng2
+  instanceType: m5.large
+cloudWatch:
+  clusterLogging:
+    enableTypes: ["audit"]
+`
+
+	overlay := `
+nodeGroups:
+- name: ng1
+  desiredCapacity: {{ .capacity }}
+- name: ng3
+  instanceType: c5.large
+cloudWatch: null
+`
+
+	merged, err := mergeSpecs([]string{base, overlay}, map[string]string{"capacity": "3"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `iam:
+    withOIDC: true
+nodeGroups:
+  - desiredCapacity: 3
+    instanceType: m5.large
+    name: ng1
+  - instanceType: m5.large
+    name: ng2
+  - instanceType: c5.large
+    name: ng3
+`, merged)
+}
+
+func TestMergeSpecs_replacesUnnamedLists(t *testing.T) {
+	merged, err := mergeSpecs([]string{
+		"availabilityZones: [us-east-2a, us-east-2b]\n",
+		"availabilityZones: [us-east-2c]\n",
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "availabilityZones:\n  - us-east-2c\n", merged)
+}
+
+func TestMergeSpecs_invalid(t *testing.T) {
+	_, err := mergeSpecs([]string{"iam: {}\n", "- foo\n"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// eksctlNodeGroupDefaults are the values eksctl fills for unset nodegroup fields.
+// They are filled on normalization so that making them explicit doesn't result in a diff.
+// Defaults that vary across eksctl versions, like volumeType and amiFamily, are deliberately left out.
+var eksctlNodeGroupDefaults = map[string]map[string]interface{}{
+	"nodeGroups": {
+		"volumeSize": 80,
+	},
+	"managedNodeGroups": {
+		"volumeSize": 80,
+	},
+}
+
+// normalizeSpec returns the canonical rendering of the spec, with sorted keys and eksctl defaults filled.
+// Templated or unparsable specs are returned as-is, so that they are validated and rendered later.
+func normalizeSpec(spec string) string {
+	if isTemplatedSpec(spec) || strings.TrimSpace(spec) == "" {
+		return spec
+	}
+
+	var m map[string]interface{}
+
+	if err := yaml.Unmarshal([]byte(spec), &m); err != nil || m == nil {
+		return spec
+	}
+
+	for key, defaults := range eksctlNodeGroupDefaults {
+		ngs, ok := m[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, ng := range ngs {
+			ngMap, ok := ng.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			// eksctl doesn't allow volume settings when a launch template is specified
+			if _, ok := ngMap["launchTemplate"]; ok {
+				continue
+			}
+
+			for k, v := range defaults {
+				if _, ok := ngMap[k]; !ok {
+					ngMap[k] = v
+				}
+			}
+		}
+	}
+
+	// yaml.v3 sorts map keys on marshaling
+	bs, err := yaml.Marshal(m)
+	if err != nil {
+		return spec
+	}
+
+	return string(bs)
+}
+
+// suppressEquivalentSpecDiff suppresses diffs between specs that are the same after normalization.
+// The spec itself is stored and passed to eksctl as-is.
+func suppressEquivalentSpecDiff(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeSpec(old) == normalizeSpec(new)
+}
@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalizeSpec(t *testing.T) {
+	implicit := `
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+iam:
+  withOIDC: true
+`
+
+	explicit := `
+iam:
+  withOIDC: true
+nodeGroups:
+- instanceType: m5.large
+  name: ng1
+  volumeSize: 80
+`
+
+	assert.Equal(t, normalizeSpec(explicit), normalizeSpec(implicit))
+	assert.NotContains(t, normalizeSpec(implicit), "amiFamily")
+	assert.NotContains(t, normalizeSpec(implicit), "volumeType")
+	assert.NotEqual(t, normalizeSpec(explicit), normalizeSpec(`
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+  volumeSize: 100
+iam:
+  withOIDC: true
+`))
+}
+
+func TestNormalizeSpec_templated(t *testing.T) {
+	spec := "nodeGroups:\n- name: {{ .ng }}\n"
+
+	assert.Equal(t, spec, normalizeSpec(spec))
+}
+
+func TestNormalizeSpec_launchTemplate(t *testing.T) {
+	normalized := normalizeSpec(`
+managedNodeGroups:
+- name: ng1
+  launchTemplate:
+    id: lt-1
+`)
+
+	assert.Contains(t, normalized, "lt-1")
+	assert.NotContains(t, normalized, "volumeSize")
+}
@@ -0,0 +1,373 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KeySpecPatches is the list of patches applied to the spec in order
+	KeySpecPatches = "spec_patches"
+	// KeyPatchedSpec is the spec with the patches applied, computed on plan so that the result shows up in the diff
+	KeyPatchedSpec = "patched_spec"
+
+	// SpecPatchTypeJSON6902 is a list of RFC 6902 JSON patch operations
+	SpecPatchTypeJSON6902 = "json6902"
+	// SpecPatchTypeStrategicMerge is a partial cluster.yaml merged into the spec, with named items like nodeGroups merged by name
+	SpecPatchTypeStrategicMerge = "strategic_merge"
+)
+
+type SpecPatch struct {
+	Type  string
+	Patch string
+}
+
+func specPatchesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      SpecPatchTypeStrategicMerge,
+					ValidateFunc: validation.StringInSlice([]string{SpecPatchTypeJSON6902, SpecPatchTypeStrategicMerge}, false),
+				},
+				// patch is either YAML or JSON
+				"patch": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+		},
+	}
+}
+
+func readSpecPatches(d Read) []SpecPatch {
+	v := d.Get(KeySpecPatches)
+	if v == nil {
+		return nil
+	}
+
+	var patches []SpecPatch
+
+	for _, p := range v.([]interface{}) {
+		if p == nil {
+			continue
+		}
+
+		m := p.(map[string]interface{})
+
+		patches = append(patches, SpecPatch{
+			Type:  m["type"].(string),
+			Patch: m["patch"].(string),
+		})
+	}
+
+	return patches
+}
+
+// planSpecPatches renders the patched spec on plan, so that `terraform plan` shows what is passed to eksctl.
+func planSpecPatches(d *schema.ResourceDiff) error {
+	if len(readSpecPatches(d)) == 0 {
+		return d.SetNew(KeyPatchedSpec, "")
+	}
+
+	for _, k := range []string{KeySpec, KeySpecs, KeySpecJSON, KeySpecVars, KeySpecPatches} {
+		if !d.NewValueKnown(k) {
+			return d.SetNewComputed(KeyPatchedSpec)
+		}
+	}
+
+	spec, err := getSpec(d)
+	if err != nil {
+		return err
+	}
+
+	return d.SetNew(KeyPatchedSpec, spec)
+}
+
+// applySpecPatches applies the patches to the spec in order.
+func applySpecPatches(spec string, patches []SpecPatch) (string, error) {
+	if len(patches) == 0 {
+		return spec, nil
+	}
+
+	var doc interface{} = map[string]interface{}{}
+
+	if err := yaml.Unmarshal([]byte(spec), &doc); err != nil {
+		return "", fmt.Errorf("parsing spec to patch: %w", err)
+	}
+
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	for i, p := range patches {
+		var err error
+
+		switch p.Type {
+		case SpecPatchTypeJSON6902:
+			doc, err = applyJSON6902(doc, p.Patch)
+		case SpecPatchTypeStrategicMerge:
+			doc, err = applyStrategicMerge(doc, p.Patch)
+		default:
+			err = fmt.Errorf("unsupported patch type %q", p.Type)
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("applying %s.%d: %w", KeySpecPatches, i, err)
+		}
+	}
+
+	bs, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("marshaling patched spec: %w", err)
+	}
+
+	return string(bs), nil
+}
+
+func applyStrategicMerge(doc interface{}, patch string) (interface{}, error) {
+	var overlay map[string]interface{}
+
+	if err := yaml.Unmarshal([]byte(patch), &overlay); err != nil {
+		return nil, fmt.Errorf("parsing patch: %w", err)
+	}
+
+	base, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("spec must be a map, but was %T", doc)
+	}
+
+	return mergeSpecMaps(base, overlay), nil
+}
+
+type jsonPatchOp struct {
+	Op    string      `yaml:"op"`
+	Path  string      `yaml:"path"`
+	From  string      `yaml:"from"`
+	Value interface{} `yaml:"value"`
+}
+
+func applyJSON6902(doc interface{}, patch string) (interface{}, error) {
+	var ops []jsonPatchOp
+
+	if err := yaml.Unmarshal([]byte(patch), &ops); err != nil {
+		return nil, fmt.Errorf("parsing patch: %w", err)
+	}
+
+	for _, op := range ops {
+		var err error
+
+		doc, err = applyJSONPatchOp(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func applyJSONPatchOp(doc interface{}, op jsonPatchOp) (interface{}, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "remove":
+		doc, _, err = mutateAt(doc, path, op.Op, op.Value)
+
+		return doc, err
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var v interface{}
+
+		if op.Op == "move" {
+			doc, v, err = mutateAt(doc, from, "remove", nil)
+		} else {
+			v, err = getAt(doc, from)
+			if err == nil {
+				v, err = deepCopy(v)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("from %s: %w", op.From, err)
+		}
+
+		doc, _, err = mutateAt(doc, path, "add", v)
+
+		return doc, err
+	case "test":
+		v, err := getAt(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(v, op.Value) {
+			return nil, fmt.Errorf("test failed: expected %v, got %v", op.Value, v)
+		}
+
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unsupported op %q", op.Op)
+}
+
+// parseJSONPointer parses the RFC 6901 JSON pointer into the reference tokens
+func parseJSONPointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid path %q: must start with /", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+func getAt(doc interface{}, path []string) (interface{}, error) {
+	for _, t := range path {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			v, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", t)
+			}
+
+			doc = v
+		case []interface{}:
+			i, err := strconv.Atoi(t)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, fmt.Errorf("index %q out of range", t)
+			}
+
+			doc = c[i]
+		default:
+			return nil, fmt.Errorf("%q not found in %T", t, doc)
+		}
+	}
+
+	return doc, nil
+}
+
+// mutateAt adds, replaces, or removes the value at the path, and returns the updated doc and the previous value.
+// The doc is returned as it may be a new slice after adding or removing an item.
+func mutateAt(doc interface{}, path []string, op string, value interface{}) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		if op == "remove" {
+			return nil, doc, nil
+		}
+
+		return value, doc, nil
+	}
+
+	t, rest := path[0], path[1:]
+
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		child, ok := c[t]
+		if !ok && (len(rest) > 0 || op != "add") {
+			return nil, nil, fmt.Errorf("%q not found", t)
+		}
+
+		if len(rest) == 0 {
+			if op == "remove" {
+				delete(c, t)
+			} else {
+				c[t] = value
+			}
+
+			return c, child, nil
+		}
+
+		updated, prev, err := mutateAt(child, rest, op, value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c[t] = updated
+
+		return c, prev, nil
+	case []interface{}:
+		if len(rest) == 0 && op == "add" && t == "-" {
+			return append(c, value), nil, nil
+		}
+
+		max := len(c)
+		if len(rest) == 0 && op == "add" {
+			max++
+		}
+
+		i, err := strconv.Atoi(t)
+		if err != nil || i < 0 || i >= max {
+			return nil, nil, fmt.Errorf("index %q out of range", t)
+		}
+
+		if len(rest) == 0 {
+			switch op {
+			case "add":
+				c = append(c, nil)
+				copy(c[i+1:], c[i:])
+				c[i] = value
+
+				return c, nil, nil
+			case "remove":
+				prev := c[i]
+
+				return append(c[:i], c[i+1:]...), prev, nil
+			default:
+				prev := c[i]
+				c[i] = value
+
+				return c, prev, nil
+			}
+		}
+
+		updated, prev, err := mutateAt(c[i], rest, op, value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c[i] = updated
+
+		return c, prev, nil
+	}
+
+	return nil, nil, fmt.Errorf("%q not found in %T", t, doc)
+}
+
+func deepCopy(v interface{}) (interface{}, error) {
+	bs, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied interface{}
+
+	if err := yaml.Unmarshal(bs, &copied); err != nil {
+		return nil, err
+	}
+
+	return copied, nil
+}
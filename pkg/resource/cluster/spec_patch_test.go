@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const specToPatch = `
+nodeGroups:
+- name: ng1
+  instanceType: m5.large
+  desiredCapacity: 1
+  labels:
+    role/app: "true"
+`
+
+func TestApplySpecPatches_json6902(t *testing.T) {
+	patched, err := applySpecPatches(specToPatch, []SpecPatch{
+		{
+			Type: SpecPatchTypeJSON6902,
+			Patch: `
+- op: test
+  path: /nodeGroups/0/name
+  value: ng1
+- op: replace
+  path: /nodeGroups/0/desiredCapacity
+  value: 3
+- op: remove
+  path: /nodeGroups/0/labels/role~1app
+- op: copy
+  from: /nodeGroups/0
+  path: /nodeGroups/-
+- op: replace
+  path: /nodeGroups/1/name
+  value: ng2
+- op: add
+  path: /iam
+  value: {withOIDC: true}
+`,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `iam:
+    withOIDC: true
+nodeGroups:
+  - desiredCapacity: 3
+    instanceType: m5.large
+    labels: {}
+    name: ng1
+  - desiredCapacity: 3
+    instanceType: m5.large
+    labels: {}
+    name: ng2
+`, patched)
+}
+
+func TestApplySpecPatches_strategicMerge(t *testing.T) {
+	patched, err := applySpecPatches(specToPatch, []SpecPatch{
+		{
+			Type:  SpecPatchTypeStrategicMerge,
+			Patch: `{"nodeGroups": [{"name": "ng1", "instanceType": "c5.large", "labels": null}]}`,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `nodeGroups:
+  - desiredCapacity: 1
+    instanceType: c5.large
+    name: ng1
+`, patched)
+}
+
+func TestApplySpecPatches_errors(t *testing.T) {
+	_, err := applySpecPatches(specToPatch, []SpecPatch{
+		{Type: SpecPatchTypeJSON6902, Patch: `[{"op": "test", "path": "/nodeGroups/0/name", "value": "ng2"}]`},
+	})
+	assert.EqualError(t, err, "applying spec_patches.0: test /nodeGroups/0/name: test failed: expected ng2, got ng1")
+
+	_, err = applySpecPatches(specToPatch, []SpecPatch{
+		{Type: SpecPatchTypeJSON6902, Patch: `[{"op": "replace", "path": "/nodeGroups/1/name", "value": "ng2"}]`},
+	})
+	assert.EqualError(t, err, `applying spec_patches.0: replace /nodeGroups/1/name: index "1" out of range`)
+}
@@ -0,0 +1,287 @@
+package cluster
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitSpecSource locates the cluster spec within a Git repository
+type GitSpecSource struct {
+	URL  string
+	Ref  string
+	Path string
+}
+
+func specSourceSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{KeySpec, KeySpecJSON, KeySpecs},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"git": {
+					Type:     schema.TypeList,
+					Required: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"url": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"ref": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "HEAD",
+							},
+							"path": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func readSpecSource(d Read) *GitSpecSource {
+	v := d.Get(KeySpecSource)
+	if v == nil {
+		return nil
+	}
+
+	sources := v.([]interface{})
+	if len(sources) == 0 || sources[0] == nil {
+		return nil
+	}
+
+	gits := sources[0].(map[string]interface{})["git"].([]interface{})
+	if len(gits) == 0 || gits[0] == nil {
+		return nil
+	}
+
+	m := gits[0].(map[string]interface{})
+
+	return &GitSpecSource{
+		URL:  m["url"].(string),
+		Ref:  m["ref"].(string),
+		Path: m["path"].(string),
+	}
+}
+
+// getSpec returns the user-provided cluster spec, rendered with `spec_vars`.
+// When `spec_source` is set, the spec is the one fetched on plan and persisted to `resolved_spec`.
+// When `spec_json` is set, the spec is the JSON converted to YAML.
+// When `specs` is set, the spec is the result of merging them in order.
+// The typed blocks like `nodegroup` are rendered into the spec, and then `spec_patches` are applied.
+func getSpec(d Read) (string, error) {
+	spec, err := getUnpatchedSpec(d)
+	if err != nil {
+		return "", err
+	}
+
+	return applySpecPatches(spec, readSpecPatches(d))
+}
+
+// parsePlannedSpec returns the spec rendered and parsed once on plan, so that the validators don't redo it on their own.
+// The spec is nil when it's empty, like when it's unknown until apply.
+func parsePlannedSpec(d Read) (*yaml.Node, error) {
+	spec, err := getSpec(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var n yaml.Node
+
+	if err := yaml.Unmarshal([]byte(spec), &n); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	if n.Kind == 0 {
+		return nil, nil
+	}
+
+	return &n, nil
+}
+
+func getUnpatchedSpec(d Read) (string, error) {
+	if specs := readSpecs(d); len(specs) > 0 {
+		merged, err := mergeSpecs(specs, readSpecVars(d))
+		if err != nil {
+			return "", err
+		}
+
+		return mergeTypedSpec(merged, d)
+	}
+
+	var spec string
+
+	if readSpecSource(d) != nil {
+		if v := d.Get(KeyResolvedSpec); v != nil {
+			spec = v.(string)
+		}
+	} else if v := d.Get(KeySpecJSON); v != nil && v.(string) != "" {
+		s, err := specJSONToYAML(v.(string))
+		if err != nil {
+			return "", err
+		}
+
+		spec = s
+	} else {
+		spec = d.Get(KeySpec).(string)
+	}
+
+	rendered, err := renderSpec(spec, readSpecVars(d))
+	if err != nil {
+		return "", err
+	}
+
+	return mergeTypedSpec(rendered, d)
+}
+
+// specKeys are the mutually exclusive attributes for giving the cluster spec
+var specKeys = []string{KeySpec, KeySpecJSON, KeySpecs, KeySpecSource}
+
+// validateSpecKeys validates on plan that exactly one of spec, spec_json, specs, and spec_source is set,
+// or none of them when the cluster is described by the typed blocks alone.
+// Values unknown on plan, like a spec read from another resource, count as set.
+func validateSpecKeys(d *schema.ResourceDiff) error {
+	isSet := func(k string) bool {
+		if !d.NewValueKnown(k) {
+			return true
+		}
+
+		switch v := d.Get(k).(type) {
+		case string:
+			return strings.TrimSpace(v) != ""
+		case []interface{}:
+			return len(v) > 0
+		}
+
+		return false
+	}
+
+	var set []string
+
+	for _, k := range specKeys {
+		if isSet(k) {
+			set = append(set, k)
+		}
+	}
+
+	var typed bool
+
+	for _, k := range typedSpecKeys {
+		typed = typed || isSet(k)
+	}
+
+	return checkSpecKeys(set, typed)
+}
+
+func checkSpecKeys(set []string, typed bool) error {
+	if len(set) > 1 {
+		return fmt.Errorf("only one of %s can be set, but got %s", strings.Join(specKeys, ", "), strings.Join(set, ", "))
+	}
+
+	if len(set) == 0 && !typed {
+		return fmt.Errorf("one of %s, or any of the typed blocks %s, must be set", strings.Join(specKeys, ", "), strings.Join(typedSpecKeys, ", "))
+	}
+
+	return nil
+}
+
+// specSourceDiff is the part of schema.ResourceDiff used by planSpecSource
+type specSourceDiff interface {
+	Read
+
+	Id() string
+	HasChange(string) bool
+	SetNew(string, interface{}) error
+}
+
+// planSpecSource fetches the spec from the spec source on plan, so that the fetched content and the commit SHA
+// are stored in the state for reproducibility and diffing.
+//
+// The spec is fetched only on create or when spec_source changes. Otherwise the spec and the commit in the state are kept,
+// so that a branch moving between plan and apply never changes the plan. Change `ref` to pick up new commits.
+func planSpecSource(d specSourceDiff, p *resource.ProviderConfig) error {
+	src := readSpecSource(d)
+	if src == nil {
+		return nil
+	}
+
+	if commit, _ := d.Get(KeySpecSourceCommit).(string); d.Id() != "" && !d.HasChange(KeySpecSource) && commit != "" {
+		return nil
+	}
+
+	content, commit, err := fetchSpecFromGit(p, *src)
+	if err != nil {
+		return fmt.Errorf("fetching spec from %s@%s:%s: %w", src.URL, src.Ref, src.Path, err)
+	}
+
+	if err := d.SetNew(KeyResolvedSpec, content); err != nil {
+		return err
+	}
+
+	if err := d.SetNew(KeySpecSourceCommit, commit); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func fetchSpecFromGit(p *resource.ProviderConfig, src GitSpecSource) (string, string, error) {
+	dir, err := p.TempDir("terraform-provider-eksctl-spec-source-")
+	if err != nil {
+		return "", "", err
+	}
+	defer p.RemoveTempFile(dir)
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+
+		r, err := resource.Run(cmd)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(r.Stdout), nil
+	}
+
+	if _, err := git("init", "--quiet"); err != nil {
+		return "", "", err
+	}
+
+	if _, err := git("fetch", "--quiet", "--depth", "1", src.URL, src.Ref); err != nil {
+		return "", "", err
+	}
+
+	if _, err := git("checkout", "--quiet", "FETCH_HEAD"); err != nil {
+		return "", "", err
+	}
+
+	commit, err := git("rev-parse", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, src.Path))
+	if err != nil {
+		return "", "", fmt.Errorf("reading %s at %s: %w", src.Path, commit, err)
+	}
+
+	log.Printf("Fetched spec from %s@%s:%s at commit %s", src.URL, src.Ref, src.Path, commit)
+
+	return string(content), commit, nil
+}
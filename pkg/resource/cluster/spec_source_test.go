@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSpecKeys(t *testing.T) {
+	assert.NoError(t, checkSpecKeys([]string{KeySpec}, false))
+	assert.NoError(t, checkSpecKeys([]string{KeySpecSource}, true))
+	assert.NoError(t, checkSpecKeys(nil, true))
+
+	assert.EqualError(t, checkSpecKeys(nil, false), "one of spec, spec_json, specs, spec_source, or any of the typed blocks vpc, nodegroup, managed_nodegroup, iam_service_account, must be set")
+	assert.EqualError(t, checkSpecKeys([]string{KeySpecJSON, KeySpecSource}, false), "only one of spec, spec_json, specs, spec_source can be set, but got spec_json, spec_source")
+}
+
+type fakeSpecSourceDiff struct {
+	mapRead
+
+	id      string
+	changed bool
+}
+
+func (d *fakeSpecSourceDiff) Id() string {
+	return d.id
+}
+
+func (d *fakeSpecSourceDiff) HasChange(k string) bool {
+	return d.changed
+}
+
+func (d *fakeSpecSourceDiff) SetNew(k string, v interface{}) error {
+	d.mapRead[k] = v
+
+	return nil
+}
+
+func TestPlanSpecSource(t *testing.T) {
+	repo, err := ioutil.TempDir("", "spec-source")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(repo)
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "--quiet")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, "cluster.yaml"), []byte("kind: ClusterConfig\n"), 0644))
+	git("add", "cluster.yaml")
+	git("commit", "--quiet", "-m", "initial")
+
+	commit := git("rev-parse", "HEAD")
+
+	newDiff := func(id string, changed bool) *fakeSpecSourceDiff {
+		return &fakeSpecSourceDiff{
+			mapRead: mapRead{
+				KeySpecSource: []interface{}{map[string]interface{}{
+					"git": []interface{}{map[string]interface{}{"url": repo, "ref": "HEAD", "path": "cluster.yaml"}},
+				}},
+				KeyResolvedSpec:     "kind: OldClusterConfig\n",
+				KeySpecSourceCommit: "0000000",
+			},
+			id:      id,
+			changed: changed,
+		}
+	}
+
+	t.Run("fetches on create", func(t *testing.T) {
+		d := newDiff("", false)
+
+		require.NoError(t, planSpecSource(d, nil))
+		assert.Equal(t, "kind: ClusterConfig\n", d.mapRead[KeyResolvedSpec])
+		assert.Equal(t, commit, d.mapRead[KeySpecSourceCommit])
+	})
+
+	t.Run("fetches when spec_source changes", func(t *testing.T) {
+		d := newDiff("id", true)
+
+		require.NoError(t, planSpecSource(d, nil))
+		assert.Equal(t, commit, d.mapRead[KeySpecSourceCommit])
+	})
+
+	t.Run("keeps the state otherwise", func(t *testing.T) {
+		d := newDiff("id", false)
+
+		require.NoError(t, planSpecSource(d, nil))
+		assert.Equal(t, "kind: OldClusterConfig\n", d.mapRead[KeyResolvedSpec])
+		assert.Equal(t, "0000000", d.mapRead[KeySpecSourceCommit])
+	})
+}
+
+func TestParsePlannedSpec(t *testing.T) {
+	spec, err := parsePlannedSpec(mapRead{KeySpec: ""})
+	require.NoError(t, err)
+	assert.Nil(t, spec)
+
+	_, err = parsePlannedSpec(mapRead{KeySpec: "nodeGroups: [\n"})
+	assert.Error(t, err)
+
+	spec, err = parsePlannedSpec(mapRead{KeySpec: "vpc:\n  id: vpc-1\n"})
+	require.NoError(t, err)
+
+	var config EksctlClusterConfig
+
+	require.NoError(t, spec.Decode(&config))
+	assert.Equal(t, "vpc-1", config.VPC.ID)
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+func readSpecVars(d Read) map[string]string {
+	vars := map[string]string{}
+
+	if v := d.Get(KeySpecVars); v != nil {
+		for k, v := range v.(map[string]interface{}) {
+			vars[k] = v.(string)
+		}
+	}
+
+	return vars
+}
+
+// isTemplatedSpec returns true when the spec seems to be a Go template that needs rendering before being parsed as YAML.
+func isTemplatedSpec(spec string) bool {
+	return strings.Contains(spec, "{{")
+}
+
+// renderSpec renders the spec as a Go template with the vars, so that e.g. `{{ .env }}` is replaced with the value of
+// `spec_vars = { env = "prod" }`. The spec is returned as-is when no vars are given.
+func renderSpec(spec string, vars map[string]string) (string, error) {
+	if len(vars) == 0 {
+		return spec, nil
+	}
+
+	tmpl, err := template.New(KeySpec).Option("missingkey=error").Parse(spec)
+	if err != nil {
+		return "", fmt.Errorf("parsing spec template: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("rendering spec template: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestRenderSpec(t *testing.T) {
+	spec := `nodeGroups:
+- name: ng-{{ .env }}
+  instanceType: {{ .instance_type }}
+`
+
+	got, err := renderSpec(spec, map[string]string{"env": "prod", "instance_type": "m5.large"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `nodeGroups:
+- name: ng-prod
+  instanceType: m5.large
+`
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("unexpected result: want (-), got (+):\n%s", d)
+	}
+}
+
+func TestRenderSpec_noVars(t *testing.T) {
+	spec := `preBootstrapCommands: ["echo {{ .notrendered }}"]`
+
+	got, err := renderSpec(spec, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != spec {
+		t.Errorf("unexpected result: want %q, got %q", spec, got)
+	}
+}
+
+func TestRenderSpec_missingVar(t *testing.T) {
+	if _, err := renderSpec(`name: {{ .missing }}`, map[string]string{"env": "prod"}); err == nil {
+		t.Fatal("expected error not occurred")
+	}
+}
@@ -0,0 +1,209 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const KeySubnetTagging = "subnet_tagging"
+
+const (
+	SubnetTaggingNone     = "none"
+	SubnetTaggingValidate = "validate"
+	SubnetTaggingFix      = "fix"
+)
+
+const (
+	subnetRoleTagELB         = "kubernetes.io/role/elb"
+	subnetRoleTagInternalELB = "kubernetes.io/role/internal-elb"
+)
+
+// subnetRoleTag returns the tag that makes the subnet discoverable for load balancers.
+// Public subnets are used for internet-facing load balancers, and private subnets for internal ones.
+func subnetRoleTag(public bool) string {
+	if public {
+		return subnetRoleTagELB
+	}
+
+	return subnetRoleTagInternalELB
+}
+
+// findMissingSubnetTags returns the role tags missing on the subnets keyed by the subnet IDs.
+// tags is the current tags of the subnets keyed by the subnet IDs.
+func findMissingSubnetTags(publicSubnetIDs, privateSubnetIDs []string, tags map[string]map[string]string) map[string]string {
+	missing := map[string]string{}
+
+	check := func(ids []string, public bool) {
+		key := subnetRoleTag(public)
+
+		for _, id := range ids {
+			if _, ok := tags[id][key]; !ok {
+				missing[id] = key
+			}
+		}
+	}
+
+	check(publicSubnetIDs, true)
+	check(privateSubnetIDs, false)
+
+	return missing
+}
+
+func getSubnetTags(svc ec2iface.EC2API, subnetIDs []string) (map[string]map[string]string, error) {
+	tags := map[string]map[string]string{}
+
+	if len(subnetIDs) == 0 {
+		return tags, nil
+	}
+
+	r, err := svc.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnetIDs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing subnets %s: %w", strings.Join(subnetIDs, ", "), err)
+	}
+
+	for _, s := range r.Subnets {
+		m := map[string]string{}
+
+		for _, t := range s.Tags {
+			m[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		tags[aws.StringValue(s.SubnetId)] = m
+	}
+
+	return tags, nil
+}
+
+func formatMissingSubnetTags(missing map[string]string) string {
+	var ids []string
+
+	for id := range missing {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	var lines []string
+
+	for _, id := range ids {
+		lines = append(lines, fmt.Sprintf("%s: %s", id, missing[id]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// subnetTagsDiff is the part of schema.ResourceDiff used by validateSubnetTags
+type subnetTagsDiff interface {
+	Read
+
+	Id() string
+}
+
+// validateSubnetTags validates on plan that the existing subnets referenced in the spec carry the role tags
+// required for Kubernetes to place load balancers, because missing tags leave LoadBalancer services pending forever.
+// Missing tags fail only the plan for creating the cluster. For existing clusters they are warned about,
+// so that tags removed outside of Terraform don't block unrelated changes.
+// spec is the one returned by parsePlannedSpec.
+func validateSubnetTags(d subnetTagsDiff, p *resource.ProviderConfig, spec *yaml.Node) error {
+	mode, _ := d.Get(KeySubnetTagging).(string)
+	if mode != SubnetTaggingValidate {
+		return nil
+	}
+
+	if v, _ := d.Get(KeyVPCID).(string); v == "" || spec == nil {
+		return nil
+	}
+
+	var config EksctlClusterConfig
+
+	if err := spec.Decode(&config); err != nil {
+		return fmt.Errorf("parsing cluster.yaml: %w", err)
+	}
+
+	public, private := subnetIDs(config.VPC.Subnets.Public), subnetIDs(config.VPC.Subnets.Private)
+
+	return validateSubnetRoleTags(ec2.New(resource.AWSSessionFromResourceData(d, p)), d.Id() == "", public, private)
+}
+
+// validateSubnetRoleTags fails when the subnets of the cluster to be created are missing the role tags, and only warns otherwise
+func validateSubnetRoleTags(svc ec2iface.EC2API, create bool, public, private []string) error {
+	tags, err := getSubnetTags(svc, append(append([]string{}, public...), private...))
+	if err != nil {
+		// Don't block plans of users who lack the permission to describe the subnets
+		log.Printf("Skipped validating subnet tags: %v", err)
+
+		return nil
+	}
+
+	missing := findMissingSubnetTags(public, private, tags)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if !create {
+		log.Printf("[WARN] the following subnets are missing the tags required for load balancers:\n%s", formatMissingSubnetTags(missing))
+
+		return nil
+	}
+
+	return fmt.Errorf("validating subnet tags: the following subnets are missing the tags required for load balancers. "+
+		"Tag them with the value \"1\", or set %s = %q to let the provider tag them on apply:\n%s", KeySubnetTagging, SubnetTaggingFix, formatMissingSubnetTags(missing))
+}
+
+// doFixSubnetTags adds the missing role tags to the existing subnets when `subnet_tagging = "fix"`
+func doFixSubnetTags(cluster *Cluster) error {
+	if cluster.SubnetTagging != SubnetTaggingFix || cluster.VPCID == "" {
+		return nil
+	}
+
+	svc := ec2.New(AWSSessionFromCluster(cluster))
+
+	tags, err := getSubnetTags(svc, append(append([]string{}, cluster.PublicSubnetIDs...), cluster.PrivateSubnetIDs...))
+	if err != nil {
+		return err
+	}
+
+	missing := findMissingSubnetTags(cluster.PublicSubnetIDs, cluster.PrivateSubnetIDs, tags)
+
+	for id, key := range missing {
+		log.Printf("Adding tag %s to subnet %s", key, id)
+
+		if _, err := svc.CreateTags(&ec2.CreateTagsInput{
+			Resources: aws.StringSlice([]string{id}),
+			Tags: []*ec2.Tag{
+				{
+					Key:   aws.String(key),
+					Value: aws.String("1"),
+				},
+			},
+		}); err != nil {
+			return fmt.Errorf("tagging subnet %s with %s: %w", id, key, err)
+		}
+	}
+
+	return nil
+}
+
+func subnetIDs(subnets map[string]Subnet) []string {
+	var ids []string
+
+	for _, s := range subnets {
+		if s.ID != "" {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingSubnetTags(t *testing.T) {
+	missing := findMissingSubnetTags(
+		[]string{"subnet-pub1", "subnet-pub2"},
+		[]string{"subnet-priv1"},
+		map[string]map[string]string{
+			"subnet-pub1":  {"kubernetes.io/role/elb": "1"},
+			"subnet-pub2":  {"kubernetes.io/role/internal-elb": "1"},
+			"subnet-priv1": {"Name": "private"},
+		},
+	)
+
+	assert.Equal(t, map[string]string{
+		"subnet-pub2":  "kubernetes.io/role/elb",
+		"subnet-priv1": "kubernetes.io/role/internal-elb",
+	}, missing)
+
+	assert.Equal(t, "subnet-priv1: kubernetes.io/role/internal-elb\nsubnet-pub2: kubernetes.io/role/elb", formatMissingSubnetTags(missing))
+}
+
+type subnetsMock struct {
+	ec2iface.EC2API
+
+	tags map[string]map[string]string
+	err  error
+}
+
+func (m *subnetsMock) DescribeSubnets(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	out := &ec2.DescribeSubnetsOutput{}
+
+	for _, id := range aws.StringValueSlice(in.SubnetIds) {
+		s := &ec2.Subnet{SubnetId: aws.String(id)}
+
+		for k, v := range m.tags[id] {
+			s.Tags = append(s.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+
+		out.Subnets = append(out.Subnets, s)
+	}
+
+	return out, nil
+}
+
+func TestValidateSubnetRoleTags(t *testing.T) {
+	svc := &subnetsMock{tags: map[string]map[string]string{
+		"subnet-pub1":  {"kubernetes.io/role/elb": "1"},
+		"subnet-priv1": {},
+	}}
+
+	err := validateSubnetRoleTags(svc, true, []string{"subnet-pub1"}, []string{"subnet-priv1"})
+	assert.EqualError(t, err, `validating subnet tags: the following subnets are missing the tags required for load balancers. Tag them with the value "1", or set subnet_tagging = "fix" to let the provider tag them on apply:
+subnet-priv1: kubernetes.io/role/internal-elb`)
+
+	// Plans for existing clusters only warn about the missing tags
+	assert.NoError(t, validateSubnetRoleTags(svc, false, []string{"subnet-pub1"}, []string{"subnet-priv1"}))
+
+	// The validation is skipped when the subnets can't be described
+	svc.err = errors.New("UnauthorizedOperation")
+
+	assert.NoError(t, validateSubnetRoleTags(svc, true, []string{"subnet-pub1"}, []string{"subnet-priv1"}))
+}
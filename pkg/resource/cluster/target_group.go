@@ -5,8 +5,12 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
 	"log"
+	"sort"
+	"strings"
 )
 
 const (
@@ -14,28 +18,81 @@ const (
 	TagKeyClusterNamePrefix = "tf-eksctl/cluster"
 )
 
-func getTargetGroupARNs(sess *session.Session, clusterNamePrefixy string) ([]string, error) {
-	api := resourcegroupstaggingapi.New(sess)
+// getTargetGroupARNs returns the sorted ARNs of the target groups matching any of the selector's tags, names, and name prefixes.
+// The result is sorted so that the plan is stable regardless of the order the APIs return target groups.
+// account identifies the AWS account for caching the target groups listed for name prefixes.
+func getTargetGroupARNs(sess *session.Session, account string, sel TargetGroupSelector) ([]string, error) {
+	selected := map[string]bool{}
+
+	if len(sel.Tags) > 0 {
+		arns, err := getTargetGroupARNsByTags(resourcegroupstaggingapi.New(sess), sel.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, arn := range arns {
+			selected[arn] = true
+		}
+	}
+
+	api := elbv2.New(sess)
+
+	arns, err := getTargetGroupARNsByNames(api, sel.Names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arn := range arns {
+		selected[arn] = true
+	}
+
+	cacheKey := remoteReadCacheKey(account, aws.StringValue(sess.Config.Region), "", "targetgroups")
+
+	arns, err = getTargetGroupARNsByNamePrefixes(api, cacheKey, sel.NamePrefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arn := range arns {
+		selected[arn] = true
+	}
+
+	var sorted []string
+
+	for arn := range selected {
+		sorted = append(sorted, arn)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
+
+// getTargetGroupARNsByTags returns the ARNs of the target groups that have all the tags
+func getTargetGroupARNsByTags(api resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI, tags map[string]string) ([]string, error) {
+	var filters []*resourcegroupstaggingapi.TagFilter
+
+	for k, v := range tags {
+		filters = append(filters, &resourcegroupstaggingapi.TagFilter{
+			Key:    aws.String(k),
+			Values: aws.StringSlice([]string{v}),
+		})
+	}
 
 	var token *string
 
 	var arns []string
 
 	for {
-		log.Printf("getting tagged resources for %s", clusterNamePrefixy)
+		log.Printf("getting target groups tagged with %v", tags)
 
 		res, err := api.GetResources(&resourcegroupstaggingapi.GetResourcesInput{
 			PaginationToken:     token,
 			ResourceTypeFilters: aws.StringSlice([]string{"elasticloadbalancing:targetgroup"}),
-			TagFilters: []*resourcegroupstaggingapi.TagFilter{
-				{
-					Key:    aws.String(TagKeyClusterNamePrefix),
-					Values: aws.StringSlice([]string{clusterNamePrefixy}),
-				},
-			},
+			TagFilters:          filters,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("getting tagged resources for %s: %w", clusterNamePrefixy, err)
+			return nil, fmt.Errorf("getting target groups tagged with %v: %w", tags, err)
 		}
 
 		for _, m := range res.ResourceTagMappingList {
@@ -53,6 +110,109 @@ func getTargetGroupARNs(sess *session.Session, clusterNamePrefixy string) ([]str
 	return arns, nil
 }
 
+// describeTargetGroupsPageSize is the maximum page size allowed by DescribeTargetGroups
+const describeTargetGroupsPageSize = 400
+
+// describeTargetGroupsMaxNames is the maximum number of names DescribeTargetGroups accepts at once
+const describeTargetGroupsMaxNames = 20
+
+// targetGroupName is the subset of the target group used for matching names
+type targetGroupName struct {
+	Name string
+	ARN  string
+}
+
+// getTargetGroupARNsByNamePrefixes returns the ARNs of the target groups whose names start with any of the prefixes.
+// ELBv2 has no server-side filter for name prefixes, so all the target groups in the region are listed once
+// and cached for the whole plan under cacheKey, instead of being listed per cluster.
+func getTargetGroupARNsByNamePrefixes(api elbv2iface.ELBV2API, cacheKey string, prefixes []string) ([]string, error) {
+	if len(prefixes) == 0 {
+		return nil, nil
+	}
+
+	v, err := remoteReadCache.getOrLoad(cacheKey, func() (interface{}, error) {
+		return listTargetGroups(api)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing target groups prefixed with %v: %w", prefixes, err)
+	}
+
+	var arns []string
+
+	for _, tg := range v.([]targetGroupName) {
+		if hasAnyPrefix(tg.Name, prefixes) {
+			arns = append(arns, tg.ARN)
+		}
+	}
+
+	return arns, nil
+}
+
+func listTargetGroups(api elbv2iface.ELBV2API) ([]targetGroupName, error) {
+	var tgs []targetGroupName
+
+	pages := 0
+
+	err := api.DescribeTargetGroupsPages(&elbv2.DescribeTargetGroupsInput{
+		PageSize: aws.Int64(describeTargetGroupsPageSize),
+	}, func(res *elbv2.DescribeTargetGroupsOutput, lastPage bool) bool {
+		pages++
+
+		for _, tg := range res.TargetGroups {
+			tgs = append(tgs, targetGroupName{Name: aws.StringValue(tg.TargetGroupName), ARN: aws.StringValue(tg.TargetGroupArn)})
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("listed %d target groups in %d pages", len(tgs), pages)
+
+	return tgs, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTargetGroupARNsByNames returns the ARNs of the target groups whose names exactly match.
+// The names are filtered server-side, in batches of the maximum number of names accepted at once.
+func getTargetGroupARNsByNames(api elbv2iface.ELBV2API, names []string) ([]string, error) {
+	var arns []string
+
+	for start := 0; start < len(names); start += describeTargetGroupsMaxNames {
+		end := start + describeTargetGroupsMaxNames
+		if end > len(names) {
+			end = len(names)
+		}
+
+		batch := names[start:end]
+
+		err := api.DescribeTargetGroupsPages(&elbv2.DescribeTargetGroupsInput{
+			Names: aws.StringSlice(batch),
+		}, func(res *elbv2.DescribeTargetGroupsOutput, lastPage bool) bool {
+			for _, tg := range res.TargetGroups {
+				arns = append(arns, aws.StringValue(tg.TargetGroupArn))
+			}
+
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing target groups %v: %w", batch, err)
+		}
+	}
+
+	return arns, nil
+}
+
 func deleteTargetGroups(set *ClusterSet) error {
 	elb := elbv2.New(AWSSessionFromCluster(set.Cluster))
 
@@ -0,0 +1,147 @@
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+)
+
+// readTargetGroupAttachments returns the ARNs of the target groups that are attached to
+// the autoscaling groups of the cluster's nodegroups, sorted for diffing.
+func readTargetGroupAttachments(cluster *Cluster, clusterName ClusterName) ([]string, error) {
+	asgs, err := getNodeGroupAutoScalingGroups(cluster, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return describeTargetGroupAttachments(autoscaling.New(AWSSessionFromCluster(cluster)), asgs)
+}
+
+func describeTargetGroupAttachments(svc autoscalingiface.AutoScalingAPI, asgs map[string]string) ([]string, error) {
+	attached := map[string]bool{}
+
+	for _, asgName := range asgs {
+		var token *string
+
+		for {
+			res, err := svc.DescribeLoadBalancerTargetGroups(&autoscaling.DescribeLoadBalancerTargetGroupsInput{
+				AutoScalingGroupName: aws.String(asgName),
+				NextToken:            token,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("describing target groups attached to %s: %w", asgName, err)
+			}
+
+			for _, s := range res.LoadBalancerTargetGroups {
+				attached[aws.StringValue(s.LoadBalancerTargetGroupARN)] = true
+			}
+
+			token = res.NextToken
+			if token == nil || *token == "" {
+				break
+			}
+		}
+	}
+
+	var arns []string
+
+	for arn := range attached {
+		arns = append(arns, arn)
+	}
+
+	sort.Strings(arns)
+
+	return arns, nil
+}
+
+// reconcileTargetGroupAttachments attaches the autoscaling groups of the cluster's nodegroups to the target groups
+// only in desired, and detaches them from the target groups only in current.
+func reconcileTargetGroupAttachments(cluster *Cluster, clusterName ClusterName, current, desired []string) error {
+	attach, detach := diffStrings(current, desired)
+
+	if len(attach) == 0 && len(detach) == 0 {
+		return nil
+	}
+
+	asgs, err := getNodeGroupAutoScalingGroups(cluster, clusterName)
+	if err != nil {
+		return err
+	}
+
+	return updateTargetGroupAttachments(autoscaling.New(AWSSessionFromCluster(cluster)), asgs, attach, detach)
+}
+
+func updateTargetGroupAttachments(svc autoscalingiface.AutoScalingAPI, asgs map[string]string, attach, detach []string) error {
+	for _, asgName := range asgs {
+		if len(attach) > 0 {
+			log.Printf("Attaching %s to target groups %v", asgName, attach)
+
+			if _, err := svc.AttachLoadBalancerTargetGroups(&autoscaling.AttachLoadBalancerTargetGroupsInput{
+				AutoScalingGroupName: aws.String(asgName),
+				TargetGroupARNs:      aws.StringSlice(attach),
+			}); err != nil {
+				return fmt.Errorf("attaching %s to target groups: %w", asgName, err)
+			}
+		}
+
+		if len(detach) > 0 {
+			log.Printf("Detaching %s from target groups %v", asgName, detach)
+
+			if _, err := svc.DetachLoadBalancerTargetGroups(&autoscaling.DetachLoadBalancerTargetGroupsInput{
+				AutoScalingGroupName: aws.String(asgName),
+				TargetGroupARNs:      aws.StringSlice(detach),
+			}); err != nil {
+				return fmt.Errorf("detaching %s from target groups: %w", asgName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// diffStrings returns the items only in b, and the items only in a.
+func diffStrings(a, b []string) ([]string, []string) {
+	inA := map[string]bool{}
+	for _, s := range a {
+		inA[s] = true
+	}
+
+	inB := map[string]bool{}
+	for _, s := range b {
+		inB[s] = true
+	}
+
+	var added, removed []string
+
+	for _, s := range b {
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+
+	for _, s := range a {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
+
+func toStrings(v interface{}) []string {
+	var ss []string
+
+	if v == nil {
+		return ss
+	}
+
+	for _, s := range v.([]interface{}) {
+		ss = append(ss, s.(string))
+	}
+
+	return ss
+}
@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAutoScaling struct {
+	autoscalingiface.AutoScalingAPI
+
+	// pages are the pages of the target group ARNs attached to each autoscaling group
+	pages map[string][][]string
+	calls []string
+}
+
+func (f *fakeAutoScaling) DescribeLoadBalancerTargetGroups(i *autoscaling.DescribeLoadBalancerTargetGroupsInput) (*autoscaling.DescribeLoadBalancerTargetGroupsOutput, error) {
+	pages := f.pages[*i.AutoScalingGroupName]
+
+	var page int
+
+	if i.NextToken != nil {
+		fmt.Sscanf(*i.NextToken, "%d", &page)
+	}
+
+	o := &autoscaling.DescribeLoadBalancerTargetGroupsOutput{}
+
+	for _, arn := range pages[page] {
+		o.LoadBalancerTargetGroups = append(o.LoadBalancerTargetGroups, &autoscaling.LoadBalancerTargetGroupState{LoadBalancerTargetGroupARN: aws.String(arn)})
+	}
+
+	if page+1 < len(pages) {
+		o.NextToken = aws.String(fmt.Sprintf("%d", page+1))
+	}
+
+	return o, nil
+}
+
+func (f *fakeAutoScaling) AttachLoadBalancerTargetGroups(i *autoscaling.AttachLoadBalancerTargetGroupsInput) (*autoscaling.AttachLoadBalancerTargetGroupsOutput, error) {
+	f.calls = append(f.calls, fmt.Sprintf("attach %s %v", *i.AutoScalingGroupName, aws.StringValueSlice(i.TargetGroupARNs)))
+
+	return &autoscaling.AttachLoadBalancerTargetGroupsOutput{}, nil
+}
+
+func (f *fakeAutoScaling) DetachLoadBalancerTargetGroups(i *autoscaling.DetachLoadBalancerTargetGroupsInput) (*autoscaling.DetachLoadBalancerTargetGroupsOutput, error) {
+	f.calls = append(f.calls, fmt.Sprintf("detach %s %v", *i.AutoScalingGroupName, aws.StringValueSlice(i.TargetGroupARNs)))
+
+	return &autoscaling.DetachLoadBalancerTargetGroupsOutput{}, nil
+}
+
+func TestDescribeTargetGroupAttachments(t *testing.T) {
+	svc := &fakeAutoScaling{
+		pages: map[string][][]string{
+			"asg1": {{"tg2"}, {"tg1"}},
+			"asg2": {{"tg1", "tg3"}},
+		},
+	}
+
+	arns, err := describeTargetGroupAttachments(svc, map[string]string{"ng1": "asg1", "ng2": "asg2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tg1", "tg2", "tg3"}, arns)
+}
+
+func TestUpdateTargetGroupAttachments(t *testing.T) {
+	svc := &fakeAutoScaling{}
+
+	attach, detach := diffStrings([]string{"tg1", "tg2"}, []string{"tg2", "tg3"})
+	assert.Equal(t, []string{"tg3"}, attach)
+	assert.Equal(t, []string{"tg1"}, detach)
+
+	require.NoError(t, updateTargetGroupAttachments(svc, map[string]string{"ng1": "asg1", "ng2": "asg2"}, attach, detach))
+
+	sort.Strings(svc.calls)
+
+	// Only the changed attachments are touched, so that the nodes in the unchanged target groups keep serving
+	assert.Equal(t, []string{
+		"attach asg1 [tg3]",
+		"attach asg2 [tg3]",
+		"detach asg1 [tg1]",
+		"detach asg2 [tg1]",
+	}, svc.calls)
+}
@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const (
+	TargetTypeInstance = "instance"
+	TargetTypeIP       = "ip"
+)
+
+// albAttachmentTargetGroupName returns the name of the target group created for the attachment of the cluster with the id
+func albAttachmentTargetGroupName(a courier.ALBAttachment, id string) string {
+	if a.TargetType == TargetTypeIP {
+		return fmt.Sprintf("%s-%d-%s", a.ServiceName, a.ServicePort, id)
+	}
+
+	return fmt.Sprintf("%s-%d-%s", a.NodeGroupName, a.NodePort, id)
+}
+
+// albAttachmentTargetGroupPort returns the port of the target group, which is the node port for the instance target type,
+// and the service port for the ip target type, which AWS Load Balancer Controller overrides with the pods' target ports.
+func albAttachmentTargetGroupPort(a courier.ALBAttachment) int {
+	if a.TargetType == TargetTypeIP {
+		return a.ServicePort
+	}
+
+	return a.NodePort
+}
+
+func validateALBAttachment(cluster *Cluster, a courier.ALBAttachment) error {
+	switch a.TargetType {
+	case TargetTypeIP:
+		if a.ServiceName == "" || a.ServicePort == 0 {
+			return fmt.Errorf("alb_attachment for listener %s: service_name and service_port are required for target_type %q", a.ListenerARN, TargetTypeIP)
+		}
+
+		if cluster.AWSLoadBalancerController == nil {
+			return fmt.Errorf("alb_attachment for listener %s: aws_load_balancer_controller is required for target_type %q", a.ListenerARN, TargetTypeIP)
+		}
+	default:
+		if a.NodeGroupName == "" || a.NodePort == 0 {
+			return fmt.Errorf("alb_attachment for listener %s: node_group_name and node_port are required for target_type %q", a.ListenerARN, TargetTypeInstance)
+		}
+	}
+
+	return nil
+}
+
+func renderTargetGroupBinding(a courier.ALBAttachment, name, targetGroupARN string) string {
+	return fmt.Sprintf(`apiVersion: elbv2.k8s.aws/v1beta1
+kind: TargetGroupBinding
+metadata:
+  name: %q
+  namespace: %q
+spec:
+  serviceRef:
+    name: %q
+    port: %d
+  targetGroupARN: %q
+  targetType: ip
+`, name, a.ServiceNamespace, a.ServiceName, a.ServicePort, targetGroupARN)
+}
+
+// doBindTargetGroups creates a TargetGroupBinding in the new cluster for each ip-mode alb_attachment,
+// so that AWS Load Balancer Controller registers the pods behind the service to the cluster's target group
+// before the traffic is shifted to it.
+func doBindTargetGroups(set *ClusterSet) error {
+	var manifests []string
+
+	for _, l := range set.ListenerStatuses {
+		if l.DesiredTG == nil {
+			continue
+		}
+
+		for _, a := range l.ALBAttachments {
+			if a.TargetType != TargetTypeIP {
+				continue
+			}
+
+			name := albAttachmentTargetGroupName(a, set.ClusterID)
+
+			manifests = append(manifests, renderTargetGroupBinding(a, name, *l.DesiredTG.TargetGroupArn))
+		}
+	}
+
+	if len(manifests) == 0 {
+		return nil
+	}
+
+	kubeconfigPath, err := writeTempKubeconfig(set.Cluster, set.ClusterName)
+	if err != nil {
+		return fmt.Errorf("preparing kubeconfig for binding target groups: %w", err)
+	}
+	defer set.Cluster.Provider.RemoveTempFile(kubeconfigPath)
+
+	cmd, err := newKubectlCommand(set.Cluster, kubeconfigPath, kubectlApplyArgs(set.Cluster)...)
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin = bytes.NewBufferString(strings.Join(manifests, "---\n"))
+
+	log.Printf("Binding %d target groups to services in %s", len(manifests), set.ClusterName)
+
+	if _, err := resource.Run(cmd); err != nil {
+		return fmt.Errorf("applying target group bindings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestALBAttachmentTargetGroup(t *testing.T) {
+	instance := courier.ALBAttachment{NodeGroupName: "ng1", NodePort: 30080}
+	ip := courier.ALBAttachment{TargetType: TargetTypeIP, ServiceName: "web", ServiceNamespace: "default", ServicePort: 8080}
+
+	assert.Equal(t, "ng1-30080-abc", albAttachmentTargetGroupName(instance, "abc"))
+	assert.Equal(t, 30080, albAttachmentTargetGroupPort(instance))
+	assert.Equal(t, "web-8080-abc", albAttachmentTargetGroupName(ip, "abc"))
+	assert.Equal(t, 8080, albAttachmentTargetGroupPort(ip))
+
+	assert.NoError(t, validateALBAttachment(&Cluster{}, instance))
+	assert.Error(t, validateALBAttachment(&Cluster{}, ip))
+	assert.NoError(t, validateALBAttachment(&Cluster{AWSLoadBalancerController: &AWSLoadBalancerController{}}, ip))
+	assert.Error(t, validateALBAttachment(&Cluster{}, courier.ALBAttachment{TargetType: TargetTypeInstance, NodeGroupName: "ng1"}))
+}
+
+func TestRenderTargetGroupBinding(t *testing.T) {
+	a := courier.ALBAttachment{TargetType: TargetTypeIP, ServiceName: "web", ServiceNamespace: "apps", ServicePort: 8080}
+
+	assert.Equal(t, `apiVersion: elbv2.k8s.aws/v1beta1
+kind: TargetGroupBinding
+metadata:
+  name: "web-8080-abc"
+  namespace: "apps"
+spec:
+  serviceRef:
+    name: "web"
+    port: 8080
+  targetGroupARN: "arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/web-8080-abc/1"
+  targetType: ip
+`, renderTargetGroupBinding(a, "web-8080-abc", "arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/web-8080-abc/1"))
+}
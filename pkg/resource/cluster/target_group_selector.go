@@ -0,0 +1,106 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+const KeyTargetGroupSelector = "target_group_selector"
+
+// TargetGroupSelector selects the target groups to be attached to the nodegroups by tags, exact names, or name prefixes.
+// Prefer tags or exact names, so that target groups of clusters sharing a name prefix, like "prod" and "prod-eu", never collide.
+type TargetGroupSelector struct {
+	Tags         map[string]string
+	Names        []string
+	NamePrefixes []string
+}
+
+func targetGroupSelectorSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{KeyTargetGroupARNs},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				// tags selects the target groups that have all the tags
+				"tags": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				// names selects the target groups whose names exactly match
+				"names": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				// name_prefixes selects the target groups whose names start with any of the prefixes
+				"name_prefixes": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func readTargetGroupSelector(d Read) *TargetGroupSelector {
+	v := d.Get(KeyTargetGroupSelector)
+	if v == nil {
+		return nil
+	}
+
+	selectors := v.([]interface{})
+	if len(selectors) == 0 || selectors[0] == nil {
+		return nil
+	}
+
+	m := selectors[0].(map[string]interface{})
+
+	sel := &TargetGroupSelector{
+		Tags:         map[string]string{},
+		Names:        toStrings(m["names"]),
+		NamePrefixes: toStrings(m["name_prefixes"]),
+	}
+
+	if tags, ok := m["tags"].(map[string]interface{}); ok {
+		for k, v := range tags {
+			sel.Tags[k] = v.(string)
+		}
+	}
+
+	return sel
+}
+
+// planTargetGroupSelector resolves the target group selector on plan into `target_group_arns`,
+// so that the attachments to the selected target groups are planned and applied in place.
+func planTargetGroupSelector(d *DiffReadWrite, p *resource.ProviderConfig) error {
+	sel := readTargetGroupSelector(d)
+	if sel == nil {
+		return nil
+	}
+
+	_, profile := resource.GetAWSRegionAndProfile(d, p)
+	account := remoteReadCacheAccount(profile, resource.GetAssumeRoles(d))
+
+	arns, err := getTargetGroupARNs(resource.AWSSessionFromResourceData(d, p), account, *sel)
+	if err != nil {
+		return err
+	}
+
+	var v []interface{}
+
+	for _, arn := range arns {
+		v = append(v, arn)
+	}
+
+	if err := d.Set(KeyTargetGroupARNs, v); err != nil {
+		return fmt.Errorf("setting %s from %s: %w", KeyTargetGroupARNs, KeyTargetGroupSelector, err)
+	}
+
+	return nil
+}
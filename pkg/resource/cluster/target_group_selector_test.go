@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taggingMock struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+
+	pages  [][]string
+	inputs []*resourcegroupstaggingapi.GetResourcesInput
+}
+
+func (m *taggingMock) GetResources(in *resourcegroupstaggingapi.GetResourcesInput) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	m.inputs = append(m.inputs, in)
+
+	page := len(m.inputs) - 1
+
+	out := &resourcegroupstaggingapi.GetResourcesOutput{}
+
+	for _, arn := range m.pages[page] {
+		out.ResourceTagMappingList = append(out.ResourceTagMappingList, &resourcegroupstaggingapi.ResourceTagMapping{ResourceARN: aws.String(arn)})
+	}
+
+	if page+1 < len(m.pages) {
+		out.PaginationToken = aws.String("next")
+	}
+
+	return out, nil
+}
+
+func TestReadTargetGroupSelector(t *testing.T) {
+	assert.Nil(t, readTargetGroupSelector(mapRead{KeyTargetGroupSelector: []interface{}{}}))
+
+	sel := readTargetGroupSelector(mapRead{
+		KeyTargetGroupSelector: []interface{}{map[string]interface{}{
+			"tags":          map[string]interface{}{"app": "web"},
+			"names":         []interface{}{"web-primary"},
+			"name_prefixes": []interface{}{},
+		}},
+	})
+
+	assert.Equal(t, &TargetGroupSelector{
+		Tags:  map[string]string{"app": "web"},
+		Names: []string{"web-primary"},
+	}, sel)
+}
+
+func TestGetTargetGroupARNsByTags(t *testing.T) {
+	api := &taggingMock{pages: [][]string{{"arn:web-primary"}, {"arn:web-secondary"}}}
+
+	arns, err := getTargetGroupARNsByTags(api, map[string]string{"app": "web", "env": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn:web-primary", "arn:web-secondary"}, arns)
+
+	require.Len(t, api.inputs, 2)
+	assert.Equal(t, "next", aws.StringValue(api.inputs[1].PaginationToken))
+
+	// The target groups must have all the tags
+	var filters []string
+
+	for _, f := range api.inputs[0].TagFilters {
+		filters = append(filters, aws.StringValue(f.Key)+"="+aws.StringValue(f.Values[0]))
+	}
+
+	sort.Strings(filters)
+
+	assert.Equal(t, []string{"app=web", "env=prod"}, filters)
+	assert.Equal(t, []string{"elasticloadbalancing:targetgroup"}, aws.StringValueSlice(api.inputs[0].ResourceTypeFilters))
+}
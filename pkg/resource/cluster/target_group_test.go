@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type targetGroupPagesMock struct {
+	elbv2iface.ELBV2API
+
+	pages  [][]string
+	inputs []*elbv2.DescribeTargetGroupsInput
+}
+
+func (m *targetGroupPagesMock) DescribeTargetGroupsPages(in *elbv2.DescribeTargetGroupsInput, f func(*elbv2.DescribeTargetGroupsOutput, bool) bool) error {
+	m.inputs = append(m.inputs, in)
+
+	if len(in.Names) > 0 {
+		var tgs []*elbv2.TargetGroup
+
+		for _, n := range aws.StringValueSlice(in.Names) {
+			tgs = append(tgs, &elbv2.TargetGroup{TargetGroupName: aws.String(n), TargetGroupArn: aws.String("arn:" + n)})
+		}
+
+		f(&elbv2.DescribeTargetGroupsOutput{TargetGroups: tgs}, true)
+
+		return nil
+	}
+
+	for i, names := range m.pages {
+		var tgs []*elbv2.TargetGroup
+
+		for _, n := range names {
+			tgs = append(tgs, &elbv2.TargetGroup{TargetGroupName: aws.String(n), TargetGroupArn: aws.String("arn:" + n)})
+		}
+
+		if !f(&elbv2.DescribeTargetGroupsOutput{TargetGroups: tgs}, i == len(m.pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func TestHasAnyPrefix(t *testing.T) {
+	assert.True(t, hasAnyPrefix("web-primary", []string{"api-", "web-"}))
+	assert.False(t, hasAnyPrefix("web-primary", []string{"api-"}))
+	assert.False(t, hasAnyPrefix("web-primary", nil))
+}
+
+func TestGetTargetGroupARNsByNamePrefixes(t *testing.T) {
+	api := &targetGroupPagesMock{pages: [][]string{{"web-primary", "api-primary"}, {"web-secondary", "db"}}}
+
+	key := remoteReadCacheKey("test", "us-east-2", "", "targetgroups")
+	defer remoteReadCache.invalidate(key)
+
+	for i := 0; i < 2; i++ {
+		arns, err := getTargetGroupARNsByNamePrefixes(api, key, []string{"web-", "api-"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"arn:web-primary", "arn:api-primary", "arn:web-secondary"}, arns)
+	}
+
+	// The target groups are listed only once, with the maximum page size
+	require.Len(t, api.inputs, 1)
+	assert.Equal(t, int64(describeTargetGroupsPageSize), aws.Int64Value(api.inputs[0].PageSize))
+}
+
+func TestGetTargetGroupARNsByNames(t *testing.T) {
+	api := &targetGroupPagesMock{}
+
+	var names []string
+
+	for i := 0; i < 25; i++ {
+		names = append(names, fmt.Sprintf("tg-%d", i))
+	}
+
+	arns, err := getTargetGroupARNsByNames(api, names)
+	require.NoError(t, err)
+
+	assert.Len(t, arns, 25)
+	require.Len(t, api.inputs, 2)
+	assert.Len(t, api.inputs[0].Names, 20)
+	assert.Len(t, api.inputs[1].Names, 5)
+}
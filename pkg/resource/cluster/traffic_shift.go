@@ -2,12 +2,13 @@ package cluster
 
 import (
 	"context"
+	"fmt"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
 	"golang.org/x/sync/errgroup"
 	"log"
-	"sync"
+	"sort"
 	"time"
 )
 
@@ -23,13 +24,51 @@ func graduallyShiftTraffic(set *ClusterSet, opts courier.CanaryOpts) error {
 	{
 		var err error
 
-		m.Analyzers, err = courier.MetricsToAnalyzers(cluster.Region, cluster.Profile, cluster.Metrics)
+		m.Analyzers, err = courier.MetricsToAnalyzers(cluster.Provider.AWSConfig(), cluster.Region, cluster.Profile, cluster.Metrics)
 		if err != nil {
 			return err
 		}
 	}
 
-	return m.SwitchTargetGroup(listenerStatuses, opts)
+	if err := m.WaitForDesiredTargetGroupsHealth(listenerStatuses, opts); err != nil {
+		return fmt.Errorf("gating traffic shift on target health: %w", err)
+	}
+
+	if err := doPrewarm(set, svc); err != nil {
+		return fmt.Errorf("prewarming cluster %s: %w", set.ClusterName, err)
+	}
+
+	if err := m.SwitchTargetGroup(listenerStatuses, opts); err != nil {
+		return err
+	}
+
+	if err := m.WaitForDesiredTargetGroupsHealth(listenerStatuses, opts); err != nil {
+		m.RollbackTraffic(listenerStatuses, opts.SwitchoverMetrics)
+
+		return fmt.Errorf("verifying target health after traffic shift: %w", err)
+	}
+
+	if err := opts.AlarmRollback.Watch(context.Background()); err != nil {
+		m.RollbackTraffic(listenerStatuses, opts.SwitchoverMetrics)
+
+		return fmt.Errorf("watching cloudwatch alarms after traffic shift: %w", err)
+	}
+
+	return nil
+}
+
+// switchoverMetrics returns the switchover metrics dimensioned by the name of the cluster the traffic is shifted to,
+// or nil when switchover_metrics is not configured.
+func (c *Cluster) switchoverMetrics(clusterName string) *courier.SwitchoverMetrics {
+	if c.SwitchoverMetrics == nil {
+		return nil
+	}
+
+	m := *c.SwitchoverMetrics
+
+	m.ClusterName = clusterName
+
+	return &m
 }
 
 type ALBRouter struct {
@@ -50,18 +89,23 @@ func (m *ALBRouter) SwitchTargetGroup(listenerStatuses ListenerStatuses, opts co
 		return nil
 	}
 
-	tCtx, cancel := context.WithCancel(context.Background())
-	g, gctx := errgroup.WithContext(tCtx)
+	var ls []courier.ListenerStatus
 
-	wg := &sync.WaitGroup{}
+	for _, k := range sortedListenerKeys(listenerStatuses) {
+		ls = append(ls, listenerStatuses[k])
+	}
 
-	for i := range listenerStatuses {
-		l := listenerStatuses[i]
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		g.Go(func() error {
-			return courier.DoGradualTrafficShift(tCtx, svc, l, 1, opts)
-		})
-	}
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		// Stop checking the metrics once the traffic is fully shifted
+		defer cancel()
+
+		return courier.DoGradualTrafficShiftAcrossListeners(gctx, svc, ls, 1, opts)
+	})
 
 	// Check per cluster metrics
 	for i := range m.Analyzers {
@@ -85,30 +129,68 @@ func (m *ALBRouter) SwitchTargetGroup(listenerStatuses ListenerStatuses, opts co
 		})
 	}
 
-	go func() {
-		defer cancel()
+	if err := g.Wait(); err != nil {
+		log.Printf("Traffic shifting canceled due to error: %v", err)
 
-		wg.Wait()
-	}()
+		return err
+	}
 
-	var err error
-	{
-		defer cancel()
+	log.Printf("Traffic shifting finished successfully.")
+
+	return nil
+}
 
-		err = g.Wait()
+func sortedListenerKeys(listenerStatuses ListenerStatuses) []string {
+	var keys []string
+
+	for k := range listenerStatuses {
+		keys = append(keys, k)
 	}
 
-	if err == nil {
-		log.Printf("Traffic shifting finished successfully.")
-	} else if err == context.Canceled {
-		log.Printf("Traffic shifting canceled externally.")
+	sort.Strings(keys)
 
-		return err
-	} else {
-		log.Printf("Traffic shifting canceled due to error: %w", err)
+	return keys
+}
 
-		return err
+// WaitForDesiredTargetGroupsHealth blocks until every desired target group has enough healthy targets
+// as configured via opts.TargetHealthCheck. It does nothing when no health check is configured.
+func (m *ALBRouter) WaitForDesiredTargetGroupsHealth(listenerStatuses ListenerStatuses, opts courier.CanaryOpts) error {
+	if opts.TargetHealthCheck == nil {
+		return nil
+	}
+
+	for _, l := range listenerStatuses {
+		if l.DesiredTG == nil {
+			continue
+		}
+
+		err := courier.WaitForTargetGroupHealth(context.Background(), m.ELBV2, *l.DesiredTG.TargetGroupArn, *opts.TargetHealthCheck)
+
+		opts.SwitchoverMetrics.PutHealthCheck(courier.SwitchoverHealthCheckTargetHealth, err)
+
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// RollbackTraffic forwards 100% of the traffic back to the current target groups.
+func (m *ALBRouter) RollbackTraffic(listenerStatuses ListenerStatuses, metrics *courier.SwitchoverMetrics) {
+	for _, l := range listenerStatuses {
+		if l.DesiredTG == nil || l.CurrentTG == nil || l.Rule == nil {
+			continue
+		}
+
+		log.Printf("Rolling back traffic for listener %s", *l.Listener.ListenerArn)
+
+		if err := courier.SetDesiredTGTrafficPercentage(m.ELBV2, l, 0); err != nil {
+			log.Printf("Failed rolling back traffic for listener %s: %v", *l.Listener.ListenerArn, err)
+
+			continue
+		}
+
+		metrics.PutRollback(*l.Listener.ListenerArn)
+	}
+}
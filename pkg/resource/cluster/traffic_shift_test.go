@@ -1,9 +1,17 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/stretchr/testify/assert"
 )
 
 type mockedAWS struct {
@@ -27,3 +35,70 @@ func (m mockedAWS) ModifyRule(i *elbv2.ModifyRuleInput) (*elbv2.ModifyRuleOutput
 
 	return m.ModifyRuleFunc(i)
 }
+
+func testListenerStatus(n string) courier.ListenerStatus {
+	return courier.ListenerStatus{
+		Listener: &elbv2.Listener{ListenerArn: aws.String("listener" + n)},
+		Rule: &elbv2.Rule{
+			RuleArn: aws.String("rule" + n),
+			Actions: []*elbv2.Action{{Type: aws.String("forward")}},
+		},
+		DesiredTG: &elbv2.TargetGroup{TargetGroupArn: aws.String("next"), TargetGroupName: aws.String("next")},
+		CurrentTG: &elbv2.TargetGroup{TargetGroupArn: aws.String("prev"), TargetGroupName: aws.String("prev")},
+	}
+}
+
+func TestALBRouter_SwitchTargetGroup(t *testing.T) {
+	var mu sync.Mutex
+
+	var calls []string
+
+	svc := mockedAWS{
+		ModifyRuleFunc: func(i *elbv2.ModifyRuleInput) (*elbv2.ModifyRuleOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, fmt.Sprintf("%s=%d", *i.RuleArn, *i.Actions[0].ForwardConfig.TargetGroups[0].Weight))
+
+			return &elbv2.ModifyRuleOutput{}, nil
+		},
+	}
+
+	m := &ALBRouter{ELBV2: svc}
+
+	listenerStatuses := ListenerStatuses{
+		"listener2": testListenerStatus("2"),
+		"listener1": testListenerStatus("1"),
+	}
+
+	// The traffic must be fully shifted before the old cluster is retired, never rolled back halfway
+	err := m.SwitchTargetGroup(listenerStatuses, courier.CanaryOpts{Steps: []int{50, 100}, BakeDuration: time.Millisecond})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rule1=50", "rule2=50", "rule1=100", "rule2=100"}, calls)
+}
+
+func TestALBRouter_SwitchTargetGroup_failure(t *testing.T) {
+	var calls []string
+
+	svc := mockedAWS{
+		ModifyRuleFunc: func(i *elbv2.ModifyRuleInput) (*elbv2.ModifyRuleOutput, error) {
+			weight := *i.Actions[0].ForwardConfig.TargetGroups[0].Weight
+
+			calls = append(calls, fmt.Sprintf("%s=%d", *i.RuleArn, weight))
+
+			if weight == 100 {
+				return nil, errors.New("throttled")
+			}
+
+			return &elbv2.ModifyRuleOutput{}, nil
+		},
+	}
+
+	m := &ALBRouter{ELBV2: svc}
+
+	err := m.SwitchTargetGroup(ListenerStatuses{"listener1": testListenerStatus("1")}, courier.CanaryOpts{Steps: []int{50, 100}, BakeDuration: time.Millisecond})
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"rule1=50", "rule1=100", "rule1=0"}, calls)
+}
@@ -0,0 +1,521 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// Typed blocks for the most common sections of cluster.yaml.
+// They are rendered into the spec, so that they get type checking and per-field diffs on plan,
+// while anything else is still written in the raw `spec`.
+const (
+	KeyVPC               = "vpc"
+	KeyManagedNodeGroup  = "managed_nodegroup"
+	KeyIAMServiceAccount = "iam_service_account"
+
+	// unsetSize is the default of the nodegroup sizes, which leaves them to eksctl.
+	// 0 can't be used for that, as it's a valid size for scaling nodegroups to zero.
+	unsetSize = -1
+)
+
+func typedVPCSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cidr": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"nat_gateway": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "",
+					ValidateFunc: validation.StringInSlice([]string{"", "Disable", "Single", "HighlyAvailable"}, false),
+				},
+				"public_access_cidrs": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"subnet": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"type": {
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validation.StringInSlice([]string{"public", "private"}, false),
+							},
+							"az": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"id": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "",
+							},
+							"cidr": {
+								Type:     schema.TypeString,
+								Optional: true,
+								Default:  "",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func typedNodeGroupSchema(managed bool) *schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"desired_capacity": sizeSchema(),
+		"min_size":         sizeSchema(),
+		"max_size":         sizeSchema(),
+		"volume_size": {
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  0,
+		},
+		"ami_family": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+		"private_networking": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// bottlerocket_settings is rendered into the settings TOML of the nodegroups with ami_family "Bottlerocket"
+		"bottlerocket_settings": bottlerocketSettingsSchema(),
+		"labels": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		// extra is the YAML of any other fields of the nodegroup
+		"extra": extraSchema(),
+	}
+
+	if managed {
+		s["instance_types"] = &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		}
+		s["spot"] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		}
+	} else {
+		s["instance_type"] = &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		}
+	}
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem:     &schema.Resource{Schema: s},
+	}
+}
+
+func typedIAMServiceAccountSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"namespace": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "default",
+				},
+				"attach_policy_arns": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"role_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				// extra is the YAML of any other fields of the service account
+				"extra": extraSchema(),
+			},
+		},
+	}
+}
+
+func sizeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      unsetSize,
+		ValidateFunc: validation.IntAtLeast(unsetSize),
+	}
+}
+
+func extraSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Default:  "",
+		ValidateFunc: func(v interface{}, name string) ([]string, []error) {
+			m := map[string]interface{}{}
+
+			if err := yaml.Unmarshal([]byte(v.(string)), &m); err != nil {
+				return nil, []error{fmt.Errorf("%s must be a YAML object: %w", name, err)}
+			}
+
+			return nil, nil
+		},
+	}
+}
+
+var typedSpecKeys = []string{KeyVPC, KeyNodeGroup, KeyManagedNodeGroup, KeyIAMServiceAccount}
+
+// hasTypedSpec returns true when any of the typed blocks is set
+func hasTypedSpec(d Read) bool {
+	for _, k := range typedSpecKeys {
+		if v, ok := d.Get(k).([]interface{}); ok && len(v) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// specChanged returns true when either the spec or any of the typed blocks is changed
+func specChanged(d interface{ HasChange(string) bool }) bool {
+	for _, k := range append([]string{KeySpec, KeySpecs, KeySpecPatches}, typedSpecKeys...) {
+		if d.HasChange(k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeTypedSpec renders the typed blocks into the spec.
+// The spec is returned as is when no typed block is set, so that existing specs are never reformatted.
+// A field set both in the spec and a typed block, or a nodegroup or service account declared in both, is an error,
+// as it's ambiguous which one the user meant.
+func mergeTypedSpec(spec string, d Read) (string, error) {
+	if !hasTypedSpec(d) {
+		return spec, nil
+	}
+
+	config := map[string]interface{}{}
+
+	if err := yaml.Unmarshal([]byte(spec), &config); err != nil {
+		return "", fmt.Errorf("parsing spec: %w", err)
+	}
+
+	if err := mergeTypedVPC(config, d.Get(KeyVPC)); err != nil {
+		return "", err
+	}
+
+	if err := mergeTypedNodeGroups(config, "nodeGroups", d.Get(KeyNodeGroup), false); err != nil {
+		return "", err
+	}
+
+	if err := mergeTypedNodeGroups(config, "managedNodeGroups", d.Get(KeyManagedNodeGroup), true); err != nil {
+		return "", err
+	}
+
+	if err := mergeTypedIAMServiceAccounts(config, d.Get(KeyIAMServiceAccount)); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(config); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func mergeTypedVPC(config map[string]interface{}, v interface{}) error {
+	blocks, _ := v.([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+
+	m := blocks[0].(map[string]interface{})
+
+	vpc, err := childMap(config, "vpc")
+	if err != nil {
+		return err
+	}
+
+	set := func(key string, value interface{}) error {
+		if _, ok := vpc[key]; ok {
+			return fmt.Errorf("vpc.%s is set in both the spec and the %s block", key, KeyVPC)
+		}
+
+		vpc[key] = value
+
+		return nil
+	}
+
+	if s := m["cidr"].(string); s != "" {
+		if err := set("cidr", s); err != nil {
+			return err
+		}
+	}
+
+	if s := m["nat_gateway"].(string); s != "" {
+		if err := set("nat", map[string]interface{}{"gateway": s}); err != nil {
+			return err
+		}
+	}
+
+	if cidrs, _ := m["public_access_cidrs"].([]interface{}); len(cidrs) > 0 {
+		if err := set("publicAccessCIDRs", cidrs); err != nil {
+			return err
+		}
+	}
+
+	if subnets, _ := m["subnet"].([]interface{}); len(subnets) > 0 {
+		if _, ok := vpc["subnets"]; ok {
+			return fmt.Errorf("vpc.subnets is set in both the spec and the %s block", KeyVPC)
+		}
+
+		bySubnetType := map[string]interface{}{}
+
+		for _, s := range subnets {
+			s := s.(map[string]interface{})
+
+			typ := s["type"].(string)
+
+			azs, _ := bySubnetType[typ].(map[string]interface{})
+			if azs == nil {
+				azs = map[string]interface{}{}
+				bySubnetType[typ] = azs
+			}
+
+			subnet := map[string]interface{}{}
+
+			if id := s["id"].(string); id != "" {
+				subnet["id"] = id
+			}
+
+			if cidr := s["cidr"].(string); cidr != "" {
+				subnet["cidr"] = cidr
+			}
+
+			azs[s["az"].(string)] = subnet
+		}
+
+		vpc["subnets"] = bySubnetType
+	}
+
+	return nil
+}
+
+func mergeTypedNodeGroups(config map[string]interface{}, key string, v interface{}, managed bool) error {
+	blocks, _ := v.([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	existing, _ := config[key].([]interface{})
+
+	names := map[string]bool{}
+
+	for _, ng := range existing {
+		if m, ok := ng.(map[string]interface{}); ok {
+			if name, ok := m["name"].(string); ok {
+				names[name] = true
+			}
+		}
+	}
+
+	for _, b := range blocks {
+		m := b.(map[string]interface{})
+
+		ng, err := extraFields(m)
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", key, m["name"], err)
+		}
+
+		name := m["name"].(string)
+
+		if names[name] {
+			return fmt.Errorf("%s %q is declared in both the spec and a block", key, name)
+		}
+
+		names[name] = true
+
+		ng["name"] = name
+
+		for attr, field := range map[string]string{"desired_capacity": "desiredCapacity", "min_size": "minSize", "max_size": "maxSize"} {
+			if size := m[attr].(int); size != unsetSize {
+				ng[field] = size
+			}
+		}
+
+		if size := m["volume_size"].(int); size > 0 {
+			ng["volumeSize"] = size
+		}
+
+		if s := m["ami_family"].(string); s != "" {
+			ng["amiFamily"] = s
+		}
+
+		if m["private_networking"].(bool) {
+			ng["privateNetworking"] = true
+		}
+
+		for attr, field := range map[string]string{"labels": "labels", "tags": "tags"} {
+			if kvs, _ := m[attr].(map[string]interface{}); len(kvs) > 0 {
+				ng[field] = kvs
+			}
+		}
+
+		if err := mergeBottlerocketSettings(ng, m); err != nil {
+			return fmt.Errorf("%s %q: %w", key, name, err)
+		}
+
+		if managed {
+			if types, _ := m["instance_types"].([]interface{}); len(types) > 0 {
+				ng["instanceTypes"] = types
+			}
+
+			if m["spot"].(bool) {
+				ng["spot"] = true
+			}
+		} else {
+			ng["instanceType"] = m["instance_type"].(string)
+		}
+
+		existing = append(existing, ng)
+	}
+
+	config[key] = existing
+
+	return nil
+}
+
+func mergeTypedIAMServiceAccounts(config map[string]interface{}, v interface{}) error {
+	blocks, _ := v.([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	iamConfig, err := childMap(config, "iam")
+	if err != nil {
+		return err
+	}
+
+	existing, _ := iamConfig["serviceAccounts"].([]interface{})
+
+	keys := map[string]bool{}
+
+	for _, sa := range existing {
+		if m, ok := sa.(map[string]interface{}); ok {
+			if md, ok := m["metadata"].(map[string]interface{}); ok {
+				keys[fmt.Sprintf("%v/%v", md["namespace"], md["name"])] = true
+			}
+		}
+	}
+
+	for _, b := range blocks {
+		m := b.(map[string]interface{})
+
+		key := fmt.Sprintf("%s/%s", m["namespace"], m["name"])
+
+		sa, err := extraFields(m)
+		if err != nil {
+			return fmt.Errorf("iam.serviceAccounts %q: %w", key, err)
+		}
+
+		if keys[key] {
+			return fmt.Errorf("iam.serviceAccounts %q is declared in both the spec and a block", key)
+		}
+
+		keys[key] = true
+
+		sa["metadata"] = map[string]interface{}{
+			"name":      m["name"].(string),
+			"namespace": m["namespace"].(string),
+		}
+
+		if arns, _ := m["attach_policy_arns"].([]interface{}); len(arns) > 0 {
+			sa["attachPolicyARNs"] = arns
+		}
+
+		if s := m["role_name"].(string); s != "" {
+			sa["roleName"] = s
+		}
+
+		existing = append(existing, sa)
+	}
+
+	iamConfig["serviceAccounts"] = existing
+
+	return nil
+}
+
+// extraFields parses the `extra` YAML of the block, on top of which the typed fields are set
+func extraFields(m map[string]interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	if s, _ := m["extra"].(string); s != "" {
+		if err := yaml.Unmarshal([]byte(s), &fields); err != nil {
+			return nil, fmt.Errorf("parsing extra: %w", err)
+		}
+	}
+
+	return fields, nil
+}
+
+// childMap returns the map at the key of the config, creating it when missing
+func childMap(config map[string]interface{}, key string) (map[string]interface{}, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		m := map[string]interface{}{}
+		config[key] = m
+
+		return m, nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s in the spec must be an object, but got %T", key, v)
+	}
+
+	return m, nil
+}
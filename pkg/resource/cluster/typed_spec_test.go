@@ -0,0 +1,124 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapRead map[string]interface{}
+
+func (m mapRead) Get(k string) interface{} {
+	return m[k]
+}
+
+func nodeGroupBlock(name string, extra map[string]interface{}) map[string]interface{} {
+	m := map[string]interface{}{
+		"name":               name,
+		"desired_capacity":   unsetSize,
+		"min_size":           unsetSize,
+		"max_size":           unsetSize,
+		"volume_size":        0,
+		"ami_family":         "",
+		"private_networking": false,
+		"labels":             map[string]interface{}{},
+		"tags":               map[string]interface{}{},
+		"extra":              "",
+	}
+
+	for k, v := range extra {
+		m[k] = v
+	}
+
+	return m
+}
+
+func TestMergeTypedSpec_noBlocks(t *testing.T) {
+	spec := "nodeGroups:\n- name: ng1\n  instanceType: m5.large\n"
+
+	merged, err := mergeTypedSpec(spec, mapRead{})
+	assert.NoError(t, err)
+	assert.Equal(t, spec, merged)
+}
+
+func TestMergeTypedSpec(t *testing.T) {
+	d := mapRead{
+		KeyVPC: []interface{}{map[string]interface{}{
+			"cidr":                "",
+			"nat_gateway":         "Single",
+			"public_access_cidrs": []interface{}{},
+			"subnet": []interface{}{
+				map[string]interface{}{"type": "private", "az": "us-east-2a", "id": "subnet-1", "cidr": ""},
+			},
+		}},
+		KeyNodeGroup: []interface{}{nodeGroupBlock("ng2", map[string]interface{}{
+			"instance_type":    "m5.large",
+			"desired_capacity": 0,
+			"extra":            "ssh:\n  allow: true\n",
+		})},
+		KeyManagedNodeGroup: []interface{}{nodeGroupBlock("mng1", map[string]interface{}{
+			"instance_types": []interface{}{"m5.large", "m5a.large"},
+			"spot":           true,
+		})},
+		KeyIAMServiceAccount: []interface{}{map[string]interface{}{
+			"name":               "s3-reader",
+			"namespace":          "default",
+			"attach_policy_arns": []interface{}{"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"},
+			"role_name":          "",
+			"extra":              "",
+		}},
+	}
+
+	merged, err := mergeTypedSpec("nodeGroups:\n- name: ng1\n  instanceType: t3.large\n", d)
+	assert.NoError(t, err)
+	assert.Equal(t, `iam:
+  serviceAccounts:
+    - attachPolicyARNs:
+        - arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess
+      metadata:
+        name: s3-reader
+        namespace: default
+managedNodeGroups:
+  - instanceTypes:
+      - m5.large
+      - m5a.large
+    name: mng1
+    spot: true
+nodeGroups:
+  - instanceType: t3.large
+    name: ng1
+  - desiredCapacity: 0
+    instanceType: m5.large
+    name: ng2
+    ssh:
+      allow: true
+vpc:
+  nat:
+    gateway: Single
+  subnets:
+    private:
+      us-east-2a:
+        id: subnet-1
+`, merged)
+}
+
+func TestMergeTypedSpec_conflicts(t *testing.T) {
+	d := mapRead{
+		KeyNodeGroup: []interface{}{nodeGroupBlock("ng1", map[string]interface{}{"instance_type": "m5.large"})},
+	}
+
+	_, err := mergeTypedSpec("nodeGroups:\n- name: ng1\n", d)
+	assert.EqualError(t, err, `nodeGroups "ng1" is declared in both the spec and a block`)
+
+	d = mapRead{
+		KeyVPC: []interface{}{map[string]interface{}{
+			"cidr":                "10.0.0.0/16",
+			"nat_gateway":         "",
+			"public_access_cidrs": []interface{}{},
+			"subnet":              []interface{}{},
+		}},
+	}
+
+	_, err = mergeTypedSpec("vpc:\n  cidr: 192.168.0.0/16\n", d)
+	assert.EqualError(t, err, "vpc.cidr is set in both the spec and the vpc block")
+}
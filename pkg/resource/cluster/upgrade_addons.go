@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const KeyUpgradeAddons = "upgrade_addons"
+
+// defaultAddonUtils maps the default addons to the `eksctl utils` subcommands that update them
+// when they aren't managed as EKS addons
+var defaultAddonUtils = []struct {
+	Addon string
+	Util  string
+}{
+	{Addon: "kube-proxy", Util: "update-kube-proxy"},
+	{Addon: "vpc-cni", Util: "update-aws-node"},
+	{Addon: "coredns", Util: "update-coredns"},
+}
+
+// postUpgradeCommands returns the args of the eksctl commands that update the system components after a control-plane upgrade,
+// so that kube-proxy, aws-node, and coredns don't end up being skewed from the new cluster version.
+//
+// Components declared in `addons` of cluster.yaml are updated as EKS addons with `eksctl update addon`,
+// and the rest with `eksctl utils update-*`.
+func (c Cluster) postUpgradeCommands() ([][]string, error) {
+	var config struct {
+		Addons []struct {
+			Name string `yaml:"name"`
+		} `yaml:"addons"`
+	}
+
+	if err := yaml.Unmarshal([]byte(c.Spec), &config); err != nil {
+		return nil, fmt.Errorf("parsing cluster.yaml: %w\nCONTENT:\n%s", err, c.Spec)
+	}
+
+	managed := map[string]bool{}
+
+	for _, a := range config.Addons {
+		managed[a.Name] = true
+	}
+
+	var cmds [][]string
+
+	for _, u := range defaultAddonUtils {
+		if !managed[u.Addon] {
+			cmds = append(cmds, []string{"utils", u.Util, "--approve"})
+		}
+	}
+
+	if len(config.Addons) > 0 {
+		cmds = append(cmds, []string{"update", "addon"})
+	}
+
+	return cmds, nil
+}
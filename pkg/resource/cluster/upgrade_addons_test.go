@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostUpgradeCommands(t *testing.T) {
+	cmds, err := Cluster{Spec: `
+nodeGroups:
+- name: ng1
+`}.postUpgradeCommands()
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"utils", "update-kube-proxy", "--approve"},
+		{"utils", "update-aws-node", "--approve"},
+		{"utils", "update-coredns", "--approve"},
+	}, cmds)
+
+	cmds, err = Cluster{Spec: `
+addons:
+- name: vpc-cni
+- name: coredns
+`}.postUpgradeCommands()
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"utils", "update-kube-proxy", "--approve"},
+		{"update", "addon"},
+	}, cmds)
+}
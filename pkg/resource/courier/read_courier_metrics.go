@@ -34,5 +34,18 @@ func readMetrics(d cluster.Read) ([]courier.Metric, error) {
 		metrics = append(metrics, ms...)
 	}
 
+	if v := d.Get("prometheus_metric"); v != nil {
+		ms, err := courier.LoadMetrics(v.([]interface{}))
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range ms {
+			ms[i].Provider = "prometheus"
+		}
+
+		metrics = append(metrics, ms...)
+	}
+
 	return metrics, nil
 }
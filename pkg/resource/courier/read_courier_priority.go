@@ -0,0 +1,140 @@
+package courier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+func readListenerARNs(d Read) []string {
+	var arns []string
+
+	if v := d.Get("listener_arns"); v != nil {
+		for _, arn := range v.([]interface{}) {
+			arns = append(arns, arn.(string))
+		}
+	}
+
+	if len(arns) > 0 {
+		return arns
+	}
+
+	if v := d.Get("listener_arn"); v != nil && v.(string) != "" {
+		arns = append(arns, v.(string))
+	}
+
+	return arns
+}
+
+func readPriorityRange(d Read) (int, int, bool, error) {
+	v := d.Get("priority_range")
+	if v == nil {
+		return 0, 0, false, nil
+	}
+
+	rs := v.([]interface{})
+	if len(rs) == 0 || rs[0] == nil {
+		return 0, 0, false, nil
+	}
+
+	m := rs[0].(map[string]interface{})
+
+	min, max := m["min"].(int), m["max"].(int)
+
+	if min > max {
+		return 0, 0, false, fmt.Errorf("priority_range.min %d must not be greater than priority_range.max %d", min, max)
+	}
+
+	return min, max, true, nil
+}
+
+// toPriorityConf returns the part of the courier config needed to look up the rule priorities on the listeners
+func toPriorityConf(d Read, p *resource.ProviderConfig) (*courier.CourierALB, error) {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
+
+	conf := &courier.CourierALB{
+		Region:       region,
+		Profile:      profile,
+		AWS:          p.AWSConfig(),
+		ListenerARNs: readListenerARNs(d),
+	}
+
+	if v := d.Get("address"); v != nil {
+		conf.Address = v.(string)
+	}
+
+	if v := d.Get("priority"); v != nil {
+		conf.Priority = v.(int)
+	}
+
+	if len(conf.ListenerARNs) == 0 {
+		return nil, errors.New("either listener_arn or listener_arns is required")
+	}
+
+	return conf, nil
+}
+
+// validatePriority fails the plan when the priority of a new rule is already used on any of the listeners
+func validatePriority(d *schema.ResourceDiff, p *resource.ProviderConfig) error {
+	if d.Id() != "" && !d.HasChange("priority") {
+		return nil
+	}
+
+	for _, k := range []string{"listener_arn", "listener_arns", "priority", "region", "profile", "address"} {
+		if !d.NewValueKnown(k) {
+			return nil
+		}
+	}
+
+	_, _, hasRange, err := readPriorityRange(d)
+	if err != nil {
+		return err
+	}
+
+	conf, err := toPriorityConf(d, p)
+	if err != nil {
+		return err
+	}
+
+	if conf.Priority == 0 {
+		if hasRange {
+			// The priority is allocated on create
+			return nil
+		}
+
+		conf.Priority = courier.DefaultRulePriority
+	}
+
+	return conf.ValidatePriority()
+}
+
+// allocatePriority sets the priority of the new rule, either the default or the lowest one unused within priority_range
+func allocatePriority(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	if v, ok := d.GetOk("priority"); ok && v.(int) != 0 {
+		return nil
+	}
+
+	min, max, hasRange, err := readPriorityRange(d)
+	if err != nil {
+		return err
+	}
+
+	if !hasRange {
+		return d.Set("priority", courier.DefaultRulePriority)
+	}
+
+	conf, err := toPriorityConf(d, p)
+	if err != nil {
+		return err
+	}
+
+	priority, err := conf.AllocatePriority(min, max)
+	if err != nil {
+		return err
+	}
+
+	return d.Set("priority", priority)
+}
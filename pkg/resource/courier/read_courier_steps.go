@@ -0,0 +1,28 @@
+package courier
+
+import (
+	"fmt"
+	"time"
+)
+
+// readSteps reads `steps` and `bake_duration`, which override `step_weight` and `step_interval` respectively when set
+func readSteps(d Read, stepInterval time.Duration) ([]int, time.Duration, error) {
+	var steps []int
+
+	if v := d.Get("steps"); v != nil {
+		for _, w := range v.([]interface{}) {
+			steps = append(steps, w.(int))
+		}
+	}
+
+	if v, _ := d.Get("bake_duration").(string); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error parsing bake_duration %v: %w", v, err)
+		}
+
+		stepInterval = d
+	}
+
+	return steps, stepInterval, nil
+}
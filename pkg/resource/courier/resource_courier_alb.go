@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"github.com/rs/xid"
+	"math"
 	"time"
 )
 
@@ -47,6 +50,172 @@ var MetricResourceSchema = map[string]*schema.Schema{
 	},
 }
 
+var GRPCHealthCheckSchema = &schema.Schema{
+	Type:       schema.TypeList,
+	Optional:   true,
+	ConfigMode: schema.SchemaConfigModeBlock,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"address": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"service": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"tls": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"timeout_sec": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  5,
+			},
+		},
+	},
+}
+
+var PrometheusGateSchema = &schema.Schema{
+	Type:       schema.TypeList,
+	Optional:   true,
+	ConfigMode: schema.SchemaConfigModeBlock,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"address": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"query": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"max": {
+				Type:     schema.TypeFloat,
+				Optional: true,
+				Default:  math.MaxFloat64,
+			},
+			"min": {
+				Type:     schema.TypeFloat,
+				Optional: true,
+				Default:  -math.MaxFloat64,
+			},
+			"on_violation": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      courier.PrometheusGateOnViolationRollback,
+				ValidateFunc: validation.StringInSlice([]string{courier.PrometheusGateOnViolationRollback, courier.PrometheusGateOnViolationPause}, false),
+			},
+			"pause_timeout_sec": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  600,
+			},
+			"interval_sec": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  10,
+			},
+		},
+	},
+}
+
+// StepsSchema is the list of weights of the desired destination at each traffic-shift step, which takes precedence over step_weight
+var StepsSchema = &schema.Schema{
+	Type:     schema.TypeList,
+	Optional: true,
+	Elem: &schema.Schema{
+		Type:         schema.TypeInt,
+		ValidateFunc: validation.IntBetween(1, 100),
+	},
+}
+
+// BakeDurationSchema is how long each step lasts before advancing to the next one, which takes precedence over step_interval
+var BakeDurationSchema = &schema.Schema{
+	Type:         schema.TypeString,
+	Optional:     true,
+	Default:      "",
+	ValidateFunc: validateOptionalDuration,
+}
+
+// ApprovalSchema pauses the traffic shift at `weight` until a file exists, an SSM parameter has the value, or an HTTP endpoint returns 200
+var ApprovalSchema = &schema.Schema{
+	Type:       schema.TypeList,
+	Optional:   true,
+	ConfigMode: schema.SchemaConfigModeBlock,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"weight": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(1, 99),
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{courier.ApprovalTypeFile, courier.ApprovalTypeSSM, courier.ApprovalTypeHTTP}, false),
+			},
+			"file_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"ssm_parameter_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"ssm_parameter_value": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  courier.DefaultApprovalSSMParameterValue,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"timeout_sec": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  3600,
+			},
+			"interval_sec": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  10,
+			},
+		},
+	},
+}
+
+// SwitchoverMetricsSchema publishes the traffic weight, the step number, and the health check results to CloudWatch during the traffic shift
+var SwitchoverMetricsSchema = &schema.Schema{
+	Type:       schema.TypeList,
+	Optional:   true,
+	MaxItems:   1,
+	ConfigMode: schema.SchemaConfigModeBlock,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  courier.DefaultSwitchoverMetricsNamespace,
+			},
+			"dimensions": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	},
+}
+
 var MetricsSchema = &schema.Schema{
 	Type:       schema.TypeList,
 	Optional:   true,
@@ -64,22 +233,36 @@ func ResourceALB() *schema.Resource {
 			id := xid.New().String()
 			d.SetId(id)
 
-			if err := createOrUpdateCourierALB(d); err != nil {
+			if err := allocatePriority(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("creating courier_alb: %w", err)
+			}
+
+			if err := createOrUpdateCourierALB(d, resource.ProviderConfigFromMeta(meta)); err != nil {
 				return fmt.Errorf("creating courier_alb: %w", err)
 			}
 			return nil
 		},
 		Update: func(d *schema.ResourceData, meta interface{}) error {
-			if err := createOrUpdateCourierALB(d); err != nil {
+			if err := createOrUpdateCourierALB(d, resource.ProviderConfigFromMeta(meta)); err != nil {
 				return fmt.Errorf("updating courier_alb: %w", err)
 			}
 			return nil
 		},
-		CustomizeDiff: func(diff *schema.ResourceDiff, i interface{}) error {
+		CustomizeDiff: func(diff *schema.ResourceDiff, meta interface{}) error {
+			p := resource.ProviderConfigFromMeta(meta)
+
+			if err := resource.ValidateAWSRegion(diff, p); err != nil {
+				return err
+			}
+
+			if err := validatePriority(diff, p); err != nil {
+				return fmt.Errorf("validating priority: %w", err)
+			}
+
 			return nil
 		},
 		Delete: func(d *schema.ResourceData, meta interface{}) error {
-			if err := deleteCourierALB(d); err != nil {
+			if err := deleteCourierALB(d, resource.ProviderConfigFromMeta(meta)); err != nil {
 				return err
 			}
 
@@ -107,24 +290,59 @@ func ResourceALB() *schema.Resource {
 				Default:  "",
 			},
 			"listener_arn": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"listener_arns"},
+			},
+			// listener_arns makes the courier manage the rule with the same priority and conditions on all the listeners,
+			// shifting their traffic in lockstep
+			"listener_arns": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"listener_arn"},
 			},
 			"step_weight": {
 				Type:         schema.TypeInt,
-				Required:     true,
+				Optional:     true,
+				Default:      courier.DefaultCanaryAdvancementStep,
 				ValidateFunc: validation.IntBetween(1, 100),
 			},
 			"step_interval": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
+				Default:      courier.DefaultCanaryAdvancementInterval.String(),
 				ValidateFunc: ValidateDuration,
 			},
+			"steps":         StepsSchema,
+			"bake_duration": BakeDurationSchema,
 			// Listener rule settings
+			// priority defaults to 10 unless priority_range is set, in which case the lowest priority unused on the listeners is allocated on create
 			"priority": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  10,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(courier.MinRulePriority, courier.MaxRulePriority),
+			},
+			"priority_range": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"priority"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(courier.MinRulePriority, courier.MaxRulePriority),
+						},
+						"max": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(courier.MinRulePriority, courier.MaxRulePriority),
+						},
+					},
+				},
 			},
 			"hosts": {
 				Type:          schema.TypeSet,
@@ -251,8 +469,13 @@ produces:
  }
 `,
 			},
-			"datadog_metric":    MetricsSchema,
-			"cloudwatch_metric": MetricsSchema,
+			"datadog_metric":     MetricsSchema,
+			"cloudwatch_metric":  MetricsSchema,
+			"prometheus_metric":  MetricsSchema,
+			"grpc_health_check":  GRPCHealthCheckSchema,
+			"prometheus_gate":    PrometheusGateSchema,
+			"approval":           ApprovalSchema,
+			"switchover_metrics": SwitchoverMetricsSchema,
 			"destination": {
 				Type:       schema.TypeList,
 				Optional:   true,
@@ -274,6 +497,14 @@ produces:
 	}
 }
 
+func validateOptionalDuration(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) == "" {
+		return
+	}
+
+	return ValidateDuration(v, k)
+}
+
 func ValidateDuration(v interface{}, k string) (ws []string, errors []error) {
 	if _, err := time.ParseDuration(v.(string)); err != nil {
 		errors = append(errors, fmt.Errorf("%q: invalid duration", k))
@@ -1,6 +1,7 @@
 package courier
 
 import (
+	"errors"
 	"fmt"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
 	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
@@ -12,19 +13,28 @@ type Read interface {
 	Get(string) interface{}
 }
 
-func toConf(d Read) (*courier.CourierALB, error) {
-	region, profile := resource.GetAWSRegionAndProfile(d)
+func toConf(d Read, p *resource.ProviderConfig) (*courier.CourierALB, error) {
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
 
 	conf := courier.CourierALB{
 		Region:  region,
 		Profile: profile,
+		AWS:     p.AWSConfig(),
 	}
 
 	if v := d.Get("address"); v != nil {
 		conf.Address = v.(string)
 	}
 
-	conf.ListenerARN = d.Get("listener_arn").(string)
+	if v := d.Get("listener_arn"); v != nil {
+		conf.ListenerARN = v.(string)
+	}
+
+	conf.ListenerARNs = readListenerARNs(d)
+
+	if len(conf.ListenerARNs) == 0 {
+		return nil, errors.New("either listener_arn or listener_arns is required")
+	}
 
 	conf.Priority = d.Get("priority").(int)
 
@@ -64,6 +74,12 @@ func toConf(d Read) (*courier.CourierALB, error) {
 		stepInterval = d
 	}
 
+	steps, stepInterval, err := readSteps(d, stepInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.Steps = steps
 	conf.StepInterval = stepInterval
 
 	metrics, err := readMetrics(d)
@@ -73,6 +89,22 @@ func toConf(d Read) (*courier.CourierALB, error) {
 
 	conf.Metrics = metrics
 
+	if v := d.Get("grpc_health_check"); v != nil {
+		conf.GRPCHealthChecks = courier.LoadGRPCHealthChecks(v.([]interface{}))
+	}
+
+	if v := d.Get("prometheus_gate"); v != nil {
+		conf.PrometheusGates = courier.LoadPrometheusGates(v.([]interface{}))
+	}
+
+	if v := d.Get("approval"); v != nil {
+		conf.Approvals = courier.LoadApprovals(v.([]interface{}), region, profile)
+	}
+
+	if v := d.Get("switchover_metrics"); v != nil {
+		conf.SwitchoverMetrics = courier.LoadSwitchoverMetrics(v.([]interface{}), region, profile)
+	}
+
 	lr, err := courier.ReadListenerRule(d)
 	if err != nil {
 		return nil, err
@@ -83,8 +115,8 @@ func toConf(d Read) (*courier.CourierALB, error) {
 	return &conf, nil
 }
 
-func deleteCourierALB(d cluster.Read) error {
-	conf, err := toConf(d)
+func deleteCourierALB(d cluster.Read, p *resource.ProviderConfig) error {
+	conf, err := toConf(d, p)
 	if err != nil {
 		return err
 	}
@@ -94,8 +126,8 @@ func deleteCourierALB(d cluster.Read) error {
 	return alb.Delete(conf)
 }
 
-func createOrUpdateCourierALB(d Read) error {
-	conf, err := toConf(d)
+func createOrUpdateCourierALB(d Read, p *resource.ProviderConfig) error {
+	conf, err := toConf(d, p)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,103 @@
+package courier
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/rs/xid"
+)
+
+// ResourceGlobalAccelerator shifts traffic between clusters by gradually updating the weights of the endpoints
+// in an AWS Global Accelerator endpoint group, for clusters fronted by Global Accelerator instead of ALB or Route 53.
+func ResourceGlobalAccelerator() *schema.Resource {
+	return &schema.Resource{
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			d.MarkNewResource()
+
+			id := xid.New().String()
+			d.SetId(id)
+
+			if err := createOrUpdateCourierGlobalAccelerator(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("creating courier_global_accelerator: %w", err)
+			}
+			return nil
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			if err := createOrUpdateCourierGlobalAccelerator(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("updating courier_global_accelerator: %w", err)
+			}
+			return nil
+		},
+		Delete: func(d *schema.ResourceData, meta interface{}) error {
+			d.SetId("")
+
+			return nil
+		},
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			// region is the region of the Global Accelerator API, which is always us-west-2 as of today.
+			// It's used for the metrics too.
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  courier.GlobalAcceleratorAPIRegion,
+			},
+			"profile": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"address": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"endpoint_group_arn": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"step_weight": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      courier.DefaultCanaryAdvancementStep,
+				ValidateFunc: validation.IntBetween(1, 100),
+			},
+			"step_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      courier.DefaultCanaryAdvancementInterval.String(),
+				ValidateFunc: ValidateDuration,
+			},
+			"steps":             StepsSchema,
+			"bake_duration":     BakeDurationSchema,
+			"datadog_metric":    MetricsSchema,
+			"cloudwatch_metric": MetricsSchema,
+			"prometheus_metric": MetricsSchema,
+			"destination": {
+				Type:       schema.TypeList,
+				Required:   true,
+				MinItems:   2,
+				MaxItems:   2,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// endpoint_id is the ARN of the ALB or NLB, or the ID of the EIP in front of the cluster
+						"endpoint_id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"weight": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(0, 100),
+						},
+					},
+				},
+			},
+		},
+	}
+}
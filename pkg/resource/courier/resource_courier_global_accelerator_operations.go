@@ -0,0 +1,94 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/globalaccelerator"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+func createOrUpdateCourierGlobalAccelerator(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	ctx := context.Background()
+
+	region := d.Get("region").(string)
+	if region == "" {
+		region = courier.GlobalAcceleratorAPIRegion
+	}
+
+	profile := d.Get("profile").(string)
+
+	sess := p.AWSConfig().NewSession(region, profile)
+
+	if v := d.Get("address"); v != nil && v.(string) != "" {
+		sess.Config.Endpoint = aws.String(v.(string))
+	}
+
+	metrics, err := readMetrics(d)
+	if err != nil {
+		return err
+	}
+
+	var destinations []courier.DestinationEndpoint
+
+	if v := d.Get("destination"); v != nil {
+		for _, arrayItem := range v.([]interface{}) {
+			m := arrayItem.(map[string]interface{})
+
+			destinations = append(destinations, courier.DestinationEndpoint{
+				EndpointID: m["endpoint_id"].(string),
+				Weight:     m["weight"].(int),
+			})
+		}
+	}
+
+	stepInterval := 1 * time.Second
+	if v := d.Get("step_interval"); v != nil {
+		d, err := time.ParseDuration(v.(string))
+		if err != nil {
+			return fmt.Errorf("error parsing step_interval %v: %w", v, err)
+		}
+
+		stepInterval = d
+	}
+
+	stepWeight := 50
+	if v := d.Get("step_weight"); v != nil {
+		stepWeight = v.(int)
+	}
+
+	steps, stepInterval, err := readSteps(d, stepInterval)
+	if err != nil {
+		return err
+	}
+
+	r := &courier.GlobalAcceleratorRouter{
+		Service:                   globalaccelerator.New(sess),
+		EndpointGroupARN:          d.Get("endpoint_group_arn").(string),
+		Destinations:              destinations,
+		CanaryAdvancementInterval: stepInterval,
+		CanaryAdvancementStep:     stepWeight,
+		Steps:                     steps,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e, errctx := errgroup.WithContext(ctx)
+
+	e.Go(func() error {
+		defer cancel()
+		return r.TrafficShift(errctx)
+	})
+
+	type templateData struct {
+	}
+
+	e.Go(func() error {
+		return courier.Analyze(errctx, p.AWSConfig(), region, profile, metrics, &templateData{})
+	})
+
+	return e.Wait()
+}
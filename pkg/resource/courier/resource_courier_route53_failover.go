@@ -0,0 +1,201 @@
+package courier
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+	"github.com/rs/xid"
+)
+
+// ResourceRoute53Failover manages latency-based or failover Route 53 record sets across clusters in multiple regions,
+// so that blue/green deployments can be extended to region evacuation scenarios.
+func ResourceRoute53Failover() *schema.Resource {
+	return &schema.Resource{
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			d.MarkNewResource()
+
+			id := xid.New().String()
+			d.SetId(id)
+
+			if err := applyCourierRoute53Failover(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("creating courier_route53_failover: %w", err)
+			}
+			return nil
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			if err := applyCourierRoute53Failover(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("updating courier_route53_failover: %w", err)
+			}
+			return nil
+		},
+		Delete: func(d *schema.ResourceData, meta interface{}) error {
+			if err := destroyCourierRoute53Failover(d, resource.ProviderConfigFromMeta(meta)); err != nil {
+				return fmt.Errorf("deleting courier_route53_failover: %w", err)
+			}
+
+			d.SetId("")
+
+			return nil
+		},
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"profile": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"address": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"zone_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "A",
+			},
+			"routing_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      courier.RoutingPolicyLatency,
+				ValidateFunc: validation.StringInSlice([]string{courier.RoutingPolicyLatency, courier.RoutingPolicyFailover}, false),
+			},
+			"ttl": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  60,
+			},
+			"evaluate_target_health": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			// health_check_ids are the IDs of the health checks created for the endpoints with health_check, keyed by set_identifier
+			"health_check_ids": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"endpoint": {
+				Type:       schema.TypeList,
+				Required:   true,
+				MinItems:   1,
+				ConfigMode: schema.SchemaConfigModeBlock,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"set_identifier": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						// region is the region of the cluster. Required for the latency routing policy
+						"region": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"alias_dns_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"alias_zone_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"values": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"health_check_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						// health_check makes the provider create and update the Route 53 health check for the endpoint, which takes precedence over health_check_id
+						"health_check": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										Default:      courier.Route53HealthCheckTypeHTTPS,
+										ValidateFunc: validation.StringInSlice([]string{courier.Route53HealthCheckTypeHTTP, courier.Route53HealthCheckTypeHTTPS, courier.Route53HealthCheckTypeTCP}, false),
+									},
+									// fqdn defaults to alias_dns_name
+									"fqdn": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "",
+									},
+									// port defaults to 443 for HTTPS and 80 for the others
+									"port": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"resource_path": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "/",
+									},
+									"failure_threshold": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      3,
+										ValidateFunc: validation.IntBetween(1, 10),
+									},
+									"request_interval": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      30,
+										ValidateFunc: validation.IntInSlice([]int{10, 30}),
+									},
+								},
+							},
+						},
+						// failover is either PRIMARY or SECONDARY. Required for the failover routing policy
+						"failover": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							ValidateFunc: validation.StringInSlice([]string{"", "PRIMARY", "SECONDARY"}, false),
+						},
+						// evacuate removes the endpoint from the routing, so that the traffic is routed to the other regions.
+						// The provider refuses to evacuate unless at least one of the remaining endpoints is healthy.
+						"evacuate": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,181 @@
+package courier
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
+)
+
+func applyCourierRoute53Failover(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	r, err := newRoute53FailoverRouter(d, p, d.Get("endpoint"))
+	if err != nil {
+		return err
+	}
+
+	// Endpoints removed from the config are no longer managed, so their record sets are deleted
+	var removed []string
+
+	if d.HasChange("endpoint") {
+		old, _ := d.GetChange("endpoint")
+
+		current := map[string]bool{}
+
+		for _, e := range r.Endpoints {
+			current[e.SetIdentifier] = true
+		}
+
+		for _, e := range readRegionalEndpoints(old) {
+			if !current[e.SetIdentifier] {
+				removed = append(removed, e.SetIdentifier)
+			}
+		}
+	}
+
+	existing := readHealthCheckIDs(d)
+
+	ids, stale, err := r.EnsureHealthChecks(existing)
+	if err != nil {
+		// Keep track of the health checks created so far, so that they are deleted later
+		for setIdentifier, id := range existing {
+			if _, ok := ids[setIdentifier]; !ok {
+				ids[setIdentifier] = id
+			}
+		}
+
+		_ = d.Set("health_check_ids", ids)
+
+		return err
+	}
+
+	if err := d.Set("health_check_ids", ids); err != nil {
+		return fmt.Errorf("setting health_check_ids: %w", err)
+	}
+
+	if err := r.Apply(removed); err != nil {
+		return err
+	}
+
+	// Health checks are deleted only after the record sets stop referencing them
+	return r.DeleteHealthChecks(stale)
+}
+
+func destroyCourierRoute53Failover(d *schema.ResourceData, p *resource.ProviderConfig) error {
+	r, err := newRoute53FailoverRouter(d, p, d.Get("endpoint"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.Destroy(); err != nil {
+		return err
+	}
+
+	var ids []string
+
+	for _, id := range readHealthCheckIDs(d) {
+		ids = append(ids, id)
+	}
+
+	return r.DeleteHealthChecks(ids)
+}
+
+func readHealthCheckIDs(d Read) map[string]string {
+	ids := map[string]string{}
+
+	if v, ok := d.Get("health_check_ids").(map[string]interface{}); ok {
+		for setIdentifier, id := range v {
+			ids[setIdentifier] = id.(string)
+		}
+	}
+
+	return ids
+}
+
+func newRoute53FailoverRouter(d Read, p *resource.ProviderConfig, endpoints interface{}) (*courier.Route53FailoverRouter, error) {
+	sess := resource.AWSSessionFromResourceData(d, p)
+
+	if v := d.Get("address"); v != nil && v.(string) != "" {
+		sess.Config.Endpoint = aws.String(v.(string))
+	}
+
+	r := &courier.Route53FailoverRouter{
+		Service:              route53.New(sess),
+		HostedZoneID:         d.Get("zone_id").(string),
+		RecordName:           d.Get("name").(string),
+		RecordType:           d.Get("type").(string),
+		RoutingPolicy:        d.Get("routing_policy").(string),
+		TTL:                  int64(d.Get("ttl").(int)),
+		EvaluateTargetHealth: d.Get("evaluate_target_health").(bool),
+		Endpoints:            readRegionalEndpoints(endpoints),
+	}
+
+	for _, e := range r.Endpoints {
+		switch r.RoutingPolicy {
+		case courier.RoutingPolicyFailover:
+			if e.Failover == "" {
+				return nil, fmt.Errorf("endpoint %q: failover must be set for routing_policy %q", e.SetIdentifier, r.RoutingPolicy)
+			}
+		default:
+			if e.Region == "" {
+				return nil, fmt.Errorf("endpoint %q: region must be set for routing_policy %q", e.SetIdentifier, r.RoutingPolicy)
+			}
+		}
+
+		if e.HealthCheck != nil && e.HealthCheck.FQDN == "" && e.AliasDNSName == "" {
+			return nil, fmt.Errorf("endpoint %q: health_check.fqdn must be set when alias_dns_name is not set", e.SetIdentifier)
+		}
+
+		if e.AliasDNSName == "" && len(e.Values) == 0 {
+			return nil, fmt.Errorf("endpoint %q: either alias_dns_name or values must be set", e.SetIdentifier)
+		}
+	}
+
+	return r, nil
+}
+
+func readRegionalEndpoints(v interface{}) []courier.RegionalEndpoint {
+	var endpoints []courier.RegionalEndpoint
+
+	if v == nil {
+		return endpoints
+	}
+
+	for _, arrayItem := range v.([]interface{}) {
+		m := arrayItem.(map[string]interface{})
+
+		e := courier.RegionalEndpoint{
+			SetIdentifier:     m["set_identifier"].(string),
+			Region:            m["region"].(string),
+			AliasDNSName:      m["alias_dns_name"].(string),
+			AliasHostedZoneID: m["alias_zone_id"].(string),
+			HealthCheckID:     m["health_check_id"].(string),
+			Failover:          m["failover"].(string),
+			Evacuate:          m["evacuate"].(bool),
+		}
+
+		if hcs, ok := m["health_check"].([]interface{}); ok && len(hcs) > 0 && hcs[0] != nil {
+			hc := hcs[0].(map[string]interface{})
+
+			e.HealthCheck = &courier.Route53HealthCheck{
+				Type:             hc["type"].(string),
+				FQDN:             hc["fqdn"].(string),
+				Port:             int64(hc["port"].(int)),
+				ResourcePath:     hc["resource_path"].(string),
+				FailureThreshold: int64(hc["failure_threshold"].(int)),
+				RequestInterval:  int64(hc["request_interval"].(int)),
+			}
+		}
+
+		if vs, ok := m["values"].([]interface{}); ok {
+			for _, v := range vs {
+				e.Values = append(e.Values, v.(string))
+			}
+		}
+
+		endpoints = append(endpoints, e)
+	}
+
+	return endpoints
+}
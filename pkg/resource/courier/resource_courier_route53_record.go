@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/courier"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/resource"
 	"github.com/rs/xid"
 )
 
@@ -15,13 +17,13 @@ func ResourceRoute53Record() *schema.Resource {
 			id := xid.New().String()
 			d.SetId(id)
 
-			if err := createOrUpdateCourierRoute53Record(d); err != nil {
+			if err := createOrUpdateCourierRoute53Record(d, resource.ProviderConfigFromMeta(meta)); err != nil {
 				return fmt.Errorf("updating courier_route53_record: %w", err)
 			}
 			return nil
 		},
 		Update: func(d *schema.ResourceData, meta interface{}) error {
-			if err := createOrUpdateCourierRoute53Record(d); err != nil {
+			if err := createOrUpdateCourierRoute53Record(d, resource.ProviderConfigFromMeta(meta)); err != nil {
 				return fmt.Errorf("updating courier_route53_record: %w", err)
 			}
 			return nil
@@ -63,16 +65,21 @@ func ResourceRoute53Record() *schema.Resource {
 			},
 			"step_weight": {
 				Type:         schema.TypeInt,
-				Required:     true,
+				Optional:     true,
+				Default:      courier.DefaultCanaryAdvancementStep,
 				ValidateFunc: validation.IntBetween(1, 100),
 			},
 			"step_interval": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
+				Default:      courier.DefaultCanaryAdvancementInterval.String(),
 				ValidateFunc: ValidateDuration,
 			},
+			"steps":             StepsSchema,
+			"bake_duration":     BakeDurationSchema,
 			"datadog_metric":    MetricsSchema,
 			"cloudwatch_metric": MetricsSchema,
+			"prometheus_metric": MetricsSchema,
 			"destination": {
 				Type:       schema.TypeList,
 				Optional:   true,
@@ -12,10 +12,10 @@ import (
 	"time"
 )
 
-func createOrUpdateCourierRoute53Record(d *schema.ResourceData) error {
+func createOrUpdateCourierRoute53Record(d *schema.ResourceData, p *resource.ProviderConfig) error {
 	ctx := context.Background()
 
-	sess := resource.AWSSessionFromResourceData(d)
+	sess := resource.AWSSessionFromResourceData(d, p)
 
 	if v := d.Get("address"); v != nil {
 		sess.Config.Endpoint = aws.String(v.(string))
@@ -30,7 +30,7 @@ func createOrUpdateCourierRoute53Record(d *schema.ResourceData) error {
 		return err
 	}
 
-	region, profile := resource.GetAWSRegionAndProfile(d)
+	region, profile := resource.GetAWSRegionAndProfile(d, p)
 
 	recordName := d.Get("name").(string)
 
@@ -71,6 +71,11 @@ func createOrUpdateCourierRoute53Record(d *schema.ResourceData) error {
 		stepWeight = v.(int)
 	}
 
+	steps, stepInterval, err := readSteps(d, stepInterval)
+	if err != nil {
+		return err
+	}
+
 	r := &courier.Route53RecordSetRouter{
 		Service:                   svc,
 		RecordName:                recordName,
@@ -78,6 +83,7 @@ func createOrUpdateCourierRoute53Record(d *schema.ResourceData) error {
 		Destinations:              destinations,
 		CanaryAdvancementInterval: stepInterval,
 		CanaryAdvancementStep:     stepWeight,
+		Steps:                     steps,
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -92,7 +98,7 @@ func createOrUpdateCourierRoute53Record(d *schema.ResourceData) error {
 	}
 
 	e.Go(func() error {
-		return courier.Analyze(errctx, region, profile, metrics, &templateData{})
+		return courier.Analyze(errctx, p.AWSConfig(), region, profile, metrics, &templateData{})
 	})
 
 	return e.Wait()
@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// envCredentialHelper attaches the credential helper of the provider to the command as a JSON array,
+// so that the command carries it through CommandRunners to ExecRunner.
+// ExecRunner removes it from the environment before running the command.
+const envCredentialHelper = "TF_EKSCTL_CREDENTIAL_HELPER"
+
+// WithCredentialHelper marks the command to run via the credential helper of the provider, if any.
+// The command is rewritten only when ExecRunner runs it, so that a CommandRunner sees the command as is.
+func (p *ProviderConfig) WithCredentialHelper(cmd *exec.Cmd) *exec.Cmd {
+	if p == nil || len(p.CredentialHelper) == 0 {
+		return cmd
+	}
+
+	helper, err := json.Marshal(p.CredentialHelper)
+	if err != nil {
+		panic(err)
+	}
+
+	setCommandEnv(cmd, envCredentialHelper, string(helper))
+
+	return cmd
+}
+
+// WithoutCredentialHelper unmarks the command, like a kubectl command that is run with the environment of an eksctl command
+func WithoutCredentialHelper(cmd *exec.Cmd) *exec.Cmd {
+	popCommandEnv(cmd, envCredentialHelper)
+
+	return cmd
+}
+
+// wrapWithCredentialHelper rewrites the command marked by WithCredentialHelper to run via the credential helper.
+// The environment variables of the command are passed to the helper, which is expected to pass them down to the wrapped command.
+func wrapWithCredentialHelper(cmd *exec.Cmd) (*exec.Cmd, error) {
+	v, ok := popCommandEnv(cmd, envCredentialHelper)
+	if !ok {
+		return cmd, nil
+	}
+
+	var helper []string
+
+	if err := json.Unmarshal([]byte(v), &helper); err != nil {
+		return nil, fmt.Errorf("parsing credential helper %q: %w", v, err)
+	}
+
+	if len(helper) == 0 {
+		return cmd, nil
+	}
+
+	bin, err := exec.LookPath(helper[0])
+	if err != nil {
+		return nil, fmt.Errorf("looking up credential helper %q: %w", helper[0], err)
+	}
+
+	args := append(append([]string{}, helper[1:]...), cmd.Path)
+	args = append(args, cmd.Args[1:]...)
+
+	wrapped := exec.Command(bin, args...)
+	wrapped.Args[0] = helper[0]
+	wrapped.Env = cmd.Env
+	wrapped.Dir = cmd.Dir
+	wrapped.Stdin = cmd.Stdin
+
+	return wrapped, nil
+}
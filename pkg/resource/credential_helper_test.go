@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCredentialHelper(t *testing.T) {
+	cmd := exec.Command("/usr/local/bin/eksctl", "get", "cluster")
+	cmd.Env = []string{"AWS_REGION=us-east-2"}
+	cmd.Stdin = strings.NewReader("kind: ClusterConfig")
+
+	var unconfigured *ProviderConfig
+
+	unwrapped, err := wrapWithCredentialHelper(unconfigured.WithCredentialHelper(cmd))
+	require.NoError(t, err)
+	assert.Same(t, cmd, unwrapped)
+
+	p := &ProviderConfig{
+		CredentialHelper: []string{"env", "FOO=bar", "--"},
+		AuditLog:         NewAuditLog(AuditLog{Path: "/dev/null"}),
+	}
+
+	// Commands not marked with WithCredentialHelper, like kubectl and hooks, are never wrapped
+	unmarked, err := wrapWithCredentialHelper(cmd)
+	require.NoError(t, err)
+	assert.Same(t, cmd, unmarked)
+
+	wrapped, err := wrapWithCredentialHelper(p.WithAuditLog(p.WithCredentialHelper(cmd), "primary"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"env", "FOO=bar", "--", "/usr/local/bin/eksctl", "get", "cluster"}, wrapped.Args)
+	assert.Same(t, cmd.Stdin, wrapped.Stdin)
+
+	// The wrapped command is recorded into the audit log on behalf of the resource too
+	auditLog, resourceID := popAuditLog(wrapped)
+	assert.Same(t, p.AuditLog, auditLog)
+	assert.Equal(t, "primary", resourceID)
+	assert.Equal(t, []string{"AWS_REGION=us-east-2"}, wrapped.Env)
+
+	// kubectl commands run with the environment of eksctl commands are never wrapped either
+	kubectl := WithoutCredentialHelper(p.WithCredentialHelper(exec.Command("kubectl", "version")))
+
+	unwrapped, err = wrapWithCredentialHelper(kubectl)
+	require.NoError(t, err)
+	assert.Same(t, kubectl, unwrapped)
+
+	// Aliased providers have their own credential helpers
+	other := &ProviderConfig{CredentialHelper: []string{"no-such-credential-helper"}}
+
+	_, err = wrapWithCredentialHelper(other.WithCredentialHelper(cmd))
+	assert.Error(t, err)
+}
+
+func TestRun_credentialHelper(t *testing.T) {
+	p := &ProviderConfig{CredentialHelper: []string{"env", "FOO=bar"}}
+
+	var seen []string
+
+	prev := SetCommandRunner(CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error) {
+		seen = cmd.Args
+
+		return ExecRunner.Run(cmd, timeout)
+	}))
+	defer SetCommandRunner(prev)
+
+	cmd := exec.Command("bash", "-c", "echo $FOO ${TF_EKSCTL_CREDENTIAL_HELPER:-unset}")
+	cmd.Env = []string{}
+
+	cmd = p.WithCredentialHelper(cmd)
+
+	res, err := Run(cmd)
+	require.NoError(t, err)
+
+	// The runner sees the command before it is wrapped, while ExecRunner runs it via the helper
+	assert.Equal(t, []string{"bash", "-c", "echo $FOO ${TF_EKSCTL_CREDENTIAL_HELPER:-unset}"}, seen)
+	assert.Equal(t, "bar unset\n", res.Stdout)
+}
@@ -37,7 +37,12 @@ func Resource() *schema.Resource {
 				)
 			}
 
-			return resource.Create(exec.Command("eksctl", args...), d, "")
+			cmd, err := newEksctlCommand(resource.ProviderConfigFromMeta(meta), args...)
+			if err != nil {
+				return err
+			}
+
+			return resource.Create(cmd, d, "")
 		},
 		Delete: func(d *schema.ResourceData, meta interface{}) error {
 			a := ReadIAMServiceAccount(d)
@@ -50,7 +55,12 @@ func Resource() *schema.Resource {
 				"--namespace", a.Namespace,
 			}
 
-			return resource.Delete(exec.Command("eksctl", args...), d)
+			cmd, err := newEksctlCommand(resource.ProviderConfigFromMeta(meta), args...)
+			if err != nil {
+				return err
+			}
+
+			return resource.Delete(cmd, d)
 		},
 		Read: func(d *schema.ResourceData, meta interface{}) error {
 			return nil
@@ -108,3 +118,15 @@ func ReadIAMServiceAccount(d *schema.ResourceData) *IAMServiceAccount {
 	a.OverrideExistingServiceAccounts = d.Get(KeyOverrideExistingServiceAccounts).(bool)
 	return &a
 }
+
+func newEksctlCommand(p *resource.ProviderConfig, args ...string) (*exec.Cmd, error) {
+	env, err := p.Environ("", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("eksctl", args...)
+	cmd.Env = env
+
+	return p.WithAuditLog(p.WithCredentialHelper(cmd), ""), nil
+}
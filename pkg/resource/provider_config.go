@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/mumoshu/terraform-provider-eksctl/pkg/awsclicompat"
+)
+
+// ProviderConfig is the configuration of a provider instance. The provider's ConfigureFunc returns it as the meta
+// passed to every resource and data source, so that aliased providers never see each other's settings.
+//
+// A nil ProviderConfig is valid and leaves everything to the environment.
+type ProviderConfig struct {
+	// Region and Profile are used by the resources that don't specify their own
+	Region  string
+	Profile string
+
+	// AWS configures the AWS sessions and the environment of subprocesses
+	AWS *awsclicompat.Config
+
+	// AWSSession is the session for the provider's region and profile
+	AWSSession *session.Session
+
+	// WorkDir is where the provider and its subprocesses write the generated files, which defaults to $TMPDIR
+	WorkDir string
+	// WorkDirCleanup is either WorkDirCleanupAlways or WorkDirCleanupNever, and defaults to the former
+	WorkDirCleanup string
+
+	// CredentialHelper is the command that wraps every eksctl invocation to provide it short-lived credentials,
+	// like `aws-vault exec <profile> --`. The eksctl command line is appended to it.
+	CredentialHelper []string
+
+	// AuditLog records the commands run on behalf of the provider, which is nil when disabled
+	AuditLog *AuditLog
+}
+
+// ProviderConfigFromMeta returns the config of the provider instance that the resource belongs to.
+// The meta is nil when the CRUD functions are called without a configured provider, like in unit tests.
+func ProviderConfigFromMeta(meta interface{}) *ProviderConfig {
+	p, _ := meta.(*ProviderConfig)
+
+	return p
+}
+
+func (p *ProviderConfig) defaults() (string, string) {
+	if p == nil {
+		return "", ""
+	}
+
+	return p.Region, p.Profile
+}
+
+// AWSConfig returns the config of the AWS sessions and the environment of subprocesses, which is nil when unconfigured
+func (p *ProviderConfig) AWSConfig() *awsclicompat.Config {
+	if p == nil {
+		return nil
+	}
+
+	return p.AWS
+}
+
+// NewAWSSession returns the session for the region and the profile, which additionally assumes the roles
+func (p *ProviderConfig) NewAWSSession(region, profile string, roles []awsclicompat.AssumeRole) *session.Session {
+	return p.AWSConfig().NewSessionWithAssumeRoles(region, profile, roles)
+}
+
+// Environ returns the environment of subprocesses that operate on behalf of the region, the profile, and the roles
+func (p *ProviderConfig) Environ(region, profile string, roles []awsclicompat.AssumeRole) ([]string, error) {
+	env, err := p.AWSConfig().EnvironForAssumeRoles(region, profile, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.withTMPDIR(env), nil
+}
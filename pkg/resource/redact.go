@@ -0,0 +1,57 @@
+package resource
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const redactedLine = "[REDACTED]"
+
+var (
+	redactOutputMu sync.RWMutex
+	redactOutput   bool
+
+	// sensitiveLinePattern matches lines that are likely to contain tokens or credentials,
+	// like the ones in a kubeconfig or `aws eks get-token` output
+	sensitiveLinePattern = regexp.MustCompile(`(?i)(token|password|secret|certificate-authority-data|client-certificate-data|client-key-data)`)
+)
+
+// SetRedactOutput enables or disables redaction of sensitive lines in the output of commands run by the provider.
+func SetRedactOutput(enabled bool) {
+	redactOutputMu.Lock()
+	defer redactOutputMu.Unlock()
+
+	redactOutput = enabled
+}
+
+func redactOutputEnabled() bool {
+	redactOutputMu.RLock()
+	defer redactOutputMu.RUnlock()
+
+	return redactOutput
+}
+
+// Redact replaces every line that seems to contain tokens or credentials with a placeholder,
+// only when the redaction is enabled via SetRedactOutput.
+func Redact(s string) string {
+	if !redactOutputEnabled() {
+		return s
+	}
+
+	return redactLines(s)
+}
+
+// redactLines replaces every line that seems to contain tokens or credentials with a placeholder,
+// regardless of SetRedactOutput. It's used for the outputs persisted in the state.
+func redactLines(s string) string {
+	lines := strings.Split(s, "\n")
+
+	for i, l := range lines {
+		if sensitiveLinePattern.MatchString(l) {
+			lines[i] = redactedLine
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,117 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// RegionNotFoundMessage explains all the places the region is looked up from, so that a plan without region
+// tells the user how to fix it instead of failing with an opaque AWS API error in the middle of an apply.
+const RegionNotFoundMessage = `no AWS region found: set the "region" attribute of either the resource or the provider, ` +
+	`AWS_REGION, AWS_DEFAULT_REGION, or "region" in the shared config (~/.aws/config), ` +
+	`or run on an EC2 instance with the instance metadata service enabled`
+
+// imdsRegionTimeout bounds the IMDS lookup, as it never responds outside of EC2
+const imdsRegionTimeout = time.Second
+
+var (
+	fallbackRegionsMu sync.Mutex
+	fallbackRegions   = map[string]string{}
+
+	// lookupFallbackRegion is overridden in tests
+	lookupFallbackRegion = regionFromSharedConfigOrIMDS
+)
+
+// fallbackRegion returns the region for the profile from the shared config or IMDS.
+// The result, including the empty one, is cached per profile, so that each plan hits IMDS at most once.
+func fallbackRegion(profile string) string {
+	fallbackRegionsMu.Lock()
+	defer fallbackRegionsMu.Unlock()
+
+	if r, ok := fallbackRegions[profile]; ok {
+		return r
+	}
+
+	r := lookupFallbackRegion(profile)
+
+	fallbackRegions[profile] = r
+
+	return r
+}
+
+func regionFromSharedConfigOrIMDS(profile string) string {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:           profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		logDebug("failed loading shared config for region", err.Error())
+
+		return ""
+	}
+
+	if r := aws.StringValue(sess.Config.Region); r != "" {
+		return r
+	}
+
+	imds := ec2metadata.New(sess, aws.NewConfig().
+		WithHTTPClient(&http.Client{Timeout: imdsRegionTimeout}).
+		WithMaxRetries(0))
+
+	r, err := imds.Region()
+	if err != nil {
+		logDebug("failed getting region from instance metadata", err.Error())
+
+		return ""
+	}
+
+	return r
+}
+
+// ValidateAWSRegion fails the plan when no region is resolved for the resource.
+// A region that is unknown until apply, like one interpolated from another resource, is never an error.
+func ValidateAWSRegion(d Read, p *ProviderConfig) error {
+	if k, ok := d.(interface{ NewValueKnown(string) bool }); ok && !k.NewValueKnown("region") {
+		return nil
+	}
+
+	if region, _ := GetAWSRegionAndProfile(d, p); region == "" {
+		return fmt.Errorf("validating region: %s", RegionNotFoundMessage)
+	}
+
+	return nil
+}
+
+type regionDiff interface {
+	Read
+
+	Id() string
+	NewValueKnown(string) bool
+	SetNew(string, interface{}) error
+}
+
+// PlanAWSRegion plans the `region` of a new resource that doesn't specify its own to be the resolved one,
+// so that the state records the region the resource is created in, even after the provider's region is changed.
+// The `region` attribute must be computed.
+func PlanAWSRegion(d regionDiff, p *ProviderConfig) error {
+	if d.Id() != "" || !d.NewValueKnown("region") {
+		return nil
+	}
+
+	if v, _ := d.Get("region").(string); v != "" {
+		return nil
+	}
+
+	region, _ := GetAWSRegionAndProfile(d, p)
+	if region == "" {
+		return nil
+	}
+
+	return d.SetNew("region", region)
+}
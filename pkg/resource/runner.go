@@ -0,0 +1,102 @@
+package resource
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandRunner runs the eksctl, kubectl, and hook commands of the provider.
+//
+// Replace it via SetCommandRunner to run the provider against a fake eksctl in tests, or to intercept the invocations,
+// like running eksctl inside a container or over SSM, from a custom build of the provider.
+//
+// The commands carry the credential helper and the audit log of the provider in the TF_EKSCTL_* environment variables,
+// which ExecRunner removes before running them.
+type CommandRunner interface {
+	// Run runs the command to completion and returns its combined output, along with the standard output alone.
+	// A zero timeout never interrupts the command, except when Terraform is canceled.
+	Run(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error)
+}
+
+// CommandRunnerFunc is the CommandRunner implemented by a function
+type CommandRunnerFunc func(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error)
+
+func (f CommandRunnerFunc) Run(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error) {
+	return f(cmd, timeout)
+}
+
+// ExecRunner is the default CommandRunner that runs the command as a subprocess.
+// Runners that rewrite the command, like the ones wrapping it with `docker run`, should delegate to it
+// so that the command is still interruptible and recorded into the transcripts and the audit log.
+var ExecRunner CommandRunner = CommandRunnerFunc(runExec)
+
+var (
+	commandRunnerMu sync.RWMutex
+	commandRunner   = ExecRunner
+)
+
+// SetCommandRunner replaces the CommandRunner used by Run and RunWithTimeout, and returns the previous one.
+// A nil runner restores ExecRunner.
+func SetCommandRunner(r CommandRunner) CommandRunner {
+	commandRunnerMu.Lock()
+	defer commandRunnerMu.Unlock()
+
+	prev := commandRunner
+
+	if r == nil {
+		r = ExecRunner
+	}
+
+	commandRunner = r
+
+	return prev
+}
+
+func getCommandRunner() CommandRunner {
+	commandRunnerMu.RLock()
+	defer commandRunnerMu.RUnlock()
+
+	return commandRunner
+}
+
+// setCommandEnv sets the environment variable of the command, which inherits the environment of the provider when unset
+func setCommandEnv(cmd *exec.Cmd, name, value string) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+
+	popCommandEnv(cmd, name)
+
+	cmd.Env = append(cmd.Env, name+"="+value)
+}
+
+// popCommandEnv removes the environment variable from the command and returns its value
+func popCommandEnv(cmd *exec.Cmd, name string) (string, bool) {
+	if cmd.Env == nil {
+		return "", false
+	}
+
+	var (
+		value string
+		found bool
+	)
+
+	env := make([]string, 0, len(cmd.Env))
+
+	for _, kv := range cmd.Env {
+		if strings.HasPrefix(kv, name+"=") {
+			value, found = strings.TrimPrefix(kv, name+"="), true
+
+			continue
+		}
+
+		env = append(env, kv)
+	}
+
+	cmd.Env = env
+
+	return value, found
+}
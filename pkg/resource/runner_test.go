@@ -0,0 +1,45 @@
+package resource
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCommandRunner(t *testing.T) {
+	var ran []string
+
+	fake := CommandRunnerFunc(func(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error) {
+		ran = append(ran, strings.Join(cmd.Args, " "))
+
+		if cmd.Args[1] == "delete" {
+			return nil, errors.New("fake failure")
+		}
+
+		return &CommandResult{Output: `[{"Name":"primary"}]`}, nil
+	})
+
+	prev := SetCommandRunner(fake)
+	defer SetCommandRunner(prev)
+
+	res, err := Run(exec.Command("eksctl", "get", "cluster", "-o", "json"))
+	require.NoError(t, err)
+	assert.Equal(t, `[{"Name":"primary"}]`, res.Output)
+
+	_, err = RunWithTimeout(exec.Command("eksctl", "delete", "cluster"), time.Minute)
+	assert.EqualError(t, err, "fake failure")
+
+	assert.Equal(t, []string{"eksctl get cluster -o json", "eksctl delete cluster"}, ran)
+
+	// nil restores the default runner
+	SetCommandRunner(nil)
+
+	res, err = Run(exec.Command("bash", "-c", "echo ok"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok\n", res.Output)
+}
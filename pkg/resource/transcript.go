@@ -0,0 +1,173 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	KeyLastRunLog       = "last_run_log"
+	KeyRunLogPath       = "run_log_path"
+	transcriptTailLines = 20
+)
+
+// TranscriptEntry is the record of a command run by the provider
+type TranscriptEntry struct {
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exit_code"`
+	StartedAt  time.Time `json:"started_at"`
+	Duration   string    `json:"duration"`
+	OutputTail string    `json:"output_tail"`
+}
+
+// Transcript records all the commands run while it's started, so that failed applies can be triaged
+// without rerunning with TF_LOG=debug.
+// Commands run for other resources concurrently, like with `terraform apply -parallelism=N`, are recorded as well.
+type Transcript struct {
+	mu      sync.Mutex
+	Entries []TranscriptEntry
+}
+
+var (
+	transcriptsMu     sync.Mutex
+	activeTranscripts = map[*Transcript]struct{}{}
+)
+
+// StartTranscript starts recording commands until Stop is called
+func StartTranscript() *Transcript {
+	t := &Transcript{}
+
+	transcriptsMu.Lock()
+	defer transcriptsMu.Unlock()
+
+	activeTranscripts[t] = struct{}{}
+
+	return t
+}
+
+func (t *Transcript) Stop() {
+	transcriptsMu.Lock()
+	defer transcriptsMu.Unlock()
+
+	delete(activeTranscripts, t)
+}
+
+func recordTranscriptEntry(e TranscriptEntry) {
+	transcriptsMu.Lock()
+	defer transcriptsMu.Unlock()
+
+	for t := range activeTranscripts {
+		t.mu.Lock()
+		t.Entries = append(t.Entries, e)
+		t.mu.Unlock()
+	}
+}
+
+func newTranscriptEntry(args []string, exitCode int, startedAt time.Time, output string) TranscriptEntry {
+	return TranscriptEntry{
+		Command:    strings.Join(redactArgs(args), " "),
+		ExitCode:   exitCode,
+		StartedAt:  startedAt.UTC(),
+		Duration:   time.Since(startedAt).Round(time.Millisecond).String(),
+		OutputTail: redactLines(tailLines(output, transcriptTailLines)),
+	}
+}
+
+// String returns the human-readable transcript
+func (t *Transcript) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+
+	for _, e := range t.Entries {
+		fmt.Fprintf(&b, "$ %s\n", e.Command)
+		fmt.Fprintf(&b, "# started at %s, took %s, exited with %d\n", e.StartedAt.Format(time.RFC3339), e.Duration, e.ExitCode)
+
+		if e.OutputTail != "" {
+			b.WriteString(e.OutputTail)
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// AppendToFile appends the entries to the file as JSON lines
+func (t *Transcript) AppendToFile(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening run log %s: %w", path, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+
+	for _, e := range t.Entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("writing run log %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// SaveTranscript stops the transcript and saves it into `last_run_log`, and appends it to `run_log_path` when set.
+// Failures are only logged, so that they never hide the error of the operation.
+func SaveTranscript(t *Transcript, d interface {
+	Get(string) interface{}
+	Set(string, interface{}) error
+}) {
+	t.Stop()
+
+	if err := d.Set(KeyLastRunLog, t.String()); err != nil {
+		logDebug("failed setting "+KeyLastRunLog, err.Error())
+	}
+
+	if path, _ := d.Get(KeyRunLogPath).(string); path != "" {
+		if err := t.AppendToFile(path); err != nil {
+			logDebug("failed saving run log", err.Error())
+		}
+	}
+}
+
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// redactArgs masks the values of flags that seem to contain credentials, like `--token=...` and `--password ...`
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		a := redacted[i]
+
+		if !strings.HasPrefix(a, "-") || !sensitiveLinePattern.MatchString(a) {
+			continue
+		}
+
+		if eq := strings.Index(a, "="); eq >= 0 {
+			redacted[i] = a[:eq+1] + redactedLine
+		} else if i+1 < len(redacted) && !strings.HasPrefix(redacted[i+1], "-") {
+			redacted[i+1] = redactedLine
+			i++
+		}
+	}
+
+	return redacted
+}
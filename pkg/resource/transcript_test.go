@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"eksctl", "create", "cluster", "--token=[REDACTED]", "--password", "[REDACTED]", "--region", "us-east-2"},
+		redactArgs([]string{"eksctl", "create", "cluster", "--token=abc", "--password", "secret", "--region", "us-east-2"}),
+	)
+}
+
+func TestTailLines(t *testing.T) {
+	assert.Equal(t, "b\nc", tailLines("a\nb\nc\n", 2))
+	assert.Equal(t, "a", tailLines("a", 2))
+}
+
+func TestTranscript(t *testing.T) {
+	tr := StartTranscript()
+
+	_, err := Run(exec.Command("bash", "-c", "echo ok"))
+	assert.NoError(t, err)
+
+	_, err = Run(exec.Command("bash", "-c", "echo token: abc; echo failed; exit 3"))
+	assert.Error(t, err)
+
+	tr.Stop()
+
+	// Commands run after Stop are not recorded
+	_, err = Run(exec.Command("bash", "-c", "echo ignored"))
+	assert.NoError(t, err)
+
+	if assert.Len(t, tr.Entries, 2) {
+		assert.Equal(t, "bash -c echo ok", tr.Entries[0].Command)
+		assert.Equal(t, 0, tr.Entries[0].ExitCode)
+		assert.Equal(t, "ok", tr.Entries[0].OutputTail)
+
+		assert.Equal(t, 3, tr.Entries[1].ExitCode)
+		assert.Equal(t, "[REDACTED]\nfailed", tr.Entries[1].OutputTail)
+	}
+
+	assert.True(t, strings.HasPrefix(tr.String(), "$ bash -c echo ok\n# started at "))
+
+	dir, err := ioutil.TempDir("", "transcript")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "run.log")
+
+	assert.NoError(t, tr.AppendToFile(path))
+	assert.NoError(t, tr.AppendToFile(path))
+
+	bs, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(bs)), "\n"), 4)
+}
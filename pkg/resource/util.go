@@ -2,8 +2,10 @@ package resource
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/armon/circbuf"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -14,6 +16,7 @@ import (
 	"os/exec"
 	"strings"
 	"syscall"
+	"time"
 )
 
 func Create(cmd *exec.Cmd, d *schema.ResourceData, newID string) error {
@@ -21,6 +24,12 @@ func Create(cmd *exec.Cmd, d *schema.ResourceData, newID string) error {
 
 	st, err := Run(cmd)
 	if err != nil {
+		// Record the partially created resource so that it can be refreshed or destroyed later,
+		// instead of losing track of what eksctl had created before it was interrupted.
+		if errors.Is(err, ErrCanceled) && newID != "" {
+			d.SetId(newID)
+		}
+
 		return err
 	}
 
@@ -49,16 +58,39 @@ func Delete(cmd *exec.Cmd, d *schema.ResourceData) error {
 	return nil
 }
 
+// Run runs the command with the CommandRunner set via SetCommandRunner
 func Run(cmd *exec.Cmd) (*CommandResult, error) {
+	return RunWithTimeout(cmd, 0)
+}
+
+// RunWithTimeout is Run that interrupts the command when it doesn't finish within the timeout.
+// A zero timeout never interrupts the command, except when Terraform is canceled.
+func RunWithTimeout(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error) {
+	return getCommandRunner().Run(cmd, timeout)
+}
+
+// runExec runs the command as a subprocess, streaming its output to the debug log and
+// recording it into the transcripts and the audit log
+func runExec(cmd *exec.Cmd, timeout time.Duration) (*CommandResult, error) {
 	const maxBufSize = 8 * 1024
 
+	cmd, err := wrapWithCredentialHelper(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog, resourceID := popAuditLog(cmd)
+
 	// Setup the command
 	pr, pw, err := os.Pipe()
 	if err != nil {
 		return nil, err
 	}
+	// Capture stdout alone too, so that the callers can parse it without the logs in stderr
+	var stdout bytes.Buffer
+
 	cmd.Stderr = pw
-	cmd.Stdout = pw
+	cmd.Stdout = io.MultiWriter(pw, &stdout)
 
 	output, _ := circbuf.NewBuffer(maxBufSize)
 
@@ -76,8 +108,19 @@ func Run(cmd *exec.Cmd) (*CommandResult, error) {
 
 	log.Printf("[DEBUG] starting command %q", cmdToLog)
 
-	// Execute the command to completion
-	runErr := cmd.Run()
+	startedAt := time.Now()
+
+	ctx := getStopContext()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Execute the command to completion, or until Terraform is canceled or the command timed out
+	runErr := runInterruptible(ctx, cmd, CancelGracePeriod)
 
 	logDebug("closing pipe writer", strings.Join(cmd.Args, " "))
 
@@ -92,8 +135,17 @@ func Run(cmd *exec.Cmd) (*CommandResult, error) {
 		//case <-ctx.Done():
 	}
 
-	out := output.String()
+	// The output is redacted only where it's logged or recorded, as the callers parse the raw output
+	raw := output.String()
+	out := Redact(raw)
 	log.Printf("[DEBUG] command %q finished with output: \"%s\"", cmdToLog, out)
+
+	if errors.Is(runErr, ErrCanceled) || errors.Is(runErr, ErrTimedOut) {
+		recordCommand(cmd, auditLog, resourceID, -1, startedAt, out)
+
+		return nil, fmt.Errorf("running %q: %w\n%s", cmdToLog, runErr, out)
+	}
+
 	var exitStatus int
 	if runErr != nil {
 		switch ee := runErr.(type) {
@@ -103,21 +155,32 @@ func Run(cmd *exec.Cmd) (*CommandResult, error) {
 			waitStatus := ee.Sys().(syscall.WaitStatus)
 			exitStatus = waitStatus.ExitStatus()
 			if exitStatus != 0 {
+				recordCommand(cmd, auditLog, resourceID, exitStatus, startedAt, out)
+
 				return nil, fmt.Errorf("running %q: %v\n%s", cmdToLog, runErr, out)
 			}
 		default:
+			recordCommand(cmd, auditLog, resourceID, -1, startedAt, out)
+
 			return nil, fmt.Errorf("running %q: %v\n%s", cmdToLog, runErr, out)
 		}
 	}
 
-	res := NewCommandResult()
-	res.Output = out
+	recordCommand(cmd, auditLog, resourceID, exitStatus, startedAt, out)
 
-	log.Printf("[DEBUG] command new state: \"%v\"", res)
+	res := NewCommandResult()
+	res.Output = raw
+	res.Stdout = stdout.String()
 
 	return res, nil
 }
 
+// recordCommand records the finished command into the active transcripts and the audit log
+func recordCommand(cmd *exec.Cmd, auditLog *AuditLog, resourceID string, exitCode int, startedAt time.Time, out string) {
+	recordTranscriptEntry(newTranscriptEntry(cmd.Args, exitCode, startedAt, out))
+	auditLog.record(newAuditEntry(cmd, resourceID, exitCode, startedAt))
+}
+
 func Hash(data interface{}) string {
 	bs, err := json.Marshal(data)
 	if err != nil {
@@ -136,14 +199,16 @@ func copyOutput(o Outputter, r io.Reader, doneCh chan<- struct{}) {
 	}
 }
 
+// SetOutput stores the output of a command in the state. Lines that seem to contain tokens or credentials are always redacted,
+// as the state is persisted regardless of redact_output.
 func SetOutput(d *schema.ResourceData, v string) {
-	d.Set(KeyOutput, v)
+	d.Set(KeyOutput, redactLines(v))
 }
 
 type debugOut struct{}
 
 func (o debugOut) Output(line string) {
-	logDebug("eksctl", line)
+	logDebug("eksctl", Redact(line))
 }
 
 type Outputter interface {
@@ -3,6 +3,7 @@ package resource
 import (
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"os/exec"
 	"strings"
 	"testing"
@@ -16,18 +17,20 @@ func TestRun_err(t *testing.T) {
 		}
 		repeats = repeats + "stdout"
 	}
-	want := fmt.Sprintf(`running "/bin/bash bash -c echo stdout; echo stdout; echo stderr 1>&2; exit 1": exit status 1
-%s
-stderr
-`, repeats)
-
 	for i := 0; i < 10; i++ {
 		t.Run(fmt.Sprintf("%3d", i), func(t *testing.T) {
 			t.Parallel()
 
-			cmd := exec.Command("bash", "-c", "echo stdout; echo stdout; echo stderr 1>&2; exit 1")
+			// Sleep to keep the order of stdout and stderr in the combined output deterministic
+			cmd := exec.Command("bash", "-c", "echo stdout; echo stdout; sleep 0.1; echo stderr 1>&2; exit 1")
 			//cmd.Stdin = bytes.NewReader([]byte("foo"))
 
+			// bash is /bin/bash or /usr/bin/bash depending on the distribution
+			want := fmt.Sprintf(`running "%s bash -c echo stdout; echo stdout; sleep 0.1; echo stderr 1>&2; exit 1": exit status 1
+%s
+stderr
+`, cmd.Path, repeats)
+
 			if _, err := Run(cmd); err != nil {
 				if d := cmp.Diff(want, err.Error()); d != "" {
 					t.Fatalf("running %s %s:\n%s", cmd.Path, strings.Join(cmd.Args, " "), d)
@@ -55,14 +58,67 @@ stderr
 		t.Run(fmt.Sprintf("%3d", i), func(t *testing.T) {
 			t.Parallel()
 
-			cmd := exec.Command("bash", "-c", "echo stdout; echo stdout; echo stderr 1>&2")
+			// stdout is copied to the combined output via a separate pipe so that it's also captured alone,
+			// which makes its interleaving with stderr best-effort. Sleep to keep the order deterministic.
+			cmd := exec.Command("bash", "-c", "echo stdout; echo stdout; sleep 0.1; echo stderr 1>&2")
 			//cmd.Stdin = bytes.NewReader([]byte("foo"))
 
 			if r, err := Run(cmd); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			} else if d := cmp.Diff(want, r.Output); d != "" {
 				t.Fatalf("unexpected output:\n%s", d)
+			} else if d := cmp.Diff(repeats+"\n", r.Stdout); d != "" {
+				t.Fatalf("unexpected stdout:\n%s", d)
 			}
 		})
 	}
 }
+
+func TestRun_redact(t *testing.T) {
+	SetRedactOutput(true)
+	defer SetRedactOutput(false)
+
+	cmd := exec.Command("bash", "-c", "echo line1; echo 'token: abcdef'; echo line3")
+
+	r, err := Run(cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The callers get the raw output to parse
+	want := `line1
+token: abcdef
+line3
+`
+
+	if d := cmp.Diff(want, r.Output); d != "" {
+		t.Fatalf("unexpected output:\n%s", d)
+	}
+
+	// The token is built within the script so that it doesn't appear in the command line in the error
+	cmd = exec.Command("bash", "-c", "t=abc; echo line1; echo \"token: ${t}def\"; exit 1")
+
+	_, err = Run(cmd)
+	if err == nil {
+		t.Fatal("no expected error occuered")
+	}
+
+	if strings.Contains(err.Error(), "abcdef") || !strings.Contains(err.Error(), "line1\n[REDACTED]") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+		KeyOutput: {Type: schema.TypeString, Computed: true},
+	}, map[string]interface{}{})
+
+	// The output in the state is redacted even when redact_output is disabled
+	SetOutput(d, "line1\ntoken: abcdef\nline3\n")
+
+	want := "line1\n[REDACTED]\nline3\n"
+
+	if diff := cmp.Diff(want, d.Get(KeyOutput).(string)); diff != "" {
+		t.Fatalf("unexpected output:\n%s", diff)
+	}
+}
@@ -9,6 +9,9 @@ import (
 // CommandResult is a wrapper around both the input and output attributes that are relavent for updates
 type CommandResult struct {
 	Output string
+	// Stdout is the standard output alone, for parsing the machine-readable output like `-o json`
+	// without the logs and warnings written to the standard error
+	Stdout string
 }
 
 // NewCommandResult is the constructor for CommandResult
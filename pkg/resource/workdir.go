@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	// WorkDirCleanupAlways removes the generated files as soon as they are no longer used
+	WorkDirCleanupAlways = "always"
+	// WorkDirCleanupNever keeps the generated files in the work dir, for debugging and auditing
+	WorkDirCleanupNever = "never"
+)
+
+// CreateWorkDir creates the directory where the provider writes the generated files like cluster.yaml files and
+// kubeconfigs, in place of $TMPDIR, when missing.
+func CreateWorkDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating work dir: %w", err)
+	}
+
+	return nil
+}
+
+// WorkDirPath returns the directory for the generated files, which defaults to $TMPDIR
+func (p *ProviderConfig) WorkDirPath() string {
+	if p != nil && p.WorkDir != "" {
+		return p.WorkDir
+	}
+
+	return os.TempDir()
+}
+
+// TempFile creates an empty file in the work dir and returns its path.
+// Call RemoveTempFile once it's no longer used.
+func (p *ProviderConfig) TempFile(pattern string) (string, error) {
+	f, err := ioutil.TempFile(p.WorkDirPath(), pattern)
+	if err != nil {
+		return "", fmt.Errorf("creating file in work dir: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// TempDir creates a directory in the work dir and returns its path.
+// Call RemoveTempFile once it's no longer used.
+func (p *ProviderConfig) TempDir(pattern string) (string, error) {
+	dir, err := ioutil.TempDir(p.WorkDirPath(), pattern)
+	if err != nil {
+		return "", fmt.Errorf("creating directory in work dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+// RemoveTempFile removes the file or the directory created by TempFile or TempDir, unless the cleanup policy keeps it
+func (p *ProviderConfig) RemoveTempFile(path string) {
+	if p != nil && p.WorkDirCleanup == WorkDirCleanupNever {
+		log.Printf("[DEBUG] keeping %s as the work dir cleanup policy is %q", path, p.WorkDirCleanup)
+
+		return
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		log.Printf("[WARN] failed removing %s: %v", path, err)
+	}
+}
+
+// withTMPDIR points TMPDIR of subprocesses to the work dir, so that eksctl, kubectl, and helm write their temporary
+// files there too
+func (p *ProviderConfig) withTMPDIR(env []string) []string {
+	if p == nil || p.WorkDir == "" {
+		return env
+	}
+
+	result := make([]string, 0, len(env)+1)
+
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, "TMPDIR=") {
+			result = append(result, kv)
+		}
+	}
+
+	return append(result, "TMPDIR="+p.WorkDir)
+}
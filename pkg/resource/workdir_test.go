@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkDir(t *testing.T) {
+	parent, err := ioutil.TempDir("", "workdir")
+	assert.NoError(t, err)
+	defer os.RemoveAll(parent)
+
+	dir := filepath.Join(parent, "work")
+
+	assert.NoError(t, CreateWorkDir(dir))
+
+	p := &ProviderConfig{WorkDir: dir, WorkDirCleanup: WorkDirCleanupNever}
+
+	assert.Equal(t, dir, p.WorkDirPath())
+
+	kept, err := p.TempFile("kubeconfig-")
+	assert.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(kept))
+
+	p.RemoveTempFile(kept)
+	assert.FileExists(t, kept)
+
+	p = &ProviderConfig{WorkDir: dir, WorkDirCleanup: WorkDirCleanupAlways}
+
+	removed, err := p.TempDir("spec-source-")
+	assert.NoError(t, err)
+
+	p.RemoveTempFile(removed)
+
+	_, err = os.Stat(removed)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWorkDir_perProvider(t *testing.T) {
+	tmpdir := os.Getenv("TMPDIR")
+
+	a := &ProviderConfig{WorkDir: "/mnt/a"}
+	b := &ProviderConfig{}
+
+	envA, err := a.Environ("", "", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, envA, "TMPDIR=/mnt/a")
+
+	envB, err := b.Environ("", "", nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, envB, "TMPDIR=/mnt/a")
+
+	var nilConfig *ProviderConfig
+
+	assert.Equal(t, os.TempDir(), nilConfig.WorkDirPath())
+	assert.Equal(t, tmpdir, os.Getenv("TMPDIR"), "the work dir must not leak into the process environment")
+}